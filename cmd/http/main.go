@@ -2,64 +2,193 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
 	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/edwins-leonardi/finaid-api/internal/adapter/config"
 	"github.com/edwins-leonardi/finaid-api/internal/adapter/handler/http"
 	"github.com/edwins-leonardi/finaid-api/internal/adapter/logger"
-	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/postgres"
-	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/postgres/repository"
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/metrics"
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage"
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/blob"
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/tracing"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
 	"github.com/edwins-leonardi/finaid-api/internal/core/service"
 )
 
 func main() {
+	// run logs what failed, exiting only here lets its deferred calls close the storage and flush the traces first
+	if err := run(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// run starts the application and blocks until the server stops
+func run() error {
 	// Load App configuration
 	config, err := config.New()
 	if err != nil {
 		slog.Error("Error loading environment variables", "error", err)
-		os.Exit(1)
+		return err
 	}
 
 	logger.Set(config.App)
 
 	slog.Info("Starting the application", "app", config.App.Name, "env", config.App.Env)
 
-	// Initialize database connection
-	db, err := postgres.New(context.Background(), config.DB)
+	// Traces of the requests, the service calls and the SQL statements
+	shutdownTracing, err := tracing.New(context.Background(), config.App, config.Tracing)
 	if err != nil {
-		slog.Error("Error connecting to database", "error", err)
-		os.Exit(1)
+		slog.Error("Error initializing tracing", "exporter", config.Tracing.Exporter, "error", err)
+		return err
 	}
-	defer db.Close()
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("Error flushing traces", "error", err)
+		}
+	}()
+
+	// Initialize the storage backend
+	repos, err := storage.New(context.Background(), config.Storage, config.DB, slog.Default())
+	if err != nil {
+		slog.Error("Error initializing storage", "driver", config.Storage.Driver, "error", err)
+		return err
+	}
+
+	// "migrate" runs the migration subcommand instead of the server
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(context.Background(), repos.Migrator, os.Args[2:]); err != nil {
+			slog.Error("Error migrating the schema", "error", err)
+			return err
+		}
+		return nil
+	}
+
+	// Closing saves the snapshot of the memory backend, so only the server closes the storage
+	defer func() {
+		if err := repos.Close(); err != nil {
+			slog.Error("Error closing storage", "error", err)
+		}
+	}()
+
+	if config.DB.MigrateOnStart {
+		if err := repos.Migrator.Up(context.Background()); err != nil {
+			slog.Error("Error migrating the schema", "error", err)
+			return err
+		}
+	}
+
+	// Stop the server and the background jobs on interrupt
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Metrics of the HTTP requests, the storage and the stored data
+	appMetrics := metrics.New()
+	appMetrics.InstrumentRepositories(repos)
+	appMetrics.RegisterExpenseStats(ctx, repos.Expense)
+	if repos.Pool != nil {
+		appMetrics.RegisterPool(repos.Pool)
+	}
+
+	// Dependency injection, each service is traced
+	// Transactions and audit trail
+	txManager := repos.TxManager
+	auditRepo := repos.Audit
+	auditService := tracing.AuditService(service.NewAuditService(auditRepo))
+	auditHandler := http.NewAuditHandler(auditService)
 
-	// Dependency injection
 	// Person
-	personRepo := repository.NewPersonRepository(db)
-	personService := service.NewPersonService(personRepo)
+	personRepo := repos.Person
+	personService := tracing.PersonService(service.NewPersonService(personRepo, txManager, auditRepo))
 	personHandler := http.NewPersonHandler(personService)
 
 	// Account
-	accountRepo := repository.NewAccountRepository(db)
-	accountService := service.NewAccountService(accountRepo, personRepo)
+	accountRepo := repos.Account
+	accountService := tracing.AccountService(service.NewAccountService(accountRepo, personRepo, txManager, auditRepo))
 	accountHandler := http.NewAccountHandler(accountService)
 
 	// Expense Category
-	expenseCategoryRepo := repository.NewExpenseCategoryRepository(db.Pool)
-	expenseCategoryService := service.NewExpenseCategoryService(expenseCategoryRepo, slog.Default())
+	expenseCategoryRepo := repos.ExpenseCategory
+	expenseCategoryService := tracing.ExpenseCategoryService(service.NewExpenseCategoryService(expenseCategoryRepo, txManager, auditRepo))
 	expenseCategoryHandler := http.NewExpenseCategoryHandler(expenseCategoryService)
 
 	// Expense SubCategory
-	expenseSubCategoryRepo := repository.NewExpenseSubCategoryRepository(db.Pool)
-	expenseSubCategoryService := service.NewExpenseSubCategoryService(expenseSubCategoryRepo, expenseCategoryRepo, slog.Default())
+	expenseSubCategoryRepo := repos.ExpenseSubCategory
+	expenseSubCategoryService := tracing.ExpenseSubCategoryService(service.NewExpenseSubCategoryService(expenseSubCategoryRepo, expenseCategoryRepo, txManager, auditRepo))
 	expenseSubCategoryHandler := http.NewExpenseSubCategoryHandler(expenseSubCategoryService)
 
 	// Expense
-	expenseRepo := repository.NewExpenseRepository(db.Pool)
-	expenseService := service.NewExpenseService(expenseRepo, expenseCategoryRepo, expenseSubCategoryRepo, personRepo, accountRepo, slog.Default())
+	tagRepo := repos.Tag
+	payeeRepo := repos.Payee
+	expenseRepo := repos.Expense
+	expenseService := tracing.ExpenseService(service.NewExpenseService(expenseRepo, expenseCategoryRepo, expenseSubCategoryRepo, payeeRepo, personRepo, accountRepo, tagRepo, txManager, auditRepo))
 	expenseHandler := http.NewExpenseHandler(expenseService)
 
+	// Tag
+	tagService := tracing.TagService(service.NewTagService(tagRepo, expenseRepo, txManager, auditRepo))
+	tagHandler := http.NewTagHandler(tagService)
+
+	// Payee
+	payeeService := tracing.PayeeService(service.NewPayeeService(payeeRepo, expenseRepo, expenseCategoryRepo, expenseSubCategoryRepo, personRepo, txManager, auditRepo))
+	payeeHandler := http.NewPayeeHandler(payeeService)
+
+	// Merge
+	mergeRepo := repos.Merge
+	mergeService := tracing.MergeService(service.NewMergeService(mergeRepo, payeeRepo, expenseCategoryRepo, expenseSubCategoryRepo, expenseRepo, txManager, auditRepo))
+	mergeHandler := http.NewMergeHandler(mergeService)
+
+	// Saved View
+	savedViewRepo := repos.SavedView
+	savedViewService := tracing.SavedViewService(service.NewSavedViewService(savedViewRepo, expenseService, txManager, auditRepo))
+	savedViewHandler := http.NewSavedViewHandler(savedViewService)
+
+	// Settlement
+	settlementRepo := repos.Settlement
+	settlementService := tracing.SettlementService(service.NewSettlementService(settlementRepo, expenseRepo, personRepo, txManager, auditRepo))
+	settlementHandler := http.NewSettlementHandler(settlementService)
+
+	// Attachment
+	blobStore, err := blob.New(config.Attachments)
+	if err != nil {
+		slog.Error("Error initializing attachment store", "error", err)
+		return err
+	}
+	signingKey := []byte(config.Attachments.SigningKey)
+	if len(signingKey) == 0 {
+		slog.Warn("No attachment signing key set, download links stop working on restart")
+		signingKey = make([]byte, 32)
+		if _, err := rand.Read(signingKey); err != nil {
+			slog.Error("Error generating the attachment signing key", "error", err)
+			return err
+		}
+	}
+	attachmentRepo := repos.Attachment
+	attachmentService := tracing.AttachmentService(service.NewAttachmentService(attachmentRepo, expenseRepo, blobStore, txManager, auditRepo))
+	attachmentHandler := http.NewAttachmentHandler(attachmentService, signingKey, config.Attachments.LinkTTL)
+
+	// Idempotency keys for create endpoints
+	idempotencyRepo := repos.Idempotency
+	idempotencyService := tracing.IdempotencyService(service.NewIdempotencyService(idempotencyRepo, config.Idempotency.TTL))
+
+	// Purge job for soft-deleted data
+	purgeService := tracing.PurgeService(service.NewPurgeService(expenseRepo, attachmentRepo, blobStore, expenseSubCategoryRepo, expenseCategoryRepo, accountRepo, txManager))
+	if config.Purge.Retention > 0 && config.Purge.Interval > 0 {
+		go runPurgeJob(ctx, purgeService, config.Purge)
+	}
+
+	// Expiry of the idempotency keys, independent of the purge of soft-deleted data
+	if config.Idempotency.PurgeInterval > 0 {
+		go runIdempotencyExpiryJob(ctx, idempotencyService, config.Idempotency.PurgeInterval)
+	}
+
 	// Init router
 	router, err := http.NewRouter(
 		config.HTTP,
@@ -68,18 +197,75 @@ func main() {
 		expenseCategoryHandler,
 		expenseSubCategoryHandler,
 		*expenseHandler,
+		tagHandler,
+		payeeHandler,
+		mergeHandler,
+		savedViewHandler,
+		settlementHandler,
+		attachmentHandler,
+		auditHandler,
+		idempotencyService,
+		repos.Migrator,
+		appMetrics,
 	)
 	if err != nil {
 		slog.Error("Error initializing router", "error", err)
-		os.Exit(1)
+		return err
 	}
 
 	// Start server
 	listenAddr := fmt.Sprintf("%s:%s", config.HTTP.URL, config.HTTP.Port)
 	slog.Info("Starting the HTTP server", "listen_address", listenAddr)
-	err = router.Serve(listenAddr)
+	err = router.Serve(ctx, listenAddr)
 	if err != nil {
 		slog.Error("Error starting the HTTP server", "error", err)
-		os.Exit(1)
+		return err
+	}
+	slog.Info("Stopped the HTTP server")
+	return nil
+}
+
+// runIdempotencyExpiryJob periodically removes the expired idempotency keys and their stored responses
+func runIdempotencyExpiryJob(ctx context.Context, idempotencyService port.IdempotencyService, interval time.Duration) {
+	logger := slog.Default().With("job", "idempotency_expiry")
+	ctx = domain.WithLogger(ctx, logger)
+	logger.Info("Starting the idempotency expiry job", "interval", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := idempotencyService.PurgeExpired(ctx); err != nil {
+			logger.Error("Error purging expired idempotency keys", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runPurgeJob periodically hard deletes rows soft deleted longer ago than the configured retention
+func runPurgeJob(ctx context.Context, purgeService port.PurgeService, config *config.Purge) {
+	// The lines logged by the job, including the service ones, are told apart by their job attribute
+	logger := slog.Default().With("job", "purge")
+	ctx = domain.WithLogger(ctx, logger)
+	logger.Info("Starting the purge job", "retention", config.Retention, "interval", config.Interval)
+
+	ticker := time.NewTicker(config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := purgeService.Purge(ctx, config.Retention); err != nil {
+			logger.Error("Error purging soft-deleted data", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
 	}
 }
@@ -2,15 +2,22 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type (
 	Container struct {
-		App  *App
-		DB   *DB
-		HTTP *HTTP
+		App         *App
+		Storage     *Storage
+		DB          *DB
+		HTTP        *HTTP
+		Purge       *Purge
+		Idempotency *Idempotency
+		Attachments *Attachments
+		Tracing     *Tracing
 	}
 
 	// App contains all the environment variables for the application
@@ -19,6 +26,13 @@ type (
 		Env  string
 	}
 
+	// Storage contains the environment variables selecting where the data is kept
+	Storage struct {
+		Driver     string // Backend keeping the data, postgres, sqlite or memory
+		Snapshot   string // JSON file the memory backend is loaded from and saved to on shutdown, empty keeps nothing
+		SQLitePath string // Database file of the sqlite backend
+	}
+
 	// Database contains all the environment variables for the database
 	DB struct {
 		Connection string
@@ -27,6 +41,8 @@ type (
 		User       string
 		Password   string
 		Name       string
+
+		MigrateOnStart bool // Whether the server applies pending schema migrations before serving
 	}
 
 	HTTP struct {
@@ -35,6 +51,39 @@ type (
 		Port           string
 		AllowedOrigins string
 	}
+
+	// Purge contains the environment variables for the soft-deleted data purge job
+	Purge struct {
+		Retention time.Duration // How long soft-deleted rows are kept, 0, the default, disables the job but not the expiry of idempotency keys
+		Interval  time.Duration // How often the job runs
+	}
+
+	// Idempotency contains the environment variables for Idempotency-Key handling
+	Idempotency struct {
+		TTL           time.Duration // How long a stored response can be replayed
+		PurgeInterval time.Duration // How often expired keys are removed, 0 disables it
+	}
+
+	// Attachments contains the environment variables for storing and downloading attachments
+	Attachments struct {
+		Store       string        // Where the content is kept, local or s3
+		Dir         string        // Directory of the local store
+		S3Endpoint  string        // Base URL of the S3 compatible service, buckets are addressed by path
+		S3Region    string        // Region requests are signed for
+		S3Bucket    string        // Bucket the content is kept in
+		S3AccessKey string        // Access key ID of the S3 credentials
+		S3SecretKey string        // Secret access key of the S3 credentials
+		SigningKey  string        // Secret signing download links, links do not survive a restart when unset
+		LinkTTL     time.Duration // How long a download link stays valid
+	}
+
+	// Tracing contains the environment variables for recording and exporting traces
+	Tracing struct {
+		Exporter     string  // Where the spans are sent, otlp or none
+		OTLPEndpoint string  // host:port of the OTLP HTTP collector, OTEL_EXPORTER_OTLP_ENDPOINT applies when unset
+		OTLPInsecure bool    // Whether the collector is reached over plain HTTP
+		SampleRatio  float64 // Share of the traces started by the server that are recorded
+	}
 )
 
 // New creates a new container instance
@@ -51,6 +100,12 @@ func New() (*Container, error) {
 		Env:  os.Getenv("APP_ENV"),
 	}
 
+	storage := &Storage{
+		Driver:     envOr("STORAGE_DRIVER", "postgres"),
+		Snapshot:   os.Getenv("STORAGE_SNAPSHOT"),
+		SQLitePath: envOr("STORAGE_SQLITE_PATH", "data/finaid.db"),
+	}
+
 	db := &DB{
 		Connection: os.Getenv("DB_CONNECTION"),
 		Host:       os.Getenv("DB_HOST"),
@@ -67,9 +122,96 @@ func New() (*Container, error) {
 		AllowedOrigins: os.Getenv("HTTP_ALLOWED_ORIGINS"),
 	}
 
+	var err error
+	if db.MigrateOnStart, err = boolEnv("DB_MIGRATE_ON_START", false); err != nil {
+		return nil, err
+	}
+
+	purge := &Purge{}
+	// Purging destroys data for good, so the job only runs once a retention is chosen
+	if purge.Retention, err = durationEnv("PURGE_RETENTION", 0); err != nil {
+		return nil, err
+	}
+	if purge.Interval, err = durationEnv("PURGE_INTERVAL", 24*time.Hour); err != nil {
+		return nil, err
+	}
+
+	idempotency := &Idempotency{}
+	if idempotency.TTL, err = durationEnv("IDEMPOTENCY_TTL", 24*time.Hour); err != nil {
+		return nil, err
+	}
+	if idempotency.PurgeInterval, err = durationEnv("IDEMPOTENCY_PURGE_INTERVAL", time.Hour); err != nil {
+		return nil, err
+	}
+
+	attachments := &Attachments{
+		Store:       envOr("ATTACHMENTS_STORE", "local"),
+		Dir:         envOr("ATTACHMENTS_DIR", "data/attachments"),
+		S3Endpoint:  os.Getenv("ATTACHMENTS_S3_ENDPOINT"),
+		S3Region:    envOr("ATTACHMENTS_S3_REGION", "us-east-1"),
+		S3Bucket:    os.Getenv("ATTACHMENTS_S3_BUCKET"),
+		S3AccessKey: os.Getenv("ATTACHMENTS_S3_ACCESS_KEY"),
+		S3SecretKey: os.Getenv("ATTACHMENTS_S3_SECRET_KEY"),
+		SigningKey:  os.Getenv("ATTACHMENTS_SIGNING_KEY"),
+	}
+	if attachments.LinkTTL, err = durationEnv("ATTACHMENTS_LINK_TTL", 15*time.Minute); err != nil {
+		return nil, err
+	}
+
+	tracing := &Tracing{
+		Exporter:     envOr("TRACING_EXPORTER", "none"),
+		OTLPEndpoint: os.Getenv("TRACING_OTLP_ENDPOINT"),
+	}
+	if tracing.OTLPInsecure, err = boolEnv("TRACING_OTLP_INSECURE", false); err != nil {
+		return nil, err
+	}
+	if tracing.SampleRatio, err = floatEnv("TRACING_SAMPLE_RATIO", 1); err != nil {
+		return nil, err
+	}
+
 	return &Container{
-		App:  app,
-		DB:   db,
-		HTTP: http,
+		App:         app,
+		Storage:     storage,
+		DB:          db,
+		HTTP:        http,
+		Purge:       purge,
+		Idempotency: idempotency,
+		Attachments: attachments,
+		Tracing:     tracing,
 	}, nil
 }
+
+// durationEnv parses a duration environment variable, returning fallback when it is unset
+func durationEnv(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// boolEnv parses a boolean environment variable, returning fallback when it is unset
+func boolEnv(key string, fallback bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// floatEnv parses a floating point environment variable, returning fallback when it is unset
+func floatEnv(key string, fallback float64) (float64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// envOr returns the value of an environment variable, or fallback when it is unset
+func envOr(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -1,7 +1,6 @@
 package http
 
 import (
-	"log/slog"
 	"strconv"
 	"time"
 
@@ -45,7 +44,7 @@ type createAccountRequest struct {
 //	@Failure		500						{object}	errorResponse			"Internal server error"
 //	@Router			/accounts [post]
 func (h *AccountHandler) Create(ctx *gin.Context) {
-	slog.Info("Handling create account request")
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Handling create account request")
 	var req createAccountRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		validationError(ctx, err)
@@ -67,54 +66,49 @@ func (h *AccountHandler) Create(ctx *gin.Context) {
 		return
 	}
 
+	setETag(ctx, account.Version)
 	rsp := newAccountResponse(&account)
 	handleSuccess(ctx, rsp)
 }
 
 type listAccountsRequest struct {
-	Skip  uint64 `form:"skip" example:"0"`
-	Limit uint64 `form:"limit" example:"10"`
+	domain.PageRequest
+	IncludeDeleted bool `form:"include_deleted" example:"false"`
 }
 
 // List godoc
 //
 //	@Summary		List accounts
-//	@Description	get a list of accounts with pagination
+//	@Description	get a page of accounts, the Link header points to the first and next pages
 //	@Tags			Accounts
 //	@Produce		json
-//	@Param			skip	query		int	false	"Number of accounts to skip"
-//	@Param			limit	query		int	false	"Number of accounts to return"
-//	@Success		200		{array}		accountResponse	"Accounts listed"
+//	@Param			cursor	query		string	false	"Cursor returned as next_cursor by the previous page"
+//	@Param			limit	query		int	false	"Number of accounts to return, at most 100"
+//	@Param			include_total	query	bool	false	"Include the total number of accounts"
+//	@Param			include_deleted	query	bool	false	"Include soft-deleted accounts"
+//	@Success		200		{object}	domain.Page[accountResponse]	"Accounts listed"
 //	@Failure		400		{object}	errorResponse	"Validation error"
 //	@Failure		401		{object}	errorResponse	"Unauthorized error"
 //	@Failure		500		{object}	errorResponse	"Internal server error"
 //	@Router			/accounts [get]
 func (h *AccountHandler) List(ctx *gin.Context) {
-	slog.Info("Handling list accounts request")
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Handling list accounts request")
 	var req listAccountsRequest
 	if err := ctx.ShouldBindQuery(&req); err != nil {
 		validationError(ctx, err)
 		return
 	}
 
-	// Set default value for Limit if not provided
-	if req.Limit == 0 {
-		req.Limit = 10
-	}
-
-	accounts, err := h.svc.ListAccounts(ctx, req.Skip, req.Limit)
+	page, err := h.svc.ListAccounts(ctx, &req.PageRequest, req.IncludeDeleted)
 	if err != nil {
 		handleError(ctx, err)
 		return
 	}
 
-	slog.Info("RESPONSE from LIST", "count", len(accounts), "skip", req.Skip, "limit", req.Limit)
-	var rsp []accountResponse
-	for _, account := range accounts {
-		rsp = append(rsp, newAccountResponse(&account))
-	}
+	rsp := mapPage(page, newAccountResponse)
 
-	slog.Info("Accounts listed", "count", len(rsp), "skip", req.Skip, "limit", req.Limit)
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Accounts listed", "count", len(rsp.Items), "has_more", rsp.HasMore, "limit", req.Limit)
+	setPageLinks(ctx, page.NextCursor)
 	handleSuccess(ctx, rsp)
 }
 
@@ -132,7 +126,7 @@ func (h *AccountHandler) List(ctx *gin.Context) {
 //	@Failure		500	{object}	errorResponse	"Internal server error"
 //	@Router			/accounts/{id} [get]
 func (h *AccountHandler) GetByID(ctx *gin.Context) {
-	slog.Info("Handling get account by ID request")
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Handling get account by ID request")
 
 	// Get account ID from URL parameter
 	idParam := ctx.Param("id")
@@ -148,6 +142,7 @@ func (h *AccountHandler) GetByID(ctx *gin.Context) {
 		return
 	}
 
+	setETag(ctx, account.Version)
 	rsp := newAccountResponse(account)
 	handleSuccess(ctx, rsp)
 }
@@ -169,16 +164,19 @@ type updateAccountRequest struct {
 //	@Accept			json
 //	@Produce		json
 //	@Param			id					path		int						true	"Account ID"
+//	@Param			If-Match			header		string					true	"ETag of the account version being changed"
 //	@Param			updateAccountRequest	body		updateAccountRequest	true	"Update account request"
 //	@Success		200					{object}	accountResponse			"Account updated"
 //	@Failure		400					{object}	errorResponse			"Validation error"
 //	@Failure		401					{object}	errorResponse			"Unauthorized error"
 //	@Failure		404					{object}	errorResponse			"Data not found error"
 //	@Failure		409					{object}	errorResponse			"Data conflict error"
+//	@Failure		412					{object}	errorResponse			"Precondition failed error"
+//	@Failure		428					{object}	errorResponse			"Precondition required error"
 //	@Failure		500					{object}	errorResponse			"Internal server error"
 //	@Router			/accounts/{id} [put]
 func (h *AccountHandler) Update(ctx *gin.Context) {
-	slog.Info("Handling update account request")
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Handling update account request")
 
 	// Get account ID from URL parameter
 	idParam := ctx.Param("id")
@@ -194,6 +192,13 @@ func (h *AccountHandler) Update(ctx *gin.Context) {
 		return
 	}
 
+	// The update must be based on the current version of the account
+	version, err := ifMatchVersion(ctx)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
 	account := domain.Account{
 		ID:             id,
 		Name:           req.Name,
@@ -202,6 +207,7 @@ func (h *AccountHandler) Update(ctx *gin.Context) {
 		InitialBalance: req.InitialBalance,
 		PrimaryOwnerID: req.PrimaryOwnerID,
 		SecondOwnerID:  req.SecondOwnerID,
+		Version:        version,
 	}
 
 	updatedAccount, err := h.svc.UpdateAccount(ctx, &account)
@@ -210,6 +216,61 @@ func (h *AccountHandler) Update(ctx *gin.Context) {
 		return
 	}
 
+	setETag(ctx, updatedAccount.Version)
+	rsp := newAccountResponse(updatedAccount)
+	handleSuccess(ctx, rsp)
+}
+
+// Patch godoc
+//
+//	@Summary		Patch an account
+//	@Description	partially update an existing account with a JSON merge patch, only the present fields are changed
+//	@Tags			Accounts
+//	@Accept			application/merge-patch+json
+//	@Produce		json
+//	@Param			id			path		int							true	"Account ID"
+//	@Param			If-Match	header		string						true	"ETag of the account version being changed"
+//	@Param			patch		body		domain.PatchAccountRequest	true	"Merge patch"
+//	@Success		200			{object}	accountResponse				"Account updated"
+//	@Failure		400			{object}	errorResponse				"Validation error"
+//	@Failure		401			{object}	errorResponse				"Unauthorized error"
+//	@Failure		404			{object}	errorResponse				"Data not found error"
+//	@Failure		412			{object}	errorResponse				"Precondition failed error"
+//	@Failure		415			{object}	errorResponse				"Unsupported media type error"
+//	@Failure		428			{object}	errorResponse				"Precondition required error"
+//	@Failure		500			{object}	errorResponse				"Internal server error"
+//	@Router			/accounts/{id} [patch]
+func (h *AccountHandler) Patch(ctx *gin.Context) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Handling patch account request")
+
+	// Get account ID from URL parameter
+	idParam := ctx.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 64)
+	if err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	var req domain.PatchAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	// The patch must be based on the current version of the account
+	req.Version, err = ifMatchVersion(ctx)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	updatedAccount, err := h.svc.PatchAccount(ctx, id, &req)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	setETag(ctx, updatedAccount.Version)
 	rsp := newAccountResponse(updatedAccount)
 	handleSuccess(ctx, rsp)
 }
@@ -221,14 +282,17 @@ func (h *AccountHandler) Update(ctx *gin.Context) {
 //	@Tags			Accounts
 //	@Produce		json
 //	@Param			id	path		int	true	"Account ID"
+//	@Param			If-Match	header		string	true	"ETag of the account version being changed"
 //	@Success		200	{object}	map[string]string	"Account deleted successfully"
 //	@Failure		400	{object}	errorResponse		"Validation error"
 //	@Failure		401	{object}	errorResponse		"Unauthorized error"
 //	@Failure		404	{object}	errorResponse		"Data not found error"
+//	@Failure		412	{object}	errorResponse		"Precondition failed error"
+//	@Failure		428	{object}	errorResponse		"Precondition required error"
 //	@Failure		500	{object}	errorResponse		"Internal server error"
 //	@Router			/accounts/{id} [delete]
 func (h *AccountHandler) Delete(ctx *gin.Context) {
-	slog.Info("Handling delete account request")
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Handling delete account request")
 
 	// Get account ID from URL parameter
 	idParam := ctx.Param("id")
@@ -238,7 +302,14 @@ func (h *AccountHandler) Delete(ctx *gin.Context) {
 		return
 	}
 
-	err = h.svc.DeleteAccount(ctx, id)
+	// The deletion must be based on the current version of the account
+	version, err := ifMatchVersion(ctx)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	err = h.svc.DeleteAccount(ctx, id, version)
 	if err != nil {
 		handleError(ctx, err)
 		return
@@ -247,17 +318,54 @@ func (h *AccountHandler) Delete(ctx *gin.Context) {
 	handleSuccess(ctx, gin.H{"message": "Account deleted successfully"})
 }
 
+// Restore godoc
+//
+//	@Summary		Restore an account
+//	@Description	restore a soft-deleted account by ID
+//	@Tags			Accounts
+//	@Produce		json
+//	@Param			id	path		int	true	"Account ID"
+//	@Success		200	{object}	accountResponse	"Account restored"
+//	@Failure		400	{object}	errorResponse	"Validation error"
+//	@Failure		401	{object}	errorResponse	"Unauthorized error"
+//	@Failure		404	{object}	errorResponse	"Data not found error"
+//	@Failure		500	{object}	errorResponse	"Internal server error"
+//	@Router			/accounts/{id}/restore [post]
+func (h *AccountHandler) Restore(ctx *gin.Context) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Handling restore account request")
+
+	// Get account ID from URL parameter
+	idParam := ctx.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 64)
+	if err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	account, err := h.svc.RestoreAccount(ctx, id)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	setETag(ctx, account.Version)
+	rsp := newAccountResponse(account)
+	handleSuccess(ctx, rsp)
+}
+
 // accountResponse represents an account response body
 type accountResponse struct {
-	ID             uint64    `json:"id" example:"1"`
-	Name           string    `json:"name" example:"Main Checking Account"`
-	Currency       string    `json:"currency" example:"USD"`
-	AccountType    string    `json:"account_type" example:"checking"`
-	InitialBalance float64   `json:"initial_balance" example:"1000.50"`
-	PrimaryOwnerID uint64    `json:"primary_owner_id" example:"1"`
-	SecondOwnerID  *uint64   `json:"second_owner_id,omitempty" example:"2"`
-	CreatedAt      time.Time `json:"created_at" example:"1970-01-01T00:00:00Z"`
-	UpdatedAt      time.Time `json:"updated_at" example:"1970-01-01T00:00:00Z"`
+	ID             uint64     `json:"id" example:"1"`
+	Name           string     `json:"name" example:"Main Checking Account"`
+	Currency       string     `json:"currency" example:"USD"`
+	AccountType    string     `json:"account_type" example:"checking"`
+	InitialBalance float64    `json:"initial_balance" example:"1000.50"`
+	PrimaryOwnerID uint64     `json:"primary_owner_id" example:"1"`
+	SecondOwnerID  *uint64    `json:"second_owner_id,omitempty" example:"2"`
+	Version        int        `json:"version" example:"1"`
+	CreatedAt      time.Time  `json:"created_at" example:"1970-01-01T00:00:00Z"`
+	UpdatedAt      time.Time  `json:"updated_at" example:"1970-01-01T00:00:00Z"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty" example:"1970-01-01T00:00:00Z"`
 }
 
 // newAccountResponse is a helper function to create a response body for handling account data
@@ -270,7 +378,9 @@ func newAccountResponse(account *domain.Account) accountResponse {
 		InitialBalance: account.InitialBalance,
 		PrimaryOwnerID: account.PrimaryOwnerID,
 		SecondOwnerID:  account.SecondOwnerID,
+		Version:        account.Version,
 		CreatedAt:      account.CreatedAt,
 		UpdatedAt:      account.UpdatedAt,
+		DeletedAt:      account.DeletedAt,
 	}
 }
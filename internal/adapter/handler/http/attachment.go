@@ -0,0 +1,286 @@
+package http
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxMultipartOverhead is the room left for the multipart framing around an uploaded file
+	maxMultipartOverhead = 64 << 10
+	// maxUploadSize is the largest upload request body accepted
+	maxUploadSize = domain.MaxAttachmentSize + maxMultipartOverhead
+)
+
+// AttachmentHandler handles HTTP requests for the attachments of expenses
+type AttachmentHandler interface {
+	Upload(c *gin.Context)
+	List(c *gin.Context)
+	GetByID(c *gin.Context)
+	Download(c *gin.Context)
+	Delete(c *gin.Context)
+}
+
+type attachmentHandler struct {
+	service    port.AttachmentService
+	signingKey []byte
+	linkTTL    time.Duration
+}
+
+// NewAttachmentHandler creates a new attachment HTTP handler.
+// Downloads require a link signed with the signing key, valid for linkTTL. The signature only proves
+// that the link was handed out by List or GetByID, which are as open as the rest of the API: callers
+// are authenticated in front of the API, if at all.
+func NewAttachmentHandler(service port.AttachmentService, signingKey []byte, linkTTL time.Duration) AttachmentHandler {
+	return &attachmentHandler{
+		service:    service,
+		signingKey: signingKey,
+		linkTTL:    linkTTL,
+	}
+}
+
+// Upload handles POST /expenses/:id/attachments
+// @Summary Upload an attachment
+// @Description Attach a scanned receipt or invoice to an expense, as the file field of a multipart form.
+// @Description The type is sniffed from the content (PDF, JPEG, PNG, GIF or WebP) and files are limited to 10 MiB.
+// @Description The same file can only be attached once to an expense, identical content is stored once across expenses.
+// @Tags attachments
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Expense ID"
+// @Param file formData file true "Receipt or invoice"
+// @Success 201 {object} ResponseData{data=domain.Attachment}
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 409 {object} ResponseError
+// @Failure 413 {object} ResponseError
+// @Failure 415 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /api/v1/expenses/{id}/attachments [post]
+func (h *attachmentHandler) Upload(c *gin.Context) {
+	expenseID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		validationError(c, err)
+		return
+	}
+
+	// Stop reading oversized uploads early
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxUploadSize)
+	file, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			handleError(c, domain.ErrPayloadTooLarge)
+			return
+		}
+		validationError(c, err)
+		return
+	}
+
+	content, err := file.Open()
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	defer content.Close()
+
+	attachment, err := h.service.Upload(c.Request.Context(), expenseID, &domain.UploadAttachmentRequest{
+		FileName: file.Filename,
+		Size:     file.Size,
+		Content:  content,
+	})
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	h.signDownloadURL(attachment)
+
+	rsp := newResponse(true, "Attachment uploaded successfully", attachment)
+	c.JSON(http.StatusCreated, rsp)
+}
+
+// List handles GET /expenses/:id/attachments
+// @Summary List attachments
+// @Description Get the attachments of an expense, each with a signed download link
+// @Tags attachments
+// @Accept json
+// @Produce json
+// @Param id path int true "Expense ID"
+// @Success 200 {object} ResponseData{data=[]domain.Attachment}
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /api/v1/expenses/{id}/attachments [get]
+func (h *attachmentHandler) List(c *gin.Context) {
+	expenseID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		validationError(c, err)
+		return
+	}
+
+	attachments, err := h.service.List(c.Request.Context(), expenseID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	for _, attachment := range attachments {
+		h.signDownloadURL(attachment)
+	}
+	handleSuccess(c, attachments)
+}
+
+// GetByID handles GET /expenses/:id/attachments/:attachment_id
+// @Summary Get attachment by ID
+// @Description Get a specific attachment of an expense with a signed download link
+// @Tags attachments
+// @Accept json
+// @Produce json
+// @Param id path int true "Expense ID"
+// @Param attachment_id path int true "Attachment ID"
+// @Success 200 {object} ResponseData{data=domain.Attachment}
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /api/v1/expenses/{id}/attachments/{attachment_id} [get]
+func (h *attachmentHandler) GetByID(c *gin.Context) {
+	expenseID, id, err := attachmentIDs(c)
+	if err != nil {
+		validationError(c, err)
+		return
+	}
+
+	attachment, err := h.service.GetByID(c.Request.Context(), expenseID, id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	h.signDownloadURL(attachment)
+	handleSuccess(c, attachment)
+}
+
+// Download handles GET /expenses/:id/attachments/:attachment_id/download
+// @Summary Download attachment
+// @Description Download the content of an attachment through the signed link returned as download_url.
+// @Description The link is a bearer credential until it expires, it is not tied to the caller it was handed out to.
+// @Tags attachments
+// @Produce application/octet-stream
+// @Param id path int true "Expense ID"
+// @Param attachment_id path int true "Attachment ID"
+// @Param expires query int true "Expiry of the link, as a Unix time"
+// @Param signature query string true "Signature of the link"
+// @Success 200 {file} file
+// @Failure 400 {object} ResponseError
+// @Failure 403 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /api/v1/expenses/{id}/attachments/{attachment_id}/download [get]
+func (h *attachmentHandler) Download(c *gin.Context) {
+	expenseID, id, err := attachmentIDs(c)
+	if err != nil {
+		validationError(c, err)
+		return
+	}
+
+	// Only links handed out by this API, and not expired yet, give access to the content
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil || time.Now().Unix() > expires || !h.validSignature(expenseID, id, expires, c.Query("signature")) {
+		handleError(c, domain.ErrForbidden)
+		return
+	}
+
+	attachment, content, err := h.service.Download(c.Request.Context(), expenseID, id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// Delete handles DELETE /expenses/:id/attachments/:attachment_id
+// @Summary Delete attachment
+// @Description Delete an attachment of an expense, its content is removed once no expense uses it
+// @Tags attachments
+// @Accept json
+// @Produce json
+// @Param id path int true "Expense ID"
+// @Param attachment_id path int true "Attachment ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /api/v1/expenses/{id}/attachments/{attachment_id} [delete]
+func (h *attachmentHandler) Delete(c *gin.Context) {
+	expenseID, id, err := attachmentIDs(c)
+	if err != nil {
+		validationError(c, err)
+		return
+	}
+
+	err = h.service.Delete(c.Request.Context(), expenseID, id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// signDownloadURL sets the download link of an attachment, signed until the link TTL elapses
+func (h *attachmentHandler) signDownloadURL(attachment *domain.Attachment) {
+	expires := time.Now().Add(h.linkTTL).Unix()
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", h.signature(attachment.ExpenseID, attachment.ID, expires))
+
+	attachment.DownloadURL = fmt.Sprintf("/api/v1/expenses/%d/attachments/%d/download?%s", attachment.ExpenseID, attachment.ID, query.Encode())
+}
+
+// signature returns the signature of a download link
+func (h *attachmentHandler) signature(expenseID, id int, expires int64) string {
+	mac := hmac.New(sha256.New, h.signingKey)
+	fmt.Fprintf(mac, "%d:%d:%d", expenseID, id, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// validSignature reports whether a download link was signed with the signing key
+func (h *attachmentHandler) validSignature(expenseID, id int, expires int64, signature string) bool {
+	given, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	expected, _ := hex.DecodeString(h.signature(expenseID, id, expires))
+	return hmac.Equal(given, expected)
+}
+
+// attachmentIDs parses the expense and attachment IDs of the request path
+func attachmentIDs(c *gin.Context) (int, int, error) {
+	expenseID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return 0, 0, err
+	}
+	id, err := strconv.Atoi(c.Param("attachment_id"))
+	if err != nil {
+		return 0, 0, err
+	}
+	return expenseID, id, nil
+}
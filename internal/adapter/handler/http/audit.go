@@ -0,0 +1,56 @@
+package http
+
+import (
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+	"github.com/gin-gonic/gin"
+)
+
+// AuditHandler handles HTTP requests for the audit trail
+type AuditHandler interface {
+	List(c *gin.Context)
+}
+
+type auditHandler struct {
+	service port.AuditService
+}
+
+// NewAuditHandler creates a new audit HTTP handler
+func NewAuditHandler(service port.AuditService) AuditHandler {
+	return &auditHandler{
+		service: service,
+	}
+}
+
+// List handles GET /audit
+// @Summary Browse the audit trail
+// @Description Get a page of the history of create, update and delete operations, optionally for a single entity, newest first,
+// @Description the Link header points to the first and next pages
+// @Tags audit
+// @Accept json
+// @Produce json
+// @Param entity query string false "Filter by entity type (person, account, expense_category, expense_subcategory, expense, saved_view, tag, settlement, attachment, payee)"
+// @Param id query int false "Filter by entity ID, requires entity"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param limit query int false "Maximum number of records to return, at most 100" default(10)
+// @Param include_total query bool false "Include the total number of entries"
+// @Success 200 {object} response{data=domain.Page[domain.AuditEntry]}
+// @Failure 400 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/v1/audit [get]
+func (h *auditHandler) List(c *gin.Context) {
+	var req domain.ListAuditEntriesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	page, err := h.service.List(c.Request.Context(), &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	setPageLinks(c, page.NextCursor)
+	handleSuccess(c, page)
+}
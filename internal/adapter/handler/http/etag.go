@@ -0,0 +1,44 @@
+package http
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// etagHeader carries the version of the entity returned in a response
+	etagHeader = "ETag"
+	// ifMatchHeader carries the version of the entity a change is based on
+	ifMatchHeader = "If-Match"
+)
+
+// setETag exposes the version of the returned entity as a strong entity tag
+func setETag(c *gin.Context, version int) {
+	c.Header(etagHeader, strconv.Quote(strconv.Itoa(version)))
+}
+
+// ifMatchVersion returns the version stated by the If-Match header of a request.
+// A wildcard returns 0, which skips the version check.
+func ifMatchVersion(c *gin.Context) (int, error) {
+	value := strings.TrimSpace(c.GetHeader(ifMatchHeader))
+	if value == "" {
+		return 0, domain.ErrPreconditionRequired
+	}
+	if value == "*" {
+		return 0, nil
+	}
+
+	// Weak or malformed entity tags can never match a stored version
+	if len(value) < 2 || !strings.HasPrefix(value, `"`) || !strings.HasSuffix(value, `"`) {
+		return 0, domain.ErrPreconditionFailed
+	}
+	version, err := strconv.Atoi(value[1 : len(value)-1])
+	if err != nil || version <= 0 {
+		return 0, domain.ErrPreconditionFailed
+	}
+
+	return version, nil
+}
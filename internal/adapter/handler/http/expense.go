@@ -22,7 +22,10 @@ func NewExpenseHandler(expenseService port.ExpenseService) *ExpenseHandler {
 
 // CreateExpense godoc
 // @Summary Create a new expense
-// @Description Create a new expense with amount, category, subcategory, date, payee, and notes
+// @Description Create a new expense with amount, category, subcategory, date, payee, and notes.
+// @Description Without a category, the default category and subcategory of the payee apply.
+// @Description Optional split lines share the amount between several categories and must add up to it.
+// @Description Optional sharing tells who paid and how the cost is divided between persons (equal, percentage or exact shares).
 // @Tags expenses
 // @Accept json
 // @Produce json
@@ -44,6 +47,8 @@ func (h *ExpenseHandler) CreateExpense(c *gin.Context) {
 		return
 	}
 
+	setETag(c, expense.Version)
+
 	rsp := newResponse(true, "Expense created successfully", expense)
 	c.JSON(http.StatusCreated, rsp)
 }
@@ -74,23 +79,36 @@ func (h *ExpenseHandler) GetExpense(c *gin.Context) {
 		return
 	}
 
+	setETag(c, expense.Version)
+
 	handleSuccess(c, expense)
 }
 
 // ListExpenses godoc
 // @Summary List expenses
-// @Description Get a list of expenses with optional filtering and pagination
+// @Description Get a page of expenses with optional filtering, the Link header points to the first and next pages.
+// @Description Split expenses match the category filters through their split lines.
 // @Tags expenses
 // @Accept json
 // @Produce json
-// @Param skip query int false "Number of expenses to skip" default(0)
-// @Param limit query int false "Maximum number of expenses to return" default(10)
-// @Param category_id query int false "Filter by expense category ID"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param limit query int false "Maximum number of expenses to return, at most 100" default(10)
+// @Param include_total query bool false "Include the total number of matching expenses"
+// @Param category_id query []int false "Filter by expense category IDs, matching any of them" collectionFormat(multi)
 // @Param subcategory_id query int false "Filter by expense subcategory ID"
-// @Param payee_id query int false "Filter by payee (person) ID"
+// @Param has_subcategory query bool false "Filter on whether a subcategory is set"
+// @Param payee_id query []int false "Filter by payee IDs, matching any of them" collectionFormat(multi)
+// @Param account_id query []int false "Filter by account IDs, matching any of them" collectionFormat(multi)
+// @Param min_amount query number false "Filter by minimum amount, inclusive"
+// @Param max_amount query number false "Filter by maximum amount, inclusive"
+// @Param notes query string false "Full-text search in the notes, every word must match"
+// @Param tag_id query []int false "Filter by tag IDs" collectionFormat(multi)
+// @Param tag_match query string false "Whether any or all of the tags must be set" Enums(any, all) default(any)
 // @Param start_date query string false "Filter by start date (YYYY-MM-DD)"
 // @Param end_date query string false "Filter by end date (YYYY-MM-DD)"
-// @Success 200 {array} domain.Expense
+// @Param sort query string false "Sort field (date, amount, created_at) with an optional :asc or :desc direction" default(date:desc)
+// @Param include_deleted query bool false "Include soft-deleted expenses"
+// @Success 200 {object} domain.Page[domain.Expense]
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /api/v1/expenses [get]
@@ -101,13 +119,54 @@ func (h *ExpenseHandler) ListExpenses(c *gin.Context) {
 		return
 	}
 
-	expenses, err := h.expenseService.List(c.Request.Context(), &req)
+	page, err := h.expenseService.List(c.Request.Context(), &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	setPageLinks(c, page.NextCursor)
+	handleSuccess(c, page)
+}
+
+// ListTagTotals godoc
+// @Summary Sum expenses by tag
+// @Description Get the number and total amount of the matching expenses for each tag, ordered by tag name.
+// @Description An expense with several tags counts towards each of them, and only the split lines matching the category filters count.
+// @Tags expenses
+// @Accept json
+// @Produce json
+// @Param category_id query []int false "Filter by expense category IDs, matching any of them" collectionFormat(multi)
+// @Param subcategory_id query int false "Filter by expense subcategory ID"
+// @Param has_subcategory query bool false "Filter on whether a subcategory is set"
+// @Param payee_id query []int false "Filter by payee IDs, matching any of them" collectionFormat(multi)
+// @Param account_id query []int false "Filter by account IDs, matching any of them" collectionFormat(multi)
+// @Param min_amount query number false "Filter by minimum amount, inclusive"
+// @Param max_amount query number false "Filter by maximum amount, inclusive"
+// @Param notes query string false "Full-text search in the notes, every word must match"
+// @Param tag_id query []int false "Filter by tag IDs" collectionFormat(multi)
+// @Param tag_match query string false "Whether any or all of the tags must be set" Enums(any, all) default(any)
+// @Param start_date query string false "Filter by start date (YYYY-MM-DD)"
+// @Param end_date query string false "Filter by end date (YYYY-MM-DD)"
+// @Param include_deleted query bool false "Include soft-deleted expenses"
+// @Success 200 {array} domain.TagTotal
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/expenses/tags/totals [get]
+func (h *ExpenseHandler) ListTagTotals(c *gin.Context) {
+	var req domain.ListExpensesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	totals, err := h.expenseService.TagTotals(c.Request.Context(), &req)
 	if err != nil {
 		handleError(c, err)
 		return
 	}
 
-	handleSuccess(c, expenses)
+	handleSuccess(c, totals)
 }
 
 // UpdateExpense godoc
@@ -117,10 +176,13 @@ func (h *ExpenseHandler) ListExpenses(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "Expense ID"
+// @Param If-Match header string true "ETag of the expense version being changed"
 // @Param expense body domain.UpdateExpenseRequest true "Updated expense data"
 // @Success 200 {object} domain.Expense
 // @Failure 400 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Failure 412 {object} ErrorResponse
+// @Failure 428 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /api/v1/expenses/{id} [put]
 func (h *ExpenseHandler) UpdateExpense(c *gin.Context) {
@@ -137,16 +199,109 @@ func (h *ExpenseHandler) UpdateExpense(c *gin.Context) {
 		return
 	}
 
+	// The update must be based on the current version of the data
+	req.Version, err = ifMatchVersion(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
 	expense, err := h.expenseService.Update(c.Request.Context(), id, &req)
 	if err != nil {
 		handleError(c, err)
 		return
 	}
 
+	setETag(c, expense.Version)
+
+	rsp := newResponse(true, "Expense updated successfully", expense)
+	c.JSON(http.StatusOK, rsp)
+}
+
+// PatchExpense godoc
+// @Summary Patch expense
+// @Description Partially update an existing expense with a JSON merge patch, only the present fields are changed
+// @Tags expenses
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path int true "Expense ID"
+// @Param If-Match header string true "ETag of the expense version being changed"
+// @Param expense body domain.PatchExpenseRequest true "Merge patch"
+// @Success 200 {object} domain.Expense
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 412 {object} ErrorResponse
+// @Failure 415 {object} ErrorResponse
+// @Failure 428 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/expenses/{id} [patch]
+func (h *ExpenseHandler) PatchExpense(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		validationError(c, err)
+		return
+	}
+
+	var req domain.PatchExpenseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	// The patch must be based on the current version of the data
+	req.Version, err = ifMatchVersion(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	expense, err := h.expenseService.Patch(c.Request.Context(), id, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	setETag(c, expense.Version)
+
 	rsp := newResponse(true, "Expense updated successfully", expense)
 	c.JSON(http.StatusOK, rsp)
 }
 
+// BulkExpenses godoc
+// @Summary Bulk expense operations
+// @Description Create expenses, update every expense matching a filter and delete expenses by ID in a single transaction.
+// @Description Each item is reported separately, with all_or_nothing any failing item rolls back the whole request.
+// @Tags expenses
+// @Accept json
+// @Produce json
+// @Param bulk body domain.BulkExpenseRequest true "Bulk operations"
+// @Success 200 {object} domain.BulkExpenseResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/expenses/bulk [post]
+func (h *ExpenseHandler) BulkExpenses(c *gin.Context) {
+	var req domain.BulkExpenseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	result, err := h.expenseService.Bulk(c.Request.Context(), &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	message := "Bulk expense operations committed"
+	if !result.Committed {
+		message = "Bulk expense operations rolled back"
+	}
+
+	rsp := newResponse(true, message, result)
+	c.JSON(http.StatusOK, rsp)
+}
+
 // DeleteExpense godoc
 // @Summary Delete expense
 // @Description Delete an expense by ID
@@ -154,9 +309,12 @@ func (h *ExpenseHandler) UpdateExpense(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "Expense ID"
+// @Param If-Match header string true "ETag of the expense version being changed"
 // @Success 204 "No Content"
 // @Failure 400 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Failure 412 {object} ErrorResponse
+// @Failure 428 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /api/v1/expenses/{id} [delete]
 func (h *ExpenseHandler) DeleteExpense(c *gin.Context) {
@@ -167,7 +325,14 @@ func (h *ExpenseHandler) DeleteExpense(c *gin.Context) {
 		return
 	}
 
-	err = h.expenseService.Delete(c.Request.Context(), id)
+	// The deletion must be based on the current version of the data
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	err = h.expenseService.Delete(c.Request.Context(), id, version)
 	if err != nil {
 		handleError(c, err)
 		return
@@ -175,3 +340,35 @@ func (h *ExpenseHandler) DeleteExpense(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+// RestoreExpense godoc
+// @Summary Restore expense
+// @Description Restore a soft-deleted expense by ID
+// @Tags expenses
+// @Accept json
+// @Produce json
+// @Param id path int true "Expense ID"
+// @Success 200 {object} domain.Expense
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/expenses/{id}/restore [post]
+func (h *ExpenseHandler) RestoreExpense(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		validationError(c, err)
+		return
+	}
+
+	expense, err := h.expenseService.Restore(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	setETag(c, expense.Version)
+
+	rsp := newResponse(true, "Expense restored successfully", expense)
+	c.JSON(http.StatusOK, rsp)
+}
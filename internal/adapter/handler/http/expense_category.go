@@ -15,7 +15,9 @@ type ExpenseCategoryHandler interface {
 	List(c *gin.Context)
 	GetByID(c *gin.Context)
 	Update(c *gin.Context)
+	Patch(c *gin.Context)
 	Delete(c *gin.Context)
+	Restore(c *gin.Context)
 }
 
 type expenseCategoryHandler struct {
@@ -53,19 +55,24 @@ func (h *expenseCategoryHandler) Create(c *gin.Context) {
 		return
 	}
 
+	setETag(c, category.Version)
+
 	rsp := newResponse(true, "Expense category created successfully", category)
 	c.JSON(http.StatusCreated, rsp)
 }
 
 // List handles GET /expense-categories
 // @Summary List expense categories
-// @Description Get a paginated list of expense categories
+// @Description Get a page of expense categories, the Link header points to the first and next pages
 // @Tags expense-categories
 // @Accept json
 // @Produce json
-// @Param skip query int false "Number of records to skip" default(0)
-// @Param limit query int false "Maximum number of records to return" default(10)
-// @Success 200 {object} ResponseData{data=[]domain.ExpenseCategory}
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param limit query int false "Maximum number of records to return, at most 100" default(10)
+// @Param include_total query bool false "Include the total number of categories"
+// @Param include_deleted query bool false "Include soft-deleted categories"
+// @Success 200 {object} ResponseData{data=domain.Page[domain.ExpenseCategory]}
+// @Failure 400 {object} ResponseError
 // @Failure 500 {object} ResponseError
 // @Router /expense-categories [get]
 func (h *expenseCategoryHandler) List(c *gin.Context) {
@@ -75,13 +82,14 @@ func (h *expenseCategoryHandler) List(c *gin.Context) {
 		return
 	}
 
-	categories, err := h.service.List(c.Request.Context(), &req)
+	page, err := h.service.List(c.Request.Context(), &req)
 	if err != nil {
 		handleError(c, err)
 		return
 	}
 
-	handleSuccess(c, categories)
+	setPageLinks(c, page.NextCursor)
+	handleSuccess(c, page)
 }
 
 // GetByID handles GET /expense-categories/:id
@@ -110,6 +118,8 @@ func (h *expenseCategoryHandler) GetByID(c *gin.Context) {
 		return
 	}
 
+	setETag(c, category.Version)
+
 	handleSuccess(c, category)
 }
 
@@ -120,10 +130,13 @@ func (h *expenseCategoryHandler) GetByID(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "Expense category ID"
+// @Param If-Match header string true "ETag of the expense category version being changed"
 // @Param category body domain.UpdateExpenseCategoryRequest true "Updated expense category data"
 // @Success 200 {object} ResponseData{data=domain.ExpenseCategory}
 // @Failure 400 {object} ResponseError
 // @Failure 404 {object} ResponseError
+// @Failure 412 {object} ResponseError
+// @Failure 428 {object} ResponseError
 // @Failure 500 {object} ResponseError
 // @Router /expense-categories/{id} [put]
 func (h *expenseCategoryHandler) Update(c *gin.Context) {
@@ -140,12 +153,71 @@ func (h *expenseCategoryHandler) Update(c *gin.Context) {
 		return
 	}
 
+	// The update must be based on the current version of the data
+	req.Version, err = ifMatchVersion(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
 	category, err := h.service.Update(c.Request.Context(), id, &req)
 	if err != nil {
 		handleError(c, err)
 		return
 	}
 
+	setETag(c, category.Version)
+
+	rsp := newResponse(true, "Expense category updated successfully", category)
+	c.JSON(http.StatusOK, rsp)
+}
+
+// Patch handles PATCH /expense-categories/:id
+// @Summary Patch expense category
+// @Description Partially update an existing expense category with a JSON merge patch, only the present fields are changed
+// @Tags expense-categories
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path int true "Expense category ID"
+// @Param If-Match header string true "ETag of the expense category version being changed"
+// @Param category body domain.PatchExpenseCategoryRequest true "Merge patch"
+// @Success 200 {object} ResponseData{data=domain.ExpenseCategory}
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 412 {object} ResponseError
+// @Failure 415 {object} ResponseError
+// @Failure 428 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /expense-categories/{id} [patch]
+func (h *expenseCategoryHandler) Patch(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		validationError(c, err)
+		return
+	}
+
+	var req domain.PatchExpenseCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	// The patch must be based on the current version of the data
+	req.Version, err = ifMatchVersion(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	category, err := h.service.Patch(c.Request.Context(), id, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	setETag(c, category.Version)
+
 	rsp := newResponse(true, "Expense category updated successfully", category)
 	c.JSON(http.StatusOK, rsp)
 }
@@ -157,9 +229,12 @@ func (h *expenseCategoryHandler) Update(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "Expense category ID"
+// @Param If-Match header string true "ETag of the expense category version being changed"
 // @Success 204 "No Content"
 // @Failure 400 {object} ResponseError
 // @Failure 404 {object} ResponseError
+// @Failure 412 {object} ResponseError
+// @Failure 428 {object} ResponseError
 // @Failure 500 {object} ResponseError
 // @Router /expense-categories/{id} [delete]
 func (h *expenseCategoryHandler) Delete(c *gin.Context) {
@@ -170,7 +245,14 @@ func (h *expenseCategoryHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	err = h.service.Delete(c.Request.Context(), id)
+	// The deletion must be based on the current version of the data
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	err = h.service.Delete(c.Request.Context(), id, version)
 	if err != nil {
 		handleError(c, err)
 		return
@@ -178,3 +260,35 @@ func (h *expenseCategoryHandler) Delete(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+// Restore handles POST /expense-categories/:id/restore
+// @Summary Restore expense category
+// @Description Restore a soft-deleted expense category by its ID
+// @Tags expense-categories
+// @Accept json
+// @Produce json
+// @Param id path int true "Expense category ID"
+// @Success 200 {object} ResponseData{data=domain.ExpenseCategory}
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /expense-categories/{id}/restore [post]
+func (h *expenseCategoryHandler) Restore(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		validationError(c, err)
+		return
+	}
+
+	category, err := h.service.Restore(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	setETag(c, category.Version)
+
+	rsp := newResponse(true, "Expense category restored successfully", category)
+	c.JSON(http.StatusOK, rsp)
+}
@@ -15,7 +15,9 @@ type ExpenseSubCategoryHandler interface {
 	List(c *gin.Context)
 	GetByID(c *gin.Context)
 	Update(c *gin.Context)
+	Patch(c *gin.Context)
 	Delete(c *gin.Context)
+	Restore(c *gin.Context)
 }
 
 type expenseSubCategoryHandler struct {
@@ -53,20 +55,26 @@ func (h *expenseSubCategoryHandler) Create(c *gin.Context) {
 		return
 	}
 
+	setETag(c, subcategory.Version)
+
 	rsp := newResponse(true, "Expense subcategory created successfully", subcategory)
 	c.JSON(http.StatusCreated, rsp)
 }
 
 // List handles GET /expenses/categories/subcategories
 // @Summary List expense subcategories
-// @Description Get a paginated list of expense subcategories, optionally filtered by expense category
+// @Description Get a page of expense subcategories, optionally filtered by expense category.
+// @Description The Link header points to the first and next pages.
 // @Tags expense-subcategories
 // @Accept json
 // @Produce json
-// @Param skip query int false "Number of records to skip" default(0)
-// @Param limit query int false "Maximum number of records to return" default(10)
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param limit query int false "Maximum number of records to return, at most 100" default(10)
+// @Param include_total query bool false "Include the total number of subcategories"
 // @Param expense_category_id query int false "Filter by expense category ID"
-// @Success 200 {object} response{data=[]domain.ExpenseSubCategory}
+// @Param include_deleted query bool false "Include soft-deleted subcategories"
+// @Success 200 {object} response{data=domain.Page[domain.ExpenseSubCategory]}
+// @Failure 400 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Router /expenses/categories/subcategories [get]
 func (h *expenseSubCategoryHandler) List(c *gin.Context) {
@@ -76,13 +84,14 @@ func (h *expenseSubCategoryHandler) List(c *gin.Context) {
 		return
 	}
 
-	subcategories, err := h.service.List(c.Request.Context(), &req)
+	page, err := h.service.List(c.Request.Context(), &req)
 	if err != nil {
 		handleError(c, err)
 		return
 	}
 
-	handleSuccess(c, subcategories)
+	setPageLinks(c, page.NextCursor)
+	handleSuccess(c, page)
 }
 
 // GetByID handles GET /expenses/categories/subcategories/:id
@@ -111,6 +120,8 @@ func (h *expenseSubCategoryHandler) GetByID(c *gin.Context) {
 		return
 	}
 
+	setETag(c, subcategory.Version)
+
 	handleSuccess(c, subcategory)
 }
 
@@ -121,10 +132,13 @@ func (h *expenseSubCategoryHandler) GetByID(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "Expense subcategory ID"
+// @Param If-Match header string true "ETag of the expense subcategory version being changed"
 // @Param subcategory body domain.UpdateExpenseSubCategoryRequest true "Updated expense subcategory data"
 // @Success 200 {object} response{data=domain.ExpenseSubCategory}
 // @Failure 400 {object} errorResponse
 // @Failure 404 {object} errorResponse
+// @Failure 412 {object} errorResponse
+// @Failure 428 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Router /expenses/categories/subcategories/{id} [put]
 func (h *expenseSubCategoryHandler) Update(c *gin.Context) {
@@ -141,12 +155,71 @@ func (h *expenseSubCategoryHandler) Update(c *gin.Context) {
 		return
 	}
 
+	// The update must be based on the current version of the data
+	req.Version, err = ifMatchVersion(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
 	subcategory, err := h.service.Update(c.Request.Context(), id, &req)
 	if err != nil {
 		handleError(c, err)
 		return
 	}
 
+	setETag(c, subcategory.Version)
+
+	rsp := newResponse(true, "Expense subcategory updated successfully", subcategory)
+	c.JSON(http.StatusOK, rsp)
+}
+
+// Patch handles PATCH /expenses/categories/subcategories/:id
+// @Summary Patch expense subcategory
+// @Description Partially update an existing expense subcategory with a JSON merge patch, only the present fields are changed
+// @Tags expense-subcategories
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path int true "Expense subcategory ID"
+// @Param If-Match header string true "ETag of the expense subcategory version being changed"
+// @Param subcategory body domain.PatchExpenseSubCategoryRequest true "Merge patch"
+// @Success 200 {object} response{data=domain.ExpenseSubCategory}
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 412 {object} errorResponse
+// @Failure 415 {object} errorResponse
+// @Failure 428 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /expenses/categories/subcategories/{id} [patch]
+func (h *expenseSubCategoryHandler) Patch(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		validationError(c, err)
+		return
+	}
+
+	var req domain.PatchExpenseSubCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	// The patch must be based on the current version of the data
+	req.Version, err = ifMatchVersion(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	subcategory, err := h.service.Patch(c.Request.Context(), id, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	setETag(c, subcategory.Version)
+
 	rsp := newResponse(true, "Expense subcategory updated successfully", subcategory)
 	c.JSON(http.StatusOK, rsp)
 }
@@ -158,9 +231,12 @@ func (h *expenseSubCategoryHandler) Update(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "Expense subcategory ID"
+// @Param If-Match header string true "ETag of the expense subcategory version being changed"
 // @Success 204 "No Content"
 // @Failure 400 {object} errorResponse
 // @Failure 404 {object} errorResponse
+// @Failure 412 {object} errorResponse
+// @Failure 428 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Router /expenses/categories/subcategories/{id} [delete]
 func (h *expenseSubCategoryHandler) Delete(c *gin.Context) {
@@ -171,7 +247,14 @@ func (h *expenseSubCategoryHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	err = h.service.Delete(c.Request.Context(), id)
+	// The deletion must be based on the current version of the data
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	err = h.service.Delete(c.Request.Context(), id, version)
 	if err != nil {
 		handleError(c, err)
 		return
@@ -179,3 +262,35 @@ func (h *expenseSubCategoryHandler) Delete(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+// Restore handles POST /expenses/categories/subcategories/:id/restore
+// @Summary Restore expense subcategory
+// @Description Restore a soft-deleted expense subcategory by its ID
+// @Tags expense-subcategories
+// @Accept json
+// @Produce json
+// @Param id path int true "Expense subcategory ID"
+// @Success 200 {object} response{data=domain.ExpenseSubCategory}
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /expenses/categories/subcategories/{id}/restore [post]
+func (h *expenseSubCategoryHandler) Restore(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		validationError(c, err)
+		return
+	}
+
+	subcategory, err := h.service.Restore(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	setETag(c, subcategory.Version)
+
+	rsp := newResponse(true, "Expense subcategory restored successfully", subcategory)
+	c.JSON(http.StatusOK, rsp)
+}
@@ -0,0 +1,37 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+	"github.com/gin-gonic/gin"
+)
+
+// health reports whether the service can serve requests, which takes a schema at the version the
+// binary expects. It answers 503 while migrations are pending or after one failed half way.
+func health(migrator port.SchemaMigrator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		status, err := migrator.Status(c)
+		if err != nil {
+			domain.LoggerFromContext(c).ErrorContext(c, "Error reading the schema version", "error", err)
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"status": "unhealthy",
+			})
+			return
+		}
+
+		if !status.Ready() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"status": "unhealthy",
+				"schema": status,
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"status": "healthy",
+			"schema": status,
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// idempotencyKeyHeader carries the client chosen key identifying a create request across retries
+	idempotencyKeyHeader = "Idempotency-Key"
+	// idempotentReplayedHeader marks responses replayed from a previous request with the same key
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	// maxIdempotencyKeyLength is the longest idempotency key accepted
+	maxIdempotencyKeyLength = 255
+	// maxIdempotencyScopeLength is the longest scope an idempotency key is stored with
+	maxIdempotencyScopeLength = 255
+)
+
+// idempotentResponseHeaders are the response headers stored and replayed with an idempotent response
+var idempotentResponseHeaders = []string{"Content-Type", etagHeader, "Location"}
+
+// idempotent replays the stored response of a request sent again with the same Idempotency-Key header
+// to the same path, keys are scoped by the method and the path with its ids. Requests without the header
+// are processed as usual.
+func idempotent(svc port.IdempotencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyKeyHeader)
+		scope := c.Request.Method + " " + c.Request.URL.Path
+		// Paths too long to be stored hold ids too long to exist, the handler rejects them
+		if key == "" || len(scope) > maxIdempotencyScopeLength {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			handleError(c, domain.InvalidField(idempotencyKeyHeader, "max", fmt.Sprintf("must have at most %d characters", maxIdempotencyKeyLength)))
+			c.Abort()
+			return
+		}
+
+		// Fingerprint the body, then put it back for the handler
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				handleError(c, domain.ErrPayloadTooLarge)
+			} else {
+				validationError(c, err)
+			}
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		sum := sha256.Sum256(body)
+		requestHash := hex.EncodeToString(sum[:])
+
+		// The outcome must be stored even if the client goes away mid-request
+		ctx := context.WithoutCancel(c.Request.Context())
+
+		replay, err := svc.Begin(ctx, key, scope, requestHash)
+		if err != nil {
+			handleError(c, err)
+			c.Abort()
+			return
+		}
+		if replay != nil {
+			for name, value := range replay.Headers {
+				c.Header(name, value)
+			}
+			c.Header(idempotentReplayedHeader, "true")
+			c.Data(replay.StatusCode, replay.Headers["Content-Type"], replay.Body)
+			c.Abort()
+			return
+		}
+
+		release := func() {
+			if err := svc.Release(ctx, key, scope); err != nil {
+				domain.LoggerFromContext(ctx).ErrorContext(ctx, "Error releasing idempotency key", "error", err)
+			}
+		}
+		// A panicking handler must not leave the key in progress, the recovery middleware answers
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				release()
+				panic(recovered)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// Server errors are not stored, so the request can be retried with the same key
+		statusCode := recorder.Status()
+		if statusCode >= http.StatusInternalServerError {
+			release()
+			return
+		}
+
+		headers := make(map[string]string)
+		for _, name := range idempotentResponseHeaders {
+			if value := recorder.Header().Get(name); value != "" {
+				headers[name] = value
+			}
+		}
+		if err := svc.Complete(ctx, key, scope, statusCode, headers, recorder.body.Bytes()); err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Error storing idempotent response", "error", err)
+		}
+	}
+}
+
+// responseRecorder keeps a copy of the response body written through it
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,148 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	memoryrepo "github.com/edwins-leonardi/finaid-api/internal/adapter/storage/memory/repository"
+	"github.com/edwins-leonardi/finaid-api/internal/core/service"
+	"github.com/gin-gonic/gin"
+)
+
+// idempotencyRouter serves POST /parents/:id/children through the idempotent middleware, calling handler
+// after counting the call
+func idempotencyRouter(calls *atomic.Int32, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(gin.Recovery())
+	svc := service.NewIdempotencyService(memoryrepo.NewIdempotencyRepository(), time.Hour)
+	router.POST("/parents/:id/children", idempotent(svc), func(c *gin.Context) {
+		calls.Add(1)
+		handler(c)
+	})
+	return router
+}
+
+// created answers with the path of the request, so that responses of different parents differ
+func created(c *gin.Context) {
+	c.JSON(http.StatusCreated, gin.H{"path": c.Request.URL.Path})
+}
+
+// postChild sends body to path with the idempotency key
+func postChild(router http.Handler, path, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(idempotencyKeyHeader, key)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotentReplay(t *testing.T) {
+	var calls atomic.Int32
+	router := idempotencyRouter(&calls, created)
+
+	first := postChild(router, "/parents/1/children", "key", `{"name":"a"}`)
+	second := postChild(router, "/parents/1/children", "key", `{"name":"a"}`)
+
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("handler calls = %d, want 1", got)
+	}
+	if second.Code != first.Code || second.Body.String() != first.Body.String() {
+		t.Errorf("replayed response = %d %s, want %d %s", second.Code, second.Body, first.Code, first.Body)
+	}
+	if got := second.Header().Get(idempotentReplayedHeader); got != "true" {
+		t.Errorf("%s header = %q, want %q", idempotentReplayedHeader, got, "true")
+	}
+	if got := first.Header().Get(idempotentReplayedHeader); got != "" {
+		t.Errorf("%s header of the first response = %q, want none", idempotentReplayedHeader, got)
+	}
+}
+
+func TestIdempotentKeyReusedWithDifferentBody(t *testing.T) {
+	var calls atomic.Int32
+	router := idempotencyRouter(&calls, created)
+
+	postChild(router, "/parents/1/children", "key", `{"name":"a"}`)
+	rec := postChild(router, "/parents/1/children", "key", `{"name":"b"}`)
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("handler calls = %d, want 1", got)
+	}
+}
+
+func TestIdempotentKeyScopedByPath(t *testing.T) {
+	var calls atomic.Int32
+	router := idempotencyRouter(&calls, created)
+
+	first := postChild(router, "/parents/1/children", "key", `{"name":"a"}`)
+	second := postChild(router, "/parents/2/children", "key", `{"name":"a"}`)
+
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("handler calls = %d, want 2", got)
+	}
+	if first.Body.String() == second.Body.String() {
+		t.Errorf("response of the second parent = %s, want its own", second.Body)
+	}
+	if got := second.Header().Get(idempotentReplayedHeader); got != "" {
+		t.Errorf("%s header = %q, want none", idempotentReplayedHeader, got)
+	}
+}
+
+func TestIdempotentRequestInProgress(t *testing.T) {
+	var calls atomic.Int32
+	started, finish := make(chan struct{}), make(chan struct{})
+	router := idempotencyRouter(&calls, func(c *gin.Context) {
+		close(started)
+		<-finish
+		created(c)
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- postChild(router, "/parents/1/children", "key", `{"name":"a"}`)
+	}()
+	<-started
+
+	rec := postChild(router, "/parents/1/children", "key", `{"name":"a"}`)
+	close(finish)
+	first := <-done
+
+	if rec.Code != http.StatusConflict {
+		t.Errorf("status while in progress = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if first.Code != http.StatusCreated {
+		t.Errorf("status of the first request = %d, want %d", first.Code, http.StatusCreated)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("handler calls = %d, want 1", got)
+	}
+}
+
+func TestIdempotentKeyReleasedOnPanic(t *testing.T) {
+	var calls atomic.Int32
+	router := idempotencyRouter(&calls, func(c *gin.Context) {
+		if calls.Load() == 1 {
+			panic("handler failure")
+		}
+		created(c)
+	})
+
+	if rec := postChild(router, "/parents/1/children", "key", `{"name":"a"}`); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status of the panicking request = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	rec := postChild(router, "/parents/1/children", "key", `{"name":"a"}`)
+	if rec.Code != http.StatusCreated {
+		t.Errorf("status of the retry = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("handler calls = %d, want 2", got)
+	}
+}
@@ -0,0 +1,187 @@
+package http
+
+import (
+	"strconv"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+	"github.com/gin-gonic/gin"
+)
+
+// MergeHandler handles HTTP requests for merging duplicate payees, expense categories and subcategories
+type MergeHandler interface {
+	MergePayees(c *gin.Context)
+	MergeExpenseCategories(c *gin.Context)
+	MergeExpenseSubCategories(c *gin.Context)
+	List(c *gin.Context)
+}
+
+type mergeHandler struct {
+	service port.MergeService
+}
+
+// NewMergeHandler creates a new merge HTTP handler
+func NewMergeHandler(service port.MergeService) MergeHandler {
+	return &mergeHandler{
+		service: service,
+	}
+}
+
+// MergePayees handles POST /payees/:id/merge
+// @Summary Merge payees
+// @Description Merge other payees into the payee of the path: their expenses move over to it, their names become its aliases and they are deleted.
+// @Description The settings of the payee of the path are kept. Every merge is recorded and listed under /merges.
+// @Tags payees
+// @Accept json
+// @Produce json
+// @Param id path int true "Payee ID"
+// @Param If-Match header string true "ETag of the payee version being changed"
+// @Param merge body domain.MergeRequest true "Payees to merge"
+// @Success 200 {object} ResponseData{data=domain.MergeResult[domain.Payee]}
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 412 {object} ResponseError
+// @Failure 428 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /api/v1/payees/{id}/merge [post]
+func (h *mergeHandler) MergePayees(c *gin.Context) {
+	id, req, ok := mergeRequest(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.MergePayees(c.Request.Context(), id, req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	setETag(c, result.Target.Version)
+
+	handleSuccess(c, result)
+}
+
+// MergeExpenseCategories handles POST /expenses/categories/:id/merge
+// @Summary Merge expense categories
+// @Description Merge other categories into the category of the path: their expenses and split lines move over to it, their names become its aliases and they are deleted.
+// @Description Their subcategories move to it as well, merged into its subcategory of the same name if any. Every merge is recorded and listed under /merges.
+// @Tags expense-categories
+// @Accept json
+// @Produce json
+// @Param id path int true "Category ID"
+// @Param If-Match header string true "ETag of the category version being changed"
+// @Param merge body domain.MergeRequest true "Categories to merge"
+// @Success 200 {object} ResponseData{data=domain.MergeResult[domain.ExpenseCategory]}
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 412 {object} ResponseError
+// @Failure 428 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /api/v1/expenses/categories/{id}/merge [post]
+func (h *mergeHandler) MergeExpenseCategories(c *gin.Context) {
+	id, req, ok := mergeRequest(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.MergeExpenseCategories(c.Request.Context(), id, req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	setETag(c, result.Target.Version)
+
+	handleSuccess(c, result)
+}
+
+// MergeExpenseSubCategories handles POST /expenses/categories/subcategories/:id/merge
+// @Summary Merge expense subcategories
+// @Description Merge other subcategories into the subcategory of the path: their expenses and split lines move over to it and to its category,
+// @Description their names become its aliases and they are deleted. Every merge is recorded and listed under /merges.
+// @Tags expense-subcategories
+// @Accept json
+// @Produce json
+// @Param id path int true "Subcategory ID"
+// @Param If-Match header string true "ETag of the subcategory version being changed"
+// @Param merge body domain.MergeRequest true "Subcategories to merge"
+// @Success 200 {object} ResponseData{data=domain.MergeResult[domain.ExpenseSubCategory]}
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 412 {object} ResponseError
+// @Failure 428 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /api/v1/expenses/categories/subcategories/{id}/merge [post]
+func (h *mergeHandler) MergeExpenseSubCategories(c *gin.Context) {
+	id, req, ok := mergeRequest(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.MergeExpenseSubCategories(c.Request.Context(), id, req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	setETag(c, result.Target.Version)
+
+	handleSuccess(c, result)
+}
+
+// List handles GET /merges
+// @Summary List merges
+// @Description Get a page of past merges of payees, expense categories and subcategories, oldest first, the Link header points to the first and next pages
+// @Tags merges
+// @Accept json
+// @Produce json
+// @Param entity query string false "Filter by entity type (payee, expense_category, expense_subcategory)"
+// @Param target_id query int false "Filter by the record merged into, requires entity"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param limit query int false "Maximum number of records to return, at most 100" default(10)
+// @Param include_total query bool false "Include the total number of merges"
+// @Success 200 {object} ResponseData{data=domain.Page[domain.Merge]}
+// @Failure 400 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /api/v1/merges [get]
+func (h *mergeHandler) List(c *gin.Context) {
+	var req domain.ListMergesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	page, err := h.service.List(c.Request.Context(), &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	setPageLinks(c, page.NextCursor)
+	handleSuccess(c, page)
+}
+
+// mergeRequest reads the target ID, body and If-Match version of a merge request, writing the error response when invalid
+func mergeRequest(c *gin.Context) (int, *domain.MergeRequest, bool) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		validationError(c, err)
+		return 0, nil, false
+	}
+
+	var req domain.MergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return 0, nil, false
+	}
+
+	// The merge must be based on the current version of the target
+	req.Version, err = ifMatchVersion(c)
+	if err != nil {
+		handleError(c, err)
+		return 0, nil, false
+	}
+
+	return id, &req, true
+}
@@ -0,0 +1,85 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	sloggin "github.com/samber/slog-gin"
+)
+
+const (
+	// actorHeader identifies who performs a request, recorded in the audit trail
+	actorHeader = "X-Actor"
+	// requestIDHeader correlates a request across systems
+	requestIDHeader = "X-Request-ID"
+	// maxRequestIDLength bounds the request ids accepted from callers, as they end up in every log line
+	maxRequestIDLength = 128
+	// mergePatchContentType is the media type of JSON merge patches (RFC 7396)
+	mergePatchContentType = "application/merge-patch+json"
+)
+
+// requestContext stores the actor and the id of the incoming request in its context, along with
+// a logger carrying them and the route. The request id sent by the caller is kept when valid,
+// otherwise one is generated, and it is returned in the response either way.
+func requestContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(requestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+		c.Header(requestIDHeader, requestID)
+		sloggin.AddCustomAttributes(c, slog.String("request_id", requestID))
+
+		ctx := domain.WithRequestID(c.Request.Context(), requestID)
+		if actor := c.GetHeader(actorHeader); actor != "" {
+			ctx = domain.WithActor(ctx, actor)
+		}
+		logger := slog.Default().With(
+			"request_id", requestID,
+			"route", c.FullPath(),
+			"actor", domain.ActorFromContext(ctx),
+		)
+		c.Request = c.Request.WithContext(domain.WithLogger(ctx, logger))
+
+		c.Next()
+	}
+}
+
+// mergePatch rejects the requests whose body is not a JSON merge patch, so that a client sending
+// another kind of patch, such as a JSON patch, is not mistaken for a merge patch
+func mergePatch() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.ContentType() != mergePatchContentType {
+			handleError(c, domain.ErrUnsupportedMediaType)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// limitBody caps the request body at limit bytes, so that middlewares reading it ahead of the handler,
+// such as idempotent, cannot be made to buffer an unbounded upload
+func limitBody(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
+
+// validRequestID reports whether id is short and only made of printable ASCII characters,
+// so that it cannot flood or forge log lines
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,129 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestMergePatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.PATCH("/persons/:id", mergePatch(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		contentType string
+		want        int
+	}{
+		{"application/merge-patch+json", http.StatusOK},
+		{"application/merge-patch+json; charset=utf-8", http.StatusOK},
+		{"application/json", http.StatusUnsupportedMediaType},
+		{"application/json-patch+json", http.StatusUnsupportedMediaType},
+		{"", http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, "/persons/1", strings.NewReader(`{"name":"Ada"}`))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestContextRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(requestContext())
+	// The handler answers with the request id its context carries
+	router.GET("/hello", func(c *gin.Context) {
+		c.String(http.StatusOK, domain.RequestIDFromContext(c.Request.Context()))
+	})
+
+	tests := []struct {
+		name      string
+		requestID string
+		kept      bool
+	}{
+		{"valid", "req-42_a.b:c", true},
+		{"longest", strings.Repeat("a", maxRequestIDLength), true},
+		{"missing", "", false},
+		{"too long", strings.Repeat("a", maxRequestIDLength+1), false},
+		{"space", "req 42", false},
+		{"line break", "req-42\nlevel=ERROR", false},
+		{"non ASCII", "req-42é", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/hello", nil)
+			if tt.requestID != "" {
+				req.Header.Set(requestIDHeader, tt.requestID)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			got := rec.Header().Get(requestIDHeader)
+			if tt.kept && got != tt.requestID {
+				t.Errorf("%s = %q, want the incoming %q", requestIDHeader, got, tt.requestID)
+			}
+			if !tt.kept {
+				if _, err := uuid.Parse(got); err != nil {
+					t.Errorf("%s = %q, want a generated UUID", requestIDHeader, got)
+				}
+			}
+			if body := rec.Body.String(); body != got {
+				t.Errorf("request id of the context = %q, want the one echoed %q", body, got)
+			}
+		})
+	}
+}
+
+func TestRequestContextLogger(t *testing.T) {
+	var logs bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(requestContext())
+	router.GET("/persons/:id", func(c *gin.Context) {
+		domain.LoggerFromContext(c.Request.Context()).InfoContext(c, "Getting person")
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/persons/1", nil)
+	req.Header.Set(requestIDHeader, "req-42")
+	req.Header.Set(actorHeader, "alice")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	// The actor is logged under its own name, log queries read caller as the code location of a line
+	var line map[string]any
+	if err := json.Unmarshal(logs.Bytes(), &line); err != nil {
+		t.Fatalf("decode log line %q: %v", logs.String(), err)
+	}
+	want := map[string]string{"request_id": "req-42", "route": "/persons/:id", "actor": "alice"}
+	for key, value := range want {
+		if line[key] != value {
+			t.Errorf("%s = %v, want %q", key, line[key], value)
+		}
+	}
+	if _, ok := line["caller"]; ok {
+		t.Errorf("log line %v has a caller attribute", line)
+	}
+}
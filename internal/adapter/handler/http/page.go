@@ -0,0 +1,50 @@
+package http
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// linkHeader carries the links to the first and next pages of a list (RFC 8288)
+const linkHeader = "Link"
+
+// setPageLinks sets the Link header to the first page and, unless this is the last page, the next one
+func setPageLinks(c *gin.Context, nextCursor string) {
+	links := []string{pageLink(c, "", "first")}
+	if nextCursor != "" {
+		links = append(links, pageLink(c, nextCursor, "next"))
+	}
+	c.Header(linkHeader, strings.Join(links, ", "))
+}
+
+// pageLink returns a link to the page of the current list starting at the cursor, keeping every other query parameter
+func pageLink(c *gin.Context, cursor, rel string) string {
+	target := *c.Request.URL
+	query := target.Query()
+	if cursor == "" {
+		query.Del("cursor")
+	} else {
+		query.Set("cursor", cursor)
+	}
+	target.RawQuery = query.Encode()
+
+	return fmt.Sprintf("<%s>; rel=%q", target.RequestURI(), rel)
+}
+
+// mapPage converts the items of a page into their response format
+func mapPage[T, R any](page *domain.Page[T], convert func(*T) R) *domain.Page[R] {
+	items := make([]R, 0, len(page.Items))
+	for i := range page.Items {
+		items = append(items, convert(&page.Items[i]))
+	}
+
+	return &domain.Page[R]{
+		Items:      items,
+		NextCursor: page.NextCursor,
+		HasMore:    page.HasMore,
+		TotalCount: page.TotalCount,
+	}
+}
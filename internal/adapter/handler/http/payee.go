@@ -0,0 +1,213 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+	"github.com/gin-gonic/gin"
+)
+
+// PayeeHandler handles HTTP requests for payees
+type PayeeHandler interface {
+	Create(c *gin.Context)
+	List(c *gin.Context)
+	GetByID(c *gin.Context)
+	Update(c *gin.Context)
+	Delete(c *gin.Context)
+}
+
+type payeeHandler struct {
+	service port.PayeeService
+}
+
+// NewPayeeHandler creates a new payee HTTP handler
+func NewPayeeHandler(service port.PayeeService) PayeeHandler {
+	return &payeeHandler{
+		service: service,
+	}
+}
+
+// Create handles POST /payees
+// @Summary Create a new payee
+// @Description Create a new payee, names are unique regardless of case
+// @Tags payees
+// @Accept json
+// @Produce json
+// @Param payee body domain.CreatePayeeRequest true "Payee data"
+// @Success 201 {object} ResponseData{data=domain.Payee}
+// @Failure 400 {object} ResponseError
+// @Failure 409 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /api/v1/payees [post]
+func (h *payeeHandler) Create(c *gin.Context) {
+	var req domain.CreatePayeeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	payee, err := h.service.Create(c.Request.Context(), &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	setETag(c, payee.Version)
+
+	rsp := newResponse(true, "Payee created successfully", payee)
+	c.JSON(http.StatusCreated, rsp)
+}
+
+// List handles GET /payees
+// @Summary List payees
+// @Description Get a page of payees, optionally searching their names and aliases, the Link header points to the first and next pages
+// @Tags payees
+// @Accept json
+// @Produce json
+// @Param q query string false "Text contained in the name or an alias of the payee, ignoring case"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param limit query int false "Maximum number of records to return, at most 100" default(10)
+// @Param include_total query bool false "Include the total number of payees"
+// @Success 200 {object} ResponseData{data=domain.Page[domain.Payee]}
+// @Failure 400 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /api/v1/payees [get]
+func (h *payeeHandler) List(c *gin.Context) {
+	var req domain.ListPayeesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	page, err := h.service.List(c.Request.Context(), &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	setPageLinks(c, page.NextCursor)
+	handleSuccess(c, page)
+}
+
+// GetByID handles GET /payees/:id
+// @Summary Get payee by ID
+// @Description Get a specific payee by its ID
+// @Tags payees
+// @Accept json
+// @Produce json
+// @Param id path int true "Payee ID"
+// @Success 200 {object} ResponseData{data=domain.Payee}
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /api/v1/payees/{id} [get]
+func (h *payeeHandler) GetByID(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		validationError(c, err)
+		return
+	}
+
+	payee, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	setETag(c, payee.Version)
+
+	handleSuccess(c, payee)
+}
+
+// Update handles PUT /payees/:id
+// @Summary Update payee
+// @Description Update the name, aliases and defaults of an existing payee
+// @Tags payees
+// @Accept json
+// @Produce json
+// @Param id path int true "Payee ID"
+// @Param If-Match header string true "ETag of the payee version being changed"
+// @Param payee body domain.UpdatePayeeRequest true "Updated payee data"
+// @Success 200 {object} ResponseData{data=domain.Payee}
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 409 {object} ResponseError
+// @Failure 412 {object} ResponseError
+// @Failure 428 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /api/v1/payees/{id} [put]
+func (h *payeeHandler) Update(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		validationError(c, err)
+		return
+	}
+
+	var req domain.UpdatePayeeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	// The update must be based on the current version of the data
+	req.Version, err = ifMatchVersion(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	payee, err := h.service.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	setETag(c, payee.Version)
+
+	rsp := newResponse(true, "Payee updated successfully", payee)
+	c.JSON(http.StatusOK, rsp)
+}
+
+// Delete handles DELETE /payees/:id
+// @Summary Delete payee
+// @Description Delete a payee by its ID, payees with expenses cannot be deleted
+// @Tags payees
+// @Accept json
+// @Produce json
+// @Param id path int true "Payee ID"
+// @Param If-Match header string true "ETag of the payee version being changed"
+// @Success 204 "No Content"
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 409 {object} ResponseError
+// @Failure 412 {object} ResponseError
+// @Failure 428 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /api/v1/payees/{id} [delete]
+func (h *payeeHandler) Delete(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		validationError(c, err)
+		return
+	}
+
+	// The deletion must be based on the current version of the data
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	err = h.service.Delete(c.Request.Context(), id, version)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
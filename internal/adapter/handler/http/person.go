@@ -1,7 +1,6 @@
 package http
 
 import (
-	"log/slog"
 	"strconv"
 	"time"
 
@@ -41,7 +40,7 @@ type createRequest struct {
 //	@Failure		500				{object}	errorResponse	"Internal server error"
 //	@Router			/persons [post]
 func (h *PersonHandler) Create(ctx *gin.Context) {
-	slog.Info("Handling create person request")
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Handling create person request")
 	var req createRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		validationError(ctx, err)
@@ -59,42 +58,43 @@ func (h *PersonHandler) Create(ctx *gin.Context) {
 		return
 	}
 
+	setETag(ctx, person.Version)
 	rsp := newPersonResponse(&person)
 
 	handleSuccess(ctx, rsp)
 }
 
-type listPersonsRequest struct {
-	Skip  uint64 `form:"skip" example:"0"`
-	Limit uint64 `form:"limit" example:"10"`
-}
-
+// List godoc
+//
+//	@Summary		List persons
+//	@Description	get a page of persons, the Link header points to the first and next pages
+//	@Tags			Persons
+//	@Produce		json
+//	@Param			cursor			query		string	false	"Cursor returned as next_cursor by the previous page"
+//	@Param			limit			query		int		false	"Number of persons to return, at most 100"
+//	@Param			include_total	query		bool	false	"Include the total number of persons"
+//	@Success		200				{object}	domain.Page[personResponse]	"Persons listed"
+//	@Failure		400				{object}	errorResponse	"Validation error"
+//	@Failure		500				{object}	errorResponse	"Internal server error"
+//	@Router			/persons [get]
 func (h *PersonHandler) List(ctx *gin.Context) {
-	slog.Info("Handling list persons request")
-	var req listPersonsRequest
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Handling list persons request")
+	var req domain.PageRequest
 	if err := ctx.ShouldBindQuery(&req); err != nil {
 		validationError(ctx, err)
 		return
 	}
 
-	// Set default value for Limit if not provided
-	if req.Limit == 0 {
-		req.Limit = 10
-	}
-
-	persons, err := h.svc.ListPersons(ctx, req.Skip, req.Limit)
+	page, err := h.svc.ListPersons(ctx, &req)
 	if err != nil {
 		handleError(ctx, err)
 		return
 	}
 
-	slog.Info("RESPONSE from LIST", "count", len(persons), "skip", req.Skip, "limit", req.Limit)
-	var rsp []personResponse
-	for _, person := range persons {
-		rsp = append(rsp, newPersonResponse(&person))
-	}
+	rsp := mapPage(page, newPersonResponse)
 
-	slog.Info("Persons listed", "count", len(rsp), "skip", req.Skip, "limit", req.Limit)
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Persons listed", "count", len(rsp.Items), "has_more", rsp.HasMore, "limit", req.Limit)
+	setPageLinks(ctx, page.NextCursor)
 	handleSuccess(ctx, rsp)
 }
 
@@ -112,7 +112,7 @@ func (h *PersonHandler) List(ctx *gin.Context) {
 //	@Failure		500	{object}	errorResponse	"Internal server error"
 //	@Router			/persons/{id} [get]
 func (h *PersonHandler) GetByID(ctx *gin.Context) {
-	slog.Info("Handling get person by ID request")
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Handling get person by ID request")
 
 	// Get person ID from URL parameter
 	idParam := ctx.Param("id")
@@ -128,6 +128,7 @@ func (h *PersonHandler) GetByID(ctx *gin.Context) {
 		return
 	}
 
+	setETag(ctx, person.Version)
 	rsp := newPersonResponse(person)
 	handleSuccess(ctx, rsp)
 }
@@ -145,16 +146,19 @@ type updateRequest struct {
 //	@Accept			json
 //	@Produce		json
 //	@Param			id				path		int				true	"Person ID"
+//	@Param			If-Match		header		string			true	"ETag of the person version being changed"
 //	@Param			updateRequest	body		updateRequest	true	"Update request"
 //	@Success		200				{object}	personResponse	"Person updated"
 //	@Failure		400				{object}	errorResponse	"Validation error"
 //	@Failure		401				{object}	errorResponse	"Unauthorized error"
 //	@Failure		404				{object}	errorResponse	"Data not found error"
 //	@Failure		409				{object}	errorResponse	"Data conflict error"
+//	@Failure		412				{object}	errorResponse	"Precondition failed error"
+//	@Failure		428				{object}	errorResponse	"Precondition required error"
 //	@Failure		500				{object}	errorResponse	"Internal server error"
 //	@Router			/persons/{id} [put]
 func (h *PersonHandler) Update(ctx *gin.Context) {
-	slog.Info("Handling update person request")
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Handling update person request")
 
 	// Get person ID from URL parameter
 	idParam := ctx.Param("id")
@@ -170,10 +174,18 @@ func (h *PersonHandler) Update(ctx *gin.Context) {
 		return
 	}
 
+	// The update must be based on the current version of the person
+	version, err := ifMatchVersion(ctx)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
 	person := domain.Person{
-		ID:    id,
-		Name:  req.Name,
-		Email: req.Email,
+		ID:      id,
+		Name:    req.Name,
+		Email:   req.Email,
+		Version: version,
 	}
 
 	updatedPerson, err := h.svc.UpdatePerson(ctx, &person)
@@ -182,6 +194,62 @@ func (h *PersonHandler) Update(ctx *gin.Context) {
 		return
 	}
 
+	setETag(ctx, updatedPerson.Version)
+	rsp := newPersonResponse(updatedPerson)
+	handleSuccess(ctx, rsp)
+}
+
+// Patch godoc
+//
+//	@Summary		Patch a person
+//	@Description	partially update an existing person with a JSON merge patch, only the present fields are changed
+//	@Tags			Persons
+//	@Accept			application/merge-patch+json
+//	@Produce		json
+//	@Param			id			path		int							true	"Person ID"
+//	@Param			If-Match	header		string						true	"ETag of the person version being changed"
+//	@Param			patch		body		domain.PatchPersonRequest	true	"Merge patch"
+//	@Success		200			{object}	personResponse				"Person updated"
+//	@Failure		400			{object}	errorResponse				"Validation error"
+//	@Failure		401			{object}	errorResponse				"Unauthorized error"
+//	@Failure		404			{object}	errorResponse				"Data not found error"
+//	@Failure		409			{object}	errorResponse				"Data conflict error"
+//	@Failure		412			{object}	errorResponse				"Precondition failed error"
+//	@Failure		415			{object}	errorResponse				"Unsupported media type error"
+//	@Failure		428			{object}	errorResponse				"Precondition required error"
+//	@Failure		500			{object}	errorResponse				"Internal server error"
+//	@Router			/persons/{id} [patch]
+func (h *PersonHandler) Patch(ctx *gin.Context) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Handling patch person request")
+
+	// Get person ID from URL parameter
+	idParam := ctx.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 64)
+	if err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	var req domain.PatchPersonRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	// The patch must be based on the current version of the person
+	req.Version, err = ifMatchVersion(ctx)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	updatedPerson, err := h.svc.PatchPerson(ctx, id, &req)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	setETag(ctx, updatedPerson.Version)
 	rsp := newPersonResponse(updatedPerson)
 	handleSuccess(ctx, rsp)
 }
@@ -193,14 +261,17 @@ func (h *PersonHandler) Update(ctx *gin.Context) {
 //	@Tags			Persons
 //	@Produce		json
 //	@Param			id	path		int	true	"Person ID"
+//	@Param			If-Match	header		string	true	"ETag of the person version being changed"
 //	@Success		200	{object}	map[string]string	"Person deleted successfully"
 //	@Failure		400	{object}	errorResponse		"Validation error"
 //	@Failure		401	{object}	errorResponse		"Unauthorized error"
 //	@Failure		404	{object}	errorResponse		"Data not found error"
+//	@Failure		412	{object}	errorResponse		"Precondition failed error"
+//	@Failure		428	{object}	errorResponse		"Precondition required error"
 //	@Failure		500	{object}	errorResponse		"Internal server error"
 //	@Router			/persons/{id} [delete]
 func (h *PersonHandler) Delete(ctx *gin.Context) {
-	slog.Info("Handling delete person request")
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Handling delete person request")
 
 	// Get person ID from URL parameter
 	idParam := ctx.Param("id")
@@ -210,7 +281,14 @@ func (h *PersonHandler) Delete(ctx *gin.Context) {
 		return
 	}
 
-	err = h.svc.DeletePerson(ctx, id)
+	// The deletion must be based on the current version of the person
+	version, err := ifMatchVersion(ctx)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	err = h.svc.DeletePerson(ctx, id, version)
 	if err != nil {
 		handleError(ctx, err)
 		return
@@ -224,6 +302,7 @@ type personResponse struct {
 	ID        uint64    `json:"id" example:"1"`
 	Name      string    `json:"name" example:"John Doe"`
 	Email     string    `json:"email" example:"test@example.com"`
+	Version   int       `json:"version" example:"1"`
 	CreatedAt time.Time `json:"created_at" example:"1970-01-01T00:00:00Z"`
 	UpdatedAt time.Time `json:"updated_at" example:"1970-01-01T00:00:00Z"`
 }
@@ -234,6 +313,7 @@ func newPersonResponse(person *domain.Person) personResponse {
 		ID:        person.ID,
 		Name:      person.Name,
 		Email:     person.Email,
+		Version:   person.Version,
 		CreatedAt: person.CreatedAt,
 		UpdatedAt: person.UpdatedAt,
 	}
@@ -1,27 +1,55 @@
 package http
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"reflect"
+	"strings"
 
 	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 )
 
-// errorResponse represents an error response body format
+// problemContentType is the media type of error responses, see RFC 7807
+const problemContentType = "application/problem+json"
+
+// errorResponse represents an error response body format, an RFC 7807 problem details object
 type errorResponse struct {
-	Success  bool     `json:"success" example:"false"`
-	Messages []string `json:"messages" example:"Error message 1, Error message 2"`
+	Type       string              `json:"type" example:"about:blank"`
+	Title      string              `json:"title" example:"Bad Request"`
+	Status     int                 `json:"status" example:"400"`
+	Code       string              `json:"code" example:"invalid_input"`
+	Detail     string              `json:"detail" example:"invalid input: amount must be greater than 0"`
+	Instance   string              `json:"instance,omitempty" example:"/api/v1/expenses"`
+	Errors     []domain.FieldError `json:"errors,omitempty"`
+	Constraint string              `json:"constraint,omitempty" example:"uk_tags_name"`
+}
+
+// errorStatus is the http status code and the stable machine readable code of a defined error
+type errorStatus struct {
+	statusCode int
+	code       string
 }
 
-// errorStatusMap is a map of defined error messages and their corresponding http status codes
-var errorStatusMap = map[error]int{
-	domain.ErrInternal:        http.StatusInternalServerError,
-	domain.ErrDataNotFound:    http.StatusNotFound,
-	domain.ErrConflictingData: http.StatusConflict,
-	domain.ErrNoUpdatedData:   http.StatusBadRequest,
-	domain.ErrInvalidInput:    http.StatusBadRequest,
+// errorStatusMap is a map of defined error messages and their corresponding http status codes and error codes
+var errorStatusMap = map[error]errorStatus{
+	domain.ErrInternal:                     {http.StatusInternalServerError, "internal_error"},
+	domain.ErrDataNotFound:                 {http.StatusNotFound, "not_found"},
+	domain.ErrConflictingData:              {http.StatusConflict, "conflicting_data"},
+	domain.ErrNoUpdatedData:                {http.StatusBadRequest, "no_updated_data"},
+	domain.ErrInvalidInput:                 {http.StatusBadRequest, "invalid_input"},
+	domain.ErrPreconditionFailed:           {http.StatusPreconditionFailed, "precondition_failed"},
+	domain.ErrPreconditionRequired:         {http.StatusPreconditionRequired, "precondition_required"},
+	domain.ErrIdempotencyKeyReused:         {http.StatusUnprocessableEntity, "idempotency_key_reused"},
+	domain.ErrIdempotencyRequestInProgress: {http.StatusConflict, "idempotency_request_in_progress"},
+	domain.ErrPayloadTooLarge:              {http.StatusRequestEntityTooLarge, "payload_too_large"},
+	domain.ErrUnsupportedMediaType:         {http.StatusUnsupportedMediaType, "unsupported_media_type"},
+	domain.ErrForbidden:                    {http.StatusForbidden, "forbidden"},
+	domain.ErrReferencedData:               {http.StatusConflict, "referenced_data"},
+	domain.ErrInvalidReference:             {http.StatusUnprocessableEntity, "invalid_reference"},
 }
 
 // response represents a response body format
@@ -40,46 +68,167 @@ func newResponse(success bool, message string, data any) response {
 	}
 }
 
-// newErrorResponse is a helper function to create an error response body
-func newErrorResponse(errMsgs []string) errorResponse {
-	return errorResponse{
-		Success:  false,
-		Messages: errMsgs,
+// newErrorResponse is a helper function to create an error response body,
+// the detail of a validation error sums up the fields at fault
+func newErrorResponse(ctx *gin.Context, status errorStatus, err error) errorResponse {
+	errRsp := errorResponse{
+		Type:     "about:blank",
+		Title:    http.StatusText(status.statusCode),
+		Status:   status.statusCode,
+		Code:     status.code,
+		Detail:   err.Error(),
+		Instance: ctx.Request.URL.Path,
+		Errors:   parseError(err),
 	}
+	if len(errRsp.Errors) > 0 {
+		errRsp.Detail = (&domain.ValidationError{Fields: errRsp.Errors}).Error()
+	}
+	return errRsp
 }
 
-// parseError parses error messages from the error object and returns a slice of error messages
-func parseError(err error) []string {
-	var errMsgs []string
+// parseError returns the fields at fault in a validation error, or nil for any other error
+func parseError(err error) []domain.FieldError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fieldErrs := make([]domain.FieldError, len(validationErrs))
+		for i, fe := range validationErrs {
+			fieldErrs[i] = domain.FieldError{
+				Field:   fieldPath(fe.Namespace()),
+				Rule:    fe.Tag(),
+				Message: ruleMessage(fe),
+			}
+		}
+		return fieldErrs
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []domain.FieldError{{Field: typeErr.Field, Rule: "type", Message: "must be " + typeName(typeErr.Type)}}
+	}
 
-	if errors.As(err, &validator.ValidationErrors{}) {
-		for _, err := range err.(validator.ValidationErrors) {
-			errMsgs = append(errMsgs, err.Error())
+	var domainErr *domain.ValidationError
+	if errors.As(err, &domainErr) {
+		return domainErr.Fields
+	}
+	return nil
+}
+
+// fieldPath strips the name of the validated struct from the namespace of a field
+func fieldPath(namespace string) string {
+	if _, path, ok := strings.Cut(namespace, "."); ok {
+		return path
+	}
+	return namespace
+}
+
+// ruleMessage explains the validation rule a field broke
+func ruleMessage(fe validator.FieldError) string {
+	// Lengths apply to strings, slices and maps, values to the other kinds
+	counted := fe.Kind() == reflect.String || fe.Kind() == reflect.Slice || fe.Kind() == reflect.Map
+	unit := "characters"
+	if fe.Kind() != reflect.String {
+		unit = "items"
+	}
+
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(fe.Param()), ", ")
+	case "min", "gte":
+		if counted {
+			return fmt.Sprintf("must have at least %s %s", fe.Param(), unit)
+		}
+		return "must be at least " + fe.Param()
+	case "max", "lte":
+		if counted {
+			return fmt.Sprintf("must have at most %s %s", fe.Param(), unit)
+		}
+		return "must be at most " + fe.Param()
+	case "gt":
+		if counted {
+			return fmt.Sprintf("must have more than %s %s", fe.Param(), unit)
+		}
+		return "must be greater than " + fe.Param()
+	case "lt":
+		if counted {
+			return fmt.Sprintf("must have less than %s %s", fe.Param(), unit)
+		}
+		return "must be less than " + fe.Param()
+	case "len":
+		if counted {
+			return fmt.Sprintf("must have exactly %s %s", fe.Param(), unit)
+		}
+		return "must be " + fe.Param()
+	}
+	if fe.Param() != "" {
+		return fmt.Sprintf("must satisfy %s=%s", fe.Tag(), fe.Param())
+	}
+	return "must satisfy " + fe.Tag()
+}
+
+// typeName names a JSON type for the go type a value failed to decode into
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	}
+	return "an object"
+}
+
+// jsonFieldName names struct fields in validation errors as the clients send them:
+// by their json tag, or their form tag for query parameters
+func jsonFieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
 		}
-	} else {
-		errMsgs = append(errMsgs, err.Error())
 	}
+	return field.Name
+}
 
-	return errMsgs
+// writeError sends an error response as problem details
+func writeError(ctx *gin.Context, status errorStatus, err error) {
+	errRsp := newErrorResponse(ctx, status, err)
+	var constraintErr *domain.ConstraintError
+	if errors.As(err, &constraintErr) {
+		errRsp.Constraint = constraintErr.Constraint
+	}
+	ctx.Header("Content-Type", problemContentType)
+	ctx.JSON(status.statusCode, errRsp)
 }
 
 // validationError sends an error response for some specific request validation error
 func validationError(ctx *gin.Context, err error) {
-	errMsgs := parseError(err)
-	errRsp := newErrorResponse(errMsgs)
-	ctx.JSON(http.StatusBadRequest, errRsp)
+	writeError(ctx, errorStatusMap[domain.ErrInvalidInput], err)
 }
 
-// handleError determines the status code of an error and returns a JSON response with the error message and status code
+// handleError determines the status code of an error and returns a problem details response with the error message and status code.
+// Errors wrapping a defined error get its status code and error code, a violated constraint is named in the response.
 func handleError(ctx *gin.Context, err error) {
-	statusCode, ok := errorStatusMap[err]
-	if !ok {
-		statusCode = http.StatusInternalServerError
+	status := errorStatusMap[domain.ErrInternal]
+	for definedErr, definedStatus := range errorStatusMap {
+		if errors.Is(err, definedErr) {
+			status = definedStatus
+			break
+		}
 	}
-
-	errMsg := parseError(err)
-	errRsp := newErrorResponse(errMsg)
-	ctx.JSON(statusCode, errRsp)
+	writeError(ctx, status, err)
 }
 
 // handleSuccess sends a success response with the specified status code and optional data
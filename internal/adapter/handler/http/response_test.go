@@ -0,0 +1,193 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// serveError answers a request to path with the problem details written by respond
+func serveError(t *testing.T, method, path, body string, respond func(ctx *gin.Context)) (*httptest.ResponseRecorder, errorResponse) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Handle(method, path, respond)
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Content-Type"); got != problemContentType {
+		t.Errorf("Content-Type = %q, want %q", got, problemContentType)
+	}
+	var errRsp errorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &errRsp); err != nil {
+		t.Fatalf("decode problem details %q: %v", rec.Body.String(), err)
+	}
+	return rec, errRsp
+}
+
+func TestHandleError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		wantStatus     int
+		wantCode       string
+		wantDetail     string
+		wantFields     []domain.FieldError
+		wantConstraint string
+	}{
+		{"internal", domain.ErrInternal, http.StatusInternalServerError, "internal_error", domain.ErrInternal.Error(), nil, ""},
+		{"unknown", errors.New("connection reset"), http.StatusInternalServerError, "internal_error", "connection reset", nil, ""},
+		{"not found", domain.ErrDataNotFound, http.StatusNotFound, "not_found", domain.ErrDataNotFound.Error(), nil, ""},
+		{
+			"wrapped not found", fmt.Errorf("getting expense 7: %w", domain.ErrDataNotFound),
+			http.StatusNotFound, "not_found", "getting expense 7: " + domain.ErrDataNotFound.Error(), nil, "",
+		},
+		{"conflicting data", domain.ErrConflictingData, http.StatusConflict, "conflicting_data", domain.ErrConflictingData.Error(), nil, ""},
+		{"no updated data", domain.ErrNoUpdatedData, http.StatusBadRequest, "no_updated_data", domain.ErrNoUpdatedData.Error(), nil, ""},
+		{"invalid input", domain.ErrInvalidInput, http.StatusBadRequest, "invalid_input", domain.ErrInvalidInput.Error(), nil, ""},
+		{"precondition failed", domain.ErrPreconditionFailed, http.StatusPreconditionFailed, "precondition_failed", domain.ErrPreconditionFailed.Error(), nil, ""},
+		{"precondition required", domain.ErrPreconditionRequired, http.StatusPreconditionRequired, "precondition_required", domain.ErrPreconditionRequired.Error(), nil, ""},
+		{"idempotency key reused", domain.ErrIdempotencyKeyReused, http.StatusUnprocessableEntity, "idempotency_key_reused", domain.ErrIdempotencyKeyReused.Error(), nil, ""},
+		{"idempotency request in progress", domain.ErrIdempotencyRequestInProgress, http.StatusConflict, "idempotency_request_in_progress", domain.ErrIdempotencyRequestInProgress.Error(), nil, ""},
+		{"payload too large", domain.ErrPayloadTooLarge, http.StatusRequestEntityTooLarge, "payload_too_large", domain.ErrPayloadTooLarge.Error(), nil, ""},
+		{"unsupported media type", domain.ErrUnsupportedMediaType, http.StatusUnsupportedMediaType, "unsupported_media_type", domain.ErrUnsupportedMediaType.Error(), nil, ""},
+		{"forbidden", domain.ErrForbidden, http.StatusForbidden, "forbidden", domain.ErrForbidden.Error(), nil, ""},
+		{"referenced data", domain.ErrReferencedData, http.StatusConflict, "referenced_data", domain.ErrReferencedData.Error(), nil, ""},
+		{"invalid reference", domain.ErrInvalidReference, http.StatusUnprocessableEntity, "invalid_reference", domain.ErrInvalidReference.Error(), nil, ""},
+		{
+			"constraint", &domain.ConstraintError{Err: domain.ErrConflictingData, Constraint: "uk_tags_name"},
+			http.StatusConflict, "conflicting_data", domain.ErrConflictingData.Error(), nil, "uk_tags_name",
+		},
+		{
+			"wrapped constraint", fmt.Errorf("deleting person 3: %w", &domain.ConstraintError{Err: domain.ErrReferencedData, Constraint: "account_primary_owner_id_fkey"}),
+			http.StatusConflict, "referenced_data", "deleting person 3: " + domain.ErrReferencedData.Error(), nil, "account_primary_owner_id_fkey",
+		},
+		{
+			"validation", domain.InvalidField("date", "datetime", "must be a date formatted as YYYY-MM-DD"),
+			http.StatusBadRequest, "invalid_input", domain.ErrInvalidInput.Error() + ": date must be a date formatted as YYYY-MM-DD",
+			[]domain.FieldError{{Field: "date", Rule: "datetime", Message: "must be a date formatted as YYYY-MM-DD"}}, "",
+		},
+		{
+			"wrapped validation", fmt.Errorf("creating expense: %w", &domain.ValidationError{Fields: []domain.FieldError{
+				{Field: "splits", Rule: "sum", Message: "must add up to the amount"},
+				{Field: "splits[1].amount", Rule: "gt", Message: "must be greater than 0"},
+			}}),
+			http.StatusBadRequest, "invalid_input",
+			domain.ErrInvalidInput.Error() + ": splits must add up to the amount; splits[1].amount must be greater than 0",
+			[]domain.FieldError{
+				{Field: "splits", Rule: "sum", Message: "must add up to the amount"},
+				{Field: "splits[1].amount", Rule: "gt", Message: "must be greater than 0"},
+			}, "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, errRsp := serveError(t, http.MethodGet, "/api/v1/expenses", "", func(ctx *gin.Context) {
+				handleError(ctx, tt.err)
+			})
+
+			if rec.Code != tt.wantStatus || errRsp.Status != tt.wantStatus {
+				t.Errorf("status = %d with %d in the body, want %d", rec.Code, errRsp.Status, tt.wantStatus)
+			}
+			if errRsp.Type != "about:blank" || errRsp.Title != http.StatusText(tt.wantStatus) || errRsp.Instance != "/api/v1/expenses" {
+				t.Errorf("type, title and instance = %q, %q, %q", errRsp.Type, errRsp.Title, errRsp.Instance)
+			}
+			if errRsp.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", errRsp.Code, tt.wantCode)
+			}
+			if errRsp.Detail != tt.wantDetail {
+				t.Errorf("detail = %q, want %q", errRsp.Detail, tt.wantDetail)
+			}
+			if !slices.Equal(errRsp.Errors, tt.wantFields) {
+				t.Errorf("errors = %+v, want %+v", errRsp.Errors, tt.wantFields)
+			}
+			if errRsp.Constraint != tt.wantConstraint {
+				t.Errorf("constraint = %q, want %q", errRsp.Constraint, tt.wantConstraint)
+			}
+		})
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	// Fields are named as in the router
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(jsonFieldName)
+	}
+
+	type line struct {
+		Quantity int `json:"quantity" binding:"min=1"`
+	}
+	type request struct {
+		Name   string   `json:"name" binding:"required,max=5"`
+		Email  string   `json:"email" binding:"omitempty,email"`
+		Amount float64  `json:"amount" binding:"gt=0"`
+		Kind   string   `json:"kind" binding:"omitempty,oneof=fixed variable"`
+		Tags   []string `json:"tags" binding:"max=2"`
+		Lines  []line   `json:"lines" binding:"dive"`
+		Ignore string   `json:"-"`
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		wantFields []domain.FieldError
+	}{
+		{
+			name: "rules",
+			body: `{"email":"nope","amount":0,"kind":"other","tags":["a","b","c"],"lines":[{"quantity":1},{"quantity":0}]}`,
+			wantFields: []domain.FieldError{
+				{Field: "name", Rule: "required", Message: "is required"},
+				{Field: "email", Rule: "email", Message: "must be a valid email address"},
+				{Field: "amount", Rule: "gt", Message: "must be greater than 0"},
+				{Field: "kind", Rule: "oneof", Message: "must be one of fixed, variable"},
+				{Field: "tags", Rule: "max", Message: "must have at most 2 items"},
+				{Field: "lines[1].quantity", Rule: "min", Message: "must be at least 1"},
+			},
+		},
+		{
+			name:       "length",
+			body:       `{"name":"Margaret","amount":1}`,
+			wantFields: []domain.FieldError{{Field: "name", Rule: "max", Message: "must have at most 5 characters"}},
+		},
+		{
+			name:       "type",
+			body:       `{"name":"Ada","amount":"ten"}`,
+			wantFields: []domain.FieldError{{Field: "amount", Rule: "type", Message: "must be a number"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, errRsp := serveError(t, http.MethodPost, "/api/v1/expenses", tt.body, func(ctx *gin.Context) {
+				var req request
+				if err := ctx.ShouldBindJSON(&req); err != nil {
+					validationError(ctx, err)
+					return
+				}
+				t.Error("request bound without an error")
+			})
+
+			if rec.Code != http.StatusBadRequest || errRsp.Code != "invalid_input" {
+				t.Errorf("status and code = %d, %q, want %d, %q", rec.Code, errRsp.Code, http.StatusBadRequest, "invalid_input")
+			}
+			if !slices.Equal(errRsp.Errors, tt.wantFields) {
+				t.Errorf("errors = %+v, want %+v", errRsp.Errors, tt.wantFields)
+			}
+			if want := (&domain.ValidationError{Fields: tt.wantFields}).Error(); errRsp.Detail != want {
+				t.Errorf("detail = %q, want %q", errRsp.Detail, want)
+			}
+		})
+	}
+}
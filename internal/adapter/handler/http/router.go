@@ -1,13 +1,20 @@
 package http
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/edwins-leonardi/finaid-api/internal/adapter/config"
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/metrics"
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/tracing"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
 	sloggin "github.com/samber/slog-gin"
 )
 
@@ -23,6 +30,16 @@ func NewRouter(
 	expenseCategoryHandler ExpenseCategoryHandler,
 	expenseSubCategoryHandler ExpenseSubCategoryHandler,
 	expenseHandler ExpenseHandler,
+	tagHandler TagHandler,
+	payeeHandler PayeeHandler,
+	mergeHandler MergeHandler,
+	savedViewHandler SavedViewHandler,
+	settlementHandler SettlementHandler,
+	attachmentHandler AttachmentHandler,
+	auditHandler AuditHandler,
+	idempotencyService port.IdempotencyService,
+	schemaMigrator port.SchemaMigrator,
+	appMetrics *metrics.Metrics,
 ) (*Router, error) {
 
 	// Disable debug mode in production
@@ -46,16 +63,37 @@ func NewRouter(
 
 	// Allow credentials and common headers
 	ginConfig.AllowCredentials = true
-	ginConfig.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization"}
+	ginConfig.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization", actorHeader, requestIDHeader, ifMatchHeader, idempotencyKeyHeader, "traceparent", "tracestate"}
+	// Let browser clients read the version of the returned data, whether a response was replayed
+	// the links between pages and the id of the request
+	ginConfig.ExposeHeaders = []string{etagHeader, idempotentReplayedHeader, linkHeader, requestIDHeader}
 
-	router := gin.New()
-	router.Use(sloggin.New(slog.Default()), gin.Recovery(), cors.New(ginConfig))
+	// Name invalid fields in error responses as the clients send them
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(jsonFieldName)
+	}
 
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status": "healthy",
-		})
+	router := gin.New()
+	// Let handlers that pass *gin.Context to services expose the request context values
+	router.ContextWithFallback = true
+	// The span and the request id come first so that the request log line carries them, and metrics
+	// come before the recovery so that requests ending in a panic count as 500 responses
+	requestLogger := sloggin.NewWithConfig(slog.Default(), sloggin.Config{
+		DefaultLevel:     slog.LevelInfo,
+		ClientErrorLevel: slog.LevelWarn,
+		ServerErrorLevel: slog.LevelError,
+		// The request id is resolved by requestContext, which adds it to the log line
+		WithRequestID: false,
 	})
+	router.Use(tracing.Middleware(), requestContext(), requestLogger, appMetrics.Middleware(), gin.Recovery(), cors.New(ginConfig))
+
+	router.GET("/health", health(schemaMigrator))
+	router.GET("/metrics", gin.WrapH(appMetrics.Handler()))
+	// Create endpoints replay the original response when retried with the same Idempotency-Key
+	idempotency := idempotent(idempotencyService)
+	// Patch endpoints only take JSON merge patches
+	patch := mergePatch()
+
 	v1 := router.Group("/api/v1")
 	{
 		hello := v1.Group("/hello")
@@ -69,45 +107,103 @@ func NewRouter(
 		user := v1.Group("/persons")
 		{
 			user.GET("", personHandler.List)
-			user.POST("", personHandler.Create)
+			user.POST("", idempotency, personHandler.Create)
 			user.GET("/:id", personHandler.GetByID)
 			user.PUT("/:id", personHandler.Update)
+			user.PATCH("/:id", patch, personHandler.Patch)
 			user.DELETE("/:id", personHandler.Delete)
 		}
 		account := v1.Group("/accounts")
 		{
 			account.GET("", accountHandler.List)
-			account.POST("", accountHandler.Create)
+			account.POST("", idempotency, accountHandler.Create)
 			account.GET("/:id", accountHandler.GetByID)
 			account.PUT("/:id", accountHandler.Update)
+			account.PATCH("/:id", patch, accountHandler.Patch)
 			account.DELETE("/:id", accountHandler.Delete)
+			account.POST("/:id/restore", accountHandler.Restore)
 		}
 		expenses := v1.Group("/expenses")
 		{
 			// Main expense routes
 			expenses.GET("", expenseHandler.ListExpenses)
-			expenses.POST("", expenseHandler.CreateExpense)
+			expenses.POST("", idempotency, expenseHandler.CreateExpense)
+			expenses.POST("/bulk", idempotency, expenseHandler.BulkExpenses)
 			expenses.GET("/:id", expenseHandler.GetExpense)
 			expenses.PUT("/:id", expenseHandler.UpdateExpense)
+			expenses.PATCH("/:id", patch, expenseHandler.PatchExpense)
 			expenses.DELETE("/:id", expenseHandler.DeleteExpense)
+			expenses.POST("/:id/restore", expenseHandler.RestoreExpense)
+			expenses.GET("/:id/attachments", attachmentHandler.List)
+			expenses.POST("/:id/attachments", limitBody(maxUploadSize), idempotency, attachmentHandler.Upload)
+			expenses.GET("/:id/attachments/:attachment_id", attachmentHandler.GetByID)
+			expenses.GET("/:id/attachments/:attachment_id/download", attachmentHandler.Download)
+			expenses.DELETE("/:id/attachments/:attachment_id", attachmentHandler.Delete)
 
 			expenseCategory := expenses.Group("/categories")
 			{
 				expenseCategory.GET("", expenseCategoryHandler.List)
-				expenseCategory.POST("", expenseCategoryHandler.Create)
+				expenseCategory.POST("", idempotency, expenseCategoryHandler.Create)
 				expenseCategory.GET("/:id", expenseCategoryHandler.GetByID)
 				expenseCategory.PUT("/:id", expenseCategoryHandler.Update)
+				expenseCategory.PATCH("/:id", patch, expenseCategoryHandler.Patch)
 				expenseCategory.DELETE("/:id", expenseCategoryHandler.Delete)
+				expenseCategory.POST("/:id/restore", expenseCategoryHandler.Restore)
+				expenseCategory.POST("/:id/merge", mergeHandler.MergeExpenseCategories)
 
 				expenseSubCategory := expenseCategory.Group("/subcategories")
 				{
 					expenseSubCategory.GET("", expenseSubCategoryHandler.List)
-					expenseSubCategory.POST("", expenseSubCategoryHandler.Create)
+					expenseSubCategory.POST("", idempotency, expenseSubCategoryHandler.Create)
 					expenseSubCategory.GET("/:id", expenseSubCategoryHandler.GetByID)
 					expenseSubCategory.PUT("/:id", expenseSubCategoryHandler.Update)
+					expenseSubCategory.PATCH("/:id", patch, expenseSubCategoryHandler.Patch)
 					expenseSubCategory.DELETE("/:id", expenseSubCategoryHandler.Delete)
+					expenseSubCategory.POST("/:id/restore", expenseSubCategoryHandler.Restore)
+					expenseSubCategory.POST("/:id/merge", mergeHandler.MergeExpenseSubCategories)
 				}
 			}
+
+			tag := expenses.Group("/tags")
+			{
+				tag.GET("", tagHandler.List)
+				tag.POST("", idempotency, tagHandler.Create)
+				tag.GET("/totals", expenseHandler.ListTagTotals)
+				tag.GET("/:id", tagHandler.GetByID)
+				tag.PUT("/:id", tagHandler.Update)
+				tag.DELETE("/:id", tagHandler.Delete)
+			}
+		}
+		payees := v1.Group("/payees")
+		{
+			payees.GET("", payeeHandler.List)
+			payees.POST("", idempotency, payeeHandler.Create)
+			payees.GET("/:id", payeeHandler.GetByID)
+			payees.PUT("/:id", payeeHandler.Update)
+			payees.DELETE("/:id", payeeHandler.Delete)
+			payees.POST("/:id/merge", mergeHandler.MergePayees)
+		}
+		v1.GET("/merges", mergeHandler.List)
+		views := v1.Group("/views")
+		{
+			views.GET("", savedViewHandler.List)
+			views.POST("", idempotency, savedViewHandler.Create)
+			views.GET("/:id", savedViewHandler.GetByID)
+			views.PUT("/:id", savedViewHandler.Update)
+			views.DELETE("/:id", savedViewHandler.Delete)
+			views.GET("/:id/expenses", savedViewHandler.ListExpenses)
+		}
+		settlements := v1.Group("/settlements")
+		{
+			settlements.GET("", settlementHandler.List)
+			settlements.POST("", idempotency, settlementHandler.Create)
+			settlements.GET("/:id", settlementHandler.GetByID)
+			settlements.DELETE("/:id", settlementHandler.Delete)
+		}
+		v1.GET("/balances", settlementHandler.Balances)
+		audit := v1.Group("/audit")
+		{
+			audit.GET("", auditHandler.List)
 		}
 	}
 
@@ -116,7 +212,28 @@ func NewRouter(
 	}, nil
 }
 
-// Serve starts the HTTP server
-func (r *Router) Serve(listenAddr string) error {
-	return r.Run(listenAddr)
+// shutdownTimeout bounds how long in-flight requests get to finish once the server stops
+const shutdownTimeout = 10 * time.Second
+
+// Serve starts the HTTP server and stops it gracefully once ctx is done
+func (r *Router) Serve(ctx context.Context, listenAddr string) error {
+	server := &http.Server{
+		Addr:    listenAddr,
+		Handler: r.Engine,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
 }
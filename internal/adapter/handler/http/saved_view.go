@@ -0,0 +1,249 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+	"github.com/gin-gonic/gin"
+)
+
+// SavedViewHandler handles HTTP requests for saved expense views
+type SavedViewHandler interface {
+	Create(c *gin.Context)
+	List(c *gin.Context)
+	GetByID(c *gin.Context)
+	Update(c *gin.Context)
+	Delete(c *gin.Context)
+	ListExpenses(c *gin.Context)
+}
+
+type savedViewHandler struct {
+	service port.SavedViewService
+}
+
+// NewSavedViewHandler creates a new saved view HTTP handler
+func NewSavedViewHandler(service port.SavedViewService) SavedViewHandler {
+	return &savedViewHandler{
+		service: service,
+	}
+}
+
+// Create handles POST /views
+// @Summary Create a new saved view
+// @Description Save a named set of expense filters, the date range is either fixed or relative (this_month, last_month, this_quarter, last_quarter, this_year, last_year, year_to_date, last_<n>_days)
+// @Tags views
+// @Accept json
+// @Produce json
+// @Param view body domain.CreateSavedViewRequest true "Saved view data"
+// @Success 201 {object} ResponseData{data=domain.SavedView}
+// @Failure 400 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /api/v1/views [post]
+func (h *savedViewHandler) Create(c *gin.Context) {
+	var req domain.CreateSavedViewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	view, err := h.service.Create(c.Request.Context(), &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	setETag(c, view.Version)
+
+	rsp := newResponse(true, "Saved view created successfully", view)
+	c.JSON(http.StatusCreated, rsp)
+}
+
+// List handles GET /views
+// @Summary List saved views
+// @Description Get a page of saved views, the Link header points to the first and next pages
+// @Tags views
+// @Accept json
+// @Produce json
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param limit query int false "Maximum number of records to return, at most 100" default(10)
+// @Param include_total query bool false "Include the total number of saved views"
+// @Success 200 {object} ResponseData{data=domain.Page[domain.SavedView]}
+// @Failure 400 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /api/v1/views [get]
+func (h *savedViewHandler) List(c *gin.Context) {
+	var req domain.ListSavedViewsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	page, err := h.service.List(c.Request.Context(), &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	setPageLinks(c, page.NextCursor)
+	handleSuccess(c, page)
+}
+
+// GetByID handles GET /views/:id
+// @Summary Get saved view by ID
+// @Description Get a specific saved view by its ID
+// @Tags views
+// @Accept json
+// @Produce json
+// @Param id path int true "Saved view ID"
+// @Success 200 {object} ResponseData{data=domain.SavedView}
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /api/v1/views/{id} [get]
+func (h *savedViewHandler) GetByID(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		validationError(c, err)
+		return
+	}
+
+	view, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	setETag(c, view.Version)
+
+	handleSuccess(c, view)
+}
+
+// Update handles PUT /views/:id
+// @Summary Update saved view
+// @Description Replace the name and filters of an existing saved view
+// @Tags views
+// @Accept json
+// @Produce json
+// @Param id path int true "Saved view ID"
+// @Param If-Match header string true "ETag of the saved view version being changed"
+// @Param view body domain.UpdateSavedViewRequest true "Updated saved view data"
+// @Success 200 {object} ResponseData{data=domain.SavedView}
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 412 {object} ResponseError
+// @Failure 428 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /api/v1/views/{id} [put]
+func (h *savedViewHandler) Update(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		validationError(c, err)
+		return
+	}
+
+	var req domain.UpdateSavedViewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	// The update must be based on the current version of the data
+	req.Version, err = ifMatchVersion(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	view, err := h.service.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	setETag(c, view.Version)
+
+	rsp := newResponse(true, "Saved view updated successfully", view)
+	c.JSON(http.StatusOK, rsp)
+}
+
+// Delete handles DELETE /views/:id
+// @Summary Delete saved view
+// @Description Delete a saved view by its ID, its expenses are left untouched
+// @Tags views
+// @Accept json
+// @Produce json
+// @Param id path int true "Saved view ID"
+// @Param If-Match header string true "ETag of the saved view version being changed"
+// @Success 204 "No Content"
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 412 {object} ResponseError
+// @Failure 428 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /api/v1/views/{id} [delete]
+func (h *savedViewHandler) Delete(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		validationError(c, err)
+		return
+	}
+
+	// The deletion must be based on the current version of the data
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	err = h.service.Delete(c.Request.Context(), id, version)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListExpenses handles GET /views/:id/expenses
+// @Summary List the expenses of a saved view
+// @Description Run the search of a saved view, resolving its relative date range on the current day. The Link header points to the first and next pages
+// @Tags views
+// @Accept json
+// @Produce json
+// @Param id path int true "Saved view ID"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param limit query int false "Maximum number of records to return, at most 100" default(10)
+// @Param include_total query bool false "Include the total number of matching expenses"
+// @Success 200 {object} ResponseData{data=domain.Page[domain.Expense]}
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /api/v1/views/{id}/expenses [get]
+func (h *savedViewHandler) ListExpenses(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		validationError(c, err)
+		return
+	}
+
+	var req domain.PageRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	page, err := h.service.ListExpenses(c.Request.Context(), id, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	setPageLinks(c, page.NextCursor)
+	handleSuccess(c, page)
+}
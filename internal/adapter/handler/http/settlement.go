@@ -0,0 +1,181 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+	"github.com/gin-gonic/gin"
+)
+
+// SettlementHandler handles HTTP requests for settlements between persons and their balances
+type SettlementHandler interface {
+	Create(c *gin.Context)
+	List(c *gin.Context)
+	GetByID(c *gin.Context)
+	Delete(c *gin.Context)
+	Balances(c *gin.Context)
+}
+
+type settlementHandler struct {
+	service port.SettlementService
+}
+
+// NewSettlementHandler creates a new settlement HTTP handler
+func NewSettlementHandler(service port.SettlementService) SettlementHandler {
+	return &settlementHandler{
+		service: service,
+	}
+}
+
+// Create handles POST /settlements
+// @Summary Record a settlement
+// @Description Record a payment from a person to another one settling shared expenses
+// @Tags settlements
+// @Accept json
+// @Produce json
+// @Param settlement body domain.CreateSettlementRequest true "Settlement data"
+// @Success 201 {object} ResponseData{data=domain.Settlement}
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /api/v1/settlements [post]
+func (h *settlementHandler) Create(c *gin.Context) {
+	var req domain.CreateSettlementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	settlement, err := h.service.Create(c.Request.Context(), &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	setETag(c, settlement.Version)
+
+	rsp := newResponse(true, "Settlement created successfully", settlement)
+	c.JSON(http.StatusCreated, rsp)
+}
+
+// List handles GET /settlements
+// @Summary List settlements
+// @Description Get a page of settlements, the Link header points to the first and next pages
+// @Tags settlements
+// @Accept json
+// @Produce json
+// @Param person_id query int false "Only settlements paid or received by this person"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param limit query int false "Maximum number of records to return, at most 100" default(10)
+// @Param include_total query bool false "Include the total number of settlements"
+// @Success 200 {object} ResponseData{data=domain.Page[domain.Settlement]}
+// @Failure 400 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /api/v1/settlements [get]
+func (h *settlementHandler) List(c *gin.Context) {
+	var req domain.ListSettlementsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	page, err := h.service.List(c.Request.Context(), &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	setPageLinks(c, page.NextCursor)
+	handleSuccess(c, page)
+}
+
+// GetByID handles GET /settlements/:id
+// @Summary Get settlement by ID
+// @Description Get a specific settlement by its ID
+// @Tags settlements
+// @Accept json
+// @Produce json
+// @Param id path int true "Settlement ID"
+// @Success 200 {object} ResponseData{data=domain.Settlement}
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /api/v1/settlements/{id} [get]
+func (h *settlementHandler) GetByID(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		validationError(c, err)
+		return
+	}
+
+	settlement, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	setETag(c, settlement.Version)
+
+	handleSuccess(c, settlement)
+}
+
+// Delete handles DELETE /settlements/:id
+// @Summary Delete settlement
+// @Description Delete a settlement recorded by mistake, the amount is owed again
+// @Tags settlements
+// @Accept json
+// @Produce json
+// @Param id path int true "Settlement ID"
+// @Param If-Match header string true "ETag of the settlement version being changed"
+// @Success 204 "No Content"
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 412 {object} ResponseError
+// @Failure 428 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /api/v1/settlements/{id} [delete]
+func (h *settlementHandler) Delete(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		validationError(c, err)
+		return
+	}
+
+	// The deletion must be based on the current version of the data
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	err = h.service.Delete(c.Request.Context(), id, version)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Balances handles GET /balances
+// @Summary Get balances between persons
+// @Description Get who owes whom once shared expenses and settlements are accounted for, with a minimal plan of payments settling every balance
+// @Tags settlements
+// @Accept json
+// @Produce json
+// @Success 200 {object} ResponseData{data=domain.Balances}
+// @Failure 500 {object} ResponseError
+// @Router /api/v1/balances [get]
+func (h *settlementHandler) Balances(c *gin.Context) {
+	balances, err := h.service.Balances(c.Request.Context())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	handleSuccess(c, balances)
+}
@@ -0,0 +1,211 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+	"github.com/gin-gonic/gin"
+)
+
+// TagHandler handles HTTP requests for expense tags
+type TagHandler interface {
+	Create(c *gin.Context)
+	List(c *gin.Context)
+	GetByID(c *gin.Context)
+	Update(c *gin.Context)
+	Delete(c *gin.Context)
+}
+
+type tagHandler struct {
+	service port.TagService
+}
+
+// NewTagHandler creates a new tag HTTP handler
+func NewTagHandler(service port.TagService) TagHandler {
+	return &tagHandler{
+		service: service,
+	}
+}
+
+// Create handles POST /expenses/tags
+// @Summary Create a new tag
+// @Description Create a new tag, names are unique regardless of case
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param tag body domain.CreateTagRequest true "Tag data"
+// @Success 201 {object} ResponseData{data=domain.Tag}
+// @Failure 400 {object} ResponseError
+// @Failure 409 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /api/v1/expenses/tags [post]
+func (h *tagHandler) Create(c *gin.Context) {
+	var req domain.CreateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	tag, err := h.service.Create(c.Request.Context(), &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	setETag(c, tag.Version)
+
+	rsp := newResponse(true, "Tag created successfully", tag)
+	c.JSON(http.StatusCreated, rsp)
+}
+
+// List handles GET /expenses/tags
+// @Summary List tags
+// @Description Get a page of tags, the Link header points to the first and next pages
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param limit query int false "Maximum number of records to return, at most 100" default(10)
+// @Param include_total query bool false "Include the total number of tags"
+// @Success 200 {object} ResponseData{data=domain.Page[domain.Tag]}
+// @Failure 400 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /api/v1/expenses/tags [get]
+func (h *tagHandler) List(c *gin.Context) {
+	var req domain.ListTagsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	page, err := h.service.List(c.Request.Context(), &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	setPageLinks(c, page.NextCursor)
+	handleSuccess(c, page)
+}
+
+// GetByID handles GET /expenses/tags/:id
+// @Summary Get tag by ID
+// @Description Get a specific tag by its ID
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path int true "Tag ID"
+// @Success 200 {object} ResponseData{data=domain.Tag}
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /api/v1/expenses/tags/{id} [get]
+func (h *tagHandler) GetByID(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		validationError(c, err)
+		return
+	}
+
+	tag, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	setETag(c, tag.Version)
+
+	handleSuccess(c, tag)
+}
+
+// Update handles PUT /expenses/tags/:id
+// @Summary Update tag
+// @Description Rename an existing tag
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path int true "Tag ID"
+// @Param If-Match header string true "ETag of the tag version being changed"
+// @Param tag body domain.UpdateTagRequest true "Updated tag data"
+// @Success 200 {object} ResponseData{data=domain.Tag}
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 409 {object} ResponseError
+// @Failure 412 {object} ResponseError
+// @Failure 428 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /api/v1/expenses/tags/{id} [put]
+func (h *tagHandler) Update(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		validationError(c, err)
+		return
+	}
+
+	var req domain.UpdateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	// The update must be based on the current version of the data
+	req.Version, err = ifMatchVersion(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	tag, err := h.service.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	setETag(c, tag.Version)
+
+	rsp := newResponse(true, "Tag updated successfully", tag)
+	c.JSON(http.StatusOK, rsp)
+}
+
+// Delete handles DELETE /expenses/tags/:id
+// @Summary Delete tag
+// @Description Delete a tag by its ID and remove it from every expense carrying it
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path int true "Tag ID"
+// @Param If-Match header string true "ETag of the tag version being changed"
+// @Success 204 "No Content"
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 412 {object} ResponseError
+// @Failure 428 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /api/v1/expenses/tags/{id} [delete]
+func (h *tagHandler) Delete(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		validationError(c, err)
+		return
+	}
+
+	// The deletion must be based on the current version of the data
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	err = h.service.Delete(c.Request.Context(), id, version)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
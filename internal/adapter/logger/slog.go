@@ -15,7 +15,7 @@ var logger *slog.Logger
 // Set sets the logger configuration based on the environment
 func Set(config *config.App) {
 	logger = slog.New(
-		slog.NewTextHandler(os.Stderr, nil),
+		traceHandler{slog.NewTextHandler(os.Stderr, nil)},
 	)
 
 	if config.Env == "production" {
@@ -28,10 +28,10 @@ func Set(config *config.App) {
 		}
 
 		logger = slog.New(
-			slogmulti.Fanout(
+			traceHandler{slogmulti.Fanout(
 				slog.NewJSONHandler(logRotate, nil),
 				slog.NewTextHandler(os.Stderr, nil),
-			),
+			)},
 		)
 	}
 
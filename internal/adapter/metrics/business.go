@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// statsTimeout bounds the query run on each scrape
+const statsTimeout = 5 * time.Second
+
+// expenseCollector counts the stored expenses on each scrape
+type expenseCollector struct {
+	ctx  context.Context
+	repo port.ExpenseRepository
+
+	stored *prometheus.Desc
+}
+
+// RegisterExpenseStats exposes the number of stored expenses, counted in the repository on each scrape.
+// The count stops when ctx is done, such as when the server shuts down.
+func (m *Metrics) RegisterExpenseStats(ctx context.Context, repo port.ExpenseRepository) {
+	m.registry.MustRegister(&expenseCollector{
+		ctx:  ctx,
+		repo: repo,
+		stored: prometheus.NewDesc(prometheus.BuildFQName(namespace, "expenses", "stored"),
+			"Number of expenses that are not deleted.", nil, nil),
+	})
+}
+
+// Describe sends the descriptor of the expense gauge
+func (c *expenseCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+// Collect counts the expenses, a failing count is reported as an invalid metric
+func (c *expenseCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(c.ctx, statsTimeout)
+	defer cancel()
+
+	count, err := c.repo.Count(ctx, port.ExpenseFilters{})
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.stored, err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.stored, prometheus.GaugeValue, float64(count))
+}
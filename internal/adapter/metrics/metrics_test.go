@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMiddlewareLabels(t *testing.T) {
+	m := New()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(m.Middleware())
+	router.GET("/expenses/:id", func(c *gin.Context) {
+		if c.Param("id") == "1" {
+			c.Status(http.StatusOK)
+			return
+		}
+		c.Status(http.StatusNotFound)
+	})
+
+	for _, path := range []string{"/expenses/1", "/expenses/1", "/expenses/2", "/unknown/1"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// Requests are labelled with the route template, not their path
+	expected := `
+# HELP finaid_http_requests_total Number of HTTP requests handled, by route and status.
+# TYPE finaid_http_requests_total counter
+finaid_http_requests_total{method="GET",route="/expenses/:id",status="200"} 2
+finaid_http_requests_total{method="GET",route="/expenses/:id",status="404"} 1
+finaid_http_requests_total{method="GET",route="unmatched",status="404"} 1
+`
+	if err := testutil.CollectAndCompare(m.httpRequests, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+}
+
+// personLookup answers every lookup of a person with err
+type personLookup struct {
+	port.PersonRepository
+	err error
+}
+
+func (r personLookup) GetPersonByID(ctx context.Context, id uint64) (*domain.Person, error) {
+	return nil, r.err
+}
+
+func TestRepositoryOutcome(t *testing.T) {
+	// Calls take no time, so that the observed durations are known
+	clock := time.Now()
+	now = func() time.Time { return clock }
+	t.Cleanup(func() { now = time.Now })
+
+	m := New()
+	missing := &personRepository{m.instrumented("person"), personLookup{err: domain.ErrDataNotFound}}
+	failing := &personRepository{m.instrumented("person"), personLookup{err: errors.New("connection reset")}}
+	missing.GetPersonByID(context.Background(), 1)
+	failing.GetPersonByID(context.Background(), 1)
+
+	expected := `
+# HELP finaid_repository_query_duration_seconds Time taken by repository calls, by repository, method and outcome.
+# TYPE finaid_repository_query_duration_seconds histogram
+` + instantCalls(`method="GetPersonByID",outcome="error",repository="person"`) +
+		instantCalls(`method="GetPersonByID",outcome="not_found",repository="person"`)
+	if err := testutil.CollectAndCompare(m.queryDuration, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+}
+
+// instantCalls returns the series of the query duration histogram labelled labels after one call taking no time
+func instantCalls(labels string) string {
+	var series strings.Builder
+	for _, bucket := range []string{"0.0005", "0.001", "0.0025", "0.005", "0.01", "0.025", "0.05", "0.1", "0.25", "0.5", "1", "2.5", "+Inf"} {
+		fmt.Fprintf(&series, "finaid_repository_query_duration_seconds_bucket{%s,le=%q} 1\n", labels, bucket)
+	}
+	fmt.Fprintf(&series, "finaid_repository_query_duration_seconds_sum{%s} 0\n", labels)
+	fmt.Fprintf(&series, "finaid_repository_query_duration_seconds_count{%s} 1\n", labels)
+	return series.String()
+}
@@ -0,0 +1,542 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+// Outcomes of a repository call
+const (
+	outcomeSuccess  = "success"
+	outcomeNotFound = "not_found"
+	outcomeError    = "error"
+)
+
+// now reads the clock timing the repository calls
+var now = time.Now
+
+// InstrumentRepositories replaces every repository with a decorator timing its calls
+func (m *Metrics) InstrumentRepositories(r *storage.Repositories) {
+	r.Person = &personRepository{m.instrumented("person"), r.Person}
+	r.Account = &accountRepository{m.instrumented("account"), r.Account}
+	r.ExpenseCategory = &expenseCategoryRepository{m.instrumented("expense_category"), r.ExpenseCategory}
+	r.ExpenseSubCategory = &expenseSubCategoryRepository{m.instrumented("expense_subcategory"), r.ExpenseSubCategory}
+	r.Expense = &expenseRepository{m.instrumented("expense"), r.Expense}
+	r.Tag = &tagRepository{m.instrumented("tag"), r.Tag}
+	r.Payee = &payeeRepository{m.instrumented("payee"), r.Payee}
+	r.Merge = &mergeRepository{m.instrumented("merge"), r.Merge}
+	r.SavedView = &savedViewRepository{m.instrumented("saved_view"), r.SavedView}
+	r.Settlement = &settlementRepository{m.instrumented("settlement"), r.Settlement}
+	r.Attachment = &attachmentRepository{m.instrumented("attachment"), r.Attachment}
+	r.Audit = &auditRepository{m.instrumented("audit"), r.Audit}
+	r.Idempotency = &idempotencyRepository{m.instrumented("idempotency"), r.Idempotency}
+}
+
+// instrumented records the duration of the calls of a repository
+type instrumented struct {
+	metrics    *Metrics
+	repository string
+}
+
+func (m *Metrics) instrumented(repository string) instrumented {
+	return instrumented{m, repository}
+}
+
+// observe records the duration of a call started at start, labelled with its outcome
+func (i instrumented) observe(method string, start time.Time, err error) {
+	outcome := outcomeSuccess
+	switch {
+	case errors.Is(err, domain.ErrDataNotFound):
+		outcome = outcomeNotFound
+	case err != nil:
+		outcome = outcomeError
+	}
+	i.metrics.queryDuration.WithLabelValues(i.repository, method, outcome).Observe(now().Sub(start).Seconds())
+}
+
+// timed records the duration of a repository call returning a result
+func timed[T any](i instrumented, method string, fn func() (T, error)) (T, error) {
+	start := now()
+	result, err := fn()
+	i.observe(method, start, err)
+	return result, err
+}
+
+// timedErr records the duration of a repository call returning only an error
+func timedErr(i instrumented, method string, fn func() error) error {
+	start := now()
+	err := fn()
+	i.observe(method, start, err)
+	return err
+}
+
+// personRepository times the calls of a PersonRepository
+type personRepository struct {
+	instrumented
+	next port.PersonRepository
+}
+
+func (r *personRepository) CreatePerson(ctx context.Context, person *domain.Person) (*domain.Person, error) {
+	return timed(r.instrumented, "CreatePerson", func() (*domain.Person, error) { return r.next.CreatePerson(ctx, person) })
+}
+
+func (r *personRepository) GetPersonByID(ctx context.Context, id uint64) (*domain.Person, error) {
+	return timed(r.instrumented, "GetPersonByID", func() (*domain.Person, error) { return r.next.GetPersonByID(ctx, id) })
+}
+
+func (r *personRepository) GetPersonByIDForShare(ctx context.Context, id uint64) (*domain.Person, error) {
+	return timed(r.instrumented, "GetPersonByIDForShare", func() (*domain.Person, error) { return r.next.GetPersonByIDForShare(ctx, id) })
+}
+
+func (r *personRepository) GetPersonByEmail(ctx context.Context, email string) (*domain.Person, error) {
+	return timed(r.instrumented, "GetPersonByEmail", func() (*domain.Person, error) { return r.next.GetPersonByEmail(ctx, email) })
+}
+
+func (r *personRepository) ListPersons(ctx context.Context, after *domain.Cursor, limit int) ([]domain.Person, error) {
+	return timed(r.instrumented, "ListPersons", func() ([]domain.Person, error) { return r.next.ListPersons(ctx, after, limit) })
+}
+
+func (r *personRepository) CountPersons(ctx context.Context) (int64, error) {
+	return timed(r.instrumented, "CountPersons", func() (int64, error) { return r.next.CountPersons(ctx) })
+}
+
+func (r *personRepository) UpdatePerson(ctx context.Context, person *domain.Person) (*domain.Person, error) {
+	return timed(r.instrumented, "UpdatePerson", func() (*domain.Person, error) { return r.next.UpdatePerson(ctx, person) })
+}
+
+func (r *personRepository) DeletePerson(ctx context.Context, id uint64, version int) error {
+	return timedErr(r.instrumented, "DeletePerson", func() error { return r.next.DeletePerson(ctx, id, version) })
+}
+
+// accountRepository times the calls of an AccountRepository
+type accountRepository struct {
+	instrumented
+	next port.AccountRepository
+}
+
+func (r *accountRepository) CreateAccount(ctx context.Context, account *domain.Account) (*domain.Account, error) {
+	return timed(r.instrumented, "CreateAccount", func() (*domain.Account, error) { return r.next.CreateAccount(ctx, account) })
+}
+
+func (r *accountRepository) GetAccountByID(ctx context.Context, id uint64) (*domain.Account, error) {
+	return timed(r.instrumented, "GetAccountByID", func() (*domain.Account, error) { return r.next.GetAccountByID(ctx, id) })
+}
+
+func (r *accountRepository) GetAccountByIDForShare(ctx context.Context, id uint64) (*domain.Account, error) {
+	return timed(r.instrumented, "GetAccountByIDForShare", func() (*domain.Account, error) { return r.next.GetAccountByIDForShare(ctx, id) })
+}
+
+func (r *accountRepository) ListAccounts(ctx context.Context, after *domain.Cursor, limit int, includeDeleted bool) ([]domain.Account, error) {
+	return timed(r.instrumented, "ListAccounts", func() ([]domain.Account, error) { return r.next.ListAccounts(ctx, after, limit, includeDeleted) })
+}
+
+func (r *accountRepository) CountAccounts(ctx context.Context, includeDeleted bool) (int64, error) {
+	return timed(r.instrumented, "CountAccounts", func() (int64, error) { return r.next.CountAccounts(ctx, includeDeleted) })
+}
+
+func (r *accountRepository) UpdateAccount(ctx context.Context, account *domain.Account) (*domain.Account, error) {
+	return timed(r.instrumented, "UpdateAccount", func() (*domain.Account, error) { return r.next.UpdateAccount(ctx, account) })
+}
+
+func (r *accountRepository) DeleteAccount(ctx context.Context, id uint64, version int) error {
+	return timedErr(r.instrumented, "DeleteAccount", func() error { return r.next.DeleteAccount(ctx, id, version) })
+}
+
+func (r *accountRepository) RestoreAccount(ctx context.Context, id uint64) error {
+	return timedErr(r.instrumented, "RestoreAccount", func() error { return r.next.RestoreAccount(ctx, id) })
+}
+
+func (r *accountRepository) PurgeAccounts(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	return timed(r.instrumented, "PurgeAccounts", func() (int64, error) { return r.next.PurgeAccounts(ctx, deletedBefore) })
+}
+
+// expenseCategoryRepository times the calls of an ExpenseCategoryRepository
+type expenseCategoryRepository struct {
+	instrumented
+	next port.ExpenseCategoryRepository
+}
+
+func (r *expenseCategoryRepository) Create(ctx context.Context, category *domain.ExpenseCategory) error {
+	return timedErr(r.instrumented, "Create", func() error { return r.next.Create(ctx, category) })
+}
+
+func (r *expenseCategoryRepository) GetByID(ctx context.Context, id int) (*domain.ExpenseCategory, error) {
+	return timed(r.instrumented, "GetByID", func() (*domain.ExpenseCategory, error) { return r.next.GetByID(ctx, id) })
+}
+
+func (r *expenseCategoryRepository) GetByIDForShare(ctx context.Context, id int) (*domain.ExpenseCategory, error) {
+	return timed(r.instrumented, "GetByIDForShare", func() (*domain.ExpenseCategory, error) { return r.next.GetByIDForShare(ctx, id) })
+}
+
+func (r *expenseCategoryRepository) List(ctx context.Context, after *domain.Cursor, limit int, includeDeleted bool) ([]*domain.ExpenseCategory, error) {
+	return timed(r.instrumented, "List", func() ([]*domain.ExpenseCategory, error) { return r.next.List(ctx, after, limit, includeDeleted) })
+}
+
+func (r *expenseCategoryRepository) Count(ctx context.Context, includeDeleted bool) (int64, error) {
+	return timed(r.instrumented, "Count", func() (int64, error) { return r.next.Count(ctx, includeDeleted) })
+}
+
+func (r *expenseCategoryRepository) Update(ctx context.Context, category *domain.ExpenseCategory) error {
+	return timedErr(r.instrumented, "Update", func() error { return r.next.Update(ctx, category) })
+}
+
+func (r *expenseCategoryRepository) Delete(ctx context.Context, id int, version int) error {
+	return timedErr(r.instrumented, "Delete", func() error { return r.next.Delete(ctx, id, version) })
+}
+
+func (r *expenseCategoryRepository) Restore(ctx context.Context, id int) error {
+	return timedErr(r.instrumented, "Restore", func() error { return r.next.Restore(ctx, id) })
+}
+
+func (r *expenseCategoryRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	return timed(r.instrumented, "Purge", func() (int64, error) { return r.next.Purge(ctx, deletedBefore) })
+}
+
+// expenseSubCategoryRepository times the calls of an ExpenseSubCategoryRepository
+type expenseSubCategoryRepository struct {
+	instrumented
+	next port.ExpenseSubCategoryRepository
+}
+
+func (r *expenseSubCategoryRepository) Create(ctx context.Context, subcategory *domain.ExpenseSubCategory) error {
+	return timedErr(r.instrumented, "Create", func() error { return r.next.Create(ctx, subcategory) })
+}
+
+func (r *expenseSubCategoryRepository) GetByID(ctx context.Context, id int) (*domain.ExpenseSubCategory, error) {
+	return timed(r.instrumented, "GetByID", func() (*domain.ExpenseSubCategory, error) { return r.next.GetByID(ctx, id) })
+}
+
+func (r *expenseSubCategoryRepository) GetByIDForShare(ctx context.Context, id int) (*domain.ExpenseSubCategory, error) {
+	return timed(r.instrumented, "GetByIDForShare", func() (*domain.ExpenseSubCategory, error) { return r.next.GetByIDForShare(ctx, id) })
+}
+
+func (r *expenseSubCategoryRepository) List(ctx context.Context, after *domain.Cursor, limit int, expenseCategoryID *int, includeDeleted bool) ([]*domain.ExpenseSubCategory, error) {
+	return timed(r.instrumented, "List", func() ([]*domain.ExpenseSubCategory, error) {
+		return r.next.List(ctx, after, limit, expenseCategoryID, includeDeleted)
+	})
+}
+
+func (r *expenseSubCategoryRepository) Count(ctx context.Context, expenseCategoryID *int, includeDeleted bool) (int64, error) {
+	return timed(r.instrumented, "Count", func() (int64, error) { return r.next.Count(ctx, expenseCategoryID, includeDeleted) })
+}
+
+func (r *expenseSubCategoryRepository) Update(ctx context.Context, subcategory *domain.ExpenseSubCategory) error {
+	return timedErr(r.instrumented, "Update", func() error { return r.next.Update(ctx, subcategory) })
+}
+
+func (r *expenseSubCategoryRepository) Delete(ctx context.Context, id int, version int) error {
+	return timedErr(r.instrumented, "Delete", func() error { return r.next.Delete(ctx, id, version) })
+}
+
+func (r *expenseSubCategoryRepository) Restore(ctx context.Context, id int) error {
+	return timedErr(r.instrumented, "Restore", func() error { return r.next.Restore(ctx, id) })
+}
+
+func (r *expenseSubCategoryRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	return timed(r.instrumented, "Purge", func() (int64, error) { return r.next.Purge(ctx, deletedBefore) })
+}
+
+// expenseRepository times the calls of an ExpenseRepository
+type expenseRepository struct {
+	instrumented
+	next port.ExpenseRepository
+}
+
+// Create also counts the created expenses and their amounts, including the ones of transactions rolled back later
+func (r *expenseRepository) Create(ctx context.Context, expense *domain.Expense) error {
+	err := timedErr(r.instrumented, "Create", func() error { return r.next.Create(ctx, expense) })
+	if err == nil {
+		r.metrics.expensesCreated.Inc()
+		r.metrics.expenseAmountCreated.Add(expense.Amount)
+	}
+	return err
+}
+
+func (r *expenseRepository) GetByID(ctx context.Context, id int) (*domain.Expense, error) {
+	return timed(r.instrumented, "GetByID", func() (*domain.Expense, error) { return r.next.GetByID(ctx, id) })
+}
+
+func (r *expenseRepository) List(ctx context.Context, filters port.ExpenseFilters) ([]*domain.Expense, error) {
+	return timed(r.instrumented, "List", func() ([]*domain.Expense, error) { return r.next.List(ctx, filters) })
+}
+
+func (r *expenseRepository) Count(ctx context.Context, filters port.ExpenseFilters) (int64, error) {
+	return timed(r.instrumented, "Count", func() (int64, error) { return r.next.Count(ctx, filters) })
+}
+
+func (r *expenseRepository) TagTotals(ctx context.Context, filters port.ExpenseFilters) ([]domain.TagTotal, error) {
+	return timed(r.instrumented, "TagTotals", func() ([]domain.TagTotal, error) { return r.next.TagTotals(ctx, filters) })
+}
+
+func (r *expenseRepository) Update(ctx context.Context, expense *domain.Expense) error {
+	return timedErr(r.instrumented, "Update", func() error { return r.next.Update(ctx, expense) })
+}
+
+func (r *expenseRepository) Delete(ctx context.Context, id int, version int) error {
+	return timedErr(r.instrumented, "Delete", func() error { return r.next.Delete(ctx, id, version) })
+}
+
+func (r *expenseRepository) Restore(ctx context.Context, id int) error {
+	return timedErr(r.instrumented, "Restore", func() error { return r.next.Restore(ctx, id) })
+}
+
+func (r *expenseRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	return timed(r.instrumented, "Purge", func() (int64, error) { return r.next.Purge(ctx, deletedBefore) })
+}
+
+func (r *expenseRepository) RemoveTag(ctx context.Context, tagID int) error {
+	return timedErr(r.instrumented, "RemoveTag", func() error { return r.next.RemoveTag(ctx, tagID) })
+}
+
+func (r *expenseRepository) ReassignPayee(ctx context.Context, fromID, toID int) (int64, error) {
+	return timed(r.instrumented, "ReassignPayee", func() (int64, error) { return r.next.ReassignPayee(ctx, fromID, toID) })
+}
+
+func (r *expenseRepository) ReassignCategory(ctx context.Context, fromID, toID int) (int64, error) {
+	return timed(r.instrumented, "ReassignCategory", func() (int64, error) { return r.next.ReassignCategory(ctx, fromID, toID) })
+}
+
+func (r *expenseRepository) ReassignSubCategory(ctx context.Context, fromID, toID, toCategoryID int) (int64, error) {
+	return timed(r.instrumented, "ReassignSubCategory", func() (int64, error) { return r.next.ReassignSubCategory(ctx, fromID, toID, toCategoryID) })
+}
+
+func (r *expenseRepository) ShareDebts(ctx context.Context) ([]domain.PersonDebt, error) {
+	return timed(r.instrumented, "ShareDebts", func() ([]domain.PersonDebt, error) { return r.next.ShareDebts(ctx) })
+}
+
+// tagRepository times the calls of a TagRepository
+type tagRepository struct {
+	instrumented
+	next port.TagRepository
+}
+
+func (r *tagRepository) Create(ctx context.Context, tag *domain.Tag) error {
+	return timedErr(r.instrumented, "Create", func() error { return r.next.Create(ctx, tag) })
+}
+
+func (r *tagRepository) GetByID(ctx context.Context, id int) (*domain.Tag, error) {
+	return timed(r.instrumented, "GetByID", func() (*domain.Tag, error) { return r.next.GetByID(ctx, id) })
+}
+
+func (r *tagRepository) GetByName(ctx context.Context, name string) (*domain.Tag, error) {
+	return timed(r.instrumented, "GetByName", func() (*domain.Tag, error) { return r.next.GetByName(ctx, name) })
+}
+
+func (r *tagRepository) List(ctx context.Context, after *domain.Cursor, limit int) ([]*domain.Tag, error) {
+	return timed(r.instrumented, "List", func() ([]*domain.Tag, error) { return r.next.List(ctx, after, limit) })
+}
+
+func (r *tagRepository) Count(ctx context.Context) (int64, error) {
+	return timed(r.instrumented, "Count", func() (int64, error) { return r.next.Count(ctx) })
+}
+
+func (r *tagRepository) Update(ctx context.Context, tag *domain.Tag) error {
+	return timedErr(r.instrumented, "Update", func() error { return r.next.Update(ctx, tag) })
+}
+
+func (r *tagRepository) Delete(ctx context.Context, id int, version int) error {
+	return timedErr(r.instrumented, "Delete", func() error { return r.next.Delete(ctx, id, version) })
+}
+
+// payeeRepository times the calls of a PayeeRepository
+type payeeRepository struct {
+	instrumented
+	next port.PayeeRepository
+}
+
+func (r *payeeRepository) Create(ctx context.Context, payee *domain.Payee) error {
+	return timedErr(r.instrumented, "Create", func() error { return r.next.Create(ctx, payee) })
+}
+
+func (r *payeeRepository) GetByID(ctx context.Context, id int) (*domain.Payee, error) {
+	return timed(r.instrumented, "GetByID", func() (*domain.Payee, error) { return r.next.GetByID(ctx, id) })
+}
+
+func (r *payeeRepository) GetByName(ctx context.Context, name string) (*domain.Payee, error) {
+	return timed(r.instrumented, "GetByName", func() (*domain.Payee, error) { return r.next.GetByName(ctx, name) })
+}
+
+func (r *payeeRepository) List(ctx context.Context, after *domain.Cursor, limit int, search string) ([]*domain.Payee, error) {
+	return timed(r.instrumented, "List", func() ([]*domain.Payee, error) { return r.next.List(ctx, after, limit, search) })
+}
+
+func (r *payeeRepository) Count(ctx context.Context, search string) (int64, error) {
+	return timed(r.instrumented, "Count", func() (int64, error) { return r.next.Count(ctx, search) })
+}
+
+func (r *payeeRepository) Update(ctx context.Context, payee *domain.Payee) error {
+	return timedErr(r.instrumented, "Update", func() error { return r.next.Update(ctx, payee) })
+}
+
+func (r *payeeRepository) Delete(ctx context.Context, id int, version int) error {
+	return timedErr(r.instrumented, "Delete", func() error { return r.next.Delete(ctx, id, version) })
+}
+
+func (r *payeeRepository) ReassignDefaultCategory(ctx context.Context, fromID, toID int) error {
+	return timedErr(r.instrumented, "ReassignDefaultCategory", func() error { return r.next.ReassignDefaultCategory(ctx, fromID, toID) })
+}
+
+func (r *payeeRepository) ReassignDefaultSubCategory(ctx context.Context, fromID, toID, toCategoryID int) error {
+	return timedErr(r.instrumented, "ReassignDefaultSubCategory", func() error { return r.next.ReassignDefaultSubCategory(ctx, fromID, toID, toCategoryID) })
+}
+
+// mergeRepository times the calls of a MergeRepository
+type mergeRepository struct {
+	instrumented
+	next port.MergeRepository
+}
+
+func (r *mergeRepository) Create(ctx context.Context, merge *domain.Merge) error {
+	return timedErr(r.instrumented, "Create", func() error { return r.next.Create(ctx, merge) })
+}
+
+func (r *mergeRepository) List(ctx context.Context, after *domain.Cursor, limit int, filters port.MergeFilters) ([]*domain.Merge, error) {
+	return timed(r.instrumented, "List", func() ([]*domain.Merge, error) { return r.next.List(ctx, after, limit, filters) })
+}
+
+func (r *mergeRepository) Count(ctx context.Context, filters port.MergeFilters) (int64, error) {
+	return timed(r.instrumented, "Count", func() (int64, error) { return r.next.Count(ctx, filters) })
+}
+
+// savedViewRepository times the calls of a SavedViewRepository
+type savedViewRepository struct {
+	instrumented
+	next port.SavedViewRepository
+}
+
+func (r *savedViewRepository) Create(ctx context.Context, view *domain.SavedView) error {
+	return timedErr(r.instrumented, "Create", func() error { return r.next.Create(ctx, view) })
+}
+
+func (r *savedViewRepository) GetByID(ctx context.Context, id int) (*domain.SavedView, error) {
+	return timed(r.instrumented, "GetByID", func() (*domain.SavedView, error) { return r.next.GetByID(ctx, id) })
+}
+
+func (r *savedViewRepository) List(ctx context.Context, after *domain.Cursor, limit int) ([]*domain.SavedView, error) {
+	return timed(r.instrumented, "List", func() ([]*domain.SavedView, error) { return r.next.List(ctx, after, limit) })
+}
+
+func (r *savedViewRepository) Count(ctx context.Context) (int64, error) {
+	return timed(r.instrumented, "Count", func() (int64, error) { return r.next.Count(ctx) })
+}
+
+func (r *savedViewRepository) Update(ctx context.Context, view *domain.SavedView) error {
+	return timedErr(r.instrumented, "Update", func() error { return r.next.Update(ctx, view) })
+}
+
+func (r *savedViewRepository) Delete(ctx context.Context, id int, version int) error {
+	return timedErr(r.instrumented, "Delete", func() error { return r.next.Delete(ctx, id, version) })
+}
+
+// settlementRepository times the calls of a SettlementRepository
+type settlementRepository struct {
+	instrumented
+	next port.SettlementRepository
+}
+
+func (r *settlementRepository) Create(ctx context.Context, settlement *domain.Settlement) error {
+	return timedErr(r.instrumented, "Create", func() error { return r.next.Create(ctx, settlement) })
+}
+
+func (r *settlementRepository) GetByID(ctx context.Context, id int) (*domain.Settlement, error) {
+	return timed(r.instrumented, "GetByID", func() (*domain.Settlement, error) { return r.next.GetByID(ctx, id) })
+}
+
+func (r *settlementRepository) List(ctx context.Context, after *domain.Cursor, limit int, personID int) ([]*domain.Settlement, error) {
+	return timed(r.instrumented, "List", func() ([]*domain.Settlement, error) { return r.next.List(ctx, after, limit, personID) })
+}
+
+func (r *settlementRepository) Count(ctx context.Context, personID int) (int64, error) {
+	return timed(r.instrumented, "Count", func() (int64, error) { return r.next.Count(ctx, personID) })
+}
+
+func (r *settlementRepository) Delete(ctx context.Context, id int, version int) error {
+	return timedErr(r.instrumented, "Delete", func() error { return r.next.Delete(ctx, id, version) })
+}
+
+func (r *settlementRepository) Totals(ctx context.Context) ([]domain.PersonDebt, error) {
+	return timed(r.instrumented, "Totals", func() ([]domain.PersonDebt, error) { return r.next.Totals(ctx) })
+}
+
+// attachmentRepository times the calls of an AttachmentRepository
+type attachmentRepository struct {
+	instrumented
+	next port.AttachmentRepository
+}
+
+func (r *attachmentRepository) Create(ctx context.Context, attachment *domain.Attachment) error {
+	return timedErr(r.instrumented, "Create", func() error { return r.next.Create(ctx, attachment) })
+}
+
+func (r *attachmentRepository) GetByID(ctx context.Context, id int) (*domain.Attachment, error) {
+	return timed(r.instrumented, "GetByID", func() (*domain.Attachment, error) { return r.next.GetByID(ctx, id) })
+}
+
+func (r *attachmentRepository) GetByChecksum(ctx context.Context, expenseID int, checksum string) (*domain.Attachment, error) {
+	return timed(r.instrumented, "GetByChecksum", func() (*domain.Attachment, error) { return r.next.GetByChecksum(ctx, expenseID, checksum) })
+}
+
+func (r *attachmentRepository) ListByExpense(ctx context.Context, expenseID int) ([]*domain.Attachment, error) {
+	return timed(r.instrumented, "ListByExpense", func() ([]*domain.Attachment, error) { return r.next.ListByExpense(ctx, expenseID) })
+}
+
+func (r *attachmentRepository) CountByChecksum(ctx context.Context, checksum string) (int64, error) {
+	return timed(r.instrumented, "CountByChecksum", func() (int64, error) { return r.next.CountByChecksum(ctx, checksum) })
+}
+
+func (r *attachmentRepository) LockContent(ctx context.Context, checksum string) error {
+	return timedErr(r.instrumented, "LockContent", func() error { return r.next.LockContent(ctx, checksum) })
+}
+
+func (r *attachmentRepository) Delete(ctx context.Context, id int) error {
+	return timedErr(r.instrumented, "Delete", func() error { return r.next.Delete(ctx, id) })
+}
+
+func (r *attachmentRepository) Purge(ctx context.Context, expensesDeletedBefore time.Time) ([]*domain.Attachment, error) {
+	return timed(r.instrumented, "Purge", func() ([]*domain.Attachment, error) { return r.next.Purge(ctx, expensesDeletedBefore) })
+}
+
+// auditRepository times the calls of an AuditRepository
+type auditRepository struct {
+	instrumented
+	next port.AuditRepository
+}
+
+func (r *auditRepository) Create(ctx context.Context, entry *domain.AuditEntry) error {
+	return timedErr(r.instrumented, "Create", func() error { return r.next.Create(ctx, entry) })
+}
+
+func (r *auditRepository) List(ctx context.Context, after *domain.Cursor, limit int, filters port.AuditFilters) ([]*domain.AuditEntry, error) {
+	return timed(r.instrumented, "List", func() ([]*domain.AuditEntry, error) { return r.next.List(ctx, after, limit, filters) })
+}
+
+func (r *auditRepository) Count(ctx context.Context, filters port.AuditFilters) (int64, error) {
+	return timed(r.instrumented, "Count", func() (int64, error) { return r.next.Count(ctx, filters) })
+}
+
+// idempotencyRepository times the calls of an IdempotencyRepository
+type idempotencyRepository struct {
+	instrumented
+	next port.IdempotencyRepository
+}
+
+func (r *idempotencyRepository) Reserve(ctx context.Context, record *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+	return timed(r.instrumented, "Reserve", func() (*domain.IdempotencyRecord, error) { return r.next.Reserve(ctx, record) })
+}
+
+func (r *idempotencyRepository) Complete(ctx context.Context, record *domain.IdempotencyRecord) error {
+	return timedErr(r.instrumented, "Complete", func() error { return r.next.Complete(ctx, record) })
+}
+
+func (r *idempotencyRepository) Release(ctx context.Context, key, scope string) error {
+	return timedErr(r.instrumented, "Release", func() error { return r.next.Release(ctx, key, scope) })
+}
+
+func (r *idempotencyRepository) PurgeExpired(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	return timed(r.instrumented, "PurgeExpired", func() (int64, error) { return r.next.PurgeExpired(ctx, expiredBefore) })
+}
@@ -0,0 +1,19 @@
+package blob
+
+import (
+	"errors"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/config"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+// New creates the blob store selected by the attachments configuration
+func New(config *config.Attachments) (port.BlobStore, error) {
+	switch config.Store {
+	case "local":
+		return NewLocalStore(config.Dir)
+	case "s3":
+		return NewS3Store(config.S3Endpoint, config.S3Region, config.S3Bucket, config.S3AccessKey, config.S3SecretKey)
+	}
+	return nil, errors.New("unknown attachments store " + config.Store)
+}
@@ -2,86 +2,221 @@ package repository
 
 import (
 	"context"
-	"log/slog"
+	"encoding/json"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
 )
 
-type AccountRepository struct {
-	data map[uint64]*domain.Account
+type accountRepository struct {
+	accounts map[uint64]*domain.Account
+	nextID   uint64
+	mu       sync.RWMutex
+	expenses *expenseRepository
 }
 
-func NewAccountRepository() *AccountRepository {
-	return &AccountRepository{
-		data: make(map[uint64]*domain.Account),
+// NewAccountRepository creates a new in-memory account repository.
+// The expense repository, created by NewExpenseRepository, keeps the accounts it uses from being purged.
+func NewAccountRepository(expenses port.ExpenseRepository) port.AccountRepository {
+	return &accountRepository{
+		accounts: make(map[uint64]*domain.Account),
+		nextID:   1,
+		expenses: expenses.(*expenseRepository),
 	}
 }
 
 // CreateAccount inserts a new Account into the repository
-func (r *AccountRepository) CreateAccount(ctx context.Context, account *domain.Account) (*domain.Account, error) {
-	account.ID = uint64(len(r.data) + 1) // Simple ID generation logic
+func (r *accountRepository) CreateAccount(ctx context.Context, account *domain.Account) (*domain.Account, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	trackRow(ctx, &r.mu, r.accounts, r.nextID, &r.nextID)
+	account.ID = r.nextID
 	account.CreatedAt = time.Now()
 	account.UpdatedAt = account.CreatedAt
+	account.Version = 1
+	r.nextID++
 
-	if _, exists := r.data[account.ID]; exists {
-		return nil, domain.ErrConflictingData
-	}
-	r.data[account.ID] = account
+	r.accounts[account.ID] = copyAccount(account)
 	return account, nil
 }
 
 // GetAccountByID selects an Account by id
-func (r *AccountRepository) GetAccountByID(ctx context.Context, id uint64) (*domain.Account, error) {
-	account, exists := r.data[id]
-	if !exists {
+func (r *accountRepository) GetAccountByID(ctx context.Context, id uint64) (*domain.Account, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	account, exists := r.accounts[id]
+	if !exists || account.DeletedAt != nil {
 		return nil, domain.ErrDataNotFound
 	}
-	return account, nil
+	return copyAccount(account), nil
+}
+
+// GetAccountByIDForShare selects an Account like GetAccountByID,
+// the in-memory store has no concurrent transactions to lock rows against
+func (r *accountRepository) GetAccountByIDForShare(ctx context.Context, id uint64) (*domain.Account, error) {
+	return r.GetAccountByID(ctx, id)
 }
 
-// ListAccounts selects a list of Accounts with pagination
-func (r *AccountRepository) ListAccounts(ctx context.Context, skip, limit uint64) ([]domain.Account, error) {
-	slog.Info("Listing accounts repo", "skip", skip, "limit", limit)
+// ListAccounts selects up to limit Accounts ordered by id, starting after the cursor when given,
+// optionally including soft-deleted ones
+func (r *accountRepository) ListAccounts(ctx context.Context, after *domain.Cursor, limit int, includeDeleted bool) ([]domain.Account, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Listing accounts repo", "after", after, "limit", limit, "include_deleted", includeDeleted)
 	var accounts []domain.Account
-	for _, account := range r.data {
-		accounts = append(accounts, *account)
-	}
-	slog.Info("Accounts found", "count", len(accounts))
-	if skip >= uint64(len(accounts)) {
-		return nil, nil // No data to return
+	for _, account := range r.accounts {
+		if account.DeletedAt != nil && !includeDeleted {
+			continue
+		}
+		if afterIDCursor(account.ID, after) {
+			accounts = append(accounts, *copyAccount(account))
+		}
 	}
-	end := skip + limit
-	if end > uint64(len(accounts)) {
-		end = uint64(len(accounts))
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].ID < accounts[j].ID
+	})
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Accounts found", "count", len(accounts))
+	return firstItems(accounts, limit), nil
+}
+
+// CountAccounts counts the Accounts, optionally including soft-deleted ones
+func (r *accountRepository) CountAccounts(ctx context.Context, includeDeleted bool) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, account := range r.accounts {
+		if account.DeletedAt == nil || includeDeleted {
+			count++
+		}
 	}
-	return accounts[skip:end], nil
+	return count, nil
 }
 
 // UpdateAccount updates an Account
-func (r *AccountRepository) UpdateAccount(ctx context.Context, account *domain.Account) (*domain.Account, error) {
-	existingAccount, exists := r.data[account.ID]
-	if !exists {
+func (r *accountRepository) UpdateAccount(ctx context.Context, account *domain.Account) (*domain.Account, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existingAccount, exists := r.accounts[account.ID]
+	if !exists || existingAccount.DeletedAt != nil {
 		return nil, domain.ErrDataNotFound
 	}
+	if !versionMatches(existingAccount.Version, account.Version) {
+		return nil, domain.ErrPreconditionFailed
+	}
 	// Update the existing account's fields
+	trackRow(ctx, &r.mu, r.accounts, account.ID, nil)
 	existingAccount.Name = account.Name
 	existingAccount.Currency = account.Currency
 	existingAccount.AccountType = account.AccountType
 	existingAccount.InitialBalance = account.InitialBalance
 	existingAccount.PrimaryOwnerID = account.PrimaryOwnerID
-	existingAccount.SecondOwnerID = account.SecondOwnerID
+	existingAccount.SecondOwnerID = copyID(account.SecondOwnerID)
 	existingAccount.UpdatedAt = time.Now()
+	existingAccount.Version++
+
+	return copyAccount(existingAccount), nil
+}
+
+// DeleteAccount soft deletes an Account
+func (r *accountRepository) DeleteAccount(ctx context.Context, id uint64, version int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	r.data[account.ID] = existingAccount
-	return existingAccount, nil
+	account, exists := r.accounts[id]
+	if !exists || account.DeletedAt != nil {
+		return domain.ErrDataNotFound
+	}
+	if !versionMatches(account.Version, version) {
+		return domain.ErrPreconditionFailed
+	}
+	trackRow(ctx, &r.mu, r.accounts, id, nil)
+	now := time.Now()
+	account.DeletedAt = &now
+	account.Version++
+	return nil
 }
 
-// DeleteAccount deletes an Account
-func (r *AccountRepository) DeleteAccount(ctx context.Context, id uint64) error {
-	if _, exists := r.data[id]; !exists {
+// RestoreAccount restores a soft-deleted Account
+func (r *accountRepository) RestoreAccount(ctx context.Context, id uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	account, exists := r.accounts[id]
+	if !exists || account.DeletedAt == nil {
 		return domain.ErrDataNotFound
 	}
-	delete(r.data, id)
+	trackRow(ctx, &r.mu, r.accounts, id, nil)
+	account.DeletedAt = nil
+	account.UpdatedAt = time.Now()
+	account.Version++
+	return nil
+}
+
+// PurgeAccounts permanently removes Accounts soft deleted before the given time
+func (r *accountRepository) PurgeAccounts(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for id, account := range r.accounts {
+		if account.DeletedAt != nil && account.DeletedAt.Before(deletedBefore) {
+			// Accounts still referenced by expenses are kept until those are purged
+			if r.expenses.references(func(expense *domain.Expense) bool { return expense.AccountID == int(id) }) {
+				continue
+			}
+			trackRow(ctx, &r.mu, r.accounts, id, nil)
+			delete(r.accounts, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// copyAccount copies an account along with its optional owner to avoid reference issues
+func copyAccount(account *domain.Account) *domain.Account {
+	accountCopy := *account
+	accountCopy.SecondOwnerID = copyID(account.SecondOwnerID)
+	return &accountCopy
+}
+
+// copyID copies an optional id
+func copyID(id *uint64) *uint64 {
+	if id == nil {
+		return nil
+	}
+	idCopy := *id
+	return &idCopy
+}
+
+func (r *accountRepository) snapshotName() string {
+	return "accounts"
+}
+
+func (r *accountRepository) snapshot() (json.RawMessage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return encodeTable(r.accounts, r.nextID)
+}
+
+func (r *accountRepository) restore(data json.RawMessage) error {
+	accounts, nextID, err := decodeTable(data, func(account *domain.Account) uint64 { return account.ID })
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.accounts = accounts
+	r.nextID = nextID
 	return nil
 }
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+type attachmentRepository struct {
+	attachments map[int]*domain.Attachment
+	nextID      int
+	mu          sync.RWMutex
+	expenses    *expenseRepository
+}
+
+// NewAttachmentRepository creates a new in-memory attachment repository.
+// The expense repository, created by NewExpenseRepository, tells which attachments are purged with their expense.
+func NewAttachmentRepository(expenses port.ExpenseRepository) port.AttachmentRepository {
+	return &attachmentRepository{
+		attachments: make(map[int]*domain.Attachment),
+		nextID:      1,
+		expenses:    expenses.(*expenseRepository),
+	}
+}
+
+func (r *attachmentRepository) Create(ctx context.Context, attachment *domain.Attachment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Same uniqueness as the database constraint
+	for _, existing := range r.attachments {
+		if existing.ExpenseID == attachment.ExpenseID && existing.Checksum == attachment.Checksum {
+			return &domain.ConstraintError{Err: domain.ErrConflictingData, Constraint: "uk_attachments_expense_checksum"}
+		}
+	}
+
+	trackRow(ctx, &r.mu, r.attachments, r.nextID, &r.nextID)
+	attachment.ID = r.nextID
+	r.nextID++
+
+	// Create a copy to avoid reference issues
+	attachmentCopy := *attachment
+	r.attachments[attachment.ID] = &attachmentCopy
+	return nil
+}
+
+func (r *attachmentRepository) GetByID(ctx context.Context, id int) (*domain.Attachment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	attachment, exists := r.attachments[id]
+	if !exists {
+		return nil, domain.ErrDataNotFound
+	}
+
+	// Return a copy to avoid reference issues
+	attachmentCopy := *attachment
+	return &attachmentCopy, nil
+}
+
+func (r *attachmentRepository) GetByChecksum(ctx context.Context, expenseID int, checksum string) (*domain.Attachment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, attachment := range r.attachments {
+		if attachment.ExpenseID == expenseID && attachment.Checksum == checksum {
+			attachmentCopy := *attachment
+			return &attachmentCopy, nil
+		}
+	}
+
+	return nil, domain.ErrDataNotFound
+}
+
+func (r *attachmentRepository) ListByExpense(ctx context.Context, expenseID int) ([]*domain.Attachment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var attachments []*domain.Attachment
+	for _, attachment := range r.attachments {
+		if attachment.ExpenseID == expenseID {
+			attachmentCopy := *attachment
+			attachments = append(attachments, &attachmentCopy)
+		}
+	}
+
+	// Sort by ID
+	sort.Slice(attachments, func(i, j int) bool {
+		return attachments[i].ID < attachments[j].ID
+	})
+
+	return attachments, nil
+}
+
+func (r *attachmentRepository) CountByChecksum(ctx context.Context, checksum string) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, attachment := range r.attachments {
+		if attachment.Checksum == checksum {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *attachmentRepository) LockContent(ctx context.Context, checksum string) error {
+	// Units of work run one at a time, nothing else can change the attachments
+	return nil
+}
+
+func (r *attachmentRepository) Delete(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.attachments[id]; !exists {
+		return domain.ErrDataNotFound
+	}
+
+	trackRow(ctx, &r.mu, r.attachments, id, nil)
+	delete(r.attachments, id)
+	return nil
+}
+
+func (r *attachmentRepository) Purge(ctx context.Context, expensesDeletedBefore time.Time) ([]*domain.Attachment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.expenses.mu.RLock()
+	defer r.expenses.mu.RUnlock()
+
+	var purged []*domain.Attachment
+	for id, attachment := range r.attachments {
+		expense, exists := r.expenses.expenses[attachment.ExpenseID]
+		if exists && expense.DeletedAt != nil && expense.DeletedAt.Before(expensesDeletedBefore) {
+			trackRow(ctx, &r.mu, r.attachments, id, nil)
+			delete(r.attachments, id)
+			purged = append(purged, attachment)
+		}
+	}
+
+	return purged, nil
+}
+
+func (r *attachmentRepository) snapshotName() string {
+	return "attachments"
+}
+
+func (r *attachmentRepository) snapshot() (json.RawMessage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return encodeTable(r.attachments, r.nextID)
+}
+
+func (r *attachmentRepository) restore(data json.RawMessage) error {
+	attachments, nextID, err := decodeTable(data, func(attachment *domain.Attachment) int { return attachment.ID })
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.attachments = attachments
+	r.nextID = nextID
+	return nil
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+type auditRepository struct {
+	mu      sync.RWMutex
+	entries []*domain.AuditEntry
+}
+
+// NewAuditRepository creates a new in-memory audit repository
+func NewAuditRepository() port.AuditRepository {
+	return &auditRepository{}
+}
+
+func (r *auditRepository) Create(ctx context.Context, entry *domain.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// The log is only appended to, undoing the entry cuts it back to its length
+	length := len(r.entries)
+	record(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.entries = r.entries[:length]
+	})
+	entry.ID = uint64(length + 1)
+
+	// Create a copy to avoid reference issues
+	entryCopy := *entry
+	r.entries = append(r.entries, &entryCopy)
+
+	return nil
+}
+
+func (r *auditRepository) List(ctx context.Context, after *domain.Cursor, limit int, filters port.AuditFilters) ([]*domain.AuditEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var entries []*domain.AuditEntry
+	for _, entry := range r.entries {
+		if !afterCreatedAtCursor(entry.CreatedAt, entry.ID, after) || !auditEntryMatches(entry, filters) {
+			continue
+		}
+		entryCopy := *entry
+		entries = append(entries, &entryCopy)
+	}
+
+	// Sort by created_at descending (newest first), then by id descending
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].ID > entries[j].ID
+		}
+		return entries[i].CreatedAt.After(entries[j].CreatedAt)
+	})
+
+	return firstItems(entries, limit), nil
+}
+
+func (r *auditRepository) Count(ctx context.Context, filters port.AuditFilters) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, entry := range r.entries {
+		if auditEntryMatches(entry, filters) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// auditEntryMatches tells whether an audit entry matches the filters
+func auditEntryMatches(entry *domain.AuditEntry, filters port.AuditFilters) bool {
+	if filters.EntityType != nil && entry.EntityType != *filters.EntityType {
+		return false
+	}
+	if filters.EntityID != nil && entry.EntityID != *filters.EntityID {
+		return false
+	}
+	return true
+}
+
+func (r *auditRepository) snapshotName() string {
+	return "audit_log"
+}
+
+func (r *auditRepository) snapshot() (json.RawMessage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return json.Marshal(r.entries)
+}
+
+func (r *auditRepository) restore(data json.RawMessage) error {
+	var entries []*domain.AuditEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = entries
+	return nil
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+)
+
+// afterIDCursor reports whether an item comes after the cursor in an ascending ID ordering
+func afterIDCursor(id uint64, after *domain.Cursor) bool {
+	return after == nil || id > after.ID
+}
+
+// afterCreatedAtCursor reports whether an item comes after the cursor in a newest first ordering,
+// by creation time then ID
+func afterCreatedAtCursor(createdAt time.Time, id uint64, after *domain.Cursor) bool {
+	if after == nil || after.CreatedAt == nil {
+		return true
+	}
+	return createdAt.Before(*after.CreatedAt) || (createdAt.Equal(*after.CreatedAt) && id < after.ID)
+}
+
+// firstItems returns at most limit items
+func firstItems[T any](items []T, limit int) []T {
+	if len(items) > limit {
+		return items[:limit]
+	}
+	return items
+}
@@ -2,7 +2,14 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"math"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
+	"time"
+	"unicode"
 
 	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
 	"github.com/edwins-leonardi/finaid-api/internal/core/port"
@@ -26,11 +33,14 @@ func (r *expenseRepository) Create(ctx context.Context, expense *domain.Expense)
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	trackRow(ctx, &r.mu, r.expenses, r.nextID, &r.nextID)
 	expense.ID = r.nextID
+	expense.Version = 1
 	r.nextID++
 
-	// Create a copy to avoid reference issues
+	// Create a copy to avoid reference issues, keeping the tags sorted like the databases return them
 	expenseCopy := *expense
+	expenseCopy.TagIDs = sortedIDs(expense.TagIDs)
 	r.expenses[expense.ID] = &expenseCopy
 
 	return nil
@@ -41,7 +51,7 @@ func (r *expenseRepository) GetByID(ctx context.Context, id int) (*domain.Expens
 	defer r.mu.RUnlock()
 
 	expense, exists := r.expenses[id]
-	if !exists {
+	if !exists || expense.DeletedAt != nil {
 		return nil, domain.ErrDataNotFound
 	}
 
@@ -56,61 +66,209 @@ func (r *expenseRepository) List(ctx context.Context, filters port.ExpenseFilter
 
 	var expenses []*domain.Expense
 
-	// Filter expenses based on criteria
+	// Filter expenses based on criteria, continuing after the last expense of the previous page
 	for _, expense := range r.expenses {
-		if r.matchesFilters(expense, filters) {
+		if r.matchesFilters(expense, filters) && expenseAfterCursor(expense, filters.After, filters.Sort) {
 			expenseCopy := *expense
 			expenses = append(expenses, &expenseCopy)
 		}
 	}
 
-	// Sort by date descending, then by created_at descending
-	for i := 0; i < len(expenses)-1; i++ {
-		for j := i + 1; j < len(expenses); j++ {
-			if expenses[i].Date.Before(expenses[j].Date) ||
-				(expenses[i].Date.Equal(expenses[j].Date) && expenses[i].CreatedAt.Before(expenses[j].CreatedAt)) {
-				expenses[i], expenses[j] = expenses[j], expenses[i]
+	// Sort by the requested field, then by created_at and ID in the same direction
+	sort.Slice(expenses, func(i, j int) bool {
+		return expenseBefore(expenses[i], expenses[j], filters.Sort)
+	})
+
+	return firstItems(expenses, filters.Limit), nil
+}
+
+func (r *expenseRepository) Count(ctx context.Context, filters port.ExpenseFilters) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, expense := range r.expenses {
+		if r.matchesFilters(expense, filters) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (r *expenseRepository) TagTotals(ctx context.Context, filters port.ExpenseFilters) ([]domain.TagTotal, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	totals := make(map[int]*domain.TagTotal)
+	for _, expense := range r.expenses {
+		if !r.matchesFilters(expense, filters) {
+			continue
+		}
+		for _, tagID := range expense.TagIDs {
+			total, exists := totals[tagID]
+			if !exists {
+				total = &domain.TagTotal{TagID: tagID}
+				totals[tagID] = total
 			}
+			total.ExpenseCount++
+			total.TotalAmount += *matchedAmount(expense, filters)
 		}
 	}
 
-	// Apply pagination
-	start := filters.Skip
-	if start >= len(expenses) {
-		return []*domain.Expense{}, nil
+	result := make([]domain.TagTotal, 0, len(totals))
+	for _, total := range totals {
+		// Round to cents like the numeric sums of Postgres
+		total.TotalAmount = math.Round(total.TotalAmount*100) / 100
+		result = append(result, *total)
 	}
 
-	end := start + filters.Limit
-	if end > len(expenses) {
-		end = len(expenses)
+	return result, nil
+}
+
+// expenseBefore reports whether an expense sorts before another one in the given ordering
+func expenseBefore(a, b *domain.Expense, order domain.ExpenseSort) bool {
+	cmp := 0
+	switch order.Field {
+	case domain.ExpenseSortDate:
+		cmp = a.Date.Compare(b.Date)
+	case domain.ExpenseSortAmount:
+		cmp = compareValues(a.Amount, b.Amount)
+	}
+	if cmp == 0 {
+		cmp = a.CreatedAt.Compare(b.CreatedAt)
+	}
+	if cmp == 0 {
+		cmp = compareValues(a.ID, b.ID)
 	}
 
-	return expenses[start:end], nil
+	if order.Descending {
+		return cmp > 0
+	}
+	return cmp < 0
 }
 
-func (r *expenseRepository) matchesFilters(expense *domain.Expense, filters port.ExpenseFilters) bool {
-	// Filter by category
-	if filters.CategoryID != nil && expense.CategoryID != *filters.CategoryID {
-		return false
+// compareValues returns -1, 0 or +1 as a is less than, equal to or greater than b
+func compareValues[T int | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
 	}
+	return 0
+}
+
+// expenseAfterCursor reports whether an expense comes after the cursor in the given ordering
+func expenseAfterCursor(expense *domain.Expense, after *domain.Cursor, order domain.ExpenseSort) bool {
+	if after == nil || after.CreatedAt == nil {
+		return true
+	}
+
+	last := &domain.Expense{ID: int(after.ID), CreatedAt: *after.CreatedAt}
+	if after.Date != nil {
+		last.Date = *after.Date
+	}
+	if after.Amount != nil {
+		last.Amount = *after.Amount
+	}
+	return expenseBefore(last, expense, order)
+}
 
-	// Filter by subcategory
-	if filters.SubCategoryID != nil {
-		if expense.SubCategoryID == nil || *expense.SubCategoryID != *filters.SubCategoryID {
+// matchesNotes reports whether every word of the query appears in the notes, ignoring case,
+// like a Postgres full-text search with the simple configuration
+func matchesNotes(notes, query string) bool {
+	words := strings.FieldsFunc(strings.ToLower(notes), isNotWordRune)
+	for _, term := range strings.FieldsFunc(strings.ToLower(query), isNotWordRune) {
+		if !slices.Contains(words, term) {
 			return false
 		}
 	}
+	return true
+}
+
+func isNotWordRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// categoryLines returns the split lines of an expense, or a single line made of the expense itself
+func categoryLines(expense *domain.Expense) []domain.ExpenseSplit {
+	if len(expense.Splits) > 0 {
+		return expense.Splits
+	}
+	return []domain.ExpenseSplit{{CategoryID: expense.CategoryID, SubCategoryID: expense.SubCategoryID, Amount: expense.Amount}}
+}
+
+// matchedAmount returns the amount of the category lines of an expense matching the category filters,
+// nil when none of them matches
+func matchedAmount(expense *domain.Expense, filters port.ExpenseFilters) *float64 {
+	var amount *float64
+	for _, line := range categoryLines(expense) {
+		if len(filters.CategoryIDs) > 0 && !slices.Contains(filters.CategoryIDs, line.CategoryID) {
+			continue
+		}
+		if filters.SubCategoryID != nil && (line.SubCategoryID == nil || *line.SubCategoryID != *filters.SubCategoryID) {
+			continue
+		}
+		if filters.HasSubCategory != nil && (line.SubCategoryID != nil) != *filters.HasSubCategory {
+			continue
+		}
+
+		if amount == nil {
+			amount = new(float64)
+		}
+		*amount += line.Amount
+	}
+	return amount
+}
+
+func (r *expenseRepository) matchesFilters(expense *domain.Expense, filters port.ExpenseFilters) bool {
+	// Exclude soft-deleted expenses unless explicitly requested
+	if expense.DeletedAt != nil && !filters.IncludeDeleted {
+		return false
+	}
+
+	// Filter by categories, through the lines of a split expense
+	if matchedAmount(expense, filters) == nil {
+		return false
+	}
+
+	// Filter by payees
+	if len(filters.PayeeIDs) > 0 && !slices.Contains(filters.PayeeIDs, expense.PayeeID) {
+		return false
+	}
 
-	// Filter by payee
-	if filters.PayeeID != nil && expense.PayeeID != *filters.PayeeID {
+	// Filter by accounts
+	if len(filters.AccountIDs) > 0 && !slices.Contains(filters.AccountIDs, expense.AccountID) {
 		return false
 	}
 
-	// Filter by account
-	if filters.AccountID != nil && expense.AccountID != *filters.AccountID {
+	// Filter by amount range
+	if filters.MinAmount != nil && expense.Amount < *filters.MinAmount {
+		return false
+	}
+	if filters.MaxAmount != nil && expense.Amount > *filters.MaxAmount {
 		return false
 	}
 
+	// Search the notes
+	if filters.NotesQuery != "" && !matchesNotes(expense.Notes, filters.NotesQuery) {
+		return false
+	}
+
+	// Filter by tags, any of them or all of them
+	if len(filters.TagIDs) > 0 {
+		matched := 0
+		for _, tagID := range filters.TagIDs {
+			if slices.Contains(expense.TagIDs, tagID) {
+				matched++
+			}
+		}
+		if matched == 0 || (filters.MatchAllTags && matched < len(filters.TagIDs)) {
+			return false
+		}
+	}
+
 	// Filter by start date
 	if filters.StartDate != nil && expense.Date.Before(*filters.StartDate) {
 		return false
@@ -128,27 +286,257 @@ func (r *expenseRepository) Update(ctx context.Context, expense *domain.Expense)
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	_, exists := r.expenses[expense.ID]
-	if !exists {
+	existing, exists := r.expenses[expense.ID]
+	if !exists || existing.DeletedAt != nil {
 		return domain.ErrDataNotFound
 	}
+	if !versionMatches(existing.Version, expense.Version) {
+		return domain.ErrPreconditionFailed
+	}
+	expense.Version = existing.Version + 1
 
-	// Create a copy to avoid reference issues
+	trackRow(ctx, &r.mu, r.expenses, expense.ID, nil)
+	// Create a copy to avoid reference issues, keeping the tags sorted like the databases return them
 	expenseCopy := *expense
+	expenseCopy.TagIDs = sortedIDs(expense.TagIDs)
 	r.expenses[expense.ID] = &expenseCopy
 
 	return nil
 }
 
-func (r *expenseRepository) Delete(ctx context.Context, id int) error {
+func (r *expenseRepository) Delete(ctx context.Context, id int, version int) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	_, exists := r.expenses[id]
-	if !exists {
+	expense, exists := r.expenses[id]
+	if !exists || expense.DeletedAt != nil {
 		return domain.ErrDataNotFound
 	}
+	if !versionMatches(expense.Version, version) {
+		return domain.ErrPreconditionFailed
+	}
+
+	trackRow(ctx, &r.mu, r.expenses, id, nil)
+	now := time.Now()
+	expense.DeletedAt = &now
+	expense.Version++
+	return nil
+}
+
+func (r *expenseRepository) Restore(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	expense, exists := r.expenses[id]
+	if !exists || expense.DeletedAt == nil {
+		return domain.ErrDataNotFound
+	}
+
+	trackRow(ctx, &r.mu, r.expenses, id, nil)
+	expense.DeletedAt = nil
+	expense.UpdatedAt = time.Now()
+	expense.Version++
+	return nil
+}
+
+func (r *expenseRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for id, expense := range r.expenses {
+		if expense.DeletedAt != nil && expense.DeletedAt.Before(deletedBefore) {
+			trackRow(ctx, &r.mu, r.expenses, id, nil)
+			delete(r.expenses, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+func (r *expenseRepository) RemoveTag(ctx context.Context, tagID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, expense := range r.expenses {
+		if !slices.Contains(expense.TagIDs, tagID) {
+			continue
+		}
+		trackRow(ctx, &r.mu, r.expenses, id, nil)
+
+		// Build a new slice, copies handed out earlier share the old one
+		var tagIDs []int
+		for _, id := range expense.TagIDs {
+			if id != tagID {
+				tagIDs = append(tagIDs, id)
+			}
+		}
+		expense.TagIDs = tagIDs
+		expense.UpdatedAt = time.Now()
+		expense.Version++
+	}
+
+	return nil
+}
+
+func (r *expenseRepository) ReassignPayee(ctx context.Context, fromID, toID int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var moved int64
+	for id, expense := range r.expenses {
+		if expense.PayeeID != fromID {
+			continue
+		}
+		trackRow(ctx, &r.mu, r.expenses, id, nil)
+		expense.PayeeID = toID
+		expense.UpdatedAt = time.Now()
+		expense.Version++
+		moved++
+	}
+
+	return moved, nil
+}
+
+func (r *expenseRepository) ReassignCategory(ctx context.Context, fromID, toID int) (int64, error) {
+	return r.reassign(ctx, func(categoryID *int, subCategoryID **int) bool {
+		if *categoryID != fromID {
+			return false
+		}
+		*categoryID = toID
+		return true
+	})
+}
+
+func (r *expenseRepository) ReassignSubCategory(ctx context.Context, fromID, toID, toCategoryID int) (int64, error) {
+	return r.reassign(ctx, func(categoryID *int, subCategoryID **int) bool {
+		if *subCategoryID == nil || **subCategoryID != fromID {
+			return false
+		}
+		*categoryID = toCategoryID
+		*subCategoryID = &toID
+		return true
+	})
+}
+
+// reassign applies move to the category and subcategory of every expense and split line,
+// making a new version of the expenses where it moved anything and returning how many there are
+func (r *expenseRepository) reassign(ctx context.Context, move func(categoryID *int, subCategoryID **int) bool) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var moved int64
+	for id, expense := range r.expenses {
+		categoryID, subCategoryID := expense.CategoryID, expense.SubCategoryID
+		changed := move(&categoryID, &subCategoryID)
+
+		// Build a new slice, copies handed out earlier share the old one
+		splits := slices.Clone(expense.Splits)
+		for i := range splits {
+			if move(&splits[i].CategoryID, &splits[i].SubCategoryID) {
+				changed = true
+			}
+		}
+
+		if changed {
+			trackRow(ctx, &r.mu, r.expenses, id, nil)
+			expense.CategoryID, expense.SubCategoryID = categoryID, subCategoryID
+			expense.Splits = splits
+			expense.UpdatedAt = time.Now()
+			expense.Version++
+			moved++
+		}
+	}
+
+	return moved, nil
+}
+
+func (r *expenseRepository) ShareDebts(ctx context.Context) ([]domain.PersonDebt, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	owed := make(map[[2]int]float64)
+	for _, expense := range r.expenses {
+		if expense.DeletedAt != nil || expense.Sharing == nil {
+			continue
+		}
+		for _, share := range expense.Sharing.Shares {
+			// The payer does not owe their own share
+			if share.PersonID != expense.Sharing.PaidByID {
+				owed[[2]int{share.PersonID, expense.Sharing.PaidByID}] += share.Amount
+			}
+		}
+	}
+
+	return personDebts(owed), nil
+}
+
+// personDebts converts amounts keyed by (from, to) person pairs, rounding them to cents like the numeric sums of Postgres
+// and ordering them by person pair
+func personDebts(amounts map[[2]int]float64) []domain.PersonDebt {
+	debts := make([]domain.PersonDebt, 0, len(amounts))
+	for pair, amount := range amounts {
+		debts = append(debts, domain.PersonDebt{
+			FromPersonID: pair[0],
+			ToPersonID:   pair[1],
+			Amount:       math.Round(amount*100) / 100,
+		})
+	}
+	sort.Slice(debts, func(i, j int) bool {
+		if debts[i].FromPersonID != debts[j].FromPersonID {
+			return debts[i].FromPersonID < debts[j].FromPersonID
+		}
+		return debts[i].ToPersonID < debts[j].ToPersonID
+	})
+	return debts
+}
+
+// sortedIDs returns a sorted copy of ids
+func sortedIDs(ids []int) []int {
+	if ids == nil {
+		return nil
+	}
+	sorted := slices.Clone(ids)
+	slices.Sort(sorted)
+	return sorted
+}
+
+// references reports whether an expense, deleted or not, matches. It lets the other repositories
+// keep the records still used by expenses like the foreign keys of the databases do.
+func (r *expenseRepository) references(match func(expense *domain.Expense) bool) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, expense := range r.expenses {
+		if match(expense) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *expenseRepository) snapshotName() string {
+	return "expenses"
+}
+
+func (r *expenseRepository) snapshot() (json.RawMessage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return encodeTable(r.expenses, r.nextID)
+}
+
+func (r *expenseRepository) restore(data json.RawMessage) error {
+	expenses, nextID, err := decodeTable(data, func(expense *domain.Expense) int { return expense.ID })
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	delete(r.expenses, id)
+	r.expenses = expenses
+	r.nextID = nextID
 	return nil
 }
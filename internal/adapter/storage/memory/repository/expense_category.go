@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"slices"
 	"sort"
 	"sync"
 	"time"
@@ -11,16 +13,22 @@ import (
 )
 
 type expenseCategoryRepository struct {
-	categories map[int]*domain.ExpenseCategory
-	nextID     int
-	mu         sync.RWMutex
+	categories    map[int]*domain.ExpenseCategory
+	nextID        int
+	mu            sync.RWMutex
+	expenses      *expenseRepository
+	subcategories port.ExpenseSubCategoryRepository
 }
 
-// NewExpenseCategoryRepository creates a new in-memory expense category repository
-func NewExpenseCategoryRepository() port.ExpenseCategoryRepository {
+// NewExpenseCategoryRepository creates a new in-memory expense category repository.
+// The expense repository, created by NewExpenseRepository, and the subcategory repository keep the
+// categories they use from being purged.
+func NewExpenseCategoryRepository(expenses port.ExpenseRepository, subcategories port.ExpenseSubCategoryRepository) port.ExpenseCategoryRepository {
 	return &expenseCategoryRepository{
-		categories: make(map[int]*domain.ExpenseCategory),
-		nextID:     1,
+		categories:    make(map[int]*domain.ExpenseCategory),
+		nextID:        1,
+		expenses:      expenses.(*expenseRepository),
+		subcategories: subcategories,
 	}
 }
 
@@ -28,15 +36,20 @@ func (r *expenseCategoryRepository) Create(ctx context.Context, category *domain
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	trackRow(ctx, &r.mu, r.categories, r.nextID, &r.nextID)
 	category.ID = r.nextID
+	category.Version = 1
 	r.nextID++
 
 	// Create a copy to avoid reference issues
 	categoryCopy := &domain.ExpenseCategory{
 		ID:        category.ID,
 		Name:      category.Name,
+		Aliases:   slices.Clone(category.Aliases),
+		Version:   category.Version,
 		CreatedAt: category.CreatedAt,
 		UpdatedAt: category.UpdatedAt,
+		DeletedAt: category.DeletedAt,
 	}
 
 	r.categories[category.ID] = categoryCopy
@@ -48,7 +61,7 @@ func (r *expenseCategoryRepository) GetByID(ctx context.Context, id int) (*domai
 	defer r.mu.RUnlock()
 
 	category, exists := r.categories[id]
-	if !exists {
+	if !exists || category.DeletedAt != nil {
 		return nil, domain.ErrDataNotFound
 	}
 
@@ -56,58 +69,90 @@ func (r *expenseCategoryRepository) GetByID(ctx context.Context, id int) (*domai
 	return &domain.ExpenseCategory{
 		ID:        category.ID,
 		Name:      category.Name,
+		Aliases:   slices.Clone(category.Aliases),
+		Version:   category.Version,
 		CreatedAt: category.CreatedAt,
 		UpdatedAt: category.UpdatedAt,
+		DeletedAt: category.DeletedAt,
 	}, nil
 }
 
-func (r *expenseCategoryRepository) List(ctx context.Context, skip, limit int) ([]*domain.ExpenseCategory, error) {
+// GetByIDForShare selects a category like GetByID,
+// the in-memory store has no concurrent transactions to lock rows against
+func (r *expenseCategoryRepository) GetByIDForShare(ctx context.Context, id int) (*domain.ExpenseCategory, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *expenseCategoryRepository) List(ctx context.Context, after *domain.Cursor, limit int, includeDeleted bool) ([]*domain.ExpenseCategory, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	// Convert map to slice
+	// Convert map to slice, skipping soft-deleted categories unless requested
 	categories := make([]*domain.ExpenseCategory, 0, len(r.categories))
 	for _, category := range r.categories {
+		if category.DeletedAt != nil && !includeDeleted {
+			continue
+		}
+		if !afterCreatedAtCursor(category.CreatedAt, uint64(category.ID), after) {
+			continue
+		}
+
 		categories = append(categories, &domain.ExpenseCategory{
 			ID:        category.ID,
 			Name:      category.Name,
+			Aliases:   slices.Clone(category.Aliases),
+			Version:   category.Version,
 			CreatedAt: category.CreatedAt,
 			UpdatedAt: category.UpdatedAt,
+			DeletedAt: category.DeletedAt,
 		})
 	}
 
-	// Sort by created_at descending (newest first)
+	// Sort by created_at then ID descending (newest first)
 	sort.Slice(categories, func(i, j int) bool {
-		return categories[i].CreatedAt.After(categories[j].CreatedAt)
+		if !categories[i].CreatedAt.Equal(categories[j].CreatedAt) {
+			return categories[i].CreatedAt.After(categories[j].CreatedAt)
+		}
+		return categories[i].ID > categories[j].ID
 	})
 
-	// Apply pagination
-	start := skip
-	if start > len(categories) {
-		start = len(categories)
-	}
+	return firstItems(categories, limit), nil
+}
 
-	end := start + limit
-	if end > len(categories) {
-		end = len(categories)
+func (r *expenseCategoryRepository) Count(ctx context.Context, includeDeleted bool) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, category := range r.categories {
+		if category.DeletedAt == nil || includeDeleted {
+			count++
+		}
 	}
 
-	return categories[start:end], nil
+	return count, nil
 }
 
 func (r *expenseCategoryRepository) Update(ctx context.Context, category *domain.ExpenseCategory) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	_, exists := r.categories[category.ID]
-	if !exists {
+	existing, exists := r.categories[category.ID]
+	if !exists || existing.DeletedAt != nil {
 		return domain.ErrDataNotFound
 	}
+	if !versionMatches(existing.Version, category.Version) {
+		return domain.ErrPreconditionFailed
+	}
+	category.Version = existing.Version + 1
 
+	trackRow(ctx, &r.mu, r.categories, category.ID, nil)
 	// Update the category
 	r.categories[category.ID] = &domain.ExpenseCategory{
 		ID:        category.ID,
 		Name:      category.Name,
+		Aliases:   slices.Clone(category.Aliases),
+		Version:   category.Version,
 		CreatedAt: category.CreatedAt,
 		UpdatedAt: time.Now(),
 	}
@@ -115,15 +160,100 @@ func (r *expenseCategoryRepository) Update(ctx context.Context, category *domain
 	return nil
 }
 
-func (r *expenseCategoryRepository) Delete(ctx context.Context, id int) error {
+func (r *expenseCategoryRepository) Delete(ctx context.Context, id int, version int) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	_, exists := r.categories[id]
-	if !exists {
+	category, exists := r.categories[id]
+	if !exists || category.DeletedAt != nil {
 		return domain.ErrDataNotFound
 	}
+	if !versionMatches(category.Version, version) {
+		return domain.ErrPreconditionFailed
+	}
+
+	trackRow(ctx, &r.mu, r.categories, id, nil)
+	now := time.Now()
+	category.DeletedAt = &now
+	category.Version++
+	return nil
+}
+
+func (r *expenseCategoryRepository) Restore(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	category, exists := r.categories[id]
+	if !exists || category.DeletedAt == nil {
+		return domain.ErrDataNotFound
+	}
+
+	trackRow(ctx, &r.mu, r.categories, id, nil)
+	category.DeletedAt = nil
+	category.UpdatedAt = time.Now()
+	category.Version++
+	return nil
+}
+
+func (r *expenseCategoryRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for id, category := range r.categories {
+		if category.DeletedAt != nil && category.DeletedAt.Before(deletedBefore) {
+			// Categories still referenced by expenses, their split lines or subcategories are kept until those are purged
+			used, err := r.used(ctx, id)
+			if err != nil {
+				return purged, err
+			}
+			if used {
+				continue
+			}
+			trackRow(ctx, &r.mu, r.categories, id, nil)
+			delete(r.categories, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// used reports whether an expense, one of its split lines or a subcategory, deleted or not, refers to the category
+func (r *expenseCategoryRepository) used(ctx context.Context, id int) (bool, error) {
+	subcategories, err := r.subcategories.Count(ctx, &id, true)
+	if err != nil || subcategories > 0 {
+		return subcategories > 0, err
+	}
+
+	return r.expenses.references(func(expense *domain.Expense) bool {
+		return expense.CategoryID == id || slices.ContainsFunc(expense.Splits, func(split domain.ExpenseSplit) bool {
+			return split.CategoryID == id
+		})
+	}), nil
+}
+
+func (r *expenseCategoryRepository) snapshotName() string {
+	return "expense_categories"
+}
+
+func (r *expenseCategoryRepository) snapshot() (json.RawMessage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return encodeTable(r.categories, r.nextID)
+}
+
+func (r *expenseCategoryRepository) restore(data json.RawMessage) error {
+	categories, nextID, err := decodeTable(data, func(expenseCategory *domain.ExpenseCategory) int { return expenseCategory.ID })
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	delete(r.categories, id)
+	r.categories = categories
+	r.nextID = nextID
 	return nil
 }
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"slices"
 	"sort"
 	"sync"
 	"time"
@@ -28,16 +30,21 @@ func (r *expenseSubCategoryRepository) Create(ctx context.Context, subcategory *
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	trackRow(ctx, &r.mu, r.subcategories, r.nextID, &r.nextID)
 	subcategory.ID = r.nextID
+	subcategory.Version = 1
 	r.nextID++
 
 	// Create a copy to avoid reference issues
 	subcategoryCopy := &domain.ExpenseSubCategory{
 		ID:                subcategory.ID,
 		Name:              subcategory.Name,
+		Aliases:           slices.Clone(subcategory.Aliases),
 		ExpenseCategoryID: subcategory.ExpenseCategoryID,
+		Version:           subcategory.Version,
 		CreatedAt:         subcategory.CreatedAt,
 		UpdatedAt:         subcategory.UpdatedAt,
+		DeletedAt:         subcategory.DeletedAt,
 	}
 
 	r.subcategories[subcategory.ID] = subcategoryCopy
@@ -49,7 +56,7 @@ func (r *expenseSubCategoryRepository) GetByID(ctx context.Context, id int) (*do
 	defer r.mu.RUnlock()
 
 	subcategory, exists := r.subcategories[id]
-	if !exists {
+	if !exists || subcategory.DeletedAt != nil {
 		return nil, domain.ErrDataNotFound
 	}
 
@@ -57,13 +64,22 @@ func (r *expenseSubCategoryRepository) GetByID(ctx context.Context, id int) (*do
 	return &domain.ExpenseSubCategory{
 		ID:                subcategory.ID,
 		Name:              subcategory.Name,
+		Aliases:           slices.Clone(subcategory.Aliases),
 		ExpenseCategoryID: subcategory.ExpenseCategoryID,
+		Version:           subcategory.Version,
 		CreatedAt:         subcategory.CreatedAt,
 		UpdatedAt:         subcategory.UpdatedAt,
+		DeletedAt:         subcategory.DeletedAt,
 	}, nil
 }
 
-func (r *expenseSubCategoryRepository) List(ctx context.Context, skip, limit int, expenseCategoryID *int) ([]*domain.ExpenseSubCategory, error) {
+// GetByIDForShare selects a subcategory like GetByID,
+// the in-memory store has no concurrent transactions to lock rows against
+func (r *expenseSubCategoryRepository) GetByIDForShare(ctx context.Context, id int) (*domain.ExpenseSubCategory, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *expenseSubCategoryRepository) List(ctx context.Context, after *domain.Cursor, limit int, expenseCategoryID *int, includeDeleted bool) ([]*domain.ExpenseSubCategory, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -75,48 +91,77 @@ func (r *expenseSubCategoryRepository) List(ctx context.Context, skip, limit int
 			continue
 		}
 
+		// Skip soft-deleted subcategories unless requested
+		if subcategory.DeletedAt != nil && !includeDeleted {
+			continue
+		}
+
+		// Continue after the last subcategory of the previous page
+		if !afterCreatedAtCursor(subcategory.CreatedAt, uint64(subcategory.ID), after) {
+			continue
+		}
+
 		subcategories = append(subcategories, &domain.ExpenseSubCategory{
 			ID:                subcategory.ID,
 			Name:              subcategory.Name,
+			Aliases:           slices.Clone(subcategory.Aliases),
 			ExpenseCategoryID: subcategory.ExpenseCategoryID,
+			Version:           subcategory.Version,
 			CreatedAt:         subcategory.CreatedAt,
 			UpdatedAt:         subcategory.UpdatedAt,
+			DeletedAt:         subcategory.DeletedAt,
 		})
 	}
 
-	// Sort by created_at descending (newest first)
+	// Sort by created_at then ID descending (newest first)
 	sort.Slice(subcategories, func(i, j int) bool {
-		return subcategories[i].CreatedAt.After(subcategories[j].CreatedAt)
+		if !subcategories[i].CreatedAt.Equal(subcategories[j].CreatedAt) {
+			return subcategories[i].CreatedAt.After(subcategories[j].CreatedAt)
+		}
+		return subcategories[i].ID > subcategories[j].ID
 	})
 
-	// Apply pagination
-	start := skip
-	if start > len(subcategories) {
-		start = len(subcategories)
-	}
+	return firstItems(subcategories, limit), nil
+}
+
+func (r *expenseSubCategoryRepository) Count(ctx context.Context, expenseCategoryID *int, includeDeleted bool) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 
-	end := start + limit
-	if end > len(subcategories) {
-		end = len(subcategories)
+	var count int64
+	for _, subcategory := range r.subcategories {
+		if expenseCategoryID != nil && subcategory.ExpenseCategoryID != *expenseCategoryID {
+			continue
+		}
+		if subcategory.DeletedAt == nil || includeDeleted {
+			count++
+		}
 	}
 
-	return subcategories[start:end], nil
+	return count, nil
 }
 
 func (r *expenseSubCategoryRepository) Update(ctx context.Context, subcategory *domain.ExpenseSubCategory) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	_, exists := r.subcategories[subcategory.ID]
-	if !exists {
+	existing, exists := r.subcategories[subcategory.ID]
+	if !exists || existing.DeletedAt != nil {
 		return domain.ErrDataNotFound
 	}
+	if !versionMatches(existing.Version, subcategory.Version) {
+		return domain.ErrPreconditionFailed
+	}
+	subcategory.Version = existing.Version + 1
 
+	trackRow(ctx, &r.mu, r.subcategories, subcategory.ID, nil)
 	// Update the subcategory
 	r.subcategories[subcategory.ID] = &domain.ExpenseSubCategory{
 		ID:                subcategory.ID,
 		Name:              subcategory.Name,
+		Aliases:           slices.Clone(subcategory.Aliases),
 		ExpenseCategoryID: subcategory.ExpenseCategoryID,
+		Version:           subcategory.Version,
 		CreatedAt:         subcategory.CreatedAt,
 		UpdatedAt:         time.Now(),
 	}
@@ -124,15 +169,78 @@ func (r *expenseSubCategoryRepository) Update(ctx context.Context, subcategory *
 	return nil
 }
 
-func (r *expenseSubCategoryRepository) Delete(ctx context.Context, id int) error {
+func (r *expenseSubCategoryRepository) Delete(ctx context.Context, id int, version int) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	_, exists := r.subcategories[id]
-	if !exists {
+	subcategory, exists := r.subcategories[id]
+	if !exists || subcategory.DeletedAt != nil {
 		return domain.ErrDataNotFound
 	}
+	if !versionMatches(subcategory.Version, version) {
+		return domain.ErrPreconditionFailed
+	}
+
+	trackRow(ctx, &r.mu, r.subcategories, id, nil)
+	now := time.Now()
+	subcategory.DeletedAt = &now
+	subcategory.Version++
+	return nil
+}
+
+func (r *expenseSubCategoryRepository) Restore(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subcategory, exists := r.subcategories[id]
+	if !exists || subcategory.DeletedAt == nil {
+		return domain.ErrDataNotFound
+	}
+
+	trackRow(ctx, &r.mu, r.subcategories, id, nil)
+	subcategory.DeletedAt = nil
+	subcategory.UpdatedAt = time.Now()
+	subcategory.Version++
+	return nil
+}
+
+func (r *expenseSubCategoryRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for id, subcategory := range r.subcategories {
+		if subcategory.DeletedAt != nil && subcategory.DeletedAt.Before(deletedBefore) {
+			trackRow(ctx, &r.mu, r.subcategories, id, nil)
+			delete(r.subcategories, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+func (r *expenseSubCategoryRepository) snapshotName() string {
+	return "expense_subcategories"
+}
+
+func (r *expenseSubCategoryRepository) snapshot() (json.RawMessage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return encodeTable(r.subcategories, r.nextID)
+}
+
+func (r *expenseSubCategoryRepository) restore(data json.RawMessage) error {
+	subcategories, nextID, err := decodeTable(data, func(expenseSubCategory *domain.ExpenseSubCategory) int { return expenseSubCategory.ID })
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	delete(r.subcategories, id)
+	r.subcategories = subcategories
+	r.nextID = nextID
 	return nil
 }
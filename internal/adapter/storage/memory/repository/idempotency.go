@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+// idempotencyKey identifies a record by its key and the scope it was used on
+type idempotencyKey struct {
+	key   string
+	scope string
+}
+
+type idempotencyRepository struct {
+	records map[idempotencyKey]*domain.IdempotencyRecord
+	mu      sync.Mutex
+}
+
+// NewIdempotencyRepository creates a new in-memory idempotency repository
+func NewIdempotencyRepository() port.IdempotencyRepository {
+	return &idempotencyRepository{
+		records: make(map[idempotencyKey]*domain.IdempotencyRecord),
+	}
+}
+
+func (r *idempotencyRepository) Reserve(ctx context.Context, record *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := idempotencyKey{key: record.Key, scope: record.Scope}
+
+	// An expired record is taken over as if the key had never been used
+	if existing, exists := r.records[id]; exists && existing.ExpiresAt.After(record.CreatedAt) {
+		// Return a copy to avoid reference issues
+		existingCopy := *existing
+		return &existingCopy, nil
+	}
+
+	// Create a copy to avoid reference issues
+	recordCopy := *record
+	recordCopy.StatusCode = 0
+	recordCopy.Headers = nil
+	recordCopy.Body = nil
+	r.records[id] = &recordCopy
+
+	return nil, nil
+}
+
+func (r *idempotencyRepository) Complete(ctx context.Context, record *domain.IdempotencyRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, exists := r.records[idempotencyKey{key: record.Key, scope: record.Scope}]
+	if !exists {
+		return domain.ErrDataNotFound
+	}
+
+	existing.StatusCode = record.StatusCode
+	existing.Headers = record.Headers
+	existing.Body = record.Body
+	return nil
+}
+
+func (r *idempotencyRepository) Release(ctx context.Context, key, scope string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := idempotencyKey{key: key, scope: scope}
+	if existing, exists := r.records[id]; exists && !existing.Completed() {
+		delete(r.records, id)
+	}
+
+	return nil
+}
+
+func (r *idempotencyRepository) PurgeExpired(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for id, record := range r.records {
+		if record.ExpiresAt.Before(expiredBefore) {
+			delete(r.records, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// journalContextKey is the context key of the journal of the running unit of work
+type journalContextKey struct{}
+
+// Journal records how to undo each write made to the repositories during a unit of work,
+// so that a failed unit of work only pays for the rows it wrote
+type Journal struct {
+	mu   sync.Mutex
+	undo []func()
+}
+
+// WithJournal returns a copy of ctx whose writes are recorded in journal
+func WithJournal(ctx context.Context, journal *Journal) context.Context {
+	return context.WithValue(ctx, journalContextKey{}, journal)
+}
+
+// JournalFromContext returns the journal of the unit of work running in ctx, nil outside of one
+func JournalFromContext(ctx context.Context) *Journal {
+	journal, _ := ctx.Value(journalContextKey{}).(*Journal)
+	return journal
+}
+
+// Len returns the number of writes recorded, the mark to roll back to
+func (j *Journal) Len() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return len(j.undo)
+}
+
+// RollbackTo undoes the writes recorded after the first n ones, the latest first
+func (j *Journal) RollbackTo(n int) {
+	j.mu.Lock()
+	undo := j.undo[n:]
+	j.undo = j.undo[:n]
+	j.mu.Unlock()
+
+	for i := len(undo) - 1; i >= 0; i-- {
+		undo[i]()
+	}
+}
+
+// record adds undo to the journal of the unit of work running in ctx, writes made outside of one are final
+func record(ctx context.Context, undo func()) {
+	journal := JournalFromContext(ctx)
+	if journal == nil {
+		return
+	}
+	journal.mu.Lock()
+	defer journal.mu.Unlock()
+	journal.undo = append(journal.undo, undo)
+}
+
+// trackRow records how to put back the row of id, or its absence, and the next id to assign unless nil,
+// as they are before a write. It is called with mu held, which the undo takes again.
+func trackRow[K comparable, T any](ctx context.Context, mu *sync.RWMutex, rows map[K]*T, id K, nextID *K) {
+	if JournalFromContext(ctx) == nil {
+		return
+	}
+
+	// Rows are changed in place, so the undo keeps a copy of the row
+	var saved *T
+	if row, exists := rows[id]; exists {
+		saved = cloneRow(row)
+	}
+	var next K
+	if nextID != nil {
+		next = *nextID
+	}
+	record(ctx, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if saved == nil {
+			delete(rows, id)
+		} else {
+			rows[id] = saved
+		}
+		if nextID != nil {
+			*nextID = next
+		}
+	})
+}
+
+// cloneRow returns a deep copy of row, rows are stored the way snapshots encode them
+func cloneRow[T any](row *T) *T {
+	data, err := json.Marshal(row)
+	if err != nil {
+		panic(err)
+	}
+	clone := new(T)
+	if err := json.Unmarshal(data, clone); err != nil {
+		panic(err)
+	}
+	return clone
+}
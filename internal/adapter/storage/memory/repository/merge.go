@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+type mergeRepository struct {
+	merges map[uint64]*domain.Merge
+	nextID uint64
+	mu     sync.RWMutex
+}
+
+// NewMergeRepository creates a new in-memory merge repository
+func NewMergeRepository() port.MergeRepository {
+	return &mergeRepository{
+		merges: make(map[uint64]*domain.Merge),
+		nextID: 1,
+	}
+}
+
+func (r *mergeRepository) Create(ctx context.Context, merge *domain.Merge) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	trackRow(ctx, &r.mu, r.merges, r.nextID, &r.nextID)
+	merge.ID = r.nextID
+	r.nextID++
+
+	// Create a copy to avoid reference issues
+	mergeCopy := *merge
+	r.merges[merge.ID] = &mergeCopy
+	return nil
+}
+
+func (r *mergeRepository) List(ctx context.Context, after *domain.Cursor, limit int, filters port.MergeFilters) ([]*domain.Merge, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	merges := make([]*domain.Merge, 0, len(r.merges))
+	for _, merge := range r.merges {
+		if !afterIDCursor(merge.ID, after) || !mergeMatches(merge, filters) {
+			continue
+		}
+		mergeCopy := *merge
+		merges = append(merges, &mergeCopy)
+	}
+
+	// Sort by ID
+	sort.Slice(merges, func(i, j int) bool {
+		return merges[i].ID < merges[j].ID
+	})
+
+	return firstItems(merges, limit), nil
+}
+
+func (r *mergeRepository) Count(ctx context.Context, filters port.MergeFilters) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, merge := range r.merges {
+		if mergeMatches(merge, filters) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// mergeMatches tells whether a merge matches the filters
+func mergeMatches(merge *domain.Merge, filters port.MergeFilters) bool {
+	if filters.EntityType != nil && merge.EntityType != *filters.EntityType {
+		return false
+	}
+	if filters.TargetID != nil && merge.TargetID != *filters.TargetID {
+		return false
+	}
+	return true
+}
+
+func (r *mergeRepository) snapshotName() string {
+	return "merges"
+}
+
+func (r *mergeRepository) snapshot() (json.RawMessage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return encodeTable(r.merges, r.nextID)
+}
+
+func (r *mergeRepository) restore(data json.RawMessage) error {
+	merges, nextID, err := decodeTable(data, func(merge *domain.Merge) uint64 { return merge.ID })
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.merges = merges
+	r.nextID = nextID
+	return nil
+}
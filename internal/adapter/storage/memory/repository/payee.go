@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+type payeeRepository struct {
+	payees map[int]*domain.Payee
+	nextID int
+	mu     sync.RWMutex
+}
+
+// NewPayeeRepository creates a new in-memory payee repository
+func NewPayeeRepository() port.PayeeRepository {
+	return &payeeRepository{
+		payees: make(map[int]*domain.Payee),
+		nextID: 1,
+	}
+}
+
+func (r *payeeRepository) Create(ctx context.Context, payee *domain.Payee) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	trackRow(ctx, &r.mu, r.payees, r.nextID, &r.nextID)
+	payee.ID = r.nextID
+	payee.Version = 1
+	r.nextID++
+
+	r.payees[payee.ID] = copyPayee(payee)
+	return nil
+}
+
+func (r *payeeRepository) GetByID(ctx context.Context, id int) (*domain.Payee, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	payee, exists := r.payees[id]
+	if !exists {
+		return nil, domain.ErrDataNotFound
+	}
+
+	return copyPayee(payee), nil
+}
+
+func (r *payeeRepository) GetByName(ctx context.Context, name string) (*domain.Payee, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, payee := range r.payees {
+		if strings.EqualFold(payee.Name, name) {
+			return copyPayee(payee), nil
+		}
+	}
+
+	return nil, domain.ErrDataNotFound
+}
+
+func (r *payeeRepository) List(ctx context.Context, after *domain.Cursor, limit int, search string) ([]*domain.Payee, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	payees := make([]*domain.Payee, 0, len(r.payees))
+	for _, payee := range r.payees {
+		if !afterIDCursor(uint64(payee.ID), after) || !payeeMatches(payee, search) {
+			continue
+		}
+		payees = append(payees, copyPayee(payee))
+	}
+
+	// Sort by ID
+	sort.Slice(payees, func(i, j int) bool {
+		return payees[i].ID < payees[j].ID
+	})
+
+	return firstItems(payees, limit), nil
+}
+
+func (r *payeeRepository) Count(ctx context.Context, search string) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, payee := range r.payees {
+		if payeeMatches(payee, search) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *payeeRepository) Update(ctx context.Context, payee *domain.Payee) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, exists := r.payees[payee.ID]
+	if !exists {
+		return domain.ErrDataNotFound
+	}
+	if !versionMatches(existing.Version, payee.Version) {
+		return domain.ErrPreconditionFailed
+	}
+	payee.Version = existing.Version + 1
+
+	trackRow(ctx, &r.mu, r.payees, payee.ID, nil)
+	// Update the payee
+	r.payees[payee.ID] = copyPayee(payee)
+	return nil
+}
+
+func (r *payeeRepository) Delete(ctx context.Context, id int, version int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	payee, exists := r.payees[id]
+	if !exists {
+		return domain.ErrDataNotFound
+	}
+	if !versionMatches(payee.Version, version) {
+		return domain.ErrPreconditionFailed
+	}
+
+	trackRow(ctx, &r.mu, r.payees, id, nil)
+	delete(r.payees, id)
+	return nil
+}
+
+func (r *payeeRepository) ReassignDefaultCategory(ctx context.Context, fromID, toID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, payee := range r.payees {
+		if payee.DefaultCategoryID == nil || *payee.DefaultCategoryID != fromID {
+			continue
+		}
+		trackRow(ctx, &r.mu, r.payees, id, nil)
+		payeeCopy := copyPayee(payee)
+		payeeCopy.DefaultCategoryID = &toID
+		payeeCopy.UpdatedAt = time.Now()
+		payeeCopy.Version++
+		r.payees[id] = payeeCopy
+	}
+
+	return nil
+}
+
+func (r *payeeRepository) ReassignDefaultSubCategory(ctx context.Context, fromID, toID, toCategoryID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, payee := range r.payees {
+		if payee.DefaultSubCategoryID == nil || *payee.DefaultSubCategoryID != fromID {
+			continue
+		}
+		trackRow(ctx, &r.mu, r.payees, id, nil)
+		payeeCopy := copyPayee(payee)
+		payeeCopy.DefaultCategoryID = &toCategoryID
+		payeeCopy.DefaultSubCategoryID = &toID
+		payeeCopy.UpdatedAt = time.Now()
+		payeeCopy.Version++
+		r.payees[id] = payeeCopy
+	}
+
+	return nil
+}
+
+// payeeMatches tells whether the name or an alias of a payee contains the search text, ignoring case
+func payeeMatches(payee *domain.Payee, search string) bool {
+	if search == "" {
+		return true
+	}
+	search = strings.ToLower(search)
+	if strings.Contains(strings.ToLower(payee.Name), search) {
+		return true
+	}
+	return slices.ContainsFunc(payee.Aliases, func(alias string) bool {
+		return strings.Contains(strings.ToLower(alias), search)
+	})
+}
+
+// copyPayee copies a payee along with its aliases to avoid reference issues
+func copyPayee(payee *domain.Payee) *domain.Payee {
+	payeeCopy := *payee
+	payeeCopy.Aliases = slices.Clone(payee.Aliases)
+	if payeeCopy.Aliases == nil {
+		payeeCopy.Aliases = []string{}
+	}
+	return &payeeCopy
+}
+
+func (r *payeeRepository) snapshotName() string {
+	return "payees"
+}
+
+func (r *payeeRepository) snapshot() (json.RawMessage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return encodeTable(r.payees, r.nextID)
+}
+
+func (r *payeeRepository) restore(data json.RawMessage) error {
+	payees, nextID, err := decodeTable(data, func(payee *domain.Payee) int { return payee.ID })
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.payees = payees
+	r.nextID = nextID
+	return nil
+}
@@ -2,91 +2,172 @@ package repository
 
 import (
 	"context"
-	"log/slog"
+	"encoding/json"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
 )
 
-type PersonRepository struct {
-	data map[uint64]*domain.Person
+type personRepository struct {
+	persons map[uint64]*domain.Person
+	nextID  uint64
+	mu      sync.RWMutex
 }
 
-func NewPersonRepository() *PersonRepository {
-	return &PersonRepository{
-		data: make(map[uint64]*domain.Person),
+// NewPersonRepository creates a new in-memory person repository
+func NewPersonRepository() port.PersonRepository {
+	return &personRepository{
+		persons: make(map[uint64]*domain.Person),
+		nextID:  1,
 	}
 }
 
 // CreatePerson inserts a new Person into the repository
-func (r *PersonRepository) CreatePerson(ctx context.Context, person *domain.Person) (*domain.Person, error) {
-	person.ID = uint64(len(r.data) + 1) // Simple ID generation logic
+func (r *personRepository) CreatePerson(ctx context.Context, person *domain.Person) (*domain.Person, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	trackRow(ctx, &r.mu, r.persons, r.nextID, &r.nextID)
+	person.ID = r.nextID
 	person.CreatedAt = time.Now()
 	person.UpdatedAt = person.CreatedAt
+	person.Version = 1
+	r.nextID++
 
-	if _, exists := r.data[person.ID]; exists {
-		return nil, domain.ErrConflictingData
-	}
-	r.data[person.ID] = person
+	// Create a copy to avoid reference issues
+	personCopy := *person
+	r.persons[person.ID] = &personCopy
 	return person, nil
 }
 
 // GetPersonByID selects a Person by id
-func (r *PersonRepository) GetPersonByID(ctx context.Context, id uint64) (*domain.Person, error) {
-	person, exists := r.data[id]
+func (r *personRepository) GetPersonByID(ctx context.Context, id uint64) (*domain.Person, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	person, exists := r.persons[id]
 	if !exists {
 		return nil, domain.ErrDataNotFound
 	}
-	return person, nil
+
+	// Return a copy to avoid reference issues
+	personCopy := *person
+	return &personCopy, nil
+}
+
+// GetPersonByIDForShare selects a Person like GetPersonByID,
+// the in-memory store has no concurrent transactions to lock rows against
+func (r *personRepository) GetPersonByIDForShare(ctx context.Context, id uint64) (*domain.Person, error) {
+	return r.GetPersonByID(ctx, id)
 }
 
 // GetPersonByEmail selects a Person by email
-func (r *PersonRepository) GetPersonByEmail(ctx context.Context, email string) (*domain.Person, error) {
-	for _, person := range r.data {
+func (r *personRepository) GetPersonByEmail(ctx context.Context, email string) (*domain.Person, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, person := range r.persons {
 		if person.Email == email {
-			return person, nil
+			personCopy := *person
+			return &personCopy, nil
 		}
 	}
 	return nil, domain.ErrDataNotFound
 }
 
-// ListPersons selects a list of Persons with pagination
-func (r *PersonRepository) ListPersons(ctx context.Context, skip, limit uint64) ([]domain.Person, error) {
-	slog.Info("Listing persons repo", "skip", skip, "limit", limit)
+// ListPersons selects up to limit Persons ordered by id, starting after the cursor when given
+func (r *personRepository) ListPersons(ctx context.Context, after *domain.Cursor, limit int) ([]domain.Person, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Listing persons repo", "after", after, "limit", limit)
 	var persons []domain.Person
-	for _, person := range r.data {
-		persons = append(persons, *person)
-	}
-	slog.Info("Persons found", "count", len(persons))
-	if skip >= uint64(len(persons)) {
-		return nil, nil // No data to return
-	}
-	end := skip + limit
-	if end > uint64(len(persons)) {
-		end = uint64(len(persons))
+	for _, person := range r.persons {
+		if afterIDCursor(person.ID, after) {
+			persons = append(persons, *person)
+		}
 	}
-	return persons[skip:end], nil
+	sort.Slice(persons, func(i, j int) bool {
+		return persons[i].ID < persons[j].ID
+	})
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Persons found", "count", len(persons))
+	return firstItems(persons, limit), nil
+}
+
+// CountPersons counts every Person
+func (r *personRepository) CountPersons(ctx context.Context) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return int64(len(r.persons)), nil
 }
 
 // UpdatePerson updates a Person
-func (r *PersonRepository) UpdatePerson(ctx context.Context, Person *domain.Person) (*domain.Person, error) {
-	existingPerson, exists := r.data[Person.ID]
+func (r *personRepository) UpdatePerson(ctx context.Context, person *domain.Person) (*domain.Person, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existingPerson, exists := r.persons[person.ID]
 	if !exists {
 		return nil, domain.ErrDataNotFound
 	}
+	if !versionMatches(existingPerson.Version, person.Version) {
+		return nil, domain.ErrPreconditionFailed
+	}
+
 	// Update the existing person's fields
-	existingPerson.Name = Person.Name
-	existingPerson.Email = Person.Email
-	// Add other fields as necessary
-	r.data[Person.ID] = existingPerson
-	return existingPerson, nil
+	trackRow(ctx, &r.mu, r.persons, person.ID, nil)
+	existingPerson.Name = person.Name
+	existingPerson.Email = person.Email
+	existingPerson.UpdatedAt = time.Now()
+	existingPerson.Version++
+
+	// Return a copy to avoid reference issues
+	personCopy := *existingPerson
+	return &personCopy, nil
 }
 
 // DeletePerson deletes a Person
-func (r *PersonRepository) DeletePerson(ctx context.Context, id uint64) error {
-	if _, exists := r.data[id]; !exists {
+func (r *personRepository) DeletePerson(ctx context.Context, id uint64, version int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	person, exists := r.persons[id]
+	if !exists {
 		return domain.ErrDataNotFound
 	}
-	delete(r.data, id)
+	if !versionMatches(person.Version, version) {
+		return domain.ErrPreconditionFailed
+	}
+	trackRow(ctx, &r.mu, r.persons, id, nil)
+	delete(r.persons, id)
+	return nil
+}
+
+func (r *personRepository) snapshotName() string {
+	return "persons"
+}
+
+func (r *personRepository) snapshot() (json.RawMessage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return encodeTable(r.persons, r.nextID)
+}
+
+func (r *personRepository) restore(data json.RawMessage) error {
+	persons, nextID, err := decodeTable(data, func(person *domain.Person) uint64 { return person.ID })
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.persons = persons
+	r.nextID = nextID
 	return nil
 }
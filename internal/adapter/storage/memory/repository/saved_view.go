@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+type savedViewRepository struct {
+	views  map[int]*domain.SavedView
+	nextID int
+	mu     sync.RWMutex
+}
+
+// NewSavedViewRepository creates a new in-memory saved view repository
+func NewSavedViewRepository() port.SavedViewRepository {
+	return &savedViewRepository{
+		views:  make(map[int]*domain.SavedView),
+		nextID: 1,
+	}
+}
+
+func (r *savedViewRepository) Create(ctx context.Context, view *domain.SavedView) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	trackRow(ctx, &r.mu, r.views, r.nextID, &r.nextID)
+	view.ID = r.nextID
+	view.Version = 1
+	r.nextID++
+
+	// Create a copy to avoid reference issues
+	viewCopy := *view
+	r.views[view.ID] = &viewCopy
+	return nil
+}
+
+func (r *savedViewRepository) GetByID(ctx context.Context, id int) (*domain.SavedView, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	view, exists := r.views[id]
+	if !exists {
+		return nil, domain.ErrDataNotFound
+	}
+
+	// Return a copy to avoid reference issues
+	viewCopy := *view
+	return &viewCopy, nil
+}
+
+func (r *savedViewRepository) List(ctx context.Context, after *domain.Cursor, limit int) ([]*domain.SavedView, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	views := make([]*domain.SavedView, 0, len(r.views))
+	for _, view := range r.views {
+		if !afterIDCursor(uint64(view.ID), after) {
+			continue
+		}
+		viewCopy := *view
+		views = append(views, &viewCopy)
+	}
+
+	// Sort by ID
+	sort.Slice(views, func(i, j int) bool {
+		return views[i].ID < views[j].ID
+	})
+
+	return firstItems(views, limit), nil
+}
+
+func (r *savedViewRepository) Count(ctx context.Context) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return int64(len(r.views)), nil
+}
+
+func (r *savedViewRepository) Update(ctx context.Context, view *domain.SavedView) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, exists := r.views[view.ID]
+	if !exists {
+		return domain.ErrDataNotFound
+	}
+	if !versionMatches(existing.Version, view.Version) {
+		return domain.ErrPreconditionFailed
+	}
+	view.Version = existing.Version + 1
+
+	trackRow(ctx, &r.mu, r.views, view.ID, nil)
+	// Update the view
+	viewCopy := *view
+	r.views[view.ID] = &viewCopy
+	return nil
+}
+
+func (r *savedViewRepository) Delete(ctx context.Context, id int, version int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	view, exists := r.views[id]
+	if !exists {
+		return domain.ErrDataNotFound
+	}
+	if !versionMatches(view.Version, version) {
+		return domain.ErrPreconditionFailed
+	}
+
+	trackRow(ctx, &r.mu, r.views, id, nil)
+	delete(r.views, id)
+	return nil
+}
+
+func (r *savedViewRepository) snapshotName() string {
+	return "saved_views"
+}
+
+func (r *savedViewRepository) snapshot() (json.RawMessage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return encodeTable(r.views, r.nextID)
+}
+
+func (r *savedViewRepository) restore(data json.RawMessage) error {
+	views, nextID, err := decodeTable(data, func(savedView *domain.SavedView) int { return savedView.ID })
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.views = views
+	r.nextID = nextID
+	return nil
+}
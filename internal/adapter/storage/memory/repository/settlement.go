@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+type settlementRepository struct {
+	settlements map[int]*domain.Settlement
+	nextID      int
+	mu          sync.RWMutex
+}
+
+// NewSettlementRepository creates a new in-memory settlement repository
+func NewSettlementRepository() port.SettlementRepository {
+	return &settlementRepository{
+		settlements: make(map[int]*domain.Settlement),
+		nextID:      1,
+	}
+}
+
+func (r *settlementRepository) Create(ctx context.Context, settlement *domain.Settlement) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	trackRow(ctx, &r.mu, r.settlements, r.nextID, &r.nextID)
+	settlement.ID = r.nextID
+	settlement.Version = 1
+	r.nextID++
+
+	// Create a copy to avoid reference issues
+	settlementCopy := *settlement
+	r.settlements[settlement.ID] = &settlementCopy
+	return nil
+}
+
+func (r *settlementRepository) GetByID(ctx context.Context, id int) (*domain.Settlement, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	settlement, exists := r.settlements[id]
+	if !exists {
+		return nil, domain.ErrDataNotFound
+	}
+
+	// Return a copy to avoid reference issues
+	settlementCopy := *settlement
+	return &settlementCopy, nil
+}
+
+func (r *settlementRepository) List(ctx context.Context, after *domain.Cursor, limit int, personID int) ([]*domain.Settlement, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	settlements := make([]*domain.Settlement, 0, len(r.settlements))
+	for _, settlement := range r.settlements {
+		if !afterIDCursor(uint64(settlement.ID), after) || !involvesPerson(settlement, personID) {
+			continue
+		}
+		settlementCopy := *settlement
+		settlements = append(settlements, &settlementCopy)
+	}
+
+	// Sort by ID
+	sort.Slice(settlements, func(i, j int) bool {
+		return settlements[i].ID < settlements[j].ID
+	})
+
+	return firstItems(settlements, limit), nil
+}
+
+func (r *settlementRepository) Count(ctx context.Context, personID int) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, settlement := range r.settlements {
+		if involvesPerson(settlement, personID) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *settlementRepository) Delete(ctx context.Context, id int, version int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	settlement, exists := r.settlements[id]
+	if !exists {
+		return domain.ErrDataNotFound
+	}
+	if !versionMatches(settlement.Version, version) {
+		return domain.ErrPreconditionFailed
+	}
+
+	trackRow(ctx, &r.mu, r.settlements, id, nil)
+	delete(r.settlements, id)
+	return nil
+}
+
+func (r *settlementRepository) Totals(ctx context.Context) ([]domain.PersonDebt, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	paid := make(map[[2]int]float64)
+	for _, settlement := range r.settlements {
+		paid[[2]int{settlement.FromPersonID, settlement.ToPersonID}] += settlement.Amount
+	}
+
+	return personDebts(paid), nil
+}
+
+// involvesPerson reports whether a person is on either side of a settlement, 0 matches every settlement
+func involvesPerson(settlement *domain.Settlement, personID int) bool {
+	return personID == 0 || settlement.FromPersonID == personID || settlement.ToPersonID == personID
+}
+
+func (r *settlementRepository) snapshotName() string {
+	return "settlements"
+}
+
+func (r *settlementRepository) snapshot() (json.RawMessage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return encodeTable(r.settlements, r.nextID)
+}
+
+func (r *settlementRepository) restore(data json.RawMessage) error {
+	settlements, nextID, err := decodeTable(data, func(settlement *domain.Settlement) int { return settlement.ID })
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.settlements = settlements
+	r.nextID = nextID
+	return nil
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+)
+
+// snapshotter is implemented by the repositories whose data is kept in snapshots
+type snapshotter interface {
+	// snapshotName names the data of the repository within a snapshot
+	snapshotName() string
+	// snapshot encodes the data of the repository
+	snapshot() (json.RawMessage, error)
+	// restore replaces the data of the repository with the one of a snapshot
+	restore(data json.RawMessage) error
+}
+
+// SaveSnapshot writes the data of the given repositories to w as a single JSON document
+func SaveSnapshot(w io.Writer, repos ...any) error {
+	document := make(map[string]json.RawMessage, len(repos))
+	for _, repo := range repos {
+		s, ok := repo.(snapshotter)
+		if !ok {
+			return fmt.Errorf("%T cannot be saved in a snapshot", repo)
+		}
+		data, err := s.snapshot()
+		if err != nil {
+			return err
+		}
+		document[s.snapshotName()] = data
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
+
+// LoadSnapshot replaces the data of the given repositories with the one read from r.
+// Repositories missing from the snapshot are left untouched.
+func LoadSnapshot(r io.Reader, repos ...any) error {
+	var document map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&document); err != nil {
+		return err
+	}
+
+	for _, repo := range repos {
+		s, ok := repo.(snapshotter)
+		if !ok {
+			return fmt.Errorf("%T cannot be loaded from a snapshot", repo)
+		}
+		data, exists := document[s.snapshotName()]
+		if !exists {
+			continue
+		}
+		if err := s.restore(data); err != nil {
+			return fmt.Errorf("loading %s from the snapshot: %w", s.snapshotName(), err)
+		}
+	}
+
+	return nil
+}
+
+// table is the snapshot of a repository keeping its rows by id
+type table[K ~int | ~uint64, T any] struct {
+	NextID K    `json:"next_id"`
+	Rows   []*T `json:"rows"`
+}
+
+// encodeTable encodes the rows of a repository ordered by id along with the next id to assign
+func encodeTable[K ~int | ~uint64, T any](rows map[K]*T, nextID K) (json.RawMessage, error) {
+	t := table[K, T]{
+		NextID: nextID,
+		Rows:   make([]*T, 0, len(rows)),
+	}
+	for _, id := range slices.Sorted(maps.Keys(rows)) {
+		t.Rows = append(t.Rows, rows[id])
+	}
+	return json.Marshal(t)
+}
+
+// decodeTable decodes the rows of a repository, indexing them with the id function, and the next id to assign
+func decodeTable[K ~int | ~uint64, T any](data json.RawMessage, id func(*T) K) (map[K]*T, K, error) {
+	var t table[K, T]
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, 0, err
+	}
+
+	rows := make(map[K]*T, len(t.Rows))
+	nextID := max(t.NextID, 1)
+	for _, row := range t.Rows {
+		rows[id(row)] = row
+		// Never hand out an id already taken, whatever the snapshot says
+		nextID = max(nextID, id(row)+1)
+	}
+	return rows, nextID, nil
+}
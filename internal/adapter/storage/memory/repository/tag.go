@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+type tagRepository struct {
+	tags   map[int]*domain.Tag
+	nextID int
+	mu     sync.RWMutex
+}
+
+// NewTagRepository creates a new in-memory tag repository
+func NewTagRepository() port.TagRepository {
+	return &tagRepository{
+		tags:   make(map[int]*domain.Tag),
+		nextID: 1,
+	}
+}
+
+func (r *tagRepository) Create(ctx context.Context, tag *domain.Tag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	trackRow(ctx, &r.mu, r.tags, r.nextID, &r.nextID)
+	tag.ID = r.nextID
+	tag.Version = 1
+	r.nextID++
+
+	// Create a copy to avoid reference issues
+	tagCopy := *tag
+	r.tags[tag.ID] = &tagCopy
+	return nil
+}
+
+func (r *tagRepository) GetByID(ctx context.Context, id int) (*domain.Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tag, exists := r.tags[id]
+	if !exists {
+		return nil, domain.ErrDataNotFound
+	}
+
+	// Return a copy to avoid reference issues
+	tagCopy := *tag
+	return &tagCopy, nil
+}
+
+func (r *tagRepository) GetByName(ctx context.Context, name string) (*domain.Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, tag := range r.tags {
+		if strings.EqualFold(tag.Name, name) {
+			tagCopy := *tag
+			return &tagCopy, nil
+		}
+	}
+
+	return nil, domain.ErrDataNotFound
+}
+
+func (r *tagRepository) List(ctx context.Context, after *domain.Cursor, limit int) ([]*domain.Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tags := make([]*domain.Tag, 0, len(r.tags))
+	for _, tag := range r.tags {
+		if !afterIDCursor(uint64(tag.ID), after) {
+			continue
+		}
+		tagCopy := *tag
+		tags = append(tags, &tagCopy)
+	}
+
+	// Sort by ID
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].ID < tags[j].ID
+	})
+
+	return firstItems(tags, limit), nil
+}
+
+func (r *tagRepository) Count(ctx context.Context) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return int64(len(r.tags)), nil
+}
+
+func (r *tagRepository) Update(ctx context.Context, tag *domain.Tag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, exists := r.tags[tag.ID]
+	if !exists {
+		return domain.ErrDataNotFound
+	}
+	if !versionMatches(existing.Version, tag.Version) {
+		return domain.ErrPreconditionFailed
+	}
+	tag.Version = existing.Version + 1
+
+	trackRow(ctx, &r.mu, r.tags, tag.ID, nil)
+	// Update the tag
+	tagCopy := *tag
+	r.tags[tag.ID] = &tagCopy
+	return nil
+}
+
+func (r *tagRepository) Delete(ctx context.Context, id int, version int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tag, exists := r.tags[id]
+	if !exists {
+		return domain.ErrDataNotFound
+	}
+	if !versionMatches(tag.Version, version) {
+		return domain.ErrPreconditionFailed
+	}
+
+	trackRow(ctx, &r.mu, r.tags, id, nil)
+	delete(r.tags, id)
+	return nil
+}
+
+func (r *tagRepository) snapshotName() string {
+	return "tags"
+}
+
+func (r *tagRepository) snapshot() (json.RawMessage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return encodeTable(r.tags, r.nextID)
+}
+
+func (r *tagRepository) restore(data json.RawMessage) error {
+	tags, nextID, err := decodeTable(data, func(tag *domain.Tag) int { return tag.ID })
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tags = tags
+	r.nextID = nextID
+	return nil
+}
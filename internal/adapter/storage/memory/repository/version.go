@@ -0,0 +1,6 @@
+package repository
+
+// versionMatches reports whether a stored version satisfies the expected one, 0 skips the check
+func versionMatches(stored, expected int) bool {
+	return expected == 0 || stored == expected
+}
@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+	"errors"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+)
+
+// errNoSchema is returned when asked to move the in-memory storage to another schema version
+var errNoSchema = errors.New("the in-memory storage has no schema to migrate")
+
+// Migrator is the in-memory counterpart of the PostgreSQL schema migrator.
+// The memory repositories need no schema, so they are always up to date.
+type Migrator struct{}
+
+// NewMigrator creates a new in-memory schema migrator
+func NewMigrator() *Migrator {
+	return &Migrator{}
+}
+
+// Up does nothing, there is never a pending migration
+func (m *Migrator) Up(ctx context.Context) error {
+	return nil
+}
+
+// Down fails, there is no migration to roll back
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	return errNoSchema
+}
+
+// Goto fails unless asked for version 0, the only version there is
+func (m *Migrator) Goto(ctx context.Context, version uint) error {
+	if version != 0 {
+		return errNoSchema
+	}
+	return nil
+}
+
+// Status reports version 0 as both the applied and the latest version
+func (m *Migrator) Status(ctx context.Context) (*domain.SchemaStatus, error) {
+	return &domain.SchemaStatus{}, nil
+}
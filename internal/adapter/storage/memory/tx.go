@@ -0,0 +1,41 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/memory/repository"
+)
+
+// TxManager is the in-memory counterpart of the PostgreSQL transaction manager.
+// Units of work run one at a time, so the checks they make still hold when they write.
+// The repositories journal how to undo each row a unit of work writes, so a failed one,
+// nested ones included, only undoes its own writes at a cost bound to the rows it wrote.
+type TxManager struct {
+	mu sync.Mutex
+}
+
+// NewTxManager creates a new in-memory transaction manager for repositories created by the repository package
+func NewTxManager() *TxManager {
+	return &TxManager{}
+}
+
+// WithinTx runs fn while holding the lock shared by every unit of work.
+// Nested calls run inside the unit of work of their caller and only undo their own writes.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	journal := repository.JournalFromContext(ctx)
+	if journal == nil {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		journal = &repository.Journal{}
+		ctx = repository.WithJournal(ctx, journal)
+	}
+
+	// Nested calls roll back to the writes journaled when they started, savepoints of sorts
+	mark := journal.Len()
+	if err := fn(ctx); err != nil {
+		journal.RollbackTo(mark)
+		return err
+	}
+	return nil
+}
@@ -2,12 +2,17 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/edwins-leonardi/finaid-api/internal/adapter/config"
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/tracing"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // DB is a wrapper for PostgreSQL database connection
@@ -28,7 +33,13 @@ func New(ctx context.Context, config *config.DB) (*DB, error) {
 		config.Name,
 	)
 
-	db, err := pgxpool.New(ctx, url)
+	poolConfig, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		return nil, err
+	}
+	poolConfig.ConnConfig.Tracer = queryTracer{}
+
+	db, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -47,9 +58,12 @@ func New(ctx context.Context, config *config.DB) (*DB, error) {
 	}, nil
 }
 
-// ErrorCode returns the error code of the given error
+// ErrorCode returns the error code of the given error, empty when it does not come from PostgreSQL
 func (db *DB) ErrorCode(err error) string {
-	pgErr := err.(*pgconn.PgError)
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return ""
+	}
 	return pgErr.Code
 }
 
@@ -57,3 +71,21 @@ func (db *DB) ErrorCode(err error) string {
 func (db *DB) Close() {
 	db.Pool.Close()
 }
+
+// queryTracer traces each statement run on the pool in a span, recording the number of rows
+// the statement returned or affected as reported by its command tag
+type queryTracer struct{}
+
+func (queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	ctx, _ = tracing.StartQuery(ctx, semconv.DBSystemPostgreSQL, data.SQL)
+	return ctx
+}
+
+func (queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	// Finding no row answers the statement, it does not fail it
+	err := data.Err
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	tracing.EndQuery(trace.SpanFromContext(ctx), data.CommandTag.RowsAffected(), err)
+}
@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Error codes of the constraint violations translated into domain errors
+const (
+	foreignKeyViolationCode = "23503"
+	uniqueViolationCode     = "23505"
+	checkViolationCode      = "23514"
+)
+
+// MapError translates constraint violations into a domain.ConstraintError wrapping the domain error they
+// stand for, and returns any other error unchanged
+func MapError(err error) error {
+	return mapError(err, false)
+}
+
+// mapError is MapError for a statement known to be removing rows or not. Both sides of a foreign key
+// report the same code, the referencing table and the constraint, and the message is worded in the
+// language of the server, so removing rows tells a row still referenced apart from a row referring
+// to a missing one.
+func mapError(err error, removing bool) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch pgErr.Code {
+	case uniqueViolationCode:
+		return &domain.ConstraintError{Err: domain.ErrConflictingData, Constraint: pgErr.ConstraintName}
+	case foreignKeyViolationCode:
+		if removing {
+			return &domain.ConstraintError{Err: domain.ErrReferencedData, Constraint: pgErr.ConstraintName}
+		}
+		return &domain.ConstraintError{Err: domain.ErrInvalidReference, Constraint: pgErr.ConstraintName}
+	case checkViolationCode:
+		return &domain.ConstraintError{Err: domain.ErrInvalidInput, Constraint: pgErr.ConstraintName}
+	}
+	return err
+}
+
+// isDelete reports whether the statement removes rows
+func isDelete(sql string) bool {
+	return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(sql)), "DELETE")
+}
+
+// mappingQuerier translates the errors of the wrapped querier with MapError
+type mappingQuerier struct {
+	Querier
+}
+
+func (q mappingQuerier) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	cmdTag, err := q.Querier.Exec(ctx, sql, arguments...)
+	return cmdTag, mapError(err, isDelete(sql))
+}
+
+func (q mappingQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	rows, err := q.Querier.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, mapError(err, isDelete(sql))
+	}
+	return mappingRows{Rows: rows, removing: isDelete(sql)}, nil
+}
+
+func (q mappingQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return mappingRow{Row: q.Querier.QueryRow(ctx, sql, args...), removing: isDelete(sql)}
+}
+
+// mappingRows translates the error reported once the rows are read
+type mappingRows struct {
+	pgx.Rows
+	removing bool
+}
+
+func (r mappingRows) Err() error {
+	return mapError(r.Rows.Err(), r.removing)
+}
+
+// mappingRow translates the error of the statement, reported when the row is scanned
+type mappingRow struct {
+	pgx.Row
+	removing bool
+}
+
+func (r mappingRow) Scan(dest ...any) error {
+	return mapError(r.Row.Scan(dest...), r.removing)
+}
@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestMapError(t *testing.T) {
+	// Messages of a server running with lc_messages set to German
+	foreignKey := &pgconn.PgError{
+		Code:           foreignKeyViolationCode,
+		Message:        `Aktualisieren oder Löschen in Tabelle »person« verletzt Fremdschlüssel-Constraint »account_primary_owner_id_fkey« von Tabelle »account«`,
+		TableName:      "account",
+		ConstraintName: "account_primary_owner_id_fkey",
+	}
+	tests := []struct {
+		name    string
+		err     error
+		sql     string
+		wantErr error
+	}{
+		{"still referenced", foreignKey, `DELETE FROM person WHERE id = $1`, domain.ErrReferencedData},
+		{"still referenced indented", foreignKey, "\n\t\tdelete from person WHERE id = $1", domain.ErrReferencedData},
+		{"invalid reference", foreignKey, `INSERT INTO account (primary_owner_id) VALUES ($1)`, domain.ErrInvalidReference},
+		{"invalid reference on update", foreignKey, `UPDATE account SET primary_owner_id = $1`, domain.ErrInvalidReference},
+		{"unique", &pgconn.PgError{Code: uniqueViolationCode, ConstraintName: "person_email_key"}, `INSERT INTO person`, domain.ErrConflictingData},
+		{"check", &pgconn.PgError{Code: checkViolationCode, ConstraintName: "expenses_amount_check"}, `UPDATE expenses`, domain.ErrInvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := mapError(tt.err, isDelete(tt.sql))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			var constraintErr *domain.ConstraintError
+			if !errors.As(err, &constraintErr) || constraintErr.Constraint != tt.err.(*pgconn.PgError).ConstraintName {
+				t.Errorf("error = %#v, want the constraint of %v", err, tt.err)
+			}
+		})
+	}
+
+	other := errors.New("connection reset")
+	if err := MapError(other); err != other {
+		t.Errorf("MapError(%v) = %v, want it unchanged", other, err)
+	}
+}
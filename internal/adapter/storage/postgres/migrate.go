@@ -0,0 +1,177 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/postgres/migrations"
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/schema"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// migrationLockKey identifies the advisory lock held while migrating, so replicas starting together migrate one at a time
+const migrationLockKey int64 = 0x66696e616964
+
+// undefinedTableCode is the PostgreSQL error code of a query on a table that does not exist
+const undefinedTableCode = "42P01"
+
+// Migrator applies the migrations embedded in the binary. The applied version is kept in the
+// schema_migrations table laid out like the migrate CLI did, so databases it migrated carry on.
+type Migrator struct {
+	pool       *pgxpool.Pool
+	migrations schema.Migrations
+	logger     *slog.Logger
+}
+
+// NewMigrator creates a new PostgreSQL schema migrator
+func NewMigrator(db *DB, logger *slog.Logger) (port.SchemaMigrator, error) {
+	loaded, err := schema.Load(migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		pool:       db.Pool,
+		migrations: loaded,
+		logger:     logger,
+	}, nil
+}
+
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *pgxpool.Conn, current uint) error {
+		return m.migrate(ctx, conn, current, m.migrations.Latest())
+	})
+}
+
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	return m.withLock(ctx, func(conn *pgxpool.Conn, current uint) error {
+		target, err := m.migrations.Rollback(current, steps)
+		if err != nil {
+			return err
+		}
+		return m.migrate(ctx, conn, current, target)
+	})
+}
+
+func (m *Migrator) Goto(ctx context.Context, version uint) error {
+	if !m.migrations.Has(version) {
+		return fmt.Errorf("there is no migration with version %d", version)
+	}
+
+	return m.withLock(ctx, func(conn *pgxpool.Conn, current uint) error {
+		return m.migrate(ctx, conn, current, version)
+	})
+}
+
+func (m *Migrator) Status(ctx context.Context) (*domain.SchemaStatus, error) {
+	version, dirty, err := schemaVersion(ctx, m.pool)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.SchemaStatus{
+		Version: version,
+		Latest:  m.migrations.Latest(),
+		Dirty:   dirty,
+	}, nil
+}
+
+// withLock runs fn on a connection holding the migration lock, passing it the applied version
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn, current uint) error) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+		return err
+	}
+	// Unlock even when ctx is done, or the lock outlives the migration on the pooled connection
+	defer conn.Exec(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+
+	query := `CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)`
+	if _, err := conn.Exec(ctx, query); err != nil {
+		return err
+	}
+
+	// Read the version once locked, another replica may just have migrated
+	current, dirty, err := schemaVersion(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("the database is dirty at version %d, repair the schema by hand and reset schema_migrations", current)
+	}
+
+	return fn(conn, current)
+}
+
+// migrate steps from the current version to the target one, each migration in its own transaction
+func (m *Migrator) migrate(ctx context.Context, conn *pgxpool.Conn, current, target uint) error {
+	steps, err := m.migrations.Steps(current, target)
+	if err != nil {
+		return err
+	}
+
+	for _, step := range steps {
+		if err := m.apply(ctx, conn, step); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apply runs the statements of a migration step and records the version they leave the schema at
+func (m *Migrator) apply(ctx context.Context, conn *pgxpool.Conn, step schema.Step) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	// Rollback is a no-op once the transaction has been committed
+	defer tx.Rollback(ctx)
+
+	// Without arguments the statements go through the simple protocol, which runs several at once
+	if _, err := tx.Exec(ctx, step.Statements); err != nil {
+		return fmt.Errorf("migration %d_%s %s: %w", step.Migration.Version, step.Migration.Name, step.Direction, err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM schema_migrations`); err != nil {
+		return err
+	}
+	if step.Version != 0 {
+		if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, FALSE)`, step.Version); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	m.logger.Info("Applied migration", "version", step.Migration.Version, "name", step.Migration.Name, "direction", step.Direction)
+	return nil
+}
+
+// schemaVersion reads the applied version, a database never migrated is at version 0
+func schemaVersion(ctx context.Context, conn Querier) (uint, bool, error) {
+	var version int64
+	var dirty bool
+	err := conn.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.Is(err, pgx.ErrNoRows) || (errors.As(err, &pgErr) && pgErr.Code == undefinedTableCode) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	if version < 0 {
+		return 0, dirty, nil
+	}
+
+	return uint(version), dirty, nil
+}
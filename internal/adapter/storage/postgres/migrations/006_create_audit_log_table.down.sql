@@ -0,0 +1,10 @@
+-- Drop trigger and function first
+DROP TRIGGER IF EXISTS trg_audit_log_append_only ON audit_log;
+DROP FUNCTION IF EXISTS audit_log_prevent_mutation();
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_audit_log_created_at;
+DROP INDEX IF EXISTS idx_audit_log_entity;
+
+-- Drop the table
+DROP TABLE IF EXISTS audit_log;
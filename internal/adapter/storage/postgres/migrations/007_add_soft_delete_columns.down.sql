@@ -0,0 +1,22 @@
+-- Restore the original audit action constraint
+ALTER TABLE audit_log DROP CONSTRAINT IF EXISTS ck_audit_log_action;
+ALTER TABLE audit_log ADD CONSTRAINT ck_audit_log_action CHECK (action IN ('create', 'update', 'delete')) NOT VALID;
+
+-- Restore the original unique constraints
+DROP INDEX IF EXISTS uk_expense_subcategories_name_category_active;
+ALTER TABLE expense_subcategories ADD CONSTRAINT uk_expense_subcategories_name_category UNIQUE (name, expense_category_id);
+
+DROP INDEX IF EXISTS uk_expense_categories_name_active;
+ALTER TABLE expense_categories ADD CONSTRAINT expense_categories_name_key UNIQUE (name);
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_expense_subcategories_deleted_at;
+DROP INDEX IF EXISTS idx_expense_categories_deleted_at;
+DROP INDEX IF EXISTS idx_account_deleted_at;
+DROP INDEX IF EXISTS idx_expenses_deleted_at;
+
+-- Drop the columns, soft-deleted rows become visible again
+ALTER TABLE expense_subcategories DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE expense_categories DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE account DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE expenses DROP COLUMN IF EXISTS deleted_at;
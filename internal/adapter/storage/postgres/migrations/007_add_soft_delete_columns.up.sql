@@ -0,0 +1,24 @@
+-- Soft delete: rows are flagged with deleted_at instead of being removed
+ALTER TABLE expenses ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE account ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE expense_categories ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE expense_subcategories ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- Create partial indexes used by the purge job
+CREATE INDEX IF NOT EXISTS idx_expenses_deleted_at ON expenses(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_account_deleted_at ON account(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_expense_categories_deleted_at ON expense_categories(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_expense_subcategories_deleted_at ON expense_subcategories(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Names only have to be unique among rows that are not deleted
+ALTER TABLE expense_categories DROP CONSTRAINT IF EXISTS expense_categories_name_key;
+CREATE UNIQUE INDEX IF NOT EXISTS uk_expense_categories_name_active
+    ON expense_categories(name) WHERE deleted_at IS NULL;
+
+ALTER TABLE expense_subcategories DROP CONSTRAINT IF EXISTS uk_expense_subcategories_name_category;
+CREATE UNIQUE INDEX IF NOT EXISTS uk_expense_subcategories_name_category_active
+    ON expense_subcategories(name, expense_category_id) WHERE deleted_at IS NULL;
+
+-- Restores are recorded in the audit trail
+ALTER TABLE audit_log DROP CONSTRAINT IF EXISTS ck_audit_log_action;
+ALTER TABLE audit_log ADD CONSTRAINT ck_audit_log_action CHECK (action IN ('create', 'update', 'delete', 'restore'));
@@ -0,0 +1,5 @@
+ALTER TABLE expenses DROP COLUMN IF EXISTS version;
+ALTER TABLE expense_subcategories DROP COLUMN IF EXISTS version;
+ALTER TABLE expense_categories DROP COLUMN IF EXISTS version;
+ALTER TABLE account DROP COLUMN IF EXISTS version;
+ALTER TABLE person DROP COLUMN IF EXISTS version;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_expense_subcategories_created_at_id;
+DROP INDEX IF EXISTS idx_expense_categories_created_at_id;
+DROP INDEX IF EXISTS idx_expenses_date_created_at_id;
@@ -0,0 +1,4 @@
+-- Create indexes matching the list orderings used by cursor pagination
+CREATE INDEX IF NOT EXISTS idx_expenses_date_created_at_id ON expenses(date DESC, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_expense_categories_created_at_id ON expense_categories(created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_expense_subcategories_created_at_id ON expense_subcategories(created_at DESC, id DESC);
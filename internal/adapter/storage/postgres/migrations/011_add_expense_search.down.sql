@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_expenses_created_at_id;
+DROP INDEX IF EXISTS idx_expenses_amount_created_at_id;
+DROP INDEX IF EXISTS idx_expenses_notes_search;
+ALTER TABLE expenses DROP COLUMN IF EXISTS notes_search;
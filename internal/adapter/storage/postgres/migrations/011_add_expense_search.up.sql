@@ -0,0 +1,8 @@
+-- Full-text search over the notes of an expense, kept up to date by Postgres
+ALTER TABLE expenses ADD COLUMN IF NOT EXISTS notes_search TSVECTOR
+    GENERATED ALWAYS AS (to_tsvector('simple', COALESCE(notes, ''))) STORED;
+CREATE INDEX IF NOT EXISTS idx_expenses_notes_search ON expenses USING GIN (notes_search);
+
+-- Create indexes matching the other orderings expenses can be listed in
+CREATE INDEX IF NOT EXISTS idx_expenses_amount_created_at_id ON expenses(amount, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_expenses_created_at_id ON expenses(created_at, id);
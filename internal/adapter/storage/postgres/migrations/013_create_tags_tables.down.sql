@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_expense_tags_tag_id;
+DROP TABLE IF EXISTS expense_tags;
+DROP INDEX IF EXISTS uk_tags_name;
+DROP TABLE IF EXISTS tags;
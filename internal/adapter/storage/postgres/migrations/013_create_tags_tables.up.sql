@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS tags (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Tag names are unique regardless of case
+CREATE UNIQUE INDEX IF NOT EXISTS uk_tags_name ON tags(LOWER(name));
+
+CREATE TABLE IF NOT EXISTS expense_tags (
+    expense_id INTEGER NOT NULL,
+    tag_id INTEGER NOT NULL,
+
+    CONSTRAINT pk_expense_tags PRIMARY KEY (expense_id, tag_id),
+
+    -- Foreign key constraints, purging an expense or deleting a tag drops its links
+    CONSTRAINT fk_expense_tags_expense
+        FOREIGN KEY (expense_id)
+        REFERENCES expenses(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_expense_tags_tag
+        FOREIGN KEY (tag_id)
+        REFERENCES tags(id)
+        ON DELETE CASCADE
+);
+
+-- Create index on tag_id for filtering and summing expenses by tag
+CREATE INDEX IF NOT EXISTS idx_expense_tags_tag_id ON expense_tags(tag_id);
@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS expense_splits (
+    expense_id INTEGER NOT NULL,
+    line INTEGER NOT NULL,
+    category_id INTEGER NOT NULL,
+    subcategory_id INTEGER,
+    amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+    notes TEXT NOT NULL DEFAULT '',
+
+    CONSTRAINT pk_expense_splits PRIMARY KEY (expense_id, line),
+
+    -- Foreign key constraints, matching the ones of the expense itself
+    CONSTRAINT fk_expense_splits_expense
+        FOREIGN KEY (expense_id)
+        REFERENCES expenses(id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_expense_splits_category
+        FOREIGN KEY (category_id)
+        REFERENCES expense_categories(id)
+        ON DELETE RESTRICT,
+
+    CONSTRAINT fk_expense_splits_subcategory
+        FOREIGN KEY (subcategory_id)
+        REFERENCES expense_subcategories(id)
+        ON DELETE SET NULL
+);
+
+-- Create indexes for filtering split expenses by category
+CREATE INDEX IF NOT EXISTS idx_expense_splits_category_id ON expense_splits(category_id);
+CREATE INDEX IF NOT EXISTS idx_expense_splits_subcategory_id ON expense_splits(subcategory_id);
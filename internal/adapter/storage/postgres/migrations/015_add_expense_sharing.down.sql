@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_settlements_to_person_id;
+DROP INDEX IF EXISTS idx_settlements_from_person_id;
+DROP TABLE IF EXISTS settlements;
+DROP INDEX IF EXISTS idx_expense_shares_person_id;
+DROP TABLE IF EXISTS expense_shares;
+DROP INDEX IF EXISTS idx_expenses_paid_by_id;
+ALTER TABLE expenses DROP COLUMN IF EXISTS sharing_method;
+ALTER TABLE expenses DROP COLUMN IF EXISTS paid_by_id;
@@ -0,0 +1,53 @@
+-- Person who paid a shared expense and how its cost is divided, both NULL when the expense is not shared
+ALTER TABLE expenses ADD COLUMN IF NOT EXISTS paid_by_id INTEGER
+    CONSTRAINT fk_expenses_paid_by REFERENCES person(id) ON DELETE RESTRICT;
+ALTER TABLE expenses ADD COLUMN IF NOT EXISTS sharing_method VARCHAR(20);
+
+CREATE TABLE IF NOT EXISTS expense_shares (
+    expense_id INTEGER NOT NULL,
+    line INTEGER NOT NULL,
+    person_id INTEGER NOT NULL,
+    percentage NUMERIC(9,6),
+    amount DECIMAL(15,2) NOT NULL CHECK (amount >= 0),
+
+    CONSTRAINT pk_expense_shares PRIMARY KEY (expense_id, line),
+
+    -- Foreign key constraints, a person cannot be deleted while sharing an expense
+    CONSTRAINT fk_expense_shares_expense
+        FOREIGN KEY (expense_id)
+        REFERENCES expenses(id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_expense_shares_person
+        FOREIGN KEY (person_id)
+        REFERENCES person(id)
+        ON DELETE RESTRICT
+);
+
+CREATE TABLE IF NOT EXISTS settlements (
+    id SERIAL PRIMARY KEY,
+    from_person_id INTEGER NOT NULL,
+    to_person_id INTEGER NOT NULL,
+    amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+    date DATE NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_settlements_from_person
+        FOREIGN KEY (from_person_id)
+        REFERENCES person(id)
+        ON DELETE RESTRICT,
+
+    CONSTRAINT fk_settlements_to_person
+        FOREIGN KEY (to_person_id)
+        REFERENCES person(id)
+        ON DELETE RESTRICT
+);
+
+-- Create indexes for computing balances and listing the settlements of a person
+CREATE INDEX IF NOT EXISTS idx_expenses_paid_by_id ON expenses(paid_by_id);
+CREATE INDEX IF NOT EXISTS idx_expense_shares_person_id ON expense_shares(person_id);
+CREATE INDEX IF NOT EXISTS idx_settlements_from_person_id ON settlements(from_person_id);
+CREATE INDEX IF NOT EXISTS idx_settlements_to_person_id ON settlements(to_person_id);
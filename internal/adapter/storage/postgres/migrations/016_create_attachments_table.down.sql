@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_attachments_checksum;
+DROP TABLE IF EXISTS attachments;
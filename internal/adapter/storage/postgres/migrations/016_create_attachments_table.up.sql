@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS attachments (
+    id SERIAL PRIMARY KEY,
+    expense_id INTEGER NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL CHECK (size > 0),
+    checksum CHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    -- Foreign key constraints, purging an expense drops its attachments
+    CONSTRAINT fk_attachments_expense
+        FOREIGN KEY (expense_id)
+        REFERENCES expenses(id)
+        ON DELETE CASCADE,
+
+    -- The same file is only attached once to an expense
+    CONSTRAINT uk_attachments_expense_checksum UNIQUE (expense_id, checksum)
+);
+
+-- Create index on checksum for finding content shared between expenses
+CREATE INDEX IF NOT EXISTS idx_attachments_checksum ON attachments(checksum);
@@ -0,0 +1,9 @@
+-- Only payees linked to a person can be turned back into persons, expenses paid to other payees block the rollback
+ALTER TABLE expenses DROP CONSTRAINT IF EXISTS fk_expenses_payee;
+UPDATE expenses e SET payee_id = py.person_id FROM payees py WHERE py.id = e.payee_id AND py.person_id IS NOT NULL;
+ALTER TABLE expenses ADD CONSTRAINT fk_expenses_payee
+    FOREIGN KEY (payee_id) REFERENCES person(id) ON DELETE RESTRICT;
+
+DROP INDEX IF EXISTS idx_payees_person_id;
+DROP INDEX IF EXISTS uk_payees_name;
+DROP TABLE IF EXISTS payees;
@@ -0,0 +1,44 @@
+CREATE TABLE IF NOT EXISTS payees (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    aliases TEXT[] NOT NULL DEFAULT '{}',
+    default_category_id INTEGER,
+    default_subcategory_id INTEGER,
+    person_id INTEGER,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    -- Foreign key constraints, the defaults and the link to a person are optional
+    CONSTRAINT fk_payees_default_category
+        FOREIGN KEY (default_category_id)
+        REFERENCES expense_categories(id)
+        ON DELETE SET NULL,
+
+    CONSTRAINT fk_payees_default_subcategory
+        FOREIGN KEY (default_subcategory_id)
+        REFERENCES expense_subcategories(id)
+        ON DELETE SET NULL,
+
+    CONSTRAINT fk_payees_person
+        FOREIGN KEY (person_id)
+        REFERENCES person(id)
+        ON DELETE SET NULL
+);
+
+-- Every person used as a payee becomes a payee linked to them, telling apart persons sharing a name
+INSERT INTO payees (name, person_id)
+SELECT CASE WHEN COUNT(*) OVER (PARTITION BY LOWER(p.name)) > 1 THEN p.name || ' (' || p.id || ')' ELSE p.name END, p.id
+FROM person p
+WHERE EXISTS (SELECT 1 FROM expenses e WHERE e.payee_id = p.id)
+ORDER BY p.id;
+
+-- Point the expenses at the new payees
+ALTER TABLE expenses DROP CONSTRAINT IF EXISTS fk_expenses_payee;
+UPDATE expenses e SET payee_id = py.id FROM payees py WHERE py.person_id = e.payee_id;
+ALTER TABLE expenses ADD CONSTRAINT fk_expenses_payee
+    FOREIGN KEY (payee_id) REFERENCES payees(id) ON DELETE RESTRICT;
+
+-- Payee names are unique regardless of case
+CREATE UNIQUE INDEX IF NOT EXISTS uk_payees_name ON payees(LOWER(name));
+CREATE INDEX IF NOT EXISTS idx_payees_person_id ON payees(person_id);
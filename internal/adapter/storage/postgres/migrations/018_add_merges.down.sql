@@ -0,0 +1,8 @@
+ALTER TABLE audit_log DROP CONSTRAINT IF EXISTS ck_audit_log_action;
+ALTER TABLE audit_log ADD CONSTRAINT ck_audit_log_action CHECK (action IN ('create', 'update', 'delete', 'restore')) NOT VALID;
+
+DROP INDEX IF EXISTS idx_merges_entity_target;
+DROP TABLE IF EXISTS merges;
+
+ALTER TABLE expense_subcategories DROP COLUMN IF EXISTS aliases;
+ALTER TABLE expense_categories DROP COLUMN IF EXISTS aliases;
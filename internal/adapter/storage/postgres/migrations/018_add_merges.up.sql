@@ -0,0 +1,21 @@
+-- Names of merged categories and subcategories are kept as aliases for future matching
+ALTER TABLE expense_categories ADD COLUMN IF NOT EXISTS aliases TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE expense_subcategories ADD COLUMN IF NOT EXISTS aliases TEXT[] NOT NULL DEFAULT '{}';
+
+-- Every record merged into another one, the source keeps a snapshot of the merged record
+CREATE TABLE IF NOT EXISTS merges (
+    id BIGSERIAL PRIMARY KEY,
+    entity_type VARCHAR(50) NOT NULL,
+    source_id INTEGER NOT NULL,
+    target_id INTEGER NOT NULL,
+    source JSONB NOT NULL,
+    expense_count BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create index for browsing the merges into a single record
+CREATE INDEX IF NOT EXISTS idx_merges_entity_target ON merges(entity_type, target_id, id);
+
+-- Merges are recorded in the audit trail
+ALTER TABLE audit_log DROP CONSTRAINT IF EXISTS ck_audit_log_action;
+ALTER TABLE audit_log ADD CONSTRAINT ck_audit_log_action CHECK (action IN ('create', 'update', 'delete', 'restore', 'merge'));
@@ -2,34 +2,37 @@ package repository
 
 import (
 	"context"
-	"log/slog"
+	"fmt"
 	"time"
 
 	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/postgres"
 	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-type AccountRepository struct {
-	db *postgres.DB
+type accountRepository struct {
+	db *pgxpool.Pool
 }
 
-func NewAccountRepository(db *postgres.DB) *AccountRepository {
-	return &AccountRepository{
+// NewAccountRepository creates a new PostgreSQL account repository
+func NewAccountRepository(db *pgxpool.Pool) port.AccountRepository {
+	return &accountRepository{
 		db: db,
 	}
 }
 
 // CreateAccount inserts a new Account into the repository
-func (r *AccountRepository) CreateAccount(ctx context.Context, account *domain.Account) (*domain.Account, error) {
+func (r *accountRepository) CreateAccount(ctx context.Context, account *domain.Account) (*domain.Account, error) {
 	query := `
 		INSERT INTO account (name, currency, account_type, initial_balance, primary_owner_id, second_owner_id, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, created_at, updated_at
+		RETURNING id, version, created_at, updated_at
 	`
 
 	now := time.Now()
-	err := r.db.QueryRow(ctx, query,
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, query,
 		account.Name,
 		account.Currency,
 		account.AccountType,
@@ -38,7 +41,7 @@ func (r *AccountRepository) CreateAccount(ctx context.Context, account *domain.A
 		account.SecondOwnerID,
 		now,
 		now,
-	).Scan(&account.ID, &account.CreatedAt, &account.UpdatedAt)
+	).Scan(&account.ID, &account.Version, &account.CreatedAt, &account.UpdatedAt)
 
 	if err != nil {
 		return nil, err
@@ -48,15 +51,26 @@ func (r *AccountRepository) CreateAccount(ctx context.Context, account *domain.A
 }
 
 // GetAccountByID selects an Account by id
-func (r *AccountRepository) GetAccountByID(ctx context.Context, id uint64) (*domain.Account, error) {
-	query := `
-		SELECT id, name, currency, account_type, initial_balance, primary_owner_id, second_owner_id, created_at, updated_at
+func (r *accountRepository) GetAccountByID(ctx context.Context, id uint64) (*domain.Account, error) {
+	return r.getAccountByID(ctx, id, "")
+}
+
+// GetAccountByIDForShare selects an Account like GetAccountByID and locks its row against updates and deletes,
+// a soft delete included, until the transaction ends
+func (r *accountRepository) GetAccountByIDForShare(ctx context.Context, id uint64) (*domain.Account, error) {
+	return r.getAccountByID(ctx, id, "FOR SHARE")
+}
+
+// getAccountByID selects an Account by id, the query ending with the lock clause
+func (r *accountRepository) getAccountByID(ctx context.Context, id uint64, lock string) (*domain.Account, error) {
+	query := fmt.Sprintf(`
+		SELECT id, name, currency, account_type, initial_balance, primary_owner_id, second_owner_id, version, created_at, updated_at, deleted_at
 		FROM account
-		WHERE id = $1
-	`
+		WHERE id = $1 AND deleted_at IS NULL
+		%s`, lock)
 
 	account := &domain.Account{}
-	err := r.db.QueryRow(ctx, query, id).Scan(
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, query, id).Scan(
 		&account.ID,
 		&account.Name,
 		&account.Currency,
@@ -64,8 +78,10 @@ func (r *AccountRepository) GetAccountByID(ctx context.Context, id uint64) (*dom
 		&account.InitialBalance,
 		&account.PrimaryOwnerID,
 		&account.SecondOwnerID,
+		&account.Version,
 		&account.CreatedAt,
 		&account.UpdatedAt,
+		&account.DeletedAt,
 	)
 
 	if err != nil {
@@ -78,18 +94,20 @@ func (r *AccountRepository) GetAccountByID(ctx context.Context, id uint64) (*dom
 	return account, nil
 }
 
-// ListAccounts selects a list of Accounts with pagination
-func (r *AccountRepository) ListAccounts(ctx context.Context, skip, limit uint64) ([]domain.Account, error) {
-	slog.Info("Listing accounts repo", "skip", skip, "limit", limit)
+// ListAccounts selects up to limit Accounts ordered by id, starting after the cursor when given,
+// optionally including soft-deleted ones
+func (r *accountRepository) ListAccounts(ctx context.Context, after *domain.Cursor, limit int, includeDeleted bool) ([]domain.Account, error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Listing accounts repo", "after", after, "limit", limit, "include_deleted", includeDeleted)
 
 	query := `
-		SELECT id, name, currency, account_type, initial_balance, primary_owner_id, second_owner_id, created_at, updated_at
+		SELECT id, name, currency, account_type, initial_balance, primary_owner_id, second_owner_id, version, created_at, updated_at, deleted_at
 		FROM account
+		WHERE ($3 OR deleted_at IS NULL) AND ($2::bigint IS NULL OR id > $2)
 		ORDER BY id
-		LIMIT $1 OFFSET $2
+		LIMIT $1
 	`
 
-	rows, err := r.db.Query(ctx, query, limit, skip)
+	rows, err := postgres.Conn(ctx, r.db).Query(ctx, query, limit, cursorID(after), includeDeleted)
 	if err != nil {
 		return nil, err
 	}
@@ -106,8 +124,10 @@ func (r *AccountRepository) ListAccounts(ctx context.Context, skip, limit uint64
 			&account.InitialBalance,
 			&account.PrimaryOwnerID,
 			&account.SecondOwnerID,
+			&account.Version,
 			&account.CreatedAt,
 			&account.UpdatedAt,
+			&account.DeletedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -119,22 +139,30 @@ func (r *AccountRepository) ListAccounts(ctx context.Context, skip, limit uint64
 		return nil, err
 	}
 
-	slog.Info("Accounts found", "count", len(accounts))
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Accounts found", "count", len(accounts))
 	return accounts, nil
 }
 
-// UpdateAccount updates an Account
-func (r *AccountRepository) UpdateAccount(ctx context.Context, account *domain.Account) (*domain.Account, error) {
+// CountAccounts counts the Accounts, optionally including soft-deleted ones
+func (r *accountRepository) CountAccounts(ctx context.Context, includeDeleted bool) (int64, error) {
+	var count int64
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, `SELECT COUNT(*) FROM account WHERE $1 OR deleted_at IS NULL`, includeDeleted).Scan(&count)
+	return count, err
+}
+
+// UpdateAccount updates an Account whose version matches Account.Version, 0 skips the check
+func (r *accountRepository) UpdateAccount(ctx context.Context, account *domain.Account) (*domain.Account, error) {
 	query := `
 		UPDATE account
-		SET name = $1, currency = $2, account_type = $3, initial_balance = $4, primary_owner_id = $5, second_owner_id = $6, updated_at = $7
-		WHERE id = $8
-		RETURNING id, name, currency, account_type, initial_balance, primary_owner_id, second_owner_id, created_at, updated_at
+		SET name = $1, currency = $2, account_type = $3, initial_balance = $4, primary_owner_id = $5, second_owner_id = $6, updated_at = $7, version = version + 1
+		WHERE id = $8 AND deleted_at IS NULL AND ($9 = 0 OR version = $9)
+		RETURNING id, name, currency, account_type, initial_balance, primary_owner_id, second_owner_id, version, created_at, updated_at
 	`
 
 	now := time.Now()
 	updatedAccount := &domain.Account{}
-	err := r.db.QueryRow(ctx, query,
+	conn := postgres.Conn(ctx, r.db)
+	err := conn.QueryRow(ctx, query,
 		account.Name,
 		account.Currency,
 		account.AccountType,
@@ -143,6 +171,7 @@ func (r *AccountRepository) UpdateAccount(ctx context.Context, account *domain.A
 		account.SecondOwnerID,
 		now,
 		account.ID,
+		account.Version,
 	).Scan(
 		&updatedAccount.ID,
 		&updatedAccount.Name,
@@ -151,13 +180,14 @@ func (r *AccountRepository) UpdateAccount(ctx context.Context, account *domain.A
 		&updatedAccount.InitialBalance,
 		&updatedAccount.PrimaryOwnerID,
 		&updatedAccount.SecondOwnerID,
+		&updatedAccount.Version,
 		&updatedAccount.CreatedAt,
 		&updatedAccount.UpdatedAt,
 	)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, domain.ErrDataNotFound
+			return nil, staleOrMissing(ctx, conn, accountExistsQuery, account.ID)
 		}
 		return nil, err
 	}
@@ -165,11 +195,31 @@ func (r *AccountRepository) UpdateAccount(ctx context.Context, account *domain.A
 	return updatedAccount, nil
 }
 
-// DeleteAccount deletes an Account
-func (r *AccountRepository) DeleteAccount(ctx context.Context, id uint64) error {
-	query := `DELETE FROM account WHERE id = $1`
+// DeleteAccount soft deletes an Account whose version matches, 0 skips the check
+func (r *accountRepository) DeleteAccount(ctx context.Context, id uint64, version int) error {
+	query := `
+		UPDATE account SET deleted_at = NOW(), version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL AND ($2 = 0 OR version = $2)
+	`
 
-	commandTag, err := r.db.Exec(ctx, query, id)
+	conn := postgres.Conn(ctx, r.db)
+	commandTag, err := conn.Exec(ctx, query, id, version)
+	if err != nil {
+		return err
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return staleOrMissing(ctx, conn, accountExistsQuery, id)
+	}
+
+	return nil
+}
+
+// RestoreAccount restores a soft-deleted Account
+func (r *accountRepository) RestoreAccount(ctx context.Context, id uint64) error {
+	query := `UPDATE account SET deleted_at = NULL, updated_at = NOW(), version = version + 1 WHERE id = $1 AND deleted_at IS NOT NULL`
+
+	commandTag, err := postgres.Conn(ctx, r.db).Exec(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -180,3 +230,23 @@ func (r *AccountRepository) DeleteAccount(ctx context.Context, id uint64) error
 
 	return nil
 }
+
+// PurgeAccounts permanently removes Accounts soft deleted before the given time
+func (r *accountRepository) PurgeAccounts(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	// Accounts still referenced by expenses are kept until those are purged
+	query := `
+		DELETE FROM account a
+		WHERE a.deleted_at < $1
+			AND NOT EXISTS (SELECT 1 FROM expenses e WHERE e.account_id = a.id)
+	`
+
+	commandTag, err := postgres.Conn(ctx, r.db).Exec(ctx, query, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	return commandTag.RowsAffected(), nil
+}
+
+// accountExistsQuery checks whether an Account exists and is not soft deleted
+const accountExistsQuery = `SELECT EXISTS (SELECT 1 FROM account WHERE id = $1 AND deleted_at IS NULL)`
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/postgres"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type attachmentRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewAttachmentRepository creates a new PostgreSQL attachment repository
+func NewAttachmentRepository(db *pgxpool.Pool) port.AttachmentRepository {
+	return &attachmentRepository{
+		db: db,
+	}
+}
+
+func (r *attachmentRepository) Create(ctx context.Context, attachment *domain.Attachment) error {
+	query := `
+		INSERT INTO attachments (expense_id, file_name, content_type, size, checksum, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`
+
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, query,
+		attachment.ExpenseID,
+		attachment.FileName,
+		attachment.ContentType,
+		attachment.Size,
+		attachment.Checksum,
+		attachment.CreatedAt,
+	).Scan(&attachment.ID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *attachmentRepository) GetByID(ctx context.Context, id int) (*domain.Attachment, error) {
+	query := `
+		SELECT id, expense_id, file_name, content_type, size, checksum, created_at
+		FROM attachments
+		WHERE id = $1`
+
+	return r.get(ctx, query, id)
+}
+
+func (r *attachmentRepository) GetByChecksum(ctx context.Context, expenseID int, checksum string) (*domain.Attachment, error) {
+	query := `
+		SELECT id, expense_id, file_name, content_type, size, checksum, created_at
+		FROM attachments
+		WHERE expense_id = $1 AND checksum = $2`
+
+	return r.get(ctx, query, expenseID, checksum)
+}
+
+func (r *attachmentRepository) ListByExpense(ctx context.Context, expenseID int) ([]*domain.Attachment, error) {
+	query := `
+		SELECT id, expense_id, file_name, content_type, size, checksum, created_at
+		FROM attachments
+		WHERE expense_id = $1
+		ORDER BY id`
+
+	return r.list(ctx, query, expenseID)
+}
+
+func (r *attachmentRepository) CountByChecksum(ctx context.Context, checksum string) (int64, error) {
+	var count int64
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, `SELECT COUNT(*) FROM attachments WHERE checksum = $1`, checksum).Scan(&count)
+	return count, err
+}
+
+func (r *attachmentRepository) LockContent(ctx context.Context, checksum string) error {
+	// A lock on the checksum rather than on rows, the content may have no attachment yet
+	_, err := postgres.Conn(ctx, r.db).Exec(ctx, `SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`, checksum)
+	return err
+}
+
+func (r *attachmentRepository) Delete(ctx context.Context, id int) error {
+	cmdTag, err := postgres.Conn(ctx, r.db).Exec(ctx, `DELETE FROM attachments WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return domain.ErrDataNotFound
+	}
+
+	return nil
+}
+
+func (r *attachmentRepository) Purge(ctx context.Context, expensesDeletedBefore time.Time) ([]*domain.Attachment, error) {
+	query := `
+		DELETE FROM attachments
+		WHERE expense_id IN (SELECT id FROM expenses WHERE deleted_at < $1)
+		RETURNING id, expense_id, file_name, content_type, size, checksum, created_at`
+
+	return r.list(ctx, query, expensesDeletedBefore)
+}
+
+// list reads the attachments returned by a statement
+func (r *attachmentRepository) list(ctx context.Context, query string, args ...any) ([]*domain.Attachment, error) {
+	rows, err := postgres.Conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attachments []*domain.Attachment
+	for rows.Next() {
+		attachment := &domain.Attachment{}
+		err := rows.Scan(
+			&attachment.ID,
+			&attachment.ExpenseID,
+			&attachment.FileName,
+			&attachment.ContentType,
+			&attachment.Size,
+			&attachment.Checksum,
+			&attachment.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+// get reads a single attachment
+func (r *attachmentRepository) get(ctx context.Context, query string, args ...any) (*domain.Attachment, error) {
+	attachment := &domain.Attachment{}
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, query, args...).Scan(
+		&attachment.ID,
+		&attachment.ExpenseID,
+		&attachment.FileName,
+		&attachment.ContentType,
+		&attachment.Size,
+		&attachment.Checksum,
+		&attachment.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return attachment, nil
+}
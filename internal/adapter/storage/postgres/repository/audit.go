@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/postgres"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type auditRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewAuditRepository creates a new PostgreSQL audit repository
+func NewAuditRepository(db *pgxpool.Pool) port.AuditRepository {
+	return &auditRepository{
+		db: db,
+	}
+}
+
+func (r *auditRepository) Create(ctx context.Context, entry *domain.AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (entity_type, entity_id, action, actor, before, after, request_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8)
+		RETURNING id`
+
+	return postgres.Conn(ctx, r.db).QueryRow(ctx, query,
+		entry.EntityType,
+		entry.EntityID,
+		entry.Action,
+		entry.Actor,
+		entry.Before,
+		entry.After,
+		entry.RequestID,
+		entry.CreatedAt,
+	).Scan(&entry.ID)
+}
+
+func (r *auditRepository) List(ctx context.Context, after *domain.Cursor, limit int, filters port.AuditFilters) ([]*domain.AuditEntry, error) {
+	query := `
+		SELECT id, entity_type, entity_id, action, actor, before, after, COALESCE(request_id, ''), created_at
+		FROM audit_log
+		WHERE ($2::timestamptz IS NULL OR (created_at, id) < ($2, $3))
+			AND ($4::text IS NULL OR entity_type = $4) AND ($5::bigint IS NULL OR entity_id = $5)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $1`
+
+	createdAt, id := cursorCreatedAt(after)
+	rows, err := postgres.Conn(ctx, r.db).Query(ctx, query, limit, createdAt, id, filters.EntityType, filters.EntityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*domain.AuditEntry
+	for rows.Next() {
+		entry := &domain.AuditEntry{}
+		err := rows.Scan(
+			&entry.ID,
+			&entry.EntityType,
+			&entry.EntityID,
+			&entry.Action,
+			&entry.Actor,
+			&entry.Before,
+			&entry.After,
+			&entry.RequestID,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *auditRepository) Count(ctx context.Context, filters port.AuditFilters) (int64, error) {
+	query := `
+		SELECT COUNT(*) FROM audit_log
+		WHERE ($1::text IS NULL OR entity_type = $1) AND ($2::bigint IS NULL OR entity_id = $2)`
+
+	var count int64
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, query, filters.EntityType, filters.EntityID).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+)
+
+// cursorID returns the ID of a cursor as a query argument, nil for the first page
+func cursorID(after *domain.Cursor) *uint64 {
+	if after == nil {
+		return nil
+	}
+	return &after.ID
+}
+
+// cursorCreatedAt returns the creation time and ID of a cursor as query arguments, both nil for the first page
+func cursorCreatedAt(after *domain.Cursor) (*time.Time, *uint64) {
+	if after == nil || after.CreatedAt == nil {
+		return nil, nil
+	}
+	return after.CreatedAt, &after.ID
+}
@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/postgres"
 	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
 	"github.com/edwins-leonardi/finaid-api/internal/core/port"
 	"github.com/jackc/pgx/v5"
@@ -25,11 +27,14 @@ func NewExpenseRepository(db *pgxpool.Pool) port.ExpenseRepository {
 
 func (r *expenseRepository) Create(ctx context.Context, expense *domain.Expense) error {
 	query := `
-		INSERT INTO expenses (amount, category_id, subcategory_id, date, payee_id, account_id, notes, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-		RETURNING id`
-
-	err := r.db.QueryRow(ctx, query,
+		INSERT INTO expenses (amount, category_id, subcategory_id, date, payee_id, account_id, notes, paid_by_id, sharing_method,
+			created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, version`
+
+	paidByID, sharingMethod := expenseSharingColumns(expense.Sharing)
+	conn := postgres.Conn(ctx, r.db)
+	err := conn.QueryRow(ctx, query,
 		expense.Amount,
 		expense.CategoryID,
 		expense.SubCategoryID,
@@ -37,25 +42,35 @@ func (r *expenseRepository) Create(ctx context.Context, expense *domain.Expense)
 		expense.PayeeID,
 		expense.AccountID,
 		expense.Notes,
+		paidByID,
+		sharingMethod,
 		expense.CreatedAt,
 		expense.UpdatedAt,
-	).Scan(&expense.ID)
+	).Scan(&expense.ID, &expense.Version)
 
 	if err != nil {
 		return err
 	}
 
-	return nil
+	if err := setExpenseTags(ctx, conn, expense.ID, expense.TagIDs); err != nil {
+		return err
+	}
+	if err := setExpenseSplits(ctx, conn, expense.ID, expense.Splits); err != nil {
+		return err
+	}
+	return setExpenseShares(ctx, conn, expense.ID, expense.Sharing)
 }
 
 func (r *expenseRepository) GetByID(ctx context.Context, id int) (*domain.Expense, error) {
 	query := `
-		SELECT id, amount, category_id, subcategory_id, date, payee_id, account_id, notes, created_at, updated_at
+		SELECT id, amount, category_id, subcategory_id, date, payee_id, account_id, notes, ` + expenseTagIDsColumn + `, ` + expenseSplitsColumn + `,
+			` + expenseSharingColumn + `,
+			version, created_at, updated_at, deleted_at
 		FROM expenses
-		WHERE id = $1`
+		WHERE id = $1 AND deleted_at IS NULL`
 
 	expense := &domain.Expense{}
-	err := r.db.QueryRow(ctx, query, id).Scan(
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, query, id).Scan(
 		&expense.ID,
 		&expense.Amount,
 		&expense.CategoryID,
@@ -64,8 +79,13 @@ func (r *expenseRepository) GetByID(ctx context.Context, id int) (*domain.Expens
 		&expense.PayeeID,
 		&expense.AccountID,
 		&expense.Notes,
+		&expense.TagIDs,
+		&expense.Splits,
+		&expense.Sharing,
+		&expense.Version,
 		&expense.CreatedAt,
 		&expense.UpdatedAt,
+		&expense.DeletedAt,
 	)
 
 	if err != nil {
@@ -79,65 +99,56 @@ func (r *expenseRepository) GetByID(ctx context.Context, id int) (*domain.Expens
 }
 
 func (r *expenseRepository) List(ctx context.Context, filters port.ExpenseFilters) ([]*domain.Expense, error) {
-	// Build dynamic query based on filters
-	var conditions []string
-	var args []interface{}
-	argIndex := 1
-
-	baseQuery := `
-		SELECT id, amount, category_id, subcategory_id, date, payee_id, account_id, notes, created_at, updated_at
-		FROM expenses`
-
-	// Add WHERE conditions based on filters
-	if filters.CategoryID != nil {
-		conditions = append(conditions, fmt.Sprintf("category_id = $%d", argIndex))
-		args = append(args, *filters.CategoryID)
-		argIndex++
+	conditions, args, _ := expenseConditions(filters)
+	argIndex := len(args) + 1
+
+	// Order by the sort field, then creation time and ID in the same direction
+	column, columnType := "date", "date"
+	var sortValue any
+	if filters.After != nil {
+		sortValue = filters.After.Date
 	}
-
-	if filters.SubCategoryID != nil {
-		conditions = append(conditions, fmt.Sprintf("subcategory_id = $%d", argIndex))
-		args = append(args, *filters.SubCategoryID)
-		argIndex++
+	switch filters.Sort.Field {
+	case domain.ExpenseSortAmount:
+		column, columnType = "amount", "numeric"
+		if filters.After != nil {
+			sortValue = filters.After.Amount
+		}
+	case domain.ExpenseSortCreatedAt:
+		column, columnType = "created_at", "timestamptz"
+		if filters.After != nil {
+			sortValue = filters.After.CreatedAt
+		}
 	}
-
-	if filters.PayeeID != nil {
-		conditions = append(conditions, fmt.Sprintf("payee_id = $%d", argIndex))
-		args = append(args, *filters.PayeeID)
-		argIndex++
+	direction, comparison := "ASC", ">"
+	if filters.Sort.Descending {
+		direction, comparison = "DESC", "<"
 	}
 
-	if filters.AccountID != nil {
-		conditions = append(conditions, fmt.Sprintf("account_id = $%d", argIndex))
-		args = append(args, *filters.AccountID)
-		argIndex++
+	// Continue after the last expense of the previous page
+	if filters.After != nil && filters.After.CreatedAt != nil {
+		conditions = append(conditions, fmt.Sprintf("(%s, created_at, id) %s ($%d::%s, $%d::timestamptz, $%d::integer)",
+			column, comparison, argIndex, columnType, argIndex+1, argIndex+2))
+		args = append(args, sortValue, *filters.After.CreatedAt, filters.After.ID)
+		argIndex += 3
 	}
 
-	if filters.StartDate != nil {
-		conditions = append(conditions, fmt.Sprintf("date >= $%d", argIndex))
-		args = append(args, *filters.StartDate)
-		argIndex++
-	}
-
-	if filters.EndDate != nil {
-		conditions = append(conditions, fmt.Sprintf("date <= $%d", argIndex))
-		args = append(args, *filters.EndDate)
-		argIndex++
-	}
-
-	// Build final query
-	query := baseQuery
+	query := `
+		SELECT id, amount, category_id, subcategory_id, date, payee_id, account_id, notes, ` + expenseTagIDsColumn + `, ` + expenseSplitsColumn + `,
+			` + expenseSharingColumn + `,
+			version, created_at, updated_at, deleted_at
+		FROM expenses`
 	if len(conditions) > 0 {
 		query += " WHERE " + strings.Join(conditions, " AND ")
 	}
 
-	query += " ORDER BY date DESC, created_at DESC"
+	query += fmt.Sprintf(" ORDER BY %[1]s %[2]s, created_at %[2]s, id %[2]s", column, direction)
 
 	// Add pagination
-	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
-	args = append(args, filters.Limit, filters.Skip)
+	query += fmt.Sprintf(" LIMIT $%d", argIndex)
+	args = append(args, filters.Limit)
 
-	rows, err := r.db.Query(ctx, query, args...)
+	rows, err := postgres.Conn(ctx, r.db).Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -155,8 +166,13 @@ func (r *expenseRepository) List(ctx context.Context, filters port.ExpenseFilter
 			&expense.PayeeID,
 			&expense.AccountID,
 			&expense.Notes,
+			&expense.TagIDs,
+			&expense.Splits,
+			&expense.Sharing,
+			&expense.Version,
 			&expense.CreatedAt,
 			&expense.UpdatedAt,
+			&expense.DeletedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -171,13 +187,185 @@ func (r *expenseRepository) List(ctx context.Context, filters port.ExpenseFilter
 	return expenses, nil
 }
 
+func (r *expenseRepository) Count(ctx context.Context, filters port.ExpenseFilters) (int64, error) {
+	conditions, args, _ := expenseConditions(filters)
+
+	query := `SELECT COUNT(*) FROM expenses`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var count int64
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, query, args...).Scan(&count)
+	return count, err
+}
+
+func (r *expenseRepository) TagTotals(ctx context.Context, filters port.ExpenseFilters) ([]domain.TagTotal, error) {
+	conditions, args, splitCondition := expenseConditions(filters)
+
+	// Only the matching lines of a split expense count when filtering by category
+	amount := "amount"
+	if splitCondition != "" {
+		amount = `CASE WHEN EXISTS (SELECT 1 FROM expense_splits s WHERE s.expense_id = expenses.id)
+			THEN (SELECT SUM(s.amount) FROM expense_splits s WHERE s.expense_id = expenses.id AND ` + splitCondition + `)
+			ELSE amount END`
+	}
+
+	query := `
+		SELECT et.tag_id, COUNT(*), COALESCE(SUM(e.amount), 0)
+		FROM expense_tags et
+		JOIN (SELECT id, ` + amount + ` AS amount FROM expenses`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += `) e ON e.id = et.expense_id
+		GROUP BY et.tag_id`
+
+	rows, err := postgres.Conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []domain.TagTotal
+	for rows.Next() {
+		var total domain.TagTotal
+		if err := rows.Scan(&total.TagID, &total.ExpenseCount, &total.TotalAmount); err != nil {
+			return nil, err
+		}
+		totals = append(totals, total)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+// expenseConditions builds the WHERE conditions and arguments of the filters, without pagination.
+// It also returns the category conditions applied to the split lines "s", empty without category filters.
+func expenseConditions(filters port.ExpenseFilters) ([]string, []interface{}, string) {
+	var conditions []string
+	var args []interface{}
+	argIndex := 1
+
+	// Exclude soft-deleted expenses unless explicitly requested
+	if !filters.IncludeDeleted {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+
+	// Add WHERE conditions based on filters
+	categoryIndex, subCategoryIndex := 0, 0
+	if len(filters.CategoryIDs) > 0 {
+		categoryIndex = argIndex
+		args = append(args, filters.CategoryIDs)
+		argIndex++
+	}
+
+	if filters.SubCategoryID != nil {
+		subCategoryIndex = argIndex
+		args = append(args, *filters.SubCategoryID)
+		argIndex++
+	}
+
+	// Category conditions of an expense or of one of its split lines, whose columns have the same names
+	categoryConditions := func(prefix string) string {
+		var conditions []string
+		if categoryIndex > 0 {
+			conditions = append(conditions, fmt.Sprintf("%scategory_id = ANY($%d)", prefix, categoryIndex))
+		}
+		if subCategoryIndex > 0 {
+			conditions = append(conditions, fmt.Sprintf("%ssubcategory_id = $%d", prefix, subCategoryIndex))
+		}
+		if filters.HasSubCategory != nil {
+			if *filters.HasSubCategory {
+				conditions = append(conditions, prefix+"subcategory_id IS NOT NULL")
+			} else {
+				conditions = append(conditions, prefix+"subcategory_id IS NULL")
+			}
+		}
+		return strings.Join(conditions, " AND ")
+	}
+
+	// A split expense matches when one of its lines does
+	splitCondition := categoryConditions("s.")
+	if splitCondition != "" {
+		conditions = append(conditions, fmt.Sprintf(`CASE WHEN EXISTS (SELECT 1 FROM expense_splits s WHERE s.expense_id = expenses.id)
+			THEN EXISTS (SELECT 1 FROM expense_splits s WHERE s.expense_id = expenses.id AND %s)
+			ELSE %s END`, splitCondition, categoryConditions("")))
+	}
+
+	if len(filters.PayeeIDs) > 0 {
+		conditions = append(conditions, fmt.Sprintf("payee_id = ANY($%d)", argIndex))
+		args = append(args, filters.PayeeIDs)
+		argIndex++
+	}
+
+	if len(filters.AccountIDs) > 0 {
+		conditions = append(conditions, fmt.Sprintf("account_id = ANY($%d)", argIndex))
+		args = append(args, filters.AccountIDs)
+		argIndex++
+	}
+
+	if filters.MinAmount != nil {
+		conditions = append(conditions, fmt.Sprintf("amount >= $%d", argIndex))
+		args = append(args, *filters.MinAmount)
+		argIndex++
+	}
+
+	if filters.MaxAmount != nil {
+		conditions = append(conditions, fmt.Sprintf("amount <= $%d", argIndex))
+		args = append(args, *filters.MaxAmount)
+		argIndex++
+	}
+
+	// Every word of the search must appear in the notes, using the GIN index
+	if filters.NotesQuery != "" {
+		conditions = append(conditions, fmt.Sprintf("notes_search @@ plainto_tsquery('simple', $%d)", argIndex))
+		args = append(args, filters.NotesQuery)
+		argIndex++
+	}
+
+	// Tags of the expense, any of them or all of them
+	if len(filters.TagIDs) > 0 {
+		if filters.MatchAllTags {
+			conditions = append(conditions, fmt.Sprintf(
+				"(SELECT COUNT(*) FROM expense_tags et WHERE et.expense_id = expenses.id AND et.tag_id = ANY($%[1]d::integer[])) = cardinality($%[1]d::integer[])",
+				argIndex))
+		} else {
+			conditions = append(conditions, fmt.Sprintf(
+				"EXISTS (SELECT 1 FROM expense_tags et WHERE et.expense_id = expenses.id AND et.tag_id = ANY($%d))", argIndex))
+		}
+		args = append(args, filters.TagIDs)
+		argIndex++
+	}
+
+	if filters.StartDate != nil {
+		conditions = append(conditions, fmt.Sprintf("date >= $%d", argIndex))
+		args = append(args, *filters.StartDate)
+		argIndex++
+	}
+
+	if filters.EndDate != nil {
+		conditions = append(conditions, fmt.Sprintf("date <= $%d", argIndex))
+		args = append(args, *filters.EndDate)
+	}
+
+	return conditions, args, splitCondition
+}
+
 func (r *expenseRepository) Update(ctx context.Context, expense *domain.Expense) error {
 	query := `
 		UPDATE expenses
-		SET amount = $2, category_id = $3, subcategory_id = $4, date = $5, payee_id = $6, account_id = $7, notes = $8, updated_at = $9
-		WHERE id = $1`
-
-	cmdTag, err := r.db.Exec(ctx, query,
+		SET amount = $2, category_id = $3, subcategory_id = $4, date = $5, payee_id = $6, account_id = $7, notes = $8,
+			paid_by_id = $9, sharing_method = $10, updated_at = $11, version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL AND ($12 = 0 OR version = $12)
+		RETURNING version`
+
+	paidByID, sharingMethod := expenseSharingColumns(expense.Sharing)
+	conn := postgres.Conn(ctx, r.db)
+	err := conn.QueryRow(ctx, query,
 		expense.ID,
 		expense.Amount,
 		expense.CategoryID,
@@ -186,23 +374,49 @@ func (r *expenseRepository) Update(ctx context.Context, expense *domain.Expense)
 		expense.PayeeID,
 		expense.AccountID,
 		expense.Notes,
+		paidByID,
+		sharingMethod,
 		expense.UpdatedAt,
-	)
+		expense.Version,
+	).Scan(&expense.Version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return staleOrMissing(ctx, conn, expenseExistsQuery, expense.ID)
+		}
+		return err
+	}
+
+	if err := setExpenseTags(ctx, conn, expense.ID, expense.TagIDs); err != nil {
+		return err
+	}
+	if err := setExpenseSplits(ctx, conn, expense.ID, expense.Splits); err != nil {
+		return err
+	}
+	return setExpenseShares(ctx, conn, expense.ID, expense.Sharing)
+}
+
+func (r *expenseRepository) Delete(ctx context.Context, id int, version int) error {
+	query := `
+		UPDATE expenses SET deleted_at = NOW(), version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL AND ($2 = 0 OR version = $2)`
+
+	conn := postgres.Conn(ctx, r.db)
+	cmdTag, err := conn.Exec(ctx, query, id, version)
 	if err != nil {
 		return err
 	}
 
 	if cmdTag.RowsAffected() == 0 {
-		return domain.ErrDataNotFound
+		return staleOrMissing(ctx, conn, expenseExistsQuery, id)
 	}
 
 	return nil
 }
 
-func (r *expenseRepository) Delete(ctx context.Context, id int) error {
-	query := `DELETE FROM expenses WHERE id = $1`
+func (r *expenseRepository) Restore(ctx context.Context, id int) error {
+	query := `UPDATE expenses SET deleted_at = NULL, updated_at = NOW(), version = version + 1 WHERE id = $1 AND deleted_at IS NOT NULL`
 
-	cmdTag, err := r.db.Exec(ctx, query, id)
+	cmdTag, err := postgres.Conn(ctx, r.db).Exec(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -213,3 +427,215 @@ func (r *expenseRepository) Delete(ctx context.Context, id int) error {
 
 	return nil
 }
+
+func (r *expenseRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	query := `DELETE FROM expenses WHERE deleted_at < $1`
+
+	cmdTag, err := postgres.Conn(ctx, r.db).Exec(ctx, query, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	return cmdTag.RowsAffected(), nil
+}
+
+func (r *expenseRepository) RemoveTag(ctx context.Context, tagID int) error {
+	// The tags are part of the expense, so removing one makes a new version of it
+	query := `
+		WITH removed AS (
+			DELETE FROM expense_tags WHERE tag_id = $1 RETURNING expense_id
+		)
+		UPDATE expenses SET updated_at = NOW(), version = version + 1
+		WHERE id IN (SELECT expense_id FROM removed)`
+
+	_, err := postgres.Conn(ctx, r.db).Exec(ctx, query, tagID)
+	return err
+}
+
+func (r *expenseRepository) ReassignPayee(ctx context.Context, fromID, toID int) (int64, error) {
+	query := `UPDATE expenses SET payee_id = $2, updated_at = NOW(), version = version + 1 WHERE payee_id = $1`
+
+	cmdTag, err := postgres.Conn(ctx, r.db).Exec(ctx, query, fromID, toID)
+	if err != nil {
+		return 0, err
+	}
+
+	return cmdTag.RowsAffected(), nil
+}
+
+func (r *expenseRepository) ReassignCategory(ctx context.Context, fromID, toID int) (int64, error) {
+	// The split lines are part of the expense, so moving one makes a new version of it
+	query := `
+		WITH moved AS (
+			UPDATE expense_splits SET category_id = $2 WHERE category_id = $1 RETURNING expense_id
+		)
+		UPDATE expenses
+		SET category_id = CASE WHEN category_id = $1 THEN $2 ELSE category_id END, updated_at = NOW(), version = version + 1
+		WHERE category_id = $1 OR id IN (SELECT expense_id FROM moved)`
+
+	cmdTag, err := postgres.Conn(ctx, r.db).Exec(ctx, query, fromID, toID)
+	if err != nil {
+		return 0, err
+	}
+
+	return cmdTag.RowsAffected(), nil
+}
+
+func (r *expenseRepository) ReassignSubCategory(ctx context.Context, fromID, toID, toCategoryID int) (int64, error) {
+	// The split lines are part of the expense, so moving one makes a new version of it
+	query := `
+		WITH moved AS (
+			UPDATE expense_splits SET subcategory_id = $2, category_id = $3 WHERE subcategory_id = $1 RETURNING expense_id
+		)
+		UPDATE expenses
+		SET category_id = CASE WHEN subcategory_id = $1 THEN $3 ELSE category_id END,
+			subcategory_id = CASE WHEN subcategory_id = $1 THEN $2 ELSE subcategory_id END,
+			updated_at = NOW(), version = version + 1
+		WHERE subcategory_id = $1 OR id IN (SELECT expense_id FROM moved)`
+
+	cmdTag, err := postgres.Conn(ctx, r.db).Exec(ctx, query, fromID, toID, toCategoryID)
+	if err != nil {
+		return 0, err
+	}
+
+	return cmdTag.RowsAffected(), nil
+}
+
+func (r *expenseRepository) ShareDebts(ctx context.Context) ([]domain.PersonDebt, error) {
+	// The payer does not owe their own share
+	query := `
+		SELECT sh.person_id, e.paid_by_id, SUM(sh.amount)
+		FROM expense_shares sh
+		JOIN expenses e ON e.id = sh.expense_id
+		WHERE e.deleted_at IS NULL AND sh.person_id <> e.paid_by_id
+		GROUP BY sh.person_id, e.paid_by_id
+		ORDER BY sh.person_id, e.paid_by_id`
+
+	rows, err := postgres.Conn(ctx, r.db).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var debts []domain.PersonDebt
+	for rows.Next() {
+		var debt domain.PersonDebt
+		if err := rows.Scan(&debt.FromPersonID, &debt.ToPersonID, &debt.Amount); err != nil {
+			return nil, err
+		}
+		debts = append(debts, debt)
+	}
+
+	return debts, rows.Err()
+}
+
+// setExpenseTags replaces the tags of an expense, the caller runs it in the same transaction as the expense change
+func setExpenseTags(ctx context.Context, q postgres.Querier, expenseID int, tagIDs []int) error {
+	if _, err := q.Exec(ctx, `DELETE FROM expense_tags WHERE expense_id = $1`, expenseID); err != nil {
+		return err
+	}
+	if len(tagIDs) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO expense_tags (expense_id, tag_id)
+		SELECT $1, UNNEST($2::integer[])`
+
+	_, err := q.Exec(ctx, query, expenseID, tagIDs)
+	return err
+}
+
+// setExpenseSplits replaces the split lines of an expense, the caller runs it in the same transaction as the expense change
+func setExpenseSplits(ctx context.Context, q postgres.Querier, expenseID int, splits []domain.ExpenseSplit) error {
+	if _, err := q.Exec(ctx, `DELETE FROM expense_splits WHERE expense_id = $1`, expenseID); err != nil {
+		return err
+	}
+	if len(splits) == 0 {
+		return nil
+	}
+
+	lines := make([]int, len(splits))
+	categoryIDs := make([]int, len(splits))
+	subCategoryIDs := make([]*int, len(splits))
+	amounts := make([]float64, len(splits))
+	notes := make([]string, len(splits))
+	for i, split := range splits {
+		lines[i] = i + 1
+		categoryIDs[i] = split.CategoryID
+		subCategoryIDs[i] = split.SubCategoryID
+		amounts[i] = split.Amount
+		notes[i] = split.Notes
+	}
+
+	query := `
+		INSERT INTO expense_splits (expense_id, line, category_id, subcategory_id, amount, notes)
+		SELECT $1, l.line, l.category_id, l.subcategory_id, l.amount, l.notes
+		FROM UNNEST($2::integer[], $3::integer[], $4::integer[], $5::numeric[], $6::text[])
+			AS l(line, category_id, subcategory_id, amount, notes)`
+
+	_, err := q.Exec(ctx, query, expenseID, lines, categoryIDs, subCategoryIDs, amounts, notes)
+	return err
+}
+
+// setExpenseShares replaces the shares of an expense, the caller runs it in the same transaction as the expense change
+func setExpenseShares(ctx context.Context, q postgres.Querier, expenseID int, sharing *domain.ExpenseSharing) error {
+	if _, err := q.Exec(ctx, `DELETE FROM expense_shares WHERE expense_id = $1`, expenseID); err != nil {
+		return err
+	}
+	if sharing == nil || len(sharing.Shares) == 0 {
+		return nil
+	}
+
+	lines := make([]int, len(sharing.Shares))
+	personIDs := make([]int, len(sharing.Shares))
+	percentages := make([]*float64, len(sharing.Shares))
+	amounts := make([]float64, len(sharing.Shares))
+	for i, share := range sharing.Shares {
+		lines[i] = i + 1
+		personIDs[i] = share.PersonID
+		if sharing.Method == domain.SharingMethodPercentage {
+			percentages[i] = &sharing.Shares[i].Percentage
+		}
+		amounts[i] = share.Amount
+	}
+
+	query := `
+		INSERT INTO expense_shares (expense_id, line, person_id, percentage, amount)
+		SELECT $1, l.line, l.person_id, l.percentage, l.amount
+		FROM UNNEST($2::integer[], $3::integer[], $4::numeric[], $5::numeric[])
+			AS l(line, person_id, percentage, amount)`
+
+	_, err := q.Exec(ctx, query, expenseID, lines, personIDs, percentages, amounts)
+	return err
+}
+
+// expenseSharingColumns returns the payer and sharing method columns of an expense, NULL when it is not shared
+func expenseSharingColumns(sharing *domain.ExpenseSharing) (*int, *string) {
+	if sharing == nil {
+		return nil, nil
+	}
+	return &sharing.PaidByID, &sharing.Method
+}
+
+// expenseSharingColumn selects the sharing of each expense as a JSON object, NULL when it is not shared
+const expenseSharingColumn = `CASE WHEN paid_by_id IS NULL THEN NULL ELSE json_build_object(
+			'paid_by_id', paid_by_id, 'method', sharing_method, 'shares', COALESCE((
+				SELECT json_agg(json_build_object(
+					'person_id', sh.person_id, 'percentage', sh.percentage, 'amount', sh.amount
+				) ORDER BY sh.line)
+				FROM expense_shares sh WHERE sh.expense_id = expenses.id), '[]')
+		) END`
+
+// expenseSplitsColumn selects the split lines of each expense as a JSON array, in their original order
+const expenseSplitsColumn = `COALESCE((
+			SELECT json_agg(json_build_object(
+				'category_id', s.category_id, 'subcategory_id', s.subcategory_id, 'amount', s.amount, 'notes', s.notes
+			) ORDER BY s.line)
+			FROM expense_splits s WHERE s.expense_id = expenses.id), '[]')`
+
+// expenseTagIDsColumn selects the sorted tag IDs of each expense as an array
+const expenseTagIDsColumn = `ARRAY(SELECT et.tag_id FROM expense_tags et WHERE et.expense_id = expenses.id ORDER BY et.tag_id)`
+
+// expenseExistsQuery checks whether an expense exists and is not soft deleted
+const expenseExistsQuery = `SELECT EXISTS (SELECT 1 FROM expenses WHERE id = $1 AND deleted_at IS NULL)`
@@ -3,7 +3,10 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/postgres"
 	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
 	"github.com/edwins-leonardi/finaid-api/internal/core/port"
 	"github.com/jackc/pgx/v5"
@@ -23,11 +26,11 @@ func NewExpenseCategoryRepository(db *pgxpool.Pool) port.ExpenseCategoryReposito
 
 func (r *expenseCategoryRepository) Create(ctx context.Context, category *domain.ExpenseCategory) error {
 	query := `
-		INSERT INTO expense_categories (name, created_at, updated_at)
-		VALUES ($1, $2, $3)
-		RETURNING id`
+		INSERT INTO expense_categories (name, aliases, created_at, updated_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, version`
 
-	err := r.db.QueryRow(ctx, query, category.Name, category.CreatedAt, category.UpdatedAt).Scan(&category.ID)
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, query, category.Name, category.Aliases, category.CreatedAt, category.UpdatedAt).Scan(&category.ID, &category.Version)
 	if err != nil {
 		return err
 	}
@@ -36,17 +39,32 @@ func (r *expenseCategoryRepository) Create(ctx context.Context, category *domain
 }
 
 func (r *expenseCategoryRepository) GetByID(ctx context.Context, id int) (*domain.ExpenseCategory, error) {
-	query := `
-		SELECT id, name, created_at, updated_at
+	return r.getByID(ctx, id, "")
+}
+
+// GetByIDForShare selects a category like GetByID and locks its row against updates and deletes,
+// a soft delete included, until the transaction ends
+func (r *expenseCategoryRepository) GetByIDForShare(ctx context.Context, id int) (*domain.ExpenseCategory, error) {
+	return r.getByID(ctx, id, "FOR SHARE")
+}
+
+// getByID selects a category by id, the query ending with the lock clause
+func (r *expenseCategoryRepository) getByID(ctx context.Context, id int, lock string) (*domain.ExpenseCategory, error) {
+	query := fmt.Sprintf(`
+		SELECT id, name, aliases, version, created_at, updated_at, deleted_at
 		FROM expense_categories
-		WHERE id = $1`
+		WHERE id = $1 AND deleted_at IS NULL
+		%s`, lock)
 
 	category := &domain.ExpenseCategory{}
-	err := r.db.QueryRow(ctx, query, id).Scan(
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, query, id).Scan(
 		&category.ID,
 		&category.Name,
+		&category.Aliases,
+		&category.Version,
 		&category.CreatedAt,
 		&category.UpdatedAt,
+		&category.DeletedAt,
 	)
 
 	if err != nil {
@@ -59,14 +77,17 @@ func (r *expenseCategoryRepository) GetByID(ctx context.Context, id int) (*domai
 	return category, nil
 }
 
-func (r *expenseCategoryRepository) List(ctx context.Context, skip, limit int) ([]*domain.ExpenseCategory, error) {
+func (r *expenseCategoryRepository) List(ctx context.Context, after *domain.Cursor, limit int, includeDeleted bool) ([]*domain.ExpenseCategory, error) {
 	query := `
-		SELECT id, name, created_at, updated_at
+		SELECT id, name, aliases, version, created_at, updated_at, deleted_at
 		FROM expense_categories
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2`
+		WHERE ($2 OR deleted_at IS NULL)
+			AND ($3::timestamptz IS NULL OR (created_at, id) < ($3, $4))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $1`
 
-	rows, err := r.db.Query(ctx, query, limit, skip)
+	createdAt, id := cursorCreatedAt(after)
+	rows, err := postgres.Conn(ctx, r.db).Query(ctx, query, limit, includeDeleted, createdAt, id)
 	if err != nil {
 		return nil, err
 	}
@@ -78,8 +99,11 @@ func (r *expenseCategoryRepository) List(ctx context.Context, skip, limit int) (
 		err := rows.Scan(
 			&category.ID,
 			&category.Name,
+			&category.Aliases,
+			&category.Version,
 			&category.CreatedAt,
 			&category.UpdatedAt,
+			&category.DeletedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -94,28 +118,55 @@ func (r *expenseCategoryRepository) List(ctx context.Context, skip, limit int) (
 	return categories, nil
 }
 
+func (r *expenseCategoryRepository) Count(ctx context.Context, includeDeleted bool) (int64, error) {
+	query := `SELECT COUNT(*) FROM expense_categories WHERE $1 OR deleted_at IS NULL`
+
+	var count int64
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, query, includeDeleted).Scan(&count)
+	return count, err
+}
+
 func (r *expenseCategoryRepository) Update(ctx context.Context, category *domain.ExpenseCategory) error {
 	query := `
 		UPDATE expense_categories
-		SET name = $2, updated_at = $3
-		WHERE id = $1`
+		SET name = $2, aliases = $3, updated_at = $4, version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL AND ($5 = 0 OR version = $5)
+		RETURNING version`
+
+	conn := postgres.Conn(ctx, r.db)
+	err := conn.QueryRow(ctx, query, category.ID, category.Name, category.Aliases, category.UpdatedAt, category.Version).Scan(&category.Version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return staleOrMissing(ctx, conn, expenseCategoryExistsQuery, category.ID)
+		}
+		return err
+	}
 
-	cmdTag, err := r.db.Exec(ctx, query, category.ID, category.Name, category.UpdatedAt)
+	return nil
+}
+
+func (r *expenseCategoryRepository) Delete(ctx context.Context, id int, version int) error {
+	query := `
+		UPDATE expense_categories SET deleted_at = NOW(), version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL AND ($2 = 0 OR version = $2)`
+
+	conn := postgres.Conn(ctx, r.db)
+	cmdTag, err := conn.Exec(ctx, query, id, version)
 	if err != nil {
 		return err
 	}
 
 	if cmdTag.RowsAffected() == 0 {
-		return domain.ErrDataNotFound
+		return staleOrMissing(ctx, conn, expenseCategoryExistsQuery, id)
 	}
 
 	return nil
 }
 
-func (r *expenseCategoryRepository) Delete(ctx context.Context, id int) error {
-	query := `DELETE FROM expense_categories WHERE id = $1`
+func (r *expenseCategoryRepository) Restore(ctx context.Context, id int) error {
+	query := `UPDATE expense_categories SET deleted_at = NULL, updated_at = NOW(), version = version + 1 WHERE id = $1 AND deleted_at IS NOT NULL`
 
-	cmdTag, err := r.db.Exec(ctx, query, id)
+	cmdTag, err := postgres.Conn(ctx, r.db).Exec(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -126,3 +177,23 @@ func (r *expenseCategoryRepository) Delete(ctx context.Context, id int) error {
 
 	return nil
 }
+
+func (r *expenseCategoryRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	// Categories still referenced by expenses, split lines or subcategories are kept until those are purged
+	query := `
+		DELETE FROM expense_categories c
+		WHERE c.deleted_at < $1
+			AND NOT EXISTS (SELECT 1 FROM expenses e WHERE e.category_id = c.id)
+			AND NOT EXISTS (SELECT 1 FROM expense_splits es WHERE es.category_id = c.id)
+			AND NOT EXISTS (SELECT 1 FROM expense_subcategories s WHERE s.expense_category_id = c.id)`
+
+	cmdTag, err := postgres.Conn(ctx, r.db).Exec(ctx, query, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	return cmdTag.RowsAffected(), nil
+}
+
+// expenseCategoryExistsQuery checks whether an expense category exists and is not soft deleted
+const expenseCategoryExistsQuery = `SELECT EXISTS (SELECT 1 FROM expense_categories WHERE id = $1 AND deleted_at IS NULL)`
@@ -3,7 +3,10 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/postgres"
 	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
 	"github.com/edwins-leonardi/finaid-api/internal/core/port"
 	"github.com/jackc/pgx/v5"
@@ -23,11 +26,11 @@ func NewExpenseSubCategoryRepository(db *pgxpool.Pool) port.ExpenseSubCategoryRe
 
 func (r *expenseSubCategoryRepository) Create(ctx context.Context, subcategory *domain.ExpenseSubCategory) error {
 	query := `
-		INSERT INTO expense_subcategories (name, expense_category_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id`
+		INSERT INTO expense_subcategories (name, aliases, expense_category_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, version`
 
-	err := r.db.QueryRow(ctx, query, subcategory.Name, subcategory.ExpenseCategoryID, subcategory.CreatedAt, subcategory.UpdatedAt).Scan(&subcategory.ID)
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, query, subcategory.Name, subcategory.Aliases, subcategory.ExpenseCategoryID, subcategory.CreatedAt, subcategory.UpdatedAt).Scan(&subcategory.ID, &subcategory.Version)
 	if err != nil {
 		return err
 	}
@@ -36,18 +39,33 @@ func (r *expenseSubCategoryRepository) Create(ctx context.Context, subcategory *
 }
 
 func (r *expenseSubCategoryRepository) GetByID(ctx context.Context, id int) (*domain.ExpenseSubCategory, error) {
-	query := `
-		SELECT id, name, expense_category_id, created_at, updated_at
+	return r.getByID(ctx, id, "")
+}
+
+// GetByIDForShare selects a subcategory like GetByID and locks its row against updates and deletes,
+// a soft delete included, until the transaction ends
+func (r *expenseSubCategoryRepository) GetByIDForShare(ctx context.Context, id int) (*domain.ExpenseSubCategory, error) {
+	return r.getByID(ctx, id, "FOR SHARE")
+}
+
+// getByID selects a subcategory by id, the query ending with the lock clause
+func (r *expenseSubCategoryRepository) getByID(ctx context.Context, id int, lock string) (*domain.ExpenseSubCategory, error) {
+	query := fmt.Sprintf(`
+		SELECT id, name, aliases, expense_category_id, version, created_at, updated_at, deleted_at
 		FROM expense_subcategories
-		WHERE id = $1`
+		WHERE id = $1 AND deleted_at IS NULL
+		%s`, lock)
 
 	subcategory := &domain.ExpenseSubCategory{}
-	err := r.db.QueryRow(ctx, query, id).Scan(
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, query, id).Scan(
 		&subcategory.ID,
 		&subcategory.Name,
+		&subcategory.Aliases,
 		&subcategory.ExpenseCategoryID,
+		&subcategory.Version,
 		&subcategory.CreatedAt,
 		&subcategory.UpdatedAt,
+		&subcategory.DeletedAt,
 	)
 
 	if err != nil {
@@ -60,28 +78,17 @@ func (r *expenseSubCategoryRepository) GetByID(ctx context.Context, id int) (*do
 	return subcategory, nil
 }
 
-func (r *expenseSubCategoryRepository) List(ctx context.Context, skip, limit int, expenseCategoryID *int) ([]*domain.ExpenseSubCategory, error) {
-	var query string
-	var args []interface{}
-
-	if expenseCategoryID != nil {
-		query = `
-			SELECT id, name, expense_category_id, created_at, updated_at
-			FROM expense_subcategories
-			WHERE expense_category_id = $1
-			ORDER BY created_at DESC
-			LIMIT $2 OFFSET $3`
-		args = []interface{}{*expenseCategoryID, limit, skip}
-	} else {
-		query = `
-			SELECT id, name, expense_category_id, created_at, updated_at
-			FROM expense_subcategories
-			ORDER BY created_at DESC
-			LIMIT $1 OFFSET $2`
-		args = []interface{}{limit, skip}
-	}
+func (r *expenseSubCategoryRepository) List(ctx context.Context, after *domain.Cursor, limit int, expenseCategoryID *int, includeDeleted bool) ([]*domain.ExpenseSubCategory, error) {
+	query := `
+		SELECT id, name, aliases, expense_category_id, version, created_at, updated_at, deleted_at
+		FROM expense_subcategories
+		WHERE ($2::integer IS NULL OR expense_category_id = $2) AND ($3 OR deleted_at IS NULL)
+			AND ($4::timestamptz IS NULL OR (created_at, id) < ($4, $5))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $1`
 
-	rows, err := r.db.Query(ctx, query, args...)
+	createdAt, id := cursorCreatedAt(after)
+	rows, err := postgres.Conn(ctx, r.db).Query(ctx, query, limit, expenseCategoryID, includeDeleted, createdAt, id)
 	if err != nil {
 		return nil, err
 	}
@@ -93,9 +100,12 @@ func (r *expenseSubCategoryRepository) List(ctx context.Context, skip, limit int
 		err := rows.Scan(
 			&subcategory.ID,
 			&subcategory.Name,
+			&subcategory.Aliases,
 			&subcategory.ExpenseCategoryID,
+			&subcategory.Version,
 			&subcategory.CreatedAt,
 			&subcategory.UpdatedAt,
+			&subcategory.DeletedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -110,28 +120,57 @@ func (r *expenseSubCategoryRepository) List(ctx context.Context, skip, limit int
 	return subcategories, nil
 }
 
+func (r *expenseSubCategoryRepository) Count(ctx context.Context, expenseCategoryID *int, includeDeleted bool) (int64, error) {
+	query := `
+		SELECT COUNT(*) FROM expense_subcategories
+		WHERE ($1::integer IS NULL OR expense_category_id = $1) AND ($2 OR deleted_at IS NULL)`
+
+	var count int64
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, query, expenseCategoryID, includeDeleted).Scan(&count)
+	return count, err
+}
+
 func (r *expenseSubCategoryRepository) Update(ctx context.Context, subcategory *domain.ExpenseSubCategory) error {
 	query := `
 		UPDATE expense_subcategories
-		SET name = $2, expense_category_id = $3, updated_at = $4
-		WHERE id = $1`
+		SET name = $2, aliases = $3, expense_category_id = $4, updated_at = $5, version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL AND ($6 = 0 OR version = $6)
+		RETURNING version`
 
-	cmdTag, err := r.db.Exec(ctx, query, subcategory.ID, subcategory.Name, subcategory.ExpenseCategoryID, subcategory.UpdatedAt)
+	conn := postgres.Conn(ctx, r.db)
+	err := conn.QueryRow(ctx, query, subcategory.ID, subcategory.Name, subcategory.Aliases, subcategory.ExpenseCategoryID, subcategory.UpdatedAt, subcategory.Version).Scan(&subcategory.Version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return staleOrMissing(ctx, conn, expenseSubCategoryExistsQuery, subcategory.ID)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *expenseSubCategoryRepository) Delete(ctx context.Context, id int, version int) error {
+	query := `
+		UPDATE expense_subcategories SET deleted_at = NOW(), version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL AND ($2 = 0 OR version = $2)`
+
+	conn := postgres.Conn(ctx, r.db)
+	cmdTag, err := conn.Exec(ctx, query, id, version)
 	if err != nil {
 		return err
 	}
 
 	if cmdTag.RowsAffected() == 0 {
-		return domain.ErrDataNotFound
+		return staleOrMissing(ctx, conn, expenseSubCategoryExistsQuery, id)
 	}
 
 	return nil
 }
 
-func (r *expenseSubCategoryRepository) Delete(ctx context.Context, id int) error {
-	query := `DELETE FROM expense_subcategories WHERE id = $1`
+func (r *expenseSubCategoryRepository) Restore(ctx context.Context, id int) error {
+	query := `UPDATE expense_subcategories SET deleted_at = NULL, updated_at = NOW(), version = version + 1 WHERE id = $1 AND deleted_at IS NOT NULL`
 
-	cmdTag, err := r.db.Exec(ctx, query, id)
+	cmdTag, err := postgres.Conn(ctx, r.db).Exec(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -142,3 +181,18 @@ func (r *expenseSubCategoryRepository) Delete(ctx context.Context, id int) error
 
 	return nil
 }
+
+func (r *expenseSubCategoryRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	// Purged subcategories are detached from remaining expenses by the ON DELETE SET NULL constraint
+	query := `DELETE FROM expense_subcategories WHERE deleted_at < $1`
+
+	cmdTag, err := postgres.Conn(ctx, r.db).Exec(ctx, query, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	return cmdTag.RowsAffected(), nil
+}
+
+// expenseSubCategoryExistsQuery checks whether an expense subcategory exists and is not soft deleted
+const expenseSubCategoryExistsQuery = `SELECT EXISTS (SELECT 1 FROM expense_subcategories WHERE id = $1 AND deleted_at IS NULL)`
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/postgres"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type mergeRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewMergeRepository creates a new PostgreSQL merge repository
+func NewMergeRepository(db *pgxpool.Pool) port.MergeRepository {
+	return &mergeRepository{
+		db: db,
+	}
+}
+
+func (r *mergeRepository) Create(ctx context.Context, merge *domain.Merge) error {
+	query := `
+		INSERT INTO merges (entity_type, source_id, target_id, source, expense_count, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`
+
+	return postgres.Conn(ctx, r.db).QueryRow(ctx, query,
+		merge.EntityType,
+		merge.SourceID,
+		merge.TargetID,
+		merge.Source,
+		merge.ExpenseCount,
+		merge.CreatedAt,
+	).Scan(&merge.ID)
+}
+
+func (r *mergeRepository) List(ctx context.Context, after *domain.Cursor, limit int, filters port.MergeFilters) ([]*domain.Merge, error) {
+	query := `
+		SELECT id, entity_type, source_id, target_id, source, expense_count, created_at
+		FROM merges
+		WHERE ($2::bigint IS NULL OR id > $2) AND ($3::text IS NULL OR entity_type = $3) AND ($4::integer IS NULL OR target_id = $4)
+		ORDER BY id
+		LIMIT $1`
+
+	rows, err := postgres.Conn(ctx, r.db).Query(ctx, query, limit, cursorID(after), filters.EntityType, filters.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var merges []*domain.Merge
+	for rows.Next() {
+		merge := &domain.Merge{}
+		err := rows.Scan(
+			&merge.ID,
+			&merge.EntityType,
+			&merge.SourceID,
+			&merge.TargetID,
+			&merge.Source,
+			&merge.ExpenseCount,
+			&merge.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		merges = append(merges, merge)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return merges, nil
+}
+
+func (r *mergeRepository) Count(ctx context.Context, filters port.MergeFilters) (int64, error) {
+	query := `
+		SELECT COUNT(*) FROM merges
+		WHERE ($1::text IS NULL OR entity_type = $1) AND ($2::integer IS NULL OR target_id = $2)`
+
+	var count int64
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, query, filters.EntityType, filters.TargetID).Scan(&count)
+	return count, err
+}
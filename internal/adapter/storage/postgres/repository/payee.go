@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/postgres"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type payeeRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPayeeRepository creates a new PostgreSQL payee repository
+func NewPayeeRepository(db *pgxpool.Pool) port.PayeeRepository {
+	return &payeeRepository{
+		db: db,
+	}
+}
+
+func (r *payeeRepository) Create(ctx context.Context, payee *domain.Payee) error {
+	query := `
+		INSERT INTO payees (name, aliases, default_category_id, default_subcategory_id, person_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, version`
+
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, query,
+		payee.Name,
+		payee.Aliases,
+		payee.DefaultCategoryID,
+		payee.DefaultSubCategoryID,
+		payee.PersonID,
+		payee.CreatedAt,
+		payee.UpdatedAt,
+	).Scan(&payee.ID, &payee.Version)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *payeeRepository) GetByID(ctx context.Context, id int) (*domain.Payee, error) {
+	query := `
+		SELECT id, name, aliases, default_category_id, default_subcategory_id, person_id, version, created_at, updated_at
+		FROM payees
+		WHERE id = $1`
+
+	payee, err := scanPayee(postgres.Conn(ctx, r.db).QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return payee, nil
+}
+
+func (r *payeeRepository) GetByName(ctx context.Context, name string) (*domain.Payee, error) {
+	query := `
+		SELECT id, name, aliases, default_category_id, default_subcategory_id, person_id, version, created_at, updated_at
+		FROM payees
+		WHERE LOWER(name) = LOWER($1)`
+
+	payee, err := scanPayee(postgres.Conn(ctx, r.db).QueryRow(ctx, query, name))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return payee, nil
+}
+
+func (r *payeeRepository) List(ctx context.Context, after *domain.Cursor, limit int, search string) ([]*domain.Payee, error) {
+	query := `
+		SELECT id, name, aliases, default_category_id, default_subcategory_id, person_id, version, created_at, updated_at
+		FROM payees
+		WHERE ($2::bigint IS NULL OR id > $2) AND ` + payeeSearchCondition("$3") + `
+		ORDER BY id
+		LIMIT $1`
+
+	rows, err := postgres.Conn(ctx, r.db).Query(ctx, query, limit, cursorID(after), payeeSearchPattern(search))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payees []*domain.Payee
+	for rows.Next() {
+		payee, err := scanPayee(rows)
+		if err != nil {
+			return nil, err
+		}
+		payees = append(payees, payee)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return payees, nil
+}
+
+func (r *payeeRepository) Count(ctx context.Context, search string) (int64, error) {
+	query := `SELECT COUNT(*) FROM payees WHERE ` + payeeSearchCondition("$1")
+
+	var count int64
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, query, payeeSearchPattern(search)).Scan(&count)
+	return count, err
+}
+
+func (r *payeeRepository) Update(ctx context.Context, payee *domain.Payee) error {
+	query := `
+		UPDATE payees
+		SET name = $2, aliases = $3, default_category_id = $4, default_subcategory_id = $5, person_id = $6,
+			updated_at = $7, version = version + 1
+		WHERE id = $1 AND ($8 = 0 OR version = $8)
+		RETURNING version`
+
+	conn := postgres.Conn(ctx, r.db)
+	err := conn.QueryRow(ctx, query,
+		payee.ID,
+		payee.Name,
+		payee.Aliases,
+		payee.DefaultCategoryID,
+		payee.DefaultSubCategoryID,
+		payee.PersonID,
+		payee.UpdatedAt,
+		payee.Version,
+	).Scan(&payee.Version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return staleOrMissing(ctx, conn, payeeExistsQuery, payee.ID)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *payeeRepository) Delete(ctx context.Context, id int, version int) error {
+	query := `DELETE FROM payees WHERE id = $1 AND ($2 = 0 OR version = $2)`
+
+	conn := postgres.Conn(ctx, r.db)
+	cmdTag, err := conn.Exec(ctx, query, id, version)
+	if err != nil {
+		return err
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return staleOrMissing(ctx, conn, payeeExistsQuery, id)
+	}
+
+	return nil
+}
+
+func (r *payeeRepository) ReassignDefaultCategory(ctx context.Context, fromID, toID int) error {
+	query := `
+		UPDATE payees SET default_category_id = $2, updated_at = NOW(), version = version + 1
+		WHERE default_category_id = $1`
+
+	_, err := postgres.Conn(ctx, r.db).Exec(ctx, query, fromID, toID)
+	return err
+}
+
+func (r *payeeRepository) ReassignDefaultSubCategory(ctx context.Context, fromID, toID, toCategoryID int) error {
+	query := `
+		UPDATE payees SET default_subcategory_id = $2, default_category_id = $3, updated_at = NOW(), version = version + 1
+		WHERE default_subcategory_id = $1`
+
+	_, err := postgres.Conn(ctx, r.db).Exec(ctx, query, fromID, toID, toCategoryID)
+	return err
+}
+
+// payeeExistsQuery checks whether a payee exists
+const payeeExistsQuery = `SELECT EXISTS (SELECT 1 FROM payees WHERE id = $1)`
+
+// payeeSearchCondition matches payees whose name or an alias matches the pattern parameter, an empty pattern matches every payee
+func payeeSearchCondition(param string) string {
+	return `(` + param + ` = '' OR name ILIKE ` + param + ` OR EXISTS (SELECT 1 FROM unnest(aliases) AS alias WHERE alias ILIKE ` + param + `))`
+}
+
+// payeeSearchPattern turns a search text into a LIKE pattern matching it anywhere, escaping the wildcards it contains
+func payeeSearchPattern(search string) string {
+	if search == "" {
+		return ""
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(search)
+	return "%" + escaped + "%"
+}
+
+// scanPayee reads a payee from a row, aliases never come back nil
+func scanPayee(row pgx.Row) (*domain.Payee, error) {
+	payee := &domain.Payee{}
+	err := row.Scan(
+		&payee.ID,
+		&payee.Name,
+		&payee.Aliases,
+		&payee.DefaultCategoryID,
+		&payee.DefaultSubCategoryID,
+		&payee.PersonID,
+		&payee.Version,
+		&payee.CreatedAt,
+		&payee.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if payee.Aliases == nil {
+		payee.Aliases = []string{}
+	}
+	return payee, nil
+}
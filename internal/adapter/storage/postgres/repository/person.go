@@ -2,39 +2,42 @@ package repository
 
 import (
 	"context"
-	"log/slog"
+	"fmt"
 	"time"
 
 	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/postgres"
 	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-type PersonRepository struct {
-	db *postgres.DB
+type personRepository struct {
+	db *pgxpool.Pool
 }
 
-func NewPersonRepository(db *postgres.DB) *PersonRepository {
-	return &PersonRepository{
+// NewPersonRepository creates a new PostgreSQL person repository
+func NewPersonRepository(db *pgxpool.Pool) port.PersonRepository {
+	return &personRepository{
 		db: db,
 	}
 }
 
 // CreatePerson inserts a new Person into the repository
-func (r *PersonRepository) CreatePerson(ctx context.Context, person *domain.Person) (*domain.Person, error) {
+func (r *personRepository) CreatePerson(ctx context.Context, person *domain.Person) (*domain.Person, error) {
 	query := `
 		INSERT INTO person (name, email, created_at, updated_at)
 		VALUES ($1, $2, $3, $4)
-		RETURNING id, created_at, updated_at
+		RETURNING id, version, created_at, updated_at
 	`
 
 	now := time.Now()
-	err := r.db.QueryRow(ctx, query,
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, query,
 		person.Name,
 		person.Email,
 		now,
 		now,
-	).Scan(&person.ID, &person.CreatedAt, &person.UpdatedAt)
+	).Scan(&person.ID, &person.Version, &person.CreatedAt, &person.UpdatedAt)
 
 	if err != nil {
 		return nil, err
@@ -44,18 +47,30 @@ func (r *PersonRepository) CreatePerson(ctx context.Context, person *domain.Pers
 }
 
 // GetPersonByID selects a Person by id
-func (r *PersonRepository) GetPersonByID(ctx context.Context, id uint64) (*domain.Person, error) {
-	query := `
-		SELECT id, name, email, created_at, updated_at
+func (r *personRepository) GetPersonByID(ctx context.Context, id uint64) (*domain.Person, error) {
+	return r.getPersonByID(ctx, id, "")
+}
+
+// GetPersonByIDForShare selects a Person like GetPersonByID and locks its row against updates and deletes
+// until the transaction ends
+func (r *personRepository) GetPersonByIDForShare(ctx context.Context, id uint64) (*domain.Person, error) {
+	return r.getPersonByID(ctx, id, "FOR SHARE")
+}
+
+// getPersonByID selects a Person by id, the query ending with the lock clause
+func (r *personRepository) getPersonByID(ctx context.Context, id uint64, lock string) (*domain.Person, error) {
+	query := fmt.Sprintf(`
+		SELECT id, name, email, version, created_at, updated_at
 		FROM person
 		WHERE id = $1
-	`
+		%s`, lock)
 
 	person := &domain.Person{}
-	err := r.db.QueryRow(ctx, query, id).Scan(
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, query, id).Scan(
 		&person.ID,
 		&person.Name,
 		&person.Email,
+		&person.Version,
 		&person.CreatedAt,
 		&person.UpdatedAt,
 	)
@@ -71,18 +86,19 @@ func (r *PersonRepository) GetPersonByID(ctx context.Context, id uint64) (*domai
 }
 
 // GetPersonByEmail selects a Person by email
-func (r *PersonRepository) GetPersonByEmail(ctx context.Context, email string) (*domain.Person, error) {
+func (r *personRepository) GetPersonByEmail(ctx context.Context, email string) (*domain.Person, error) {
 	query := `
-		SELECT id, name, email, created_at, updated_at
+		SELECT id, name, email, version, created_at, updated_at
 		FROM person
 		WHERE email = $1
 	`
 
 	person := &domain.Person{}
-	err := r.db.QueryRow(ctx, query, email).Scan(
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, query, email).Scan(
 		&person.ID,
 		&person.Name,
 		&person.Email,
+		&person.Version,
 		&person.CreatedAt,
 		&person.UpdatedAt,
 	)
@@ -97,18 +113,19 @@ func (r *PersonRepository) GetPersonByEmail(ctx context.Context, email string) (
 	return person, nil
 }
 
-// ListPersons selects a list of Persons with pagination
-func (r *PersonRepository) ListPersons(ctx context.Context, skip, limit uint64) ([]domain.Person, error) {
-	slog.Info("Listing persons repo", "skip", skip, "limit", limit)
+// ListPersons selects up to limit Persons ordered by id, starting after the cursor when given
+func (r *personRepository) ListPersons(ctx context.Context, after *domain.Cursor, limit int) ([]domain.Person, error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Listing persons repo", "after", after, "limit", limit)
 
 	query := `
-		SELECT id, name, email, created_at, updated_at
+		SELECT id, name, email, version, created_at, updated_at
 		FROM person
+		WHERE $2::bigint IS NULL OR id > $2
 		ORDER BY id
-		LIMIT $1 OFFSET $2
+		LIMIT $1
 	`
 
-	rows, err := r.db.Query(ctx, query, limit, skip)
+	rows, err := postgres.Conn(ctx, r.db).Query(ctx, query, limit, cursorID(after))
 	if err != nil {
 		return nil, err
 	}
@@ -121,6 +138,7 @@ func (r *PersonRepository) ListPersons(ctx context.Context, skip, limit uint64)
 			&person.ID,
 			&person.Name,
 			&person.Email,
+			&person.Version,
 			&person.CreatedAt,
 			&person.UpdatedAt,
 		)
@@ -134,37 +152,47 @@ func (r *PersonRepository) ListPersons(ctx context.Context, skip, limit uint64)
 		return nil, err
 	}
 
-	slog.Info("Persons found", "count", len(persons))
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Persons found", "count", len(persons))
 	return persons, nil
 }
 
-// UpdatePerson updates a Person
-func (r *PersonRepository) UpdatePerson(ctx context.Context, person *domain.Person) (*domain.Person, error) {
+// CountPersons counts every Person
+func (r *personRepository) CountPersons(ctx context.Context) (int64, error) {
+	var count int64
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, `SELECT COUNT(*) FROM person`).Scan(&count)
+	return count, err
+}
+
+// UpdatePerson updates a Person whose version matches Person.Version, 0 skips the check
+func (r *personRepository) UpdatePerson(ctx context.Context, person *domain.Person) (*domain.Person, error) {
 	query := `
 		UPDATE person
-		SET name = $1, email = $2, updated_at = $3
-		WHERE id = $4
-		RETURNING id, name, email, created_at, updated_at
+		SET name = $1, email = $2, updated_at = $3, version = version + 1
+		WHERE id = $4 AND ($5 = 0 OR version = $5)
+		RETURNING id, name, email, version, created_at, updated_at
 	`
 
 	now := time.Now()
 	updatedPerson := &domain.Person{}
-	err := r.db.QueryRow(ctx, query,
+	conn := postgres.Conn(ctx, r.db)
+	err := conn.QueryRow(ctx, query,
 		person.Name,
 		person.Email,
 		now,
 		person.ID,
+		person.Version,
 	).Scan(
 		&updatedPerson.ID,
 		&updatedPerson.Name,
 		&updatedPerson.Email,
+		&updatedPerson.Version,
 		&updatedPerson.CreatedAt,
 		&updatedPerson.UpdatedAt,
 	)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, domain.ErrDataNotFound
+			return nil, staleOrMissing(ctx, conn, personExistsQuery, person.ID)
 		}
 		return nil, err
 	}
@@ -172,18 +200,22 @@ func (r *PersonRepository) UpdatePerson(ctx context.Context, person *domain.Pers
 	return updatedPerson, nil
 }
 
-// DeletePerson deletes a Person
-func (r *PersonRepository) DeletePerson(ctx context.Context, id uint64) error {
-	query := `DELETE FROM person WHERE id = $1`
+// DeletePerson deletes a Person whose version matches, 0 skips the check
+func (r *personRepository) DeletePerson(ctx context.Context, id uint64, version int) error {
+	query := `DELETE FROM person WHERE id = $1 AND ($2 = 0 OR version = $2)`
 
-	commandTag, err := r.db.Exec(ctx, query, id)
+	conn := postgres.Conn(ctx, r.db)
+	commandTag, err := conn.Exec(ctx, query, id, version)
 	if err != nil {
 		return err
 	}
 
 	if commandTag.RowsAffected() == 0 {
-		return domain.ErrDataNotFound
+		return staleOrMissing(ctx, conn, personExistsQuery, id)
 	}
 
 	return nil
 }
+
+// personExistsQuery checks whether a Person exists
+const personExistsQuery = `SELECT EXISTS (SELECT 1 FROM person WHERE id = $1)`
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/postgres"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type savedViewRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewSavedViewRepository creates a new PostgreSQL saved view repository
+func NewSavedViewRepository(db *pgxpool.Pool) port.SavedViewRepository {
+	return &savedViewRepository{
+		db: db,
+	}
+}
+
+func (r *savedViewRepository) Create(ctx context.Context, view *domain.SavedView) error {
+	query := `
+		INSERT INTO saved_views (name, filters, created_at, updated_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, version`
+
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, query, view.Name, view.Filters, view.CreatedAt, view.UpdatedAt).Scan(&view.ID, &view.Version)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *savedViewRepository) GetByID(ctx context.Context, id int) (*domain.SavedView, error) {
+	query := `
+		SELECT id, name, filters, version, created_at, updated_at
+		FROM saved_views
+		WHERE id = $1`
+
+	view := &domain.SavedView{}
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, query, id).Scan(
+		&view.ID,
+		&view.Name,
+		&view.Filters,
+		&view.Version,
+		&view.CreatedAt,
+		&view.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return view, nil
+}
+
+func (r *savedViewRepository) List(ctx context.Context, after *domain.Cursor, limit int) ([]*domain.SavedView, error) {
+	query := `
+		SELECT id, name, filters, version, created_at, updated_at
+		FROM saved_views
+		WHERE $2::bigint IS NULL OR id > $2
+		ORDER BY id
+		LIMIT $1`
+
+	rows, err := postgres.Conn(ctx, r.db).Query(ctx, query, limit, cursorID(after))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var views []*domain.SavedView
+	for rows.Next() {
+		view := &domain.SavedView{}
+		err := rows.Scan(
+			&view.ID,
+			&view.Name,
+			&view.Filters,
+			&view.Version,
+			&view.CreatedAt,
+			&view.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, view)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return views, nil
+}
+
+func (r *savedViewRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, `SELECT COUNT(*) FROM saved_views`).Scan(&count)
+	return count, err
+}
+
+func (r *savedViewRepository) Update(ctx context.Context, view *domain.SavedView) error {
+	query := `
+		UPDATE saved_views
+		SET name = $2, filters = $3, updated_at = $4, version = version + 1
+		WHERE id = $1 AND ($5 = 0 OR version = $5)
+		RETURNING version`
+
+	conn := postgres.Conn(ctx, r.db)
+	err := conn.QueryRow(ctx, query, view.ID, view.Name, view.Filters, view.UpdatedAt, view.Version).Scan(&view.Version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return staleOrMissing(ctx, conn, savedViewExistsQuery, view.ID)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *savedViewRepository) Delete(ctx context.Context, id int, version int) error {
+	query := `DELETE FROM saved_views WHERE id = $1 AND ($2 = 0 OR version = $2)`
+
+	conn := postgres.Conn(ctx, r.db)
+	cmdTag, err := conn.Exec(ctx, query, id, version)
+	if err != nil {
+		return err
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return staleOrMissing(ctx, conn, savedViewExistsQuery, id)
+	}
+
+	return nil
+}
+
+// savedViewExistsQuery checks whether a saved view exists
+const savedViewExistsQuery = `SELECT EXISTS (SELECT 1 FROM saved_views WHERE id = $1)`
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/postgres"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type settlementRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewSettlementRepository creates a new PostgreSQL settlement repository
+func NewSettlementRepository(db *pgxpool.Pool) port.SettlementRepository {
+	return &settlementRepository{
+		db: db,
+	}
+}
+
+func (r *settlementRepository) Create(ctx context.Context, settlement *domain.Settlement) error {
+	query := `
+		INSERT INTO settlements (from_person_id, to_person_id, amount, date, notes, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, version`
+
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, query,
+		settlement.FromPersonID,
+		settlement.ToPersonID,
+		settlement.Amount,
+		settlement.Date,
+		settlement.Notes,
+		settlement.CreatedAt,
+		settlement.UpdatedAt,
+	).Scan(&settlement.ID, &settlement.Version)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *settlementRepository) GetByID(ctx context.Context, id int) (*domain.Settlement, error) {
+	query := `
+		SELECT id, from_person_id, to_person_id, amount, date, notes, version, created_at, updated_at
+		FROM settlements
+		WHERE id = $1`
+
+	settlement := &domain.Settlement{}
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, query, id).Scan(
+		&settlement.ID,
+		&settlement.FromPersonID,
+		&settlement.ToPersonID,
+		&settlement.Amount,
+		&settlement.Date,
+		&settlement.Notes,
+		&settlement.Version,
+		&settlement.CreatedAt,
+		&settlement.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return settlement, nil
+}
+
+func (r *settlementRepository) List(ctx context.Context, after *domain.Cursor, limit int, personID int) ([]*domain.Settlement, error) {
+	query := `
+		SELECT id, from_person_id, to_person_id, amount, date, notes, version, created_at, updated_at
+		FROM settlements
+		WHERE ($2::bigint IS NULL OR id > $2)
+			AND ($3 = 0 OR from_person_id = $3 OR to_person_id = $3)
+		ORDER BY id
+		LIMIT $1`
+
+	rows, err := postgres.Conn(ctx, r.db).Query(ctx, query, limit, cursorID(after), personID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var settlements []*domain.Settlement
+	for rows.Next() {
+		settlement := &domain.Settlement{}
+		err := rows.Scan(
+			&settlement.ID,
+			&settlement.FromPersonID,
+			&settlement.ToPersonID,
+			&settlement.Amount,
+			&settlement.Date,
+			&settlement.Notes,
+			&settlement.Version,
+			&settlement.CreatedAt,
+			&settlement.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		settlements = append(settlements, settlement)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return settlements, nil
+}
+
+func (r *settlementRepository) Count(ctx context.Context, personID int) (int64, error) {
+	query := `SELECT COUNT(*) FROM settlements WHERE $1 = 0 OR from_person_id = $1 OR to_person_id = $1`
+
+	var count int64
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, query, personID).Scan(&count)
+	return count, err
+}
+
+func (r *settlementRepository) Delete(ctx context.Context, id int, version int) error {
+	query := `DELETE FROM settlements WHERE id = $1 AND ($2 = 0 OR version = $2)`
+
+	conn := postgres.Conn(ctx, r.db)
+	cmdTag, err := conn.Exec(ctx, query, id, version)
+	if err != nil {
+		return err
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return staleOrMissing(ctx, conn, settlementExistsQuery, id)
+	}
+
+	return nil
+}
+
+func (r *settlementRepository) Totals(ctx context.Context) ([]domain.PersonDebt, error) {
+	query := `
+		SELECT from_person_id, to_person_id, SUM(amount)
+		FROM settlements
+		GROUP BY from_person_id, to_person_id
+		ORDER BY from_person_id, to_person_id`
+
+	rows, err := postgres.Conn(ctx, r.db).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []domain.PersonDebt
+	for rows.Next() {
+		var total domain.PersonDebt
+		if err := rows.Scan(&total.FromPersonID, &total.ToPersonID, &total.Amount); err != nil {
+			return nil, err
+		}
+		totals = append(totals, total)
+	}
+
+	return totals, rows.Err()
+}
+
+// settlementExistsQuery checks whether a settlement exists
+const settlementExistsQuery = `SELECT EXISTS (SELECT 1 FROM settlements WHERE id = $1)`
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/postgres"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type tagRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewTagRepository creates a new PostgreSQL tag repository
+func NewTagRepository(db *pgxpool.Pool) port.TagRepository {
+	return &tagRepository{
+		db: db,
+	}
+}
+
+func (r *tagRepository) Create(ctx context.Context, tag *domain.Tag) error {
+	query := `
+		INSERT INTO tags (name, created_at, updated_at)
+		VALUES ($1, $2, $3)
+		RETURNING id, version`
+
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, query, tag.Name, tag.CreatedAt, tag.UpdatedAt).Scan(&tag.ID, &tag.Version)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *tagRepository) GetByID(ctx context.Context, id int) (*domain.Tag, error) {
+	query := `
+		SELECT id, name, version, created_at, updated_at
+		FROM tags
+		WHERE id = $1`
+
+	tag := &domain.Tag{}
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, query, id).Scan(
+		&tag.ID,
+		&tag.Name,
+		&tag.Version,
+		&tag.CreatedAt,
+		&tag.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+func (r *tagRepository) GetByName(ctx context.Context, name string) (*domain.Tag, error) {
+	query := `
+		SELECT id, name, version, created_at, updated_at
+		FROM tags
+		WHERE LOWER(name) = LOWER($1)`
+
+	tag := &domain.Tag{}
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, query, name).Scan(
+		&tag.ID,
+		&tag.Name,
+		&tag.Version,
+		&tag.CreatedAt,
+		&tag.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+func (r *tagRepository) List(ctx context.Context, after *domain.Cursor, limit int) ([]*domain.Tag, error) {
+	query := `
+		SELECT id, name, version, created_at, updated_at
+		FROM tags
+		WHERE $2::bigint IS NULL OR id > $2
+		ORDER BY id
+		LIMIT $1`
+
+	rows, err := postgres.Conn(ctx, r.db).Query(ctx, query, limit, cursorID(after))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []*domain.Tag
+	for rows.Next() {
+		tag := &domain.Tag{}
+		err := rows.Scan(
+			&tag.ID,
+			&tag.Name,
+			&tag.Version,
+			&tag.CreatedAt,
+			&tag.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+func (r *tagRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := postgres.Conn(ctx, r.db).QueryRow(ctx, `SELECT COUNT(*) FROM tags`).Scan(&count)
+	return count, err
+}
+
+func (r *tagRepository) Update(ctx context.Context, tag *domain.Tag) error {
+	query := `
+		UPDATE tags
+		SET name = $2, updated_at = $3, version = version + 1
+		WHERE id = $1 AND ($4 = 0 OR version = $4)
+		RETURNING version`
+
+	conn := postgres.Conn(ctx, r.db)
+	err := conn.QueryRow(ctx, query, tag.ID, tag.Name, tag.UpdatedAt, tag.Version).Scan(&tag.Version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return staleOrMissing(ctx, conn, tagExistsQuery, tag.ID)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *tagRepository) Delete(ctx context.Context, id int, version int) error {
+	query := `DELETE FROM tags WHERE id = $1 AND ($2 = 0 OR version = $2)`
+
+	conn := postgres.Conn(ctx, r.db)
+	cmdTag, err := conn.Exec(ctx, query, id, version)
+	if err != nil {
+		return err
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return staleOrMissing(ctx, conn, tagExistsQuery, id)
+	}
+
+	return nil
+}
+
+// tagExistsQuery checks whether a tag exists
+const tagExistsQuery = `SELECT EXISTS (SELECT 1 FROM tags WHERE id = $1)`
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Querier is the set of query methods shared by the connection pool and transactions
+type Querier interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// txContextKey is the context key under which the active transaction is stored
+type txContextKey struct{}
+
+// Conn returns the transaction carried by ctx, falling back to the pool when there is none.
+// Constraint violations of its statements are translated into domain errors by MapError.
+func Conn(ctx context.Context, pool *pgxpool.Pool) Querier {
+	if tx, ok := ctx.Value(txContextKey{}).(pgx.Tx); ok {
+		return mappingQuerier{tx}
+	}
+	return mappingQuerier{pool}
+}
+
+// TxManager runs units of work inside a PostgreSQL transaction
+type TxManager struct {
+	pool *pgxpool.Pool
+}
+
+// NewTxManager creates a new PostgreSQL transaction manager
+func NewTxManager(db *DB) *TxManager {
+	return &TxManager{
+		pool: db.Pool,
+	}
+}
+
+// WithinTx runs fn inside a transaction. When ctx already carries one, fn runs inside
+// a savepoint of it, so a failing fn only undoes its own work and the caller decides
+// whether the outer transaction still commits.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	var tx pgx.Tx
+	var err error
+	if outer, ok := ctx.Value(txContextKey{}).(pgx.Tx); ok {
+		tx, err = outer.Begin(ctx)
+	} else {
+		tx, err = m.pool.Begin(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	// Rollback is a no-op once the transaction has been committed
+	defer tx.Rollback(ctx)
+
+	if err := fn(context.WithValue(ctx, txContextKey{}, tx)); err != nil {
+		return err
+	}
+
+	return MapError(tx.Commit(ctx))
+}
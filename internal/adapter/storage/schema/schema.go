@@ -0,0 +1,158 @@
+package schema
+
+import (
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// fileName matches <version>_<name>.<up|down>.sql
+var fileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration is a schema change along with the statements undoing it
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string // Empty when the migration cannot be rolled back
+}
+
+// Step is a migration applied in one direction, leaving the schema at Version
+type Step struct {
+	Migration  *Migration
+	Direction  string // up or down
+	Statements string
+	Version    uint
+}
+
+// Migrations is a set of migrations sorted by version
+type Migrations []*Migration
+
+// Load reads the migration files at the root of fsys, pairing the up and down statements of each version
+func Load(fsys fs.FS) (Migrations, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint]*Migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("migration %s has an invalid version", entry.Name())
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, exists := byVersion[uint(version)]
+		if !exists {
+			migration = &Migration{Version: uint(version), Name: match[2]}
+			byVersion[migration.Version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migrations %d_%s and %d_%s share a version", version, migration.Name, version, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make(Migrations, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Latest returns the version of the newest migration, 0 when there is none
+func (m Migrations) Latest() uint {
+	if len(m) == 0 {
+		return 0
+	}
+	return m[len(m)-1].Version
+}
+
+// Has tells whether there is a migration with the given version, 0 stands for no migration and always exists
+func (m Migrations) Has(version uint) bool {
+	return version == 0 || m.index(version) >= 0
+}
+
+// Rollback returns the version left after rolling back the given number of migrations from current
+func (m Migrations) Rollback(current uint, steps int) (uint, error) {
+	if steps < 1 {
+		return 0, fmt.Errorf("the number of migrations to roll back must be positive, got %d", steps)
+	}
+	if current == 0 {
+		return 0, nil
+	}
+
+	index := m.index(current)
+	if index < 0 {
+		return 0, fmt.Errorf("the schema is at version %d which this binary does not carry", current)
+	}
+	if index-steps < 0 {
+		return 0, nil
+	}
+	return m[index-steps].Version, nil
+}
+
+// Steps returns the migrations to apply, in order, to move the schema from the current version to the target one
+func (m Migrations) Steps(current, target uint) ([]Step, error) {
+	var steps []Step
+	if target >= current {
+		for _, migration := range m {
+			if migration.Version <= current || migration.Version > target {
+				continue
+			}
+			steps = append(steps, Step{Migration: migration, Direction: "up", Statements: migration.Up, Version: migration.Version})
+		}
+		return steps, nil
+	}
+
+	if !m.Has(current) {
+		return nil, fmt.Errorf("the schema is at version %d which this binary does not carry", current)
+	}
+	for i := len(m) - 1; i >= 0; i-- {
+		migration := m[i]
+		if migration.Version > current || migration.Version <= target {
+			continue
+		}
+		if migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s cannot be rolled back", migration.Version, migration.Name)
+		}
+		var previous uint
+		if i > 0 {
+			previous = m[i-1].Version
+		}
+		steps = append(steps, Step{Migration: migration, Direction: "down", Statements: migration.Down, Version: previous})
+	}
+	return steps, nil
+}
+
+// index returns the position of the migration with the given version, -1 when there is none
+func (m Migrations) index(version uint) int {
+	i := sort.Search(len(m), func(i int) bool {
+		return m[i].Version >= version
+	})
+	if i < len(m) && m[i].Version == version {
+		return i
+	}
+	return -1
+}
@@ -0,0 +1,83 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+
+	"modernc.org/sqlite"
+)
+
+// driverName is the database/sql driver of modernc.org/sqlite, a cgo-free port of SQLite
+const driverName = "sqlite"
+
+// The functions the repositories rely on are available on every connection opened from then on
+func init() {
+	if err := sqlite.RegisterDeterministicScalarFunction("notes_match", 2, notesMatchFunc); err != nil {
+		panic(err)
+	}
+}
+
+// DB is a wrapper for SQLite database connection
+type DB struct {
+	*sql.DB
+}
+
+// New opens the SQLite database file at path, creating it along with its directory when missing.
+// Foreign keys are enforced on every connection and writers wait for each other instead of failing.
+func New(ctx context.Context, path string) (*DB, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+
+	// Transactions take the write lock upfront, so two of them never deadlock upgrading a read lock.
+	// Times are written in the format SQLite's own date functions read.
+	dsn := "file:" + path + "?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)" +
+		"&_txlock=immediate&_time_format=sqlite"
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &DB{db}, nil
+}
+
+// Close closes the database connection
+func (db *DB) Close() {
+	db.DB.Close()
+}
+
+// notesMatchFunc is notesMatch called from SQL, where NULL notes match no word
+func notesMatchFunc(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	notes, _ := args[0].(string)
+	query, _ := args[1].(string)
+	return notesMatch(notes, query), nil
+}
+
+// notesMatch reports whether every word of the query appears in the notes, ignoring case,
+// like a Postgres full-text search with the simple configuration
+func notesMatch(notes, query string) bool {
+	words := strings.FieldsFunc(strings.ToLower(notes), isNotWordRune)
+	for _, term := range strings.FieldsFunc(strings.ToLower(query), isNotWordRune) {
+		if !slices.Contains(words, term) {
+			return false
+		}
+	}
+	return true
+}
+
+func isNotWordRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
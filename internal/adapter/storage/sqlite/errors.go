@@ -0,0 +1,128 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/tracing"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"go.opentelemetry.io/otel/trace"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// MapError translates constraint violations into a domain.ConstraintError wrapping the domain error they
+// stand for, and returns any other error unchanged
+func MapError(err error) error {
+	return mapError(err, false)
+}
+
+// mapError is MapError for a statement known to be removing rows or not. SQLite names neither the
+// foreign key nor its side, so removing rows tells a row still referenced apart from a row referring
+// to a missing one.
+func mapError(err error, removing bool) error {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+
+	// Connections report extended result codes, telling the kind of constraint apart
+	switch sqliteErr.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+		return &domain.ConstraintError{Err: domain.ErrConflictingData, Constraint: constraintName(sqliteErr)}
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		if removing {
+			return &domain.ConstraintError{Err: domain.ErrReferencedData}
+		}
+		return &domain.ConstraintError{Err: domain.ErrInvalidReference}
+	case sqlite3.SQLITE_CONSTRAINT_TRIGGER:
+		// ON DELETE RESTRICT foreign keys are enforced like triggers and reported as such
+		if strings.HasPrefix(errorMessage(sqliteErr), "FOREIGN KEY") {
+			return &domain.ConstraintError{Err: domain.ErrReferencedData}
+		}
+	case sqlite3.SQLITE_CONSTRAINT_CHECK:
+		return &domain.ConstraintError{Err: domain.ErrInvalidInput, Constraint: constraintName(sqliteErr)}
+	}
+	return err
+}
+
+// constraintName returns what SQLite tells of the violated constraint, the name of a check or unique index,
+// or the constrained columns
+func constraintName(err *sqlite.Error) string {
+	_, name, found := strings.Cut(errorMessage(err), "constraint failed: ")
+	if !found {
+		return ""
+	}
+	name = strings.TrimPrefix(name, "index ")
+	return strings.Trim(name, "'")
+}
+
+// errorMessage returns the message of SQLite for err. The driver reports it after the description
+// of the result code and before the code itself, as in "constraint failed: <message> (2067)".
+func errorMessage(err *sqlite.Error) string {
+	message := err.Error()
+	if i := strings.LastIndex(message, " ("); i >= 0 {
+		message = message[:i]
+	}
+	if _, detail, found := strings.Cut(message, ": "); found {
+		return detail
+	}
+	return message
+}
+
+// isDelete reports whether the statement removes rows
+func isDelete(query string) bool {
+	return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(query)), "DELETE")
+}
+
+// mappingRows translates the error reported once the rows are read and ends the span
+// of the statement, counting the rows read, when they are closed
+type mappingRows struct {
+	*sql.Rows
+	span  trace.Span
+	count int64
+}
+
+func (r *mappingRows) Next() bool {
+	if !r.Rows.Next() {
+		return false
+	}
+	r.count++
+	return true
+}
+
+func (r *mappingRows) Close() error {
+	err := r.Rows.Close()
+	tracing.EndQuery(r.span, r.count, r.Rows.Err())
+	return err
+}
+
+func (r *mappingRows) Err() error {
+	return MapError(r.Rows.Err())
+}
+
+// mappingRow translates the error of the statement, reported when the row is scanned,
+// and ends the span of the statement once it is
+type mappingRow struct {
+	*sql.Row
+	span trace.Span
+}
+
+func (r mappingRow) Scan(dest ...any) error {
+	err := r.Row.Scan(dest...)
+	switch {
+	case err == nil:
+		tracing.EndQuery(r.span, 1, nil)
+	case errors.Is(err, sql.ErrNoRows):
+		// Finding no row answers the statement, it does not fail it
+		tracing.EndQuery(r.span, 0, nil)
+	default:
+		tracing.EndQuery(r.span, 0, err)
+	}
+	return MapError(err)
+}
+
+func (r mappingRow) Err() error {
+	return MapError(r.Row.Err())
+}
@@ -0,0 +1,153 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/schema"
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/sqlite/migrations"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+// Migrator applies the migrations embedded in the binary, keeping the applied version in a
+// schema_migrations table laid out like the PostgreSQL one
+type Migrator struct {
+	db         *sql.DB
+	migrations schema.Migrations
+	logger     *slog.Logger
+}
+
+// NewMigrator creates a new SQLite schema migrator
+func NewMigrator(db *DB, logger *slog.Logger) (port.SchemaMigrator, error) {
+	loaded, err := schema.Load(migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:         db.DB,
+		migrations: loaded,
+		logger:     logger,
+	}, nil
+}
+
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.withinTx(ctx, func(current uint) (uint, error) {
+		return m.migrations.Latest(), nil
+	})
+}
+
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	return m.withinTx(ctx, func(current uint) (uint, error) {
+		return m.migrations.Rollback(current, steps)
+	})
+}
+
+func (m *Migrator) Goto(ctx context.Context, version uint) error {
+	if !m.migrations.Has(version) {
+		return fmt.Errorf("there is no migration with version %d", version)
+	}
+
+	return m.withinTx(ctx, func(current uint) (uint, error) {
+		return version, nil
+	})
+}
+
+func (m *Migrator) Status(ctx context.Context) (*domain.SchemaStatus, error) {
+	version, dirty, err := schemaVersion(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.SchemaStatus{
+		Version: version,
+		Latest:  m.migrations.Latest(),
+		Dirty:   dirty,
+	}, nil
+}
+
+// withinTx migrates the schema to the version returned by target, given the applied one.
+// SQLite changes its schema transactionally, so the whole run is a single transaction
+// holding the write lock, and a failing migration leaves the schema as it was.
+func (m *Migrator) withinTx(ctx context.Context, target func(current uint) (uint, error)) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// Rollback is a no-op once the transaction has been committed
+	defer tx.Rollback()
+
+	query := `CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)`
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return err
+	}
+
+	current, dirty, err := schemaVersion(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("the database is dirty at version %d, repair the schema by hand and reset schema_migrations", current)
+	}
+
+	version, err := target(current)
+	if err != nil {
+		return err
+	}
+	steps, err := m.migrations.Steps(current, version)
+	if err != nil {
+		return err
+	}
+
+	for _, step := range steps {
+		if _, err := tx.ExecContext(ctx, step.Statements); err != nil {
+			return fmt.Errorf("migration %d_%s %s: %w", step.Migration.Version, step.Migration.Name, step.Direction, err)
+		}
+	}
+	if len(steps) > 0 {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations`); err != nil {
+			return err
+		}
+		if version != 0 {
+			if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES (?1, FALSE)`, version); err != nil {
+				return err
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	for _, step := range steps {
+		m.logger.Info("Applied migration", "version", step.Migration.Version, "name", step.Migration.Name, "direction", step.Direction)
+	}
+	return nil
+}
+
+// schemaVersion reads the applied version, a database never migrated is at version 0
+func schemaVersion(ctx context.Context, q sqlQuerier) (uint, bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations')`
+	if err := q.QueryRowContext(ctx, query).Scan(&exists); err != nil || !exists {
+		return 0, false, err
+	}
+
+	var version int64
+	var dirty bool
+	err := q.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	if version < 0 {
+		return 0, dirty, nil
+	}
+
+	return uint(version), dirty, nil
+}
@@ -0,0 +1,19 @@
+-- Drop the tables referencing others first
+DROP TABLE IF EXISTS idempotency_keys;
+DROP TRIGGER IF EXISTS trg_audit_log_no_delete;
+DROP TRIGGER IF EXISTS trg_audit_log_no_update;
+DROP TABLE IF EXISTS audit_log;
+DROP TABLE IF EXISTS merges;
+DROP TABLE IF EXISTS saved_views;
+DROP TABLE IF EXISTS attachments;
+DROP TABLE IF EXISTS settlements;
+DROP TABLE IF EXISTS expense_shares;
+DROP TABLE IF EXISTS expense_splits;
+DROP TABLE IF EXISTS expense_tags;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS expenses;
+DROP TABLE IF EXISTS payees;
+DROP TABLE IF EXISTS expense_subcategories;
+DROP TABLE IF EXISTS expense_categories;
+DROP TABLE IF EXISTS account;
+DROP TABLE IF EXISTS person;
@@ -0,0 +1,349 @@
+-- The whole schema the PostgreSQL migrations build up, laid out for SQLite:
+-- times are stored as UTC text, arrays and JSON documents as JSON text
+
+CREATE TABLE IF NOT EXISTS person (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_person_email ON person(email);
+
+CREATE TABLE IF NOT EXISTS account (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(255) NOT NULL,
+    currency VARCHAR(10) NOT NULL,
+    account_type VARCHAR(50) NOT NULL,
+    initial_balance DECIMAL(15, 2) NOT NULL DEFAULT 0.00,
+    primary_owner_id INTEGER NOT NULL,
+    second_owner_id INTEGER,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+
+    CONSTRAINT fk_account_primary_owner FOREIGN KEY (primary_owner_id) REFERENCES person(id) ON DELETE RESTRICT,
+    CONSTRAINT fk_account_second_owner FOREIGN KEY (second_owner_id) REFERENCES person(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_primary_owner ON account(primary_owner_id);
+CREATE INDEX IF NOT EXISTS idx_account_second_owner ON account(second_owner_id);
+CREATE INDEX IF NOT EXISTS idx_account_deleted_at ON account(deleted_at) WHERE deleted_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS expense_categories (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(100) NOT NULL,
+    aliases TEXT NOT NULL DEFAULT '[]',
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+-- Names only have to be unique among rows that are not deleted
+CREATE UNIQUE INDEX IF NOT EXISTS uk_expense_categories_name_active
+    ON expense_categories(name) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_expense_categories_created_at_id ON expense_categories(created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_expense_categories_deleted_at ON expense_categories(deleted_at) WHERE deleted_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS expense_subcategories (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(100) NOT NULL,
+    aliases TEXT NOT NULL DEFAULT '[]',
+    expense_category_id INTEGER NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+
+    CONSTRAINT fk_expense_subcategories_category
+        FOREIGN KEY (expense_category_id)
+        REFERENCES expense_categories(id)
+        ON DELETE RESTRICT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uk_expense_subcategories_name_category_active
+    ON expense_subcategories(name, expense_category_id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_expense_subcategories_category_id ON expense_subcategories(expense_category_id);
+CREATE INDEX IF NOT EXISTS idx_expense_subcategories_created_at_id ON expense_subcategories(created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_expense_subcategories_deleted_at ON expense_subcategories(deleted_at) WHERE deleted_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS payees (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(255) NOT NULL,
+    aliases TEXT NOT NULL DEFAULT '[]',
+    default_category_id INTEGER,
+    default_subcategory_id INTEGER,
+    person_id INTEGER,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_payees_default_category
+        FOREIGN KEY (default_category_id)
+        REFERENCES expense_categories(id)
+        ON DELETE SET NULL,
+
+    CONSTRAINT fk_payees_default_subcategory
+        FOREIGN KEY (default_subcategory_id)
+        REFERENCES expense_subcategories(id)
+        ON DELETE SET NULL,
+
+    CONSTRAINT fk_payees_person
+        FOREIGN KEY (person_id)
+        REFERENCES person(id)
+        ON DELETE SET NULL
+);
+
+-- Payee names are unique regardless of case
+CREATE UNIQUE INDEX IF NOT EXISTS uk_payees_name ON payees(LOWER(name));
+CREATE INDEX IF NOT EXISTS idx_payees_person_id ON payees(person_id);
+
+CREATE TABLE IF NOT EXISTS expenses (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    amount DECIMAL(15,2) NOT NULL CHECK (amount >= 0),
+    category_id INTEGER NOT NULL,
+    subcategory_id INTEGER,
+    date DATE NOT NULL,
+    payee_id INTEGER NOT NULL,
+    account_id INTEGER NOT NULL,
+    notes TEXT,
+    paid_by_id INTEGER,
+    sharing_method VARCHAR(20),
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+
+    CONSTRAINT fk_expenses_category
+        FOREIGN KEY (category_id)
+        REFERENCES expense_categories(id)
+        ON DELETE RESTRICT,
+
+    CONSTRAINT fk_expenses_subcategory
+        FOREIGN KEY (subcategory_id)
+        REFERENCES expense_subcategories(id)
+        ON DELETE SET NULL,
+
+    CONSTRAINT fk_expenses_payee
+        FOREIGN KEY (payee_id)
+        REFERENCES payees(id)
+        ON DELETE RESTRICT,
+
+    CONSTRAINT fk_expenses_account
+        FOREIGN KEY (account_id)
+        REFERENCES account(id)
+        ON DELETE RESTRICT,
+
+    CONSTRAINT fk_expenses_paid_by
+        FOREIGN KEY (paid_by_id)
+        REFERENCES person(id)
+        ON DELETE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS idx_expenses_category_id ON expenses(category_id);
+CREATE INDEX IF NOT EXISTS idx_expenses_subcategory_id ON expenses(subcategory_id);
+CREATE INDEX IF NOT EXISTS idx_expenses_payee_id ON expenses(payee_id);
+CREATE INDEX IF NOT EXISTS idx_expenses_account_id ON expenses(account_id);
+CREATE INDEX IF NOT EXISTS idx_expenses_paid_by_id ON expenses(paid_by_id);
+CREATE INDEX IF NOT EXISTS idx_expenses_date_created_at_id ON expenses(date DESC, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_expenses_amount_created_at_id ON expenses(amount, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_expenses_created_at_id ON expenses(created_at, id);
+CREATE INDEX IF NOT EXISTS idx_expenses_deleted_at ON expenses(deleted_at) WHERE deleted_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(50) NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Tag names are unique regardless of case
+CREATE UNIQUE INDEX IF NOT EXISTS uk_tags_name ON tags(LOWER(name));
+
+CREATE TABLE IF NOT EXISTS expense_tags (
+    expense_id INTEGER NOT NULL,
+    tag_id INTEGER NOT NULL,
+
+    CONSTRAINT pk_expense_tags PRIMARY KEY (expense_id, tag_id),
+
+    -- Purging an expense or deleting a tag drops its links
+    CONSTRAINT fk_expense_tags_expense
+        FOREIGN KEY (expense_id)
+        REFERENCES expenses(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_expense_tags_tag
+        FOREIGN KEY (tag_id)
+        REFERENCES tags(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_expense_tags_tag_id ON expense_tags(tag_id);
+
+CREATE TABLE IF NOT EXISTS expense_splits (
+    expense_id INTEGER NOT NULL,
+    line INTEGER NOT NULL,
+    category_id INTEGER NOT NULL,
+    subcategory_id INTEGER,
+    amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+    notes TEXT NOT NULL DEFAULT '',
+
+    CONSTRAINT pk_expense_splits PRIMARY KEY (expense_id, line),
+
+    CONSTRAINT fk_expense_splits_expense
+        FOREIGN KEY (expense_id)
+        REFERENCES expenses(id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_expense_splits_category
+        FOREIGN KEY (category_id)
+        REFERENCES expense_categories(id)
+        ON DELETE RESTRICT,
+
+    CONSTRAINT fk_expense_splits_subcategory
+        FOREIGN KEY (subcategory_id)
+        REFERENCES expense_subcategories(id)
+        ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_expense_splits_category_id ON expense_splits(category_id);
+CREATE INDEX IF NOT EXISTS idx_expense_splits_subcategory_id ON expense_splits(subcategory_id);
+
+CREATE TABLE IF NOT EXISTS expense_shares (
+    expense_id INTEGER NOT NULL,
+    line INTEGER NOT NULL,
+    person_id INTEGER NOT NULL,
+    percentage NUMERIC(9,6),
+    amount DECIMAL(15,2) NOT NULL CHECK (amount >= 0),
+
+    CONSTRAINT pk_expense_shares PRIMARY KEY (expense_id, line),
+
+    -- A person cannot be deleted while sharing an expense
+    CONSTRAINT fk_expense_shares_expense
+        FOREIGN KEY (expense_id)
+        REFERENCES expenses(id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_expense_shares_person
+        FOREIGN KEY (person_id)
+        REFERENCES person(id)
+        ON DELETE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS idx_expense_shares_person_id ON expense_shares(person_id);
+
+CREATE TABLE IF NOT EXISTS settlements (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    from_person_id INTEGER NOT NULL,
+    to_person_id INTEGER NOT NULL,
+    amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+    date DATE NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_settlements_from_person
+        FOREIGN KEY (from_person_id)
+        REFERENCES person(id)
+        ON DELETE RESTRICT,
+
+    CONSTRAINT fk_settlements_to_person
+        FOREIGN KEY (to_person_id)
+        REFERENCES person(id)
+        ON DELETE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS idx_settlements_from_person_id ON settlements(from_person_id);
+CREATE INDEX IF NOT EXISTS idx_settlements_to_person_id ON settlements(to_person_id);
+
+CREATE TABLE IF NOT EXISTS attachments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    expense_id INTEGER NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL CHECK (size > 0),
+    checksum CHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    -- Purging an expense drops its attachments
+    CONSTRAINT fk_attachments_expense
+        FOREIGN KEY (expense_id)
+        REFERENCES expenses(id)
+        ON DELETE CASCADE,
+
+    -- The same file is only attached once to an expense
+    CONSTRAINT uk_attachments_expense_checksum UNIQUE (expense_id, checksum)
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_checksum ON attachments(checksum);
+
+CREATE TABLE IF NOT EXISTS saved_views (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(100) NOT NULL,
+    filters TEXT NOT NULL DEFAULT '{}',
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS merges (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    entity_type VARCHAR(50) NOT NULL,
+    source_id INTEGER NOT NULL,
+    target_id INTEGER NOT NULL,
+    source TEXT NOT NULL,
+    expense_count BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_merges_entity_target ON merges(entity_type, target_id, id);
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id BIGINT NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    before TEXT,
+    after TEXT,
+    request_id VARCHAR(100),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT ck_audit_log_action CHECK (action IN ('create', 'update', 'delete', 'restore', 'merge'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(entity_type, entity_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at);
+
+-- The audit trail is append-only: reject any attempt to rewrite history
+CREATE TRIGGER IF NOT EXISTS trg_audit_log_no_update
+    BEFORE UPDATE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_audit_log_no_delete
+    BEFORE DELETE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key VARCHAR(255) NOT NULL,
+    scope VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    status_code INTEGER,
+    headers TEXT,
+    body BLOB,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+
+    CONSTRAINT pk_idempotency_keys PRIMARY KEY (key, scope)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
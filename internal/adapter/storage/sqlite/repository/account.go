@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/sqlite"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+type accountRepository struct {
+	db *sql.DB
+}
+
+// NewAccountRepository creates a new SQLite account repository
+func NewAccountRepository(db *sql.DB) port.AccountRepository {
+	return &accountRepository{
+		db: db,
+	}
+}
+
+// CreateAccount inserts a new Account into the repository
+func (r *accountRepository) CreateAccount(ctx context.Context, account *domain.Account) (*domain.Account, error) {
+	query := `
+		INSERT INTO account (name, currency, account_type, initial_balance, primary_owner_id, second_owner_id, created_at, updated_at)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8)
+		RETURNING id, version, created_at, updated_at
+	`
+
+	now := time.Now()
+	err := sqlite.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		account.Name,
+		account.Currency,
+		account.AccountType,
+		account.InitialBalance,
+		account.PrimaryOwnerID,
+		account.SecondOwnerID,
+		now,
+		now,
+	).Scan(&account.ID, &account.Version, &account.CreatedAt, &account.UpdatedAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// GetAccountByID selects an Account by id
+func (r *accountRepository) GetAccountByID(ctx context.Context, id uint64) (*domain.Account, error) {
+	query := `
+		SELECT id, name, currency, account_type, initial_balance, primary_owner_id, second_owner_id, version, created_at, updated_at, deleted_at
+		FROM account
+		WHERE id = ?1 AND deleted_at IS NULL
+	`
+
+	account := &domain.Account{}
+	err := sqlite.Conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
+		&account.ID,
+		&account.Name,
+		&account.Currency,
+		&account.AccountType,
+		&account.InitialBalance,
+		&account.PrimaryOwnerID,
+		&account.SecondOwnerID,
+		&account.Version,
+		&account.CreatedAt,
+		&account.UpdatedAt,
+		&account.DeletedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// GetAccountByIDForShare selects an Account like GetAccountByID,
+// SQLite transactions take the write lock when they begin, so rows cannot change under them
+func (r *accountRepository) GetAccountByIDForShare(ctx context.Context, id uint64) (*domain.Account, error) {
+	return r.GetAccountByID(ctx, id)
+}
+
+// ListAccounts selects up to limit Accounts ordered by id, starting after the cursor when given,
+// optionally including soft-deleted ones
+func (r *accountRepository) ListAccounts(ctx context.Context, after *domain.Cursor, limit int, includeDeleted bool) ([]domain.Account, error) {
+	query := `
+		SELECT id, name, currency, account_type, initial_balance, primary_owner_id, second_owner_id, version, created_at, updated_at, deleted_at
+		FROM account
+		WHERE (?3 OR deleted_at IS NULL) AND (?2 IS NULL OR id > ?2)
+		ORDER BY id
+		LIMIT ?1
+	`
+
+	rows, err := sqlite.Conn(ctx, r.db).QueryContext(ctx, query, limit, cursorID(after), includeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []domain.Account
+	for rows.Next() {
+		var account domain.Account
+		err := rows.Scan(
+			&account.ID,
+			&account.Name,
+			&account.Currency,
+			&account.AccountType,
+			&account.InitialBalance,
+			&account.PrimaryOwnerID,
+			&account.SecondOwnerID,
+			&account.Version,
+			&account.CreatedAt,
+			&account.UpdatedAt,
+			&account.DeletedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return accounts, nil
+}
+
+// CountAccounts counts the Accounts, optionally including soft-deleted ones
+func (r *accountRepository) CountAccounts(ctx context.Context, includeDeleted bool) (int64, error) {
+	var count int64
+	err := sqlite.Conn(ctx, r.db).QueryRowContext(ctx, `SELECT COUNT(*) FROM account WHERE ?1 OR deleted_at IS NULL`, includeDeleted).Scan(&count)
+	return count, err
+}
+
+// UpdateAccount updates an Account whose version matches Account.Version, 0 skips the check
+func (r *accountRepository) UpdateAccount(ctx context.Context, account *domain.Account) (*domain.Account, error) {
+	query := `
+		UPDATE account
+		SET name = ?1, currency = ?2, account_type = ?3, initial_balance = ?4, primary_owner_id = ?5, second_owner_id = ?6, updated_at = ?7, version = version + 1
+		WHERE id = ?8 AND deleted_at IS NULL AND (?9 = 0 OR version = ?9)
+		RETURNING id, name, currency, account_type, initial_balance, primary_owner_id, second_owner_id, version, created_at, updated_at
+	`
+
+	updatedAccount := &domain.Account{}
+	conn := sqlite.Conn(ctx, r.db)
+	err := conn.QueryRowContext(ctx, query,
+		account.Name,
+		account.Currency,
+		account.AccountType,
+		account.InitialBalance,
+		account.PrimaryOwnerID,
+		account.SecondOwnerID,
+		time.Now(),
+		account.ID,
+		account.Version,
+	).Scan(
+		&updatedAccount.ID,
+		&updatedAccount.Name,
+		&updatedAccount.Currency,
+		&updatedAccount.AccountType,
+		&updatedAccount.InitialBalance,
+		&updatedAccount.PrimaryOwnerID,
+		&updatedAccount.SecondOwnerID,
+		&updatedAccount.Version,
+		&updatedAccount.CreatedAt,
+		&updatedAccount.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, staleOrMissing(ctx, conn, accountExistsQuery, account.ID)
+		}
+		return nil, err
+	}
+
+	return updatedAccount, nil
+}
+
+// DeleteAccount soft deletes an Account whose version matches, 0 skips the check
+func (r *accountRepository) DeleteAccount(ctx context.Context, id uint64, version int) error {
+	query := `
+		UPDATE account SET deleted_at = ?3, version = version + 1
+		WHERE id = ?1 AND deleted_at IS NULL AND (?2 = 0 OR version = ?2)
+	`
+
+	conn := sqlite.Conn(ctx, r.db)
+	affected, err := rowsAffected(conn.ExecContext(ctx, query, id, version, time.Now()))
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return staleOrMissing(ctx, conn, accountExistsQuery, id)
+	}
+
+	return nil
+}
+
+// RestoreAccount restores a soft-deleted Account
+func (r *accountRepository) RestoreAccount(ctx context.Context, id uint64) error {
+	query := `UPDATE account SET deleted_at = NULL, updated_at = ?2, version = version + 1 WHERE id = ?1 AND deleted_at IS NOT NULL`
+
+	affected, err := rowsAffected(sqlite.Conn(ctx, r.db).ExecContext(ctx, query, id, time.Now()))
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return domain.ErrDataNotFound
+	}
+
+	return nil
+}
+
+// PurgeAccounts permanently removes Accounts soft deleted before the given time
+func (r *accountRepository) PurgeAccounts(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	// Accounts still referenced by expenses are kept until those are purged
+	query := `
+		DELETE FROM account
+		WHERE deleted_at < ?1
+			AND NOT EXISTS (SELECT 1 FROM expenses e WHERE e.account_id = account.id)
+	`
+
+	return rowsAffected(sqlite.Conn(ctx, r.db).ExecContext(ctx, query, deletedBefore))
+}
+
+// accountExistsQuery checks whether an Account exists and is not soft deleted
+const accountExistsQuery = `SELECT EXISTS (SELECT 1 FROM account WHERE id = ?1 AND deleted_at IS NULL)`
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/sqlite"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+type attachmentRepository struct {
+	db *sql.DB
+}
+
+// NewAttachmentRepository creates a new SQLite attachment repository
+func NewAttachmentRepository(db *sql.DB) port.AttachmentRepository {
+	return &attachmentRepository{
+		db: db,
+	}
+}
+
+func (r *attachmentRepository) Create(ctx context.Context, attachment *domain.Attachment) error {
+	query := `
+		INSERT INTO attachments (expense_id, file_name, content_type, size, checksum, created_at)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+		RETURNING id`
+
+	err := sqlite.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		attachment.ExpenseID,
+		attachment.FileName,
+		attachment.ContentType,
+		attachment.Size,
+		attachment.Checksum,
+		attachment.CreatedAt,
+	).Scan(&attachment.ID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *attachmentRepository) GetByID(ctx context.Context, id int) (*domain.Attachment, error) {
+	query := `
+		SELECT id, expense_id, file_name, content_type, size, checksum, created_at
+		FROM attachments
+		WHERE id = ?1`
+
+	return r.get(ctx, query, id)
+}
+
+func (r *attachmentRepository) GetByChecksum(ctx context.Context, expenseID int, checksum string) (*domain.Attachment, error) {
+	query := `
+		SELECT id, expense_id, file_name, content_type, size, checksum, created_at
+		FROM attachments
+		WHERE expense_id = ?1 AND checksum = ?2`
+
+	return r.get(ctx, query, expenseID, checksum)
+}
+
+func (r *attachmentRepository) ListByExpense(ctx context.Context, expenseID int) ([]*domain.Attachment, error) {
+	query := `
+		SELECT id, expense_id, file_name, content_type, size, checksum, created_at
+		FROM attachments
+		WHERE expense_id = ?1
+		ORDER BY id`
+
+	return r.list(ctx, query, expenseID)
+}
+
+func (r *attachmentRepository) CountByChecksum(ctx context.Context, checksum string) (int64, error) {
+	var count int64
+	err := sqlite.Conn(ctx, r.db).QueryRowContext(ctx, `SELECT COUNT(*) FROM attachments WHERE checksum = ?1`, checksum).Scan(&count)
+	return count, err
+}
+
+func (r *attachmentRepository) LockContent(ctx context.Context, checksum string) error {
+	// SQLite transactions take the write lock when they begin, nothing else can change the attachments
+	return nil
+}
+
+func (r *attachmentRepository) Delete(ctx context.Context, id int) error {
+	affected, err := rowsAffected(sqlite.Conn(ctx, r.db).ExecContext(ctx, `DELETE FROM attachments WHERE id = ?1`, id))
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return domain.ErrDataNotFound
+	}
+
+	return nil
+}
+
+func (r *attachmentRepository) Purge(ctx context.Context, expensesDeletedBefore time.Time) ([]*domain.Attachment, error) {
+	query := `
+		DELETE FROM attachments
+		WHERE expense_id IN (SELECT id FROM expenses WHERE deleted_at < ?1)
+		RETURNING id, expense_id, file_name, content_type, size, checksum, created_at`
+
+	return r.list(ctx, query, expensesDeletedBefore)
+}
+
+// list reads the attachments returned by a statement
+func (r *attachmentRepository) list(ctx context.Context, query string, args ...any) ([]*domain.Attachment, error) {
+	rows, err := sqlite.Conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attachments []*domain.Attachment
+	for rows.Next() {
+		attachment := &domain.Attachment{}
+		err := rows.Scan(
+			&attachment.ID,
+			&attachment.ExpenseID,
+			&attachment.FileName,
+			&attachment.ContentType,
+			&attachment.Size,
+			&attachment.Checksum,
+			&attachment.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+// get reads a single attachment
+func (r *attachmentRepository) get(ctx context.Context, query string, args ...any) (*domain.Attachment, error) {
+	attachment := &domain.Attachment{}
+	err := sqlite.Conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(
+		&attachment.ID,
+		&attachment.ExpenseID,
+		&attachment.FileName,
+		&attachment.ContentType,
+		&attachment.Size,
+		&attachment.Checksum,
+		&attachment.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return attachment, nil
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/sqlite"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+type auditRepository struct {
+	db *sql.DB
+}
+
+// NewAuditRepository creates a new SQLite audit repository
+func NewAuditRepository(db *sql.DB) port.AuditRepository {
+	return &auditRepository{
+		db: db,
+	}
+}
+
+func (r *auditRepository) Create(ctx context.Context, entry *domain.AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (entity_type, entity_id, action, actor, before, after, request_id, created_at)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6, NULLIF(?7, ''), ?8)
+		RETURNING id`
+
+	return sqlite.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		entry.EntityType,
+		entry.EntityID,
+		entry.Action,
+		entry.Actor,
+		jsonValue{entry.Before},
+		jsonValue{entry.After},
+		entry.RequestID,
+		entry.CreatedAt,
+	).Scan(&entry.ID)
+}
+
+func (r *auditRepository) List(ctx context.Context, after *domain.Cursor, limit int, filters port.AuditFilters) ([]*domain.AuditEntry, error) {
+	query := `
+		SELECT id, entity_type, entity_id, action, actor, before, after, COALESCE(request_id, ''), created_at
+		FROM audit_log
+		WHERE (?2 IS NULL OR (created_at, id) < (?2, ?3))
+			AND (?4 IS NULL OR entity_type = ?4) AND (?5 IS NULL OR entity_id = ?5)
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?1`
+
+	createdAt, id := cursorCreatedAt(after)
+	rows, err := sqlite.Conn(ctx, r.db).QueryContext(ctx, query, limit, createdAt, id, filters.EntityType, filters.EntityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*domain.AuditEntry
+	for rows.Next() {
+		entry := &domain.AuditEntry{}
+		err := rows.Scan(
+			&entry.ID,
+			&entry.EntityType,
+			&entry.EntityID,
+			&entry.Action,
+			&entry.Actor,
+			jsonValue{&entry.Before},
+			jsonValue{&entry.After},
+			&entry.RequestID,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *auditRepository) Count(ctx context.Context, filters port.AuditFilters) (int64, error) {
+	query := `
+		SELECT COUNT(*) FROM audit_log
+		WHERE (?1 IS NULL OR entity_type = ?1) AND (?2 IS NULL OR entity_id = ?2)`
+
+	var count int64
+	err := sqlite.Conn(ctx, r.db).QueryRowContext(ctx, query, filters.EntityType, filters.EntityID).Scan(&count)
+	return count, err
+}
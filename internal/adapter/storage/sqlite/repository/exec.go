@@ -0,0 +1,11 @@
+package repository
+
+import "database/sql"
+
+// rowsAffected returns the number of rows changed by a statement, taking the result and error of its execution
+func rowsAffected(result sql.Result, err error) (int64, error) {
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,611 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/sqlite"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+type expenseRepository struct {
+	db *sql.DB
+}
+
+// NewExpenseRepository creates a new SQLite expense repository
+func NewExpenseRepository(db *sql.DB) port.ExpenseRepository {
+	return &expenseRepository{
+		db: db,
+	}
+}
+
+func (r *expenseRepository) Create(ctx context.Context, expense *domain.Expense) error {
+	query := `
+		INSERT INTO expenses (amount, category_id, subcategory_id, date, payee_id, account_id, notes, paid_by_id, sharing_method,
+			created_at, updated_at)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10, ?11)
+		RETURNING id, version`
+
+	paidByID, sharingMethod := expenseSharingColumns(expense.Sharing)
+	conn := sqlite.Conn(ctx, r.db)
+	err := conn.QueryRowContext(ctx, query,
+		expense.Amount,
+		expense.CategoryID,
+		expense.SubCategoryID,
+		expense.Date,
+		expense.PayeeID,
+		expense.AccountID,
+		expense.Notes,
+		paidByID,
+		sharingMethod,
+		expense.CreatedAt,
+		expense.UpdatedAt,
+	).Scan(&expense.ID, &expense.Version)
+
+	if err != nil {
+		return err
+	}
+
+	if err := setExpenseTags(ctx, conn, expense.ID, expense.TagIDs); err != nil {
+		return err
+	}
+	if err := setExpenseSplits(ctx, conn, expense.ID, expense.Splits); err != nil {
+		return err
+	}
+	return setExpenseShares(ctx, conn, expense.ID, expense.Sharing)
+}
+
+func (r *expenseRepository) GetByID(ctx context.Context, id int) (*domain.Expense, error) {
+	query := `
+		SELECT id, amount, category_id, subcategory_id, date, payee_id, account_id, notes, ` + expenseTagIDsColumn + `, ` + expenseSplitsColumn + `,
+			` + expenseSharingColumn + `,
+			version, created_at, updated_at, deleted_at
+		FROM expenses
+		WHERE id = ?1 AND deleted_at IS NULL`
+
+	expense, err := scanExpense(sqlite.Conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return expense, nil
+}
+
+func (r *expenseRepository) List(ctx context.Context, filters port.ExpenseFilters) ([]*domain.Expense, error) {
+	conditions, args, _ := expenseConditions(filters)
+	argIndex := len(args) + 1
+
+	// Order by the sort field, then creation time and ID in the same direction
+	column := "date"
+	var sortValue any
+	if filters.After != nil {
+		sortValue = filters.After.Date
+	}
+	switch filters.Sort.Field {
+	case domain.ExpenseSortAmount:
+		column = "amount"
+		if filters.After != nil {
+			sortValue = filters.After.Amount
+		}
+	case domain.ExpenseSortCreatedAt:
+		column = "created_at"
+		if filters.After != nil {
+			sortValue = filters.After.CreatedAt
+		}
+	}
+	direction, comparison := "ASC", ">"
+	if filters.Sort.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	// Continue after the last expense of the previous page
+	if filters.After != nil && filters.After.CreatedAt != nil {
+		conditions = append(conditions, fmt.Sprintf("(%s, created_at, id) %s (?%d, ?%d, ?%d)",
+			column, comparison, argIndex, argIndex+1, argIndex+2))
+		args = append(args, sortValue, *filters.After.CreatedAt, filters.After.ID)
+		argIndex += 3
+	}
+
+	query := `
+		SELECT id, amount, category_id, subcategory_id, date, payee_id, account_id, notes, ` + expenseTagIDsColumn + `, ` + expenseSplitsColumn + `,
+			` + expenseSharingColumn + `,
+			version, created_at, updated_at, deleted_at
+		FROM expenses`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query += fmt.Sprintf(" ORDER BY %[1]s %[2]s, created_at %[2]s, id %[2]s", column, direction)
+
+	// Add pagination
+	query += fmt.Sprintf(" LIMIT ?%d", argIndex)
+	args = append(args, filters.Limit)
+
+	rows, err := sqlite.Conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var expenses []*domain.Expense
+	for rows.Next() {
+		expense, err := scanExpense(rows)
+		if err != nil {
+			return nil, err
+		}
+		expenses = append(expenses, expense)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return expenses, nil
+}
+
+func (r *expenseRepository) Count(ctx context.Context, filters port.ExpenseFilters) (int64, error) {
+	conditions, args, _ := expenseConditions(filters)
+
+	query := `SELECT COUNT(*) FROM expenses`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var count int64
+	err := sqlite.Conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&count)
+	return count, err
+}
+
+func (r *expenseRepository) TagTotals(ctx context.Context, filters port.ExpenseFilters) ([]domain.TagTotal, error) {
+	conditions, args, splitCondition := expenseConditions(filters)
+
+	// Only the matching lines of a split expense count when filtering by category
+	amount := "amount"
+	if splitCondition != "" {
+		amount = `CASE WHEN EXISTS (SELECT 1 FROM expense_splits s WHERE s.expense_id = expenses.id)
+			THEN (SELECT SUM(s.amount) FROM expense_splits s WHERE s.expense_id = expenses.id AND ` + splitCondition + `)
+			ELSE amount END`
+	}
+
+	query := `
+		SELECT et.tag_id, COUNT(*), COALESCE(SUM(e.amount), 0)
+		FROM expense_tags et
+		JOIN (SELECT id, ` + amount + ` AS amount FROM expenses`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += `) e ON e.id = et.expense_id
+		GROUP BY et.tag_id`
+
+	rows, err := sqlite.Conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []domain.TagTotal
+	for rows.Next() {
+		var total domain.TagTotal
+		if err := rows.Scan(&total.TagID, &total.ExpenseCount, &total.TotalAmount); err != nil {
+			return nil, err
+		}
+		totals = append(totals, total)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+// expenseConditions builds the WHERE conditions and arguments of the filters, without pagination.
+// It also returns the category conditions applied to the split lines "s", empty without category filters.
+// Lists of IDs are bound as JSON arrays and read with json_each.
+func expenseConditions(filters port.ExpenseFilters) ([]string, []interface{}, string) {
+	var conditions []string
+	var args []interface{}
+	argIndex := 1
+
+	// Exclude soft-deleted expenses unless explicitly requested
+	if !filters.IncludeDeleted {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+
+	// Add WHERE conditions based on filters
+	categoryIndex, subCategoryIndex := 0, 0
+	if len(filters.CategoryIDs) > 0 {
+		categoryIndex = argIndex
+		args = append(args, jsonArray(filters.CategoryIDs))
+		argIndex++
+	}
+
+	if filters.SubCategoryID != nil {
+		subCategoryIndex = argIndex
+		args = append(args, *filters.SubCategoryID)
+		argIndex++
+	}
+
+	// Category conditions of an expense or of one of its split lines, whose columns have the same names
+	categoryConditions := func(prefix string) string {
+		var conditions []string
+		if categoryIndex > 0 {
+			conditions = append(conditions, fmt.Sprintf("%scategory_id IN (SELECT value FROM json_each(?%d))", prefix, categoryIndex))
+		}
+		if subCategoryIndex > 0 {
+			conditions = append(conditions, fmt.Sprintf("%ssubcategory_id = ?%d", prefix, subCategoryIndex))
+		}
+		if filters.HasSubCategory != nil {
+			if *filters.HasSubCategory {
+				conditions = append(conditions, prefix+"subcategory_id IS NOT NULL")
+			} else {
+				conditions = append(conditions, prefix+"subcategory_id IS NULL")
+			}
+		}
+		return strings.Join(conditions, " AND ")
+	}
+
+	// A split expense matches when one of its lines does
+	splitCondition := categoryConditions("s.")
+	if splitCondition != "" {
+		conditions = append(conditions, fmt.Sprintf(`CASE WHEN EXISTS (SELECT 1 FROM expense_splits s WHERE s.expense_id = expenses.id)
+			THEN EXISTS (SELECT 1 FROM expense_splits s WHERE s.expense_id = expenses.id AND %s)
+			ELSE %s END`, splitCondition, categoryConditions("")))
+	}
+
+	if len(filters.PayeeIDs) > 0 {
+		conditions = append(conditions, fmt.Sprintf("payee_id IN (SELECT value FROM json_each(?%d))", argIndex))
+		args = append(args, jsonArray(filters.PayeeIDs))
+		argIndex++
+	}
+
+	if len(filters.AccountIDs) > 0 {
+		conditions = append(conditions, fmt.Sprintf("account_id IN (SELECT value FROM json_each(?%d))", argIndex))
+		args = append(args, jsonArray(filters.AccountIDs))
+		argIndex++
+	}
+
+	if filters.MinAmount != nil {
+		conditions = append(conditions, fmt.Sprintf("amount >= ?%d", argIndex))
+		args = append(args, *filters.MinAmount)
+		argIndex++
+	}
+
+	if filters.MaxAmount != nil {
+		conditions = append(conditions, fmt.Sprintf("amount <= ?%d", argIndex))
+		args = append(args, *filters.MaxAmount)
+		argIndex++
+	}
+
+	// Every word of the search must appear in the notes
+	if filters.NotesQuery != "" {
+		conditions = append(conditions, fmt.Sprintf("notes_match(COALESCE(notes, ''), ?%d)", argIndex))
+		args = append(args, filters.NotesQuery)
+		argIndex++
+	}
+
+	// Tags of the expense, any of them or all of them
+	if len(filters.TagIDs) > 0 {
+		if filters.MatchAllTags {
+			conditions = append(conditions, fmt.Sprintf(
+				"(SELECT COUNT(*) FROM expense_tags et WHERE et.expense_id = expenses.id AND et.tag_id IN (SELECT value FROM json_each(?%[1]d))) = json_array_length(?%[1]d)",
+				argIndex))
+		} else {
+			// An EXISTS subquery here returns the expense once per matching tag with SQLite 3.51
+			conditions = append(conditions, fmt.Sprintf(
+				"id IN (SELECT et.expense_id FROM expense_tags et WHERE et.tag_id IN (SELECT value FROM json_each(?%d)))", argIndex))
+		}
+		args = append(args, jsonArray(filters.TagIDs))
+		argIndex++
+	}
+
+	if filters.StartDate != nil {
+		conditions = append(conditions, fmt.Sprintf("date >= ?%d", argIndex))
+		args = append(args, *filters.StartDate)
+		argIndex++
+	}
+
+	if filters.EndDate != nil {
+		conditions = append(conditions, fmt.Sprintf("date <= ?%d", argIndex))
+		args = append(args, *filters.EndDate)
+	}
+
+	return conditions, args, splitCondition
+}
+
+func (r *expenseRepository) Update(ctx context.Context, expense *domain.Expense) error {
+	query := `
+		UPDATE expenses
+		SET amount = ?2, category_id = ?3, subcategory_id = ?4, date = ?5, payee_id = ?6, account_id = ?7, notes = ?8,
+			paid_by_id = ?9, sharing_method = ?10, updated_at = ?11, version = version + 1
+		WHERE id = ?1 AND deleted_at IS NULL AND (?12 = 0 OR version = ?12)
+		RETURNING version`
+
+	paidByID, sharingMethod := expenseSharingColumns(expense.Sharing)
+	conn := sqlite.Conn(ctx, r.db)
+	err := conn.QueryRowContext(ctx, query,
+		expense.ID,
+		expense.Amount,
+		expense.CategoryID,
+		expense.SubCategoryID,
+		expense.Date,
+		expense.PayeeID,
+		expense.AccountID,
+		expense.Notes,
+		paidByID,
+		sharingMethod,
+		expense.UpdatedAt,
+		expense.Version,
+	).Scan(&expense.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return staleOrMissing(ctx, conn, expenseExistsQuery, expense.ID)
+		}
+		return err
+	}
+
+	if err := setExpenseTags(ctx, conn, expense.ID, expense.TagIDs); err != nil {
+		return err
+	}
+	if err := setExpenseSplits(ctx, conn, expense.ID, expense.Splits); err != nil {
+		return err
+	}
+	return setExpenseShares(ctx, conn, expense.ID, expense.Sharing)
+}
+
+func (r *expenseRepository) Delete(ctx context.Context, id int, version int) error {
+	query := `
+		UPDATE expenses SET deleted_at = ?3, version = version + 1
+		WHERE id = ?1 AND deleted_at IS NULL AND (?2 = 0 OR version = ?2)`
+
+	conn := sqlite.Conn(ctx, r.db)
+	affected, err := rowsAffected(conn.ExecContext(ctx, query, id, version, time.Now()))
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return staleOrMissing(ctx, conn, expenseExistsQuery, id)
+	}
+
+	return nil
+}
+
+func (r *expenseRepository) Restore(ctx context.Context, id int) error {
+	query := `UPDATE expenses SET deleted_at = NULL, updated_at = ?2, version = version + 1 WHERE id = ?1 AND deleted_at IS NOT NULL`
+
+	affected, err := rowsAffected(sqlite.Conn(ctx, r.db).ExecContext(ctx, query, id, time.Now()))
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return domain.ErrDataNotFound
+	}
+
+	return nil
+}
+
+func (r *expenseRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	query := `DELETE FROM expenses WHERE deleted_at < ?1`
+
+	return rowsAffected(sqlite.Conn(ctx, r.db).ExecContext(ctx, query, deletedBefore))
+}
+
+func (r *expenseRepository) RemoveTag(ctx context.Context, tagID int) error {
+	// The tags are part of the expense, so removing one makes a new version of it.
+	// SQLite has no data-modifying CTE, the caller runs both statements in one transaction.
+	query := `
+		UPDATE expenses SET updated_at = ?2, version = version + 1
+		WHERE id IN (SELECT expense_id FROM expense_tags WHERE tag_id = ?1)`
+
+	conn := sqlite.Conn(ctx, r.db)
+	if _, err := conn.ExecContext(ctx, query, tagID, time.Now()); err != nil {
+		return err
+	}
+
+	_, err := conn.ExecContext(ctx, `DELETE FROM expense_tags WHERE tag_id = ?1`, tagID)
+	return err
+}
+
+func (r *expenseRepository) ReassignPayee(ctx context.Context, fromID, toID int) (int64, error) {
+	query := `UPDATE expenses SET payee_id = ?2, updated_at = ?3, version = version + 1 WHERE payee_id = ?1`
+
+	return rowsAffected(sqlite.Conn(ctx, r.db).ExecContext(ctx, query, fromID, toID, time.Now()))
+}
+
+func (r *expenseRepository) ReassignCategory(ctx context.Context, fromID, toID int) (int64, error) {
+	// The split lines are part of the expense, so moving one makes a new version of it.
+	// The expenses are updated while their lines still point at the source category.
+	query := `
+		UPDATE expenses
+		SET category_id = CASE WHEN category_id = ?1 THEN ?2 ELSE category_id END, updated_at = ?3, version = version + 1
+		WHERE category_id = ?1 OR id IN (SELECT expense_id FROM expense_splits WHERE category_id = ?1)`
+
+	conn := sqlite.Conn(ctx, r.db)
+	moved, err := rowsAffected(conn.ExecContext(ctx, query, fromID, toID, time.Now()))
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := conn.ExecContext(ctx, `UPDATE expense_splits SET category_id = ?2 WHERE category_id = ?1`, fromID, toID); err != nil {
+		return 0, err
+	}
+
+	return moved, nil
+}
+
+func (r *expenseRepository) ReassignSubCategory(ctx context.Context, fromID, toID, toCategoryID int) (int64, error) {
+	// The split lines are part of the expense, so moving one makes a new version of it.
+	// The expenses are updated while their lines still point at the source subcategory.
+	query := `
+		UPDATE expenses
+		SET category_id = CASE WHEN subcategory_id = ?1 THEN ?3 ELSE category_id END,
+			subcategory_id = CASE WHEN subcategory_id = ?1 THEN ?2 ELSE subcategory_id END,
+			updated_at = ?4, version = version + 1
+		WHERE subcategory_id = ?1 OR id IN (SELECT expense_id FROM expense_splits WHERE subcategory_id = ?1)`
+
+	conn := sqlite.Conn(ctx, r.db)
+	moved, err := rowsAffected(conn.ExecContext(ctx, query, fromID, toID, toCategoryID, time.Now()))
+	if err != nil {
+		return 0, err
+	}
+
+	splitsQuery := `UPDATE expense_splits SET subcategory_id = ?2, category_id = ?3 WHERE subcategory_id = ?1`
+	if _, err := conn.ExecContext(ctx, splitsQuery, fromID, toID, toCategoryID); err != nil {
+		return 0, err
+	}
+
+	return moved, nil
+}
+
+func (r *expenseRepository) ShareDebts(ctx context.Context) ([]domain.PersonDebt, error) {
+	// The payer does not owe their own share
+	query := `
+		SELECT sh.person_id, e.paid_by_id, SUM(sh.amount)
+		FROM expense_shares sh
+		JOIN expenses e ON e.id = sh.expense_id
+		WHERE e.deleted_at IS NULL AND sh.person_id <> e.paid_by_id
+		GROUP BY sh.person_id, e.paid_by_id
+		ORDER BY sh.person_id, e.paid_by_id`
+
+	rows, err := sqlite.Conn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var debts []domain.PersonDebt
+	for rows.Next() {
+		var debt domain.PersonDebt
+		if err := rows.Scan(&debt.FromPersonID, &debt.ToPersonID, &debt.Amount); err != nil {
+			return nil, err
+		}
+		debts = append(debts, debt)
+	}
+
+	return debts, rows.Err()
+}
+
+// setExpenseTags replaces the tags of an expense, the caller runs it in the same transaction as the expense change
+func setExpenseTags(ctx context.Context, q sqlite.Querier, expenseID int, tagIDs []int) error {
+	if _, err := q.ExecContext(ctx, `DELETE FROM expense_tags WHERE expense_id = ?1`, expenseID); err != nil {
+		return err
+	}
+	if len(tagIDs) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO expense_tags (expense_id, tag_id)
+		SELECT ?1, value FROM json_each(?2)`
+
+	_, err := q.ExecContext(ctx, query, expenseID, jsonArray(tagIDs))
+	return err
+}
+
+// setExpenseSplits replaces the split lines of an expense, the caller runs it in the same transaction as the expense change
+func setExpenseSplits(ctx context.Context, q sqlite.Querier, expenseID int, splits []domain.ExpenseSplit) error {
+	if _, err := q.ExecContext(ctx, `DELETE FROM expense_splits WHERE expense_id = ?1`, expenseID); err != nil {
+		return err
+	}
+	if len(splits) == 0 {
+		return nil
+	}
+
+	// The lines keep the order of the array
+	query := `
+		INSERT INTO expense_splits (expense_id, line, category_id, subcategory_id, amount, notes)
+		SELECT ?1, l.key + 1, l.value ->> 'category_id', l.value ->> 'subcategory_id', l.value ->> 'amount',
+			COALESCE(l.value ->> 'notes', '')
+		FROM json_each(?2) AS l`
+
+	_, err := q.ExecContext(ctx, query, expenseID, jsonArray(splits))
+	return err
+}
+
+// setExpenseShares replaces the shares of an expense, the caller runs it in the same transaction as the expense change
+func setExpenseShares(ctx context.Context, q sqlite.Querier, expenseID int, sharing *domain.ExpenseSharing) error {
+	if _, err := q.ExecContext(ctx, `DELETE FROM expense_shares WHERE expense_id = ?1`, expenseID); err != nil {
+		return err
+	}
+	if sharing == nil || len(sharing.Shares) == 0 {
+		return nil
+	}
+
+	// Only the percentage method keeps the percentage of each share
+	query := `
+		INSERT INTO expense_shares (expense_id, line, person_id, percentage, amount)
+		SELECT ?1, l.key + 1, l.value ->> 'person_id',
+			CASE WHEN ?3 = '` + domain.SharingMethodPercentage + `' THEN COALESCE(l.value ->> 'percentage', 0) END,
+			l.value ->> 'amount'
+		FROM json_each(?2) AS l`
+
+	_, err := q.ExecContext(ctx, query, expenseID, jsonArray(sharing.Shares), sharing.Method)
+	return err
+}
+
+// expenseSharingColumns returns the payer and sharing method columns of an expense, NULL when it is not shared
+func expenseSharingColumns(sharing *domain.ExpenseSharing) (*int, *string) {
+	if sharing == nil {
+		return nil, nil
+	}
+	return &sharing.PaidByID, &sharing.Method
+}
+
+// scanExpense reads an expense selected along with its tags, split lines and sharing
+func scanExpense(row interface{ Scan(dest ...any) error }) (*domain.Expense, error) {
+	expense := &domain.Expense{}
+	err := row.Scan(
+		&expense.ID,
+		&expense.Amount,
+		&expense.CategoryID,
+		&expense.SubCategoryID,
+		&expense.Date,
+		&expense.PayeeID,
+		&expense.AccountID,
+		&expense.Notes,
+		jsonValue{&expense.TagIDs},
+		jsonValue{&expense.Splits},
+		jsonValue{&expense.Sharing},
+		&expense.Version,
+		&expense.CreatedAt,
+		&expense.UpdatedAt,
+		&expense.DeletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return expense, nil
+}
+
+// expenseSharingColumn selects the sharing of each expense as a JSON object, NULL when it is not shared.
+// A subquery loses the JSON subtype of its result, which json() restores so the shares nest as an array.
+const expenseSharingColumn = `CASE WHEN paid_by_id IS NULL THEN NULL ELSE json_object(
+			'paid_by_id', paid_by_id, 'method', sharing_method, 'shares', json(COALESCE((
+				SELECT json_group_array(json_object(
+					'person_id', sh.person_id, 'percentage', sh.percentage, 'amount', sh.amount
+				) ORDER BY sh.line)
+				FROM expense_shares sh WHERE sh.expense_id = expenses.id), '[]'))
+		) END`
+
+// expenseSplitsColumn selects the split lines of each expense as a JSON array, in their original order
+const expenseSplitsColumn = `COALESCE((
+			SELECT json_group_array(json_object(
+				'category_id', s.category_id, 'subcategory_id', s.subcategory_id, 'amount', s.amount, 'notes', s.notes
+			) ORDER BY s.line)
+			FROM expense_splits s WHERE s.expense_id = expenses.id), '[]')`
+
+// expenseTagIDsColumn selects the sorted tag IDs of each expense as a JSON array
+const expenseTagIDsColumn = `(SELECT json_group_array(et.tag_id ORDER BY et.tag_id) FROM expense_tags et WHERE et.expense_id = expenses.id)`
+
+// expenseExistsQuery checks whether an expense exists and is not soft deleted
+const expenseExistsQuery = `SELECT EXISTS (SELECT 1 FROM expenses WHERE id = ?1 AND deleted_at IS NULL)`
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/sqlite"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+type expenseCategoryRepository struct {
+	db *sql.DB
+}
+
+// NewExpenseCategoryRepository creates a new SQLite expense category repository
+func NewExpenseCategoryRepository(db *sql.DB) port.ExpenseCategoryRepository {
+	return &expenseCategoryRepository{
+		db: db,
+	}
+}
+
+func (r *expenseCategoryRepository) Create(ctx context.Context, category *domain.ExpenseCategory) error {
+	query := `
+		INSERT INTO expense_categories (name, aliases, created_at, updated_at)
+		VALUES (?1, ?2, ?3, ?4)
+		RETURNING id, version`
+
+	err := sqlite.Conn(ctx, r.db).QueryRowContext(ctx, query, category.Name, jsonArray(category.Aliases), category.CreatedAt, category.UpdatedAt).Scan(&category.ID, &category.Version)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *expenseCategoryRepository) GetByID(ctx context.Context, id int) (*domain.ExpenseCategory, error) {
+	query := `
+		SELECT id, name, aliases, version, created_at, updated_at, deleted_at
+		FROM expense_categories
+		WHERE id = ?1 AND deleted_at IS NULL`
+
+	category := &domain.ExpenseCategory{}
+	err := sqlite.Conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
+		&category.ID,
+		&category.Name,
+		jsonValue{&category.Aliases},
+		&category.Version,
+		&category.CreatedAt,
+		&category.UpdatedAt,
+		&category.DeletedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return category, nil
+}
+
+// GetByIDForShare selects a category like GetByID,
+// SQLite transactions take the write lock when they begin, so rows cannot change under them
+func (r *expenseCategoryRepository) GetByIDForShare(ctx context.Context, id int) (*domain.ExpenseCategory, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *expenseCategoryRepository) List(ctx context.Context, after *domain.Cursor, limit int, includeDeleted bool) ([]*domain.ExpenseCategory, error) {
+	query := `
+		SELECT id, name, aliases, version, created_at, updated_at, deleted_at
+		FROM expense_categories
+		WHERE (?2 OR deleted_at IS NULL)
+			AND (?3 IS NULL OR (created_at, id) < (?3, ?4))
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?1`
+
+	createdAt, id := cursorCreatedAt(after)
+	rows, err := sqlite.Conn(ctx, r.db).QueryContext(ctx, query, limit, includeDeleted, createdAt, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []*domain.ExpenseCategory
+	for rows.Next() {
+		category := &domain.ExpenseCategory{}
+		err := rows.Scan(
+			&category.ID,
+			&category.Name,
+			jsonValue{&category.Aliases},
+			&category.Version,
+			&category.CreatedAt,
+			&category.UpdatedAt,
+			&category.DeletedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+func (r *expenseCategoryRepository) Count(ctx context.Context, includeDeleted bool) (int64, error) {
+	query := `SELECT COUNT(*) FROM expense_categories WHERE ?1 OR deleted_at IS NULL`
+
+	var count int64
+	err := sqlite.Conn(ctx, r.db).QueryRowContext(ctx, query, includeDeleted).Scan(&count)
+	return count, err
+}
+
+func (r *expenseCategoryRepository) Update(ctx context.Context, category *domain.ExpenseCategory) error {
+	query := `
+		UPDATE expense_categories
+		SET name = ?2, aliases = ?3, updated_at = ?4, version = version + 1
+		WHERE id = ?1 AND deleted_at IS NULL AND (?5 = 0 OR version = ?5)
+		RETURNING version`
+
+	conn := sqlite.Conn(ctx, r.db)
+	err := conn.QueryRowContext(ctx, query, category.ID, category.Name, jsonArray(category.Aliases), category.UpdatedAt, category.Version).Scan(&category.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return staleOrMissing(ctx, conn, expenseCategoryExistsQuery, category.ID)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *expenseCategoryRepository) Delete(ctx context.Context, id int, version int) error {
+	query := `
+		UPDATE expense_categories SET deleted_at = ?3, version = version + 1
+		WHERE id = ?1 AND deleted_at IS NULL AND (?2 = 0 OR version = ?2)`
+
+	conn := sqlite.Conn(ctx, r.db)
+	affected, err := rowsAffected(conn.ExecContext(ctx, query, id, version, time.Now()))
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return staleOrMissing(ctx, conn, expenseCategoryExistsQuery, id)
+	}
+
+	return nil
+}
+
+func (r *expenseCategoryRepository) Restore(ctx context.Context, id int) error {
+	query := `UPDATE expense_categories SET deleted_at = NULL, updated_at = ?2, version = version + 1 WHERE id = ?1 AND deleted_at IS NOT NULL`
+
+	affected, err := rowsAffected(sqlite.Conn(ctx, r.db).ExecContext(ctx, query, id, time.Now()))
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return domain.ErrDataNotFound
+	}
+
+	return nil
+}
+
+func (r *expenseCategoryRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	// Categories still referenced by expenses, split lines or subcategories are kept until those are purged
+	query := `
+		DELETE FROM expense_categories
+		WHERE deleted_at < ?1
+			AND NOT EXISTS (SELECT 1 FROM expenses e WHERE e.category_id = expense_categories.id)
+			AND NOT EXISTS (SELECT 1 FROM expense_splits es WHERE es.category_id = expense_categories.id)
+			AND NOT EXISTS (SELECT 1 FROM expense_subcategories s WHERE s.expense_category_id = expense_categories.id)`
+
+	return rowsAffected(sqlite.Conn(ctx, r.db).ExecContext(ctx, query, deletedBefore))
+}
+
+// expenseCategoryExistsQuery checks whether an expense category exists and is not soft deleted
+const expenseCategoryExistsQuery = `SELECT EXISTS (SELECT 1 FROM expense_categories WHERE id = ?1 AND deleted_at IS NULL)`
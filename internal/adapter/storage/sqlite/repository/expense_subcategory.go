@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/sqlite"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+type expenseSubCategoryRepository struct {
+	db *sql.DB
+}
+
+// NewExpenseSubCategoryRepository creates a new SQLite expense subcategory repository
+func NewExpenseSubCategoryRepository(db *sql.DB) port.ExpenseSubCategoryRepository {
+	return &expenseSubCategoryRepository{
+		db: db,
+	}
+}
+
+func (r *expenseSubCategoryRepository) Create(ctx context.Context, subcategory *domain.ExpenseSubCategory) error {
+	query := `
+		INSERT INTO expense_subcategories (name, aliases, expense_category_id, created_at, updated_at)
+		VALUES (?1, ?2, ?3, ?4, ?5)
+		RETURNING id, version`
+
+	err := sqlite.Conn(ctx, r.db).QueryRowContext(ctx, query, subcategory.Name, jsonArray(subcategory.Aliases), subcategory.ExpenseCategoryID, subcategory.CreatedAt, subcategory.UpdatedAt).Scan(&subcategory.ID, &subcategory.Version)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *expenseSubCategoryRepository) GetByID(ctx context.Context, id int) (*domain.ExpenseSubCategory, error) {
+	query := `
+		SELECT id, name, aliases, expense_category_id, version, created_at, updated_at, deleted_at
+		FROM expense_subcategories
+		WHERE id = ?1 AND deleted_at IS NULL`
+
+	subcategory := &domain.ExpenseSubCategory{}
+	err := sqlite.Conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
+		&subcategory.ID,
+		&subcategory.Name,
+		jsonValue{&subcategory.Aliases},
+		&subcategory.ExpenseCategoryID,
+		&subcategory.Version,
+		&subcategory.CreatedAt,
+		&subcategory.UpdatedAt,
+		&subcategory.DeletedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return subcategory, nil
+}
+
+// GetByIDForShare selects a subcategory like GetByID,
+// SQLite transactions take the write lock when they begin, so rows cannot change under them
+func (r *expenseSubCategoryRepository) GetByIDForShare(ctx context.Context, id int) (*domain.ExpenseSubCategory, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *expenseSubCategoryRepository) List(ctx context.Context, after *domain.Cursor, limit int, expenseCategoryID *int, includeDeleted bool) ([]*domain.ExpenseSubCategory, error) {
+	query := `
+		SELECT id, name, aliases, expense_category_id, version, created_at, updated_at, deleted_at
+		FROM expense_subcategories
+		WHERE (?2 IS NULL OR expense_category_id = ?2) AND (?3 OR deleted_at IS NULL)
+			AND (?4 IS NULL OR (created_at, id) < (?4, ?5))
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?1`
+
+	createdAt, id := cursorCreatedAt(after)
+	rows, err := sqlite.Conn(ctx, r.db).QueryContext(ctx, query, limit, expenseCategoryID, includeDeleted, createdAt, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subcategories []*domain.ExpenseSubCategory
+	for rows.Next() {
+		subcategory := &domain.ExpenseSubCategory{}
+		err := rows.Scan(
+			&subcategory.ID,
+			&subcategory.Name,
+			jsonValue{&subcategory.Aliases},
+			&subcategory.ExpenseCategoryID,
+			&subcategory.Version,
+			&subcategory.CreatedAt,
+			&subcategory.UpdatedAt,
+			&subcategory.DeletedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		subcategories = append(subcategories, subcategory)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return subcategories, nil
+}
+
+func (r *expenseSubCategoryRepository) Count(ctx context.Context, expenseCategoryID *int, includeDeleted bool) (int64, error) {
+	query := `
+		SELECT COUNT(*) FROM expense_subcategories
+		WHERE (?1 IS NULL OR expense_category_id = ?1) AND (?2 OR deleted_at IS NULL)`
+
+	var count int64
+	err := sqlite.Conn(ctx, r.db).QueryRowContext(ctx, query, expenseCategoryID, includeDeleted).Scan(&count)
+	return count, err
+}
+
+func (r *expenseSubCategoryRepository) Update(ctx context.Context, subcategory *domain.ExpenseSubCategory) error {
+	query := `
+		UPDATE expense_subcategories
+		SET name = ?2, aliases = ?3, expense_category_id = ?4, updated_at = ?5, version = version + 1
+		WHERE id = ?1 AND deleted_at IS NULL AND (?6 = 0 OR version = ?6)
+		RETURNING version`
+
+	conn := sqlite.Conn(ctx, r.db)
+	err := conn.QueryRowContext(ctx, query, subcategory.ID, subcategory.Name, jsonArray(subcategory.Aliases), subcategory.ExpenseCategoryID, subcategory.UpdatedAt, subcategory.Version).Scan(&subcategory.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return staleOrMissing(ctx, conn, expenseSubCategoryExistsQuery, subcategory.ID)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *expenseSubCategoryRepository) Delete(ctx context.Context, id int, version int) error {
+	query := `
+		UPDATE expense_subcategories SET deleted_at = ?3, version = version + 1
+		WHERE id = ?1 AND deleted_at IS NULL AND (?2 = 0 OR version = ?2)`
+
+	conn := sqlite.Conn(ctx, r.db)
+	affected, err := rowsAffected(conn.ExecContext(ctx, query, id, version, time.Now()))
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return staleOrMissing(ctx, conn, expenseSubCategoryExistsQuery, id)
+	}
+
+	return nil
+}
+
+func (r *expenseSubCategoryRepository) Restore(ctx context.Context, id int) error {
+	query := `UPDATE expense_subcategories SET deleted_at = NULL, updated_at = ?2, version = version + 1 WHERE id = ?1 AND deleted_at IS NOT NULL`
+
+	affected, err := rowsAffected(sqlite.Conn(ctx, r.db).ExecContext(ctx, query, id, time.Now()))
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return domain.ErrDataNotFound
+	}
+
+	return nil
+}
+
+func (r *expenseSubCategoryRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	// Purged subcategories are detached from remaining expenses by the ON DELETE SET NULL constraint
+	query := `DELETE FROM expense_subcategories WHERE deleted_at < ?1`
+
+	return rowsAffected(sqlite.Conn(ctx, r.db).ExecContext(ctx, query, deletedBefore))
+}
+
+// expenseSubCategoryExistsQuery checks whether an expense subcategory exists and is not soft deleted
+const expenseSubCategoryExistsQuery = `SELECT EXISTS (SELECT 1 FROM expense_subcategories WHERE id = ?1 AND deleted_at IS NULL)`
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/sqlite"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+type idempotencyRepository struct {
+	db *sql.DB
+}
+
+// NewIdempotencyRepository creates a new SQLite idempotency repository
+func NewIdempotencyRepository(db *sql.DB) port.IdempotencyRepository {
+	return &idempotencyRepository{
+		db: db,
+	}
+}
+
+func (r *idempotencyRepository) Reserve(ctx context.Context, record *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+	// An expired record is taken over as if the key had never been used
+	query := `
+		INSERT INTO idempotency_keys (key, scope, request_hash, created_at, expires_at)
+		VALUES (?1, ?2, ?3, ?4, ?5)
+		ON CONFLICT (key, scope) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash, status_code = NULL, headers = NULL, body = NULL,
+			created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= EXCLUDED.created_at`
+
+	conn := sqlite.Conn(ctx, r.db)
+	affected, err := rowsAffected(conn.ExecContext(ctx, query, record.Key, record.Scope, record.RequestHash, record.CreatedAt, record.ExpiresAt))
+	if err != nil {
+		return nil, err
+	}
+	if affected == 1 {
+		return nil, nil
+	}
+
+	existing := &domain.IdempotencyRecord{}
+	var statusCode *int
+	err = conn.QueryRowContext(ctx, `
+		SELECT key, scope, request_hash, status_code, headers, body, created_at, expires_at
+		FROM idempotency_keys
+		WHERE key = ?1 AND scope = ?2`,
+		record.Key, record.Scope,
+	).Scan(
+		&existing.Key,
+		&existing.Scope,
+		&existing.RequestHash,
+		&statusCode,
+		jsonValue{&existing.Headers},
+		&existing.Body,
+		&existing.CreatedAt,
+		&existing.ExpiresAt,
+	)
+	if err != nil {
+		// The holder released the key in the meantime, the client can simply retry
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrIdempotencyRequestInProgress
+		}
+		return nil, err
+	}
+	if statusCode != nil {
+		existing.StatusCode = *statusCode
+	}
+
+	return existing, nil
+}
+
+func (r *idempotencyRepository) Complete(ctx context.Context, record *domain.IdempotencyRecord) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = ?3, headers = ?4, body = ?5
+		WHERE key = ?1 AND scope = ?2`
+
+	affected, err := rowsAffected(sqlite.Conn(ctx, r.db).ExecContext(ctx, query, record.Key, record.Scope, record.StatusCode, jsonValue{record.Headers}, record.Body))
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return domain.ErrDataNotFound
+	}
+
+	return nil
+}
+
+func (r *idempotencyRepository) Release(ctx context.Context, key, scope string) error {
+	query := `DELETE FROM idempotency_keys WHERE key = ?1 AND scope = ?2 AND status_code IS NULL`
+
+	_, err := sqlite.Conn(ctx, r.db).ExecContext(ctx, query, key, scope)
+	return err
+}
+
+func (r *idempotencyRepository) PurgeExpired(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	query := `DELETE FROM idempotency_keys WHERE expires_at < ?1`
+
+	return rowsAffected(sqlite.Conn(ctx, r.db).ExecContext(ctx, query, expiredBefore))
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// jsonValue binds its value as JSON text, and scans JSON text into its value, which must then be a pointer.
+// A value encoding to null is bound as NULL, and NULL leaves the value untouched when scanned.
+type jsonValue struct {
+	v any
+}
+
+func (j jsonValue) Value() (driver.Value, error) {
+	data, err := json.Marshal(j.v)
+	if err != nil {
+		return nil, err
+	}
+	if string(data) == "null" {
+		return nil, nil
+	}
+	return string(data), nil
+}
+
+func (j jsonValue) Scan(src any) error {
+	switch data := src.(type) {
+	case nil:
+		return nil
+	case string:
+		return json.Unmarshal([]byte(data), j.v)
+	case []byte:
+		return json.Unmarshal(data, j.v)
+	}
+	return fmt.Errorf("cannot scan %T as JSON", src)
+}
+
+// jsonArray binds a slice as a JSON array, so queries can read it with json_each
+func jsonArray[T any](values []T) jsonValue {
+	if values == nil {
+		values = []T{}
+	}
+	return jsonValue{values}
+}
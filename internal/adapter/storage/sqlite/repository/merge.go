@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/sqlite"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+type mergeRepository struct {
+	db *sql.DB
+}
+
+// NewMergeRepository creates a new SQLite merge repository
+func NewMergeRepository(db *sql.DB) port.MergeRepository {
+	return &mergeRepository{
+		db: db,
+	}
+}
+
+func (r *mergeRepository) Create(ctx context.Context, merge *domain.Merge) error {
+	query := `
+		INSERT INTO merges (entity_type, source_id, target_id, source, expense_count, created_at)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+		RETURNING id`
+
+	return sqlite.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		merge.EntityType,
+		merge.SourceID,
+		merge.TargetID,
+		jsonValue{merge.Source},
+		merge.ExpenseCount,
+		merge.CreatedAt,
+	).Scan(&merge.ID)
+}
+
+func (r *mergeRepository) List(ctx context.Context, after *domain.Cursor, limit int, filters port.MergeFilters) ([]*domain.Merge, error) {
+	query := `
+		SELECT id, entity_type, source_id, target_id, source, expense_count, created_at
+		FROM merges
+		WHERE (?2 IS NULL OR id > ?2) AND (?3 IS NULL OR entity_type = ?3) AND (?4 IS NULL OR target_id = ?4)
+		ORDER BY id
+		LIMIT ?1`
+
+	rows, err := sqlite.Conn(ctx, r.db).QueryContext(ctx, query, limit, cursorID(after), filters.EntityType, filters.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var merges []*domain.Merge
+	for rows.Next() {
+		merge := &domain.Merge{}
+		err := rows.Scan(
+			&merge.ID,
+			&merge.EntityType,
+			&merge.SourceID,
+			&merge.TargetID,
+			jsonValue{&merge.Source},
+			&merge.ExpenseCount,
+			&merge.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		merges = append(merges, merge)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return merges, nil
+}
+
+func (r *mergeRepository) Count(ctx context.Context, filters port.MergeFilters) (int64, error) {
+	query := `
+		SELECT COUNT(*) FROM merges
+		WHERE (?1 IS NULL OR entity_type = ?1) AND (?2 IS NULL OR target_id = ?2)`
+
+	var count int64
+	err := sqlite.Conn(ctx, r.db).QueryRowContext(ctx, query, filters.EntityType, filters.TargetID).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/sqlite"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+type payeeRepository struct {
+	db *sql.DB
+}
+
+// NewPayeeRepository creates a new SQLite payee repository
+func NewPayeeRepository(db *sql.DB) port.PayeeRepository {
+	return &payeeRepository{
+		db: db,
+	}
+}
+
+func (r *payeeRepository) Create(ctx context.Context, payee *domain.Payee) error {
+	query := `
+		INSERT INTO payees (name, aliases, default_category_id, default_subcategory_id, person_id, created_at, updated_at)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7)
+		RETURNING id, version`
+
+	err := sqlite.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		payee.Name,
+		jsonArray(payee.Aliases),
+		payee.DefaultCategoryID,
+		payee.DefaultSubCategoryID,
+		payee.PersonID,
+		payee.CreatedAt,
+		payee.UpdatedAt,
+	).Scan(&payee.ID, &payee.Version)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *payeeRepository) GetByID(ctx context.Context, id int) (*domain.Payee, error) {
+	query := `
+		SELECT id, name, aliases, default_category_id, default_subcategory_id, person_id, version, created_at, updated_at
+		FROM payees
+		WHERE id = ?1`
+
+	payee, err := scanPayee(sqlite.Conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return payee, nil
+}
+
+func (r *payeeRepository) GetByName(ctx context.Context, name string) (*domain.Payee, error) {
+	query := `
+		SELECT id, name, aliases, default_category_id, default_subcategory_id, person_id, version, created_at, updated_at
+		FROM payees
+		WHERE LOWER(name) = LOWER(?1)`
+
+	payee, err := scanPayee(sqlite.Conn(ctx, r.db).QueryRowContext(ctx, query, name))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return payee, nil
+}
+
+func (r *payeeRepository) List(ctx context.Context, after *domain.Cursor, limit int, search string) ([]*domain.Payee, error) {
+	query := `
+		SELECT id, name, aliases, default_category_id, default_subcategory_id, person_id, version, created_at, updated_at
+		FROM payees
+		WHERE (?2 IS NULL OR id > ?2) AND ` + payeeSearchCondition("?3") + `
+		ORDER BY id
+		LIMIT ?1`
+
+	rows, err := sqlite.Conn(ctx, r.db).QueryContext(ctx, query, limit, cursorID(after), payeeSearchPattern(search))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payees []*domain.Payee
+	for rows.Next() {
+		payee, err := scanPayee(rows)
+		if err != nil {
+			return nil, err
+		}
+		payees = append(payees, payee)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return payees, nil
+}
+
+func (r *payeeRepository) Count(ctx context.Context, search string) (int64, error) {
+	query := `SELECT COUNT(*) FROM payees WHERE ` + payeeSearchCondition("?1")
+
+	var count int64
+	err := sqlite.Conn(ctx, r.db).QueryRowContext(ctx, query, payeeSearchPattern(search)).Scan(&count)
+	return count, err
+}
+
+func (r *payeeRepository) Update(ctx context.Context, payee *domain.Payee) error {
+	query := `
+		UPDATE payees
+		SET name = ?2, aliases = ?3, default_category_id = ?4, default_subcategory_id = ?5, person_id = ?6,
+			updated_at = ?7, version = version + 1
+		WHERE id = ?1 AND (?8 = 0 OR version = ?8)
+		RETURNING version`
+
+	conn := sqlite.Conn(ctx, r.db)
+	err := conn.QueryRowContext(ctx, query,
+		payee.ID,
+		payee.Name,
+		jsonArray(payee.Aliases),
+		payee.DefaultCategoryID,
+		payee.DefaultSubCategoryID,
+		payee.PersonID,
+		payee.UpdatedAt,
+		payee.Version,
+	).Scan(&payee.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return staleOrMissing(ctx, conn, payeeExistsQuery, payee.ID)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *payeeRepository) Delete(ctx context.Context, id int, version int) error {
+	query := `DELETE FROM payees WHERE id = ?1 AND (?2 = 0 OR version = ?2)`
+
+	conn := sqlite.Conn(ctx, r.db)
+	affected, err := rowsAffected(conn.ExecContext(ctx, query, id, version))
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return staleOrMissing(ctx, conn, payeeExistsQuery, id)
+	}
+
+	return nil
+}
+
+func (r *payeeRepository) ReassignDefaultCategory(ctx context.Context, fromID, toID int) error {
+	query := `
+		UPDATE payees SET default_category_id = ?2, updated_at = ?3, version = version + 1
+		WHERE default_category_id = ?1`
+
+	_, err := sqlite.Conn(ctx, r.db).ExecContext(ctx, query, fromID, toID, time.Now())
+	return err
+}
+
+func (r *payeeRepository) ReassignDefaultSubCategory(ctx context.Context, fromID, toID, toCategoryID int) error {
+	query := `
+		UPDATE payees SET default_subcategory_id = ?2, default_category_id = ?3, updated_at = ?4, version = version + 1
+		WHERE default_subcategory_id = ?1`
+
+	_, err := sqlite.Conn(ctx, r.db).ExecContext(ctx, query, fromID, toID, toCategoryID, time.Now())
+	return err
+}
+
+// payeeExistsQuery checks whether a payee exists
+const payeeExistsQuery = `SELECT EXISTS (SELECT 1 FROM payees WHERE id = ?1)`
+
+// payeeSearchCondition matches payees whose name or an alias matches the pattern parameter, an empty pattern matches every payee.
+// LIKE ignores the case of ASCII letters only, unlike the ILIKE of PostgreSQL.
+func payeeSearchCondition(param string) string {
+	return `(` + param + ` = '' OR name LIKE ` + param + ` ESCAPE '\' OR EXISTS (SELECT 1 FROM json_each(aliases) AS alias WHERE alias.value LIKE ` + param + ` ESCAPE '\'))`
+}
+
+// payeeSearchPattern turns a search text into a LIKE pattern matching it anywhere, escaping the wildcards it contains
+func payeeSearchPattern(search string) string {
+	if search == "" {
+		return ""
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(search)
+	return "%" + escaped + "%"
+}
+
+// scanPayee reads a payee from a row, aliases never come back nil
+func scanPayee(row interface{ Scan(dest ...any) error }) (*domain.Payee, error) {
+	payee := &domain.Payee{}
+	err := row.Scan(
+		&payee.ID,
+		&payee.Name,
+		jsonValue{&payee.Aliases},
+		&payee.DefaultCategoryID,
+		&payee.DefaultSubCategoryID,
+		&payee.PersonID,
+		&payee.Version,
+		&payee.CreatedAt,
+		&payee.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if payee.Aliases == nil {
+		payee.Aliases = []string{}
+	}
+	return payee, nil
+}
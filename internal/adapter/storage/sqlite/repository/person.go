@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/sqlite"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+type personRepository struct {
+	db *sql.DB
+}
+
+// NewPersonRepository creates a new SQLite person repository
+func NewPersonRepository(db *sql.DB) port.PersonRepository {
+	return &personRepository{
+		db: db,
+	}
+}
+
+// CreatePerson inserts a new Person into the repository
+func (r *personRepository) CreatePerson(ctx context.Context, person *domain.Person) (*domain.Person, error) {
+	query := `
+		INSERT INTO person (name, email, created_at, updated_at)
+		VALUES (?1, ?2, ?3, ?4)
+		RETURNING id, version, created_at, updated_at
+	`
+
+	now := time.Now()
+	err := sqlite.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		person.Name,
+		person.Email,
+		now,
+		now,
+	).Scan(&person.ID, &person.Version, &person.CreatedAt, &person.UpdatedAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return person, nil
+}
+
+// GetPersonByID selects a Person by id
+func (r *personRepository) GetPersonByID(ctx context.Context, id uint64) (*domain.Person, error) {
+	query := `
+		SELECT id, name, email, version, created_at, updated_at
+		FROM person
+		WHERE id = ?1
+	`
+
+	return r.get(ctx, query, id)
+}
+
+// GetPersonByIDForShare selects a Person like GetPersonByID,
+// SQLite transactions take the write lock when they begin, so rows cannot change under them
+func (r *personRepository) GetPersonByIDForShare(ctx context.Context, id uint64) (*domain.Person, error) {
+	return r.GetPersonByID(ctx, id)
+}
+
+// GetPersonByEmail selects a Person by email
+func (r *personRepository) GetPersonByEmail(ctx context.Context, email string) (*domain.Person, error) {
+	query := `
+		SELECT id, name, email, version, created_at, updated_at
+		FROM person
+		WHERE email = ?1
+	`
+
+	return r.get(ctx, query, email)
+}
+
+// ListPersons selects up to limit Persons ordered by id, starting after the cursor when given
+func (r *personRepository) ListPersons(ctx context.Context, after *domain.Cursor, limit int) ([]domain.Person, error) {
+	query := `
+		SELECT id, name, email, version, created_at, updated_at
+		FROM person
+		WHERE ?2 IS NULL OR id > ?2
+		ORDER BY id
+		LIMIT ?1
+	`
+
+	rows, err := sqlite.Conn(ctx, r.db).QueryContext(ctx, query, limit, cursorID(after))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var persons []domain.Person
+	for rows.Next() {
+		var person domain.Person
+		err := rows.Scan(
+			&person.ID,
+			&person.Name,
+			&person.Email,
+			&person.Version,
+			&person.CreatedAt,
+			&person.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		persons = append(persons, person)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return persons, nil
+}
+
+// CountPersons counts every Person
+func (r *personRepository) CountPersons(ctx context.Context) (int64, error) {
+	var count int64
+	err := sqlite.Conn(ctx, r.db).QueryRowContext(ctx, `SELECT COUNT(*) FROM person`).Scan(&count)
+	return count, err
+}
+
+// UpdatePerson updates a Person whose version matches Person.Version, 0 skips the check
+func (r *personRepository) UpdatePerson(ctx context.Context, person *domain.Person) (*domain.Person, error) {
+	query := `
+		UPDATE person
+		SET name = ?1, email = ?2, updated_at = ?3, version = version + 1
+		WHERE id = ?4 AND (?5 = 0 OR version = ?5)
+		RETURNING id, name, email, version, created_at, updated_at
+	`
+
+	updatedPerson := &domain.Person{}
+	conn := sqlite.Conn(ctx, r.db)
+	err := conn.QueryRowContext(ctx, query,
+		person.Name,
+		person.Email,
+		time.Now(),
+		person.ID,
+		person.Version,
+	).Scan(
+		&updatedPerson.ID,
+		&updatedPerson.Name,
+		&updatedPerson.Email,
+		&updatedPerson.Version,
+		&updatedPerson.CreatedAt,
+		&updatedPerson.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, staleOrMissing(ctx, conn, personExistsQuery, person.ID)
+		}
+		return nil, err
+	}
+
+	return updatedPerson, nil
+}
+
+// DeletePerson deletes a Person whose version matches, 0 skips the check
+func (r *personRepository) DeletePerson(ctx context.Context, id uint64, version int) error {
+	query := `DELETE FROM person WHERE id = ?1 AND (?2 = 0 OR version = ?2)`
+
+	conn := sqlite.Conn(ctx, r.db)
+	affected, err := rowsAffected(conn.ExecContext(ctx, query, id, version))
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return staleOrMissing(ctx, conn, personExistsQuery, id)
+	}
+
+	return nil
+}
+
+// get selects a single Person
+func (r *personRepository) get(ctx context.Context, query string, args ...any) (*domain.Person, error) {
+	person := &domain.Person{}
+	err := sqlite.Conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(
+		&person.ID,
+		&person.Name,
+		&person.Email,
+		&person.Version,
+		&person.CreatedAt,
+		&person.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return person, nil
+}
+
+// personExistsQuery checks whether a Person exists
+const personExistsQuery = `SELECT EXISTS (SELECT 1 FROM person WHERE id = ?1)`
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/sqlite"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+type savedViewRepository struct {
+	db *sql.DB
+}
+
+// NewSavedViewRepository creates a new SQLite saved view repository
+func NewSavedViewRepository(db *sql.DB) port.SavedViewRepository {
+	return &savedViewRepository{
+		db: db,
+	}
+}
+
+func (r *savedViewRepository) Create(ctx context.Context, view *domain.SavedView) error {
+	query := `
+		INSERT INTO saved_views (name, filters, created_at, updated_at)
+		VALUES (?1, ?2, ?3, ?4)
+		RETURNING id, version`
+
+	err := sqlite.Conn(ctx, r.db).QueryRowContext(ctx, query, view.Name, jsonValue{view.Filters}, view.CreatedAt, view.UpdatedAt).Scan(&view.ID, &view.Version)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *savedViewRepository) GetByID(ctx context.Context, id int) (*domain.SavedView, error) {
+	query := `
+		SELECT id, name, filters, version, created_at, updated_at
+		FROM saved_views
+		WHERE id = ?1`
+
+	view := &domain.SavedView{}
+	err := sqlite.Conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
+		&view.ID,
+		&view.Name,
+		jsonValue{&view.Filters},
+		&view.Version,
+		&view.CreatedAt,
+		&view.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return view, nil
+}
+
+func (r *savedViewRepository) List(ctx context.Context, after *domain.Cursor, limit int) ([]*domain.SavedView, error) {
+	query := `
+		SELECT id, name, filters, version, created_at, updated_at
+		FROM saved_views
+		WHERE ?2 IS NULL OR id > ?2
+		ORDER BY id
+		LIMIT ?1`
+
+	rows, err := sqlite.Conn(ctx, r.db).QueryContext(ctx, query, limit, cursorID(after))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var views []*domain.SavedView
+	for rows.Next() {
+		view := &domain.SavedView{}
+		err := rows.Scan(
+			&view.ID,
+			&view.Name,
+			jsonValue{&view.Filters},
+			&view.Version,
+			&view.CreatedAt,
+			&view.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, view)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return views, nil
+}
+
+func (r *savedViewRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := sqlite.Conn(ctx, r.db).QueryRowContext(ctx, `SELECT COUNT(*) FROM saved_views`).Scan(&count)
+	return count, err
+}
+
+func (r *savedViewRepository) Update(ctx context.Context, view *domain.SavedView) error {
+	query := `
+		UPDATE saved_views
+		SET name = ?2, filters = ?3, updated_at = ?4, version = version + 1
+		WHERE id = ?1 AND (?5 = 0 OR version = ?5)
+		RETURNING version`
+
+	conn := sqlite.Conn(ctx, r.db)
+	err := conn.QueryRowContext(ctx, query, view.ID, view.Name, jsonValue{view.Filters}, view.UpdatedAt, view.Version).Scan(&view.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return staleOrMissing(ctx, conn, savedViewExistsQuery, view.ID)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *savedViewRepository) Delete(ctx context.Context, id int, version int) error {
+	query := `DELETE FROM saved_views WHERE id = ?1 AND (?2 = 0 OR version = ?2)`
+
+	conn := sqlite.Conn(ctx, r.db)
+	affected, err := rowsAffected(conn.ExecContext(ctx, query, id, version))
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return staleOrMissing(ctx, conn, savedViewExistsQuery, id)
+	}
+
+	return nil
+}
+
+// savedViewExistsQuery checks whether a saved view exists
+const savedViewExistsQuery = `SELECT EXISTS (SELECT 1 FROM saved_views WHERE id = ?1)`
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/sqlite"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+type tagRepository struct {
+	db *sql.DB
+}
+
+// NewTagRepository creates a new SQLite tag repository
+func NewTagRepository(db *sql.DB) port.TagRepository {
+	return &tagRepository{
+		db: db,
+	}
+}
+
+func (r *tagRepository) Create(ctx context.Context, tag *domain.Tag) error {
+	query := `
+		INSERT INTO tags (name, created_at, updated_at)
+		VALUES (?1, ?2, ?3)
+		RETURNING id, version`
+
+	err := sqlite.Conn(ctx, r.db).QueryRowContext(ctx, query, tag.Name, tag.CreatedAt, tag.UpdatedAt).Scan(&tag.ID, &tag.Version)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *tagRepository) GetByID(ctx context.Context, id int) (*domain.Tag, error) {
+	query := `
+		SELECT id, name, version, created_at, updated_at
+		FROM tags
+		WHERE id = ?1`
+
+	tag := &domain.Tag{}
+	err := sqlite.Conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
+		&tag.ID,
+		&tag.Name,
+		&tag.Version,
+		&tag.CreatedAt,
+		&tag.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+func (r *tagRepository) GetByName(ctx context.Context, name string) (*domain.Tag, error) {
+	query := `
+		SELECT id, name, version, created_at, updated_at
+		FROM tags
+		WHERE LOWER(name) = LOWER(?1)`
+
+	tag := &domain.Tag{}
+	err := sqlite.Conn(ctx, r.db).QueryRowContext(ctx, query, name).Scan(
+		&tag.ID,
+		&tag.Name,
+		&tag.Version,
+		&tag.CreatedAt,
+		&tag.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+func (r *tagRepository) List(ctx context.Context, after *domain.Cursor, limit int) ([]*domain.Tag, error) {
+	query := `
+		SELECT id, name, version, created_at, updated_at
+		FROM tags
+		WHERE ?2 IS NULL OR id > ?2
+		ORDER BY id
+		LIMIT ?1`
+
+	rows, err := sqlite.Conn(ctx, r.db).QueryContext(ctx, query, limit, cursorID(after))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []*domain.Tag
+	for rows.Next() {
+		tag := &domain.Tag{}
+		err := rows.Scan(
+			&tag.ID,
+			&tag.Name,
+			&tag.Version,
+			&tag.CreatedAt,
+			&tag.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+func (r *tagRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := sqlite.Conn(ctx, r.db).QueryRowContext(ctx, `SELECT COUNT(*) FROM tags`).Scan(&count)
+	return count, err
+}
+
+func (r *tagRepository) Update(ctx context.Context, tag *domain.Tag) error {
+	query := `
+		UPDATE tags
+		SET name = ?2, updated_at = ?3, version = version + 1
+		WHERE id = ?1 AND (?4 = 0 OR version = ?4)
+		RETURNING version`
+
+	conn := sqlite.Conn(ctx, r.db)
+	err := conn.QueryRowContext(ctx, query, tag.ID, tag.Name, tag.UpdatedAt, tag.Version).Scan(&tag.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return staleOrMissing(ctx, conn, tagExistsQuery, tag.ID)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *tagRepository) Delete(ctx context.Context, id int, version int) error {
+	query := `DELETE FROM tags WHERE id = ?1 AND (?2 = 0 OR version = ?2)`
+
+	conn := sqlite.Conn(ctx, r.db)
+	affected, err := rowsAffected(conn.ExecContext(ctx, query, id, version))
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return staleOrMissing(ctx, conn, tagExistsQuery, id)
+	}
+
+	return nil
+}
+
+// tagExistsQuery checks whether a tag exists
+const tagExistsQuery = `SELECT EXISTS (SELECT 1 FROM tags WHERE id = ?1)`
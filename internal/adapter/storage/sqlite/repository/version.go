@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/sqlite"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+)
+
+// staleOrMissing tells apart a row that no longer exists from one whose version changed,
+// once a version-guarded update or delete has matched no rows
+func staleOrMissing(ctx context.Context, q sqlite.Querier, existsQuery string, id any) error {
+	var exists bool
+	if err := q.QueryRowContext(ctx, existsQuery, id).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrDataNotFound
+	}
+	return domain.ErrPreconditionFailed
+}
@@ -0,0 +1,164 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/tracing"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Querier is the set of query methods shared by the database and transactions, as returned by Conn
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) Row
+}
+
+// Rows is the result of a query, read like sql.Rows
+type Rows interface {
+	Next() bool
+	Scan(dest ...any) error
+	Close() error
+	Err() error
+}
+
+// Row is the result of a query selecting a single row, read like sql.Row
+type Row interface {
+	Scan(dest ...any) error
+	Err() error
+}
+
+// sqlQuerier is the set of query methods shared by sql.DB and sql.Tx
+type sqlQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// txContextKey is the context key under which the active transaction is stored
+type txContextKey struct{}
+
+// tx is a transaction along with the number of savepoints opened within it
+type tx struct {
+	*sql.Tx
+	savepoints int
+}
+
+// Conn returns the transaction carried by ctx, falling back to the database when there is none.
+// Constraint violations of its statements are translated into domain errors by MapError.
+func Conn(ctx context.Context, db *sql.DB) Querier {
+	if tx, ok := ctx.Value(txContextKey{}).(*tx); ok {
+		return utcQuerier{tx.Tx}
+	}
+	return utcQuerier{db}
+}
+
+// utcQuerier binds times in UTC and translates the errors of the statements with MapError.
+// SQLite keeps times as text, which only sorts and compares chronologically when every time
+// is written with the same offset. Each statement is traced in a span ended once its rows are read.
+type utcQuerier struct {
+	q sqlQuerier
+}
+
+func (u utcQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, span := tracing.StartQuery(ctx, semconv.DBSystemSqlite, query)
+	result, err := u.q.ExecContext(ctx, query, utc(args)...)
+	var affected int64
+	if err == nil {
+		affected, _ = result.RowsAffected()
+	}
+	tracing.EndQuery(span, affected, err)
+	return result, mapError(err, isDelete(query))
+}
+
+func (u utcQuerier) QueryContext(ctx context.Context, query string, args ...any) (Rows, error) {
+	ctx, span := tracing.StartQuery(ctx, semconv.DBSystemSqlite, query)
+	rows, err := u.q.QueryContext(ctx, query, utc(args)...)
+	if err != nil {
+		tracing.EndQuery(span, 0, err)
+		return nil, MapError(err)
+	}
+	return &mappingRows{Rows: rows, span: span}, nil
+}
+
+func (u utcQuerier) QueryRowContext(ctx context.Context, query string, args ...any) Row {
+	ctx, span := tracing.StartQuery(ctx, semconv.DBSystemSqlite, query)
+	return mappingRow{u.q.QueryRowContext(ctx, query, utc(args)...), span}
+}
+
+// utc returns the arguments with their times converted to UTC
+func utc(args []any) []any {
+	converted := make([]any, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case time.Time:
+			converted[i] = v.UTC()
+		case *time.Time:
+			if v != nil {
+				converted[i] = v.UTC()
+			}
+		default:
+			converted[i] = arg
+		}
+	}
+	return converted
+}
+
+// TxManager runs units of work inside a SQLite transaction
+type TxManager struct {
+	db *sql.DB
+}
+
+// NewTxManager creates a new SQLite transaction manager
+func NewTxManager(db *DB) *TxManager {
+	return &TxManager{
+		db: db.DB,
+	}
+}
+
+// WithinTx runs fn inside a transaction. When ctx already carries one, fn runs inside
+// a savepoint of it, so a failing fn only undoes its own work and the caller decides
+// whether the outer transaction still commits.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if outer, ok := ctx.Value(txContextKey{}).(*tx); ok {
+		return outer.withinSavepoint(ctx, fn)
+	}
+
+	sqlTx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// Rollback is a no-op once the transaction has been committed
+	defer sqlTx.Rollback()
+
+	if err := fn(context.WithValue(ctx, txContextKey{}, &tx{Tx: sqlTx})); err != nil {
+		return err
+	}
+
+	return MapError(sqlTx.Commit())
+}
+
+// withinSavepoint runs fn inside a new savepoint of the transaction
+func (t *tx) withinSavepoint(ctx context.Context, fn func(ctx context.Context) error) error {
+	t.savepoints++
+	name := fmt.Sprintf("sp_%d", t.savepoints)
+	defer func() { t.savepoints-- }()
+
+	if _, err := t.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+
+	if err := fn(ctx); err != nil {
+		// Undo the work of fn, then drop the savepoint and carry on with the outer transaction
+		if _, rollbackErr := t.ExecContext(context.WithoutCancel(ctx), "ROLLBACK TO "+name); rollbackErr == nil {
+			t.ExecContext(context.WithoutCancel(ctx), "RELEASE "+name)
+		}
+		return err
+	}
+
+	_, err := t.ExecContext(ctx, "RELEASE "+name)
+	return err
+}
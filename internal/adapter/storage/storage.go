@@ -0,0 +1,213 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/config"
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/memory"
+	memoryrepo "github.com/edwins-leonardi/finaid-api/internal/adapter/storage/memory/repository"
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/postgres"
+	postgresrepo "github.com/edwins-leonardi/finaid-api/internal/adapter/storage/postgres/repository"
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/sqlite"
+	sqliterepo "github.com/edwins-leonardi/finaid-api/internal/adapter/storage/sqlite/repository"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repositories is the full set of repositories of a storage backend, along with its
+// transaction manager and schema migrator
+type Repositories struct {
+	Person             port.PersonRepository
+	Account            port.AccountRepository
+	ExpenseCategory    port.ExpenseCategoryRepository
+	ExpenseSubCategory port.ExpenseSubCategoryRepository
+	Expense            port.ExpenseRepository
+	Tag                port.TagRepository
+	Payee              port.PayeeRepository
+	Merge              port.MergeRepository
+	SavedView          port.SavedViewRepository
+	Settlement         port.SettlementRepository
+	Attachment         port.AttachmentRepository
+	Audit              port.AuditRepository
+	Idempotency        port.IdempotencyRepository
+
+	TxManager port.TxManager
+	Migrator  port.SchemaMigrator
+
+	// Pool is the connection pool of the PostgreSQL backend, nil with the other backends
+	Pool *pgxpool.Pool
+
+	close func() error
+}
+
+// New creates the repositories of the backend selected by the storage configuration
+func New(ctx context.Context, config *config.Storage, db *config.DB, logger *slog.Logger) (*Repositories, error) {
+	switch config.Driver {
+	case "postgres":
+		return newPostgres(ctx, db, logger)
+	case "sqlite":
+		return newSQLite(ctx, config.SQLitePath, logger)
+	case "memory":
+		return newMemory(config.Snapshot, logger)
+	}
+	return nil, errors.New("unknown storage driver " + config.Driver)
+}
+
+// Close releases the backend, saving the snapshot of the memory backend when one is configured
+func (r *Repositories) Close() error {
+	return r.close()
+}
+
+// newPostgres creates the repositories of the PostgreSQL backend
+func newPostgres(ctx context.Context, config *config.DB, logger *slog.Logger) (*Repositories, error) {
+	db, err := postgres.New(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	migrator, err := postgres.NewMigrator(db, logger)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Repositories{
+		Person:             postgresrepo.NewPersonRepository(db.Pool),
+		Account:            postgresrepo.NewAccountRepository(db.Pool),
+		ExpenseCategory:    postgresrepo.NewExpenseCategoryRepository(db.Pool),
+		ExpenseSubCategory: postgresrepo.NewExpenseSubCategoryRepository(db.Pool),
+		Expense:            postgresrepo.NewExpenseRepository(db.Pool),
+		Tag:                postgresrepo.NewTagRepository(db.Pool),
+		Payee:              postgresrepo.NewPayeeRepository(db.Pool),
+		Merge:              postgresrepo.NewMergeRepository(db.Pool),
+		SavedView:          postgresrepo.NewSavedViewRepository(db.Pool),
+		Settlement:         postgresrepo.NewSettlementRepository(db.Pool),
+		Attachment:         postgresrepo.NewAttachmentRepository(db.Pool),
+		Audit:              postgresrepo.NewAuditRepository(db.Pool),
+		Idempotency:        postgresrepo.NewIdempotencyRepository(db.Pool),
+		TxManager:          postgres.NewTxManager(db),
+		Migrator:           migrator,
+		Pool:               db.Pool,
+		close: func() error {
+			db.Close()
+			return nil
+		},
+	}, nil
+}
+
+// newSQLite creates the repositories of the SQLite backend, kept in the database file at path
+func newSQLite(ctx context.Context, path string, logger *slog.Logger) (*Repositories, error) {
+	db, err := sqlite.New(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	migrator, err := sqlite.NewMigrator(db, logger)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Repositories{
+		Person:             sqliterepo.NewPersonRepository(db.DB),
+		Account:            sqliterepo.NewAccountRepository(db.DB),
+		ExpenseCategory:    sqliterepo.NewExpenseCategoryRepository(db.DB),
+		ExpenseSubCategory: sqliterepo.NewExpenseSubCategoryRepository(db.DB),
+		Expense:            sqliterepo.NewExpenseRepository(db.DB),
+		Tag:                sqliterepo.NewTagRepository(db.DB),
+		Payee:              sqliterepo.NewPayeeRepository(db.DB),
+		Merge:              sqliterepo.NewMergeRepository(db.DB),
+		SavedView:          sqliterepo.NewSavedViewRepository(db.DB),
+		Settlement:         sqliterepo.NewSettlementRepository(db.DB),
+		Attachment:         sqliterepo.NewAttachmentRepository(db.DB),
+		Audit:              sqliterepo.NewAuditRepository(db.DB),
+		Idempotency:        sqliterepo.NewIdempotencyRepository(db.DB),
+		TxManager:          sqlite.NewTxManager(db),
+		Migrator:           migrator,
+		close: func() error {
+			db.Close()
+			return nil
+		},
+	}, nil
+}
+
+// newMemory creates the repositories of the in-memory backend, loaded from the snapshot file when
+// there is one. Idempotency keys are short lived and left out of snapshots, and are not rolled back
+// either, like the keys of the other backends which are reserved outside of units of work.
+func newMemory(snapshot string, logger *slog.Logger) (*Repositories, error) {
+	expenses := memoryrepo.NewExpenseRepository()
+	subcategories := memoryrepo.NewExpenseSubCategoryRepository()
+	r := &Repositories{
+		Person:             memoryrepo.NewPersonRepository(),
+		Account:            memoryrepo.NewAccountRepository(expenses),
+		ExpenseCategory:    memoryrepo.NewExpenseCategoryRepository(expenses, subcategories),
+		ExpenseSubCategory: subcategories,
+		Expense:            expenses,
+		Tag:                memoryrepo.NewTagRepository(),
+		Payee:              memoryrepo.NewPayeeRepository(),
+		Merge:              memoryrepo.NewMergeRepository(),
+		SavedView:          memoryrepo.NewSavedViewRepository(),
+		Settlement:         memoryrepo.NewSettlementRepository(),
+		Attachment:         memoryrepo.NewAttachmentRepository(expenses),
+		Audit:              memoryrepo.NewAuditRepository(),
+		Idempotency:        memoryrepo.NewIdempotencyRepository(),
+		Migrator:           memory.NewMigrator(),
+	}
+	r.TxManager = memory.NewTxManager()
+
+	if snapshot == "" {
+		r.close = func() error { return nil }
+		return r, nil
+	}
+
+	repos := []any{r.Person, r.Account, r.ExpenseCategory, r.ExpenseSubCategory, r.Expense, r.Tag,
+		r.Payee, r.Merge, r.SavedView, r.Settlement, r.Attachment, r.Audit}
+	file, err := os.Open(snapshot)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		logger.Info("No storage snapshot yet, starting empty", "snapshot", snapshot)
+	case err != nil:
+		return nil, err
+	default:
+		err = memoryrepo.LoadSnapshot(file, repos...)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Loaded the storage snapshot", "snapshot", snapshot)
+	}
+
+	r.close = func() error {
+		if err := saveSnapshot(snapshot, repos); err != nil {
+			return err
+		}
+		logger.Info("Saved the storage snapshot", "snapshot", snapshot)
+		return nil
+	}
+	return r, nil
+}
+
+// saveSnapshot writes the snapshot next to the previous one and then replaces it,
+// so a failing save never leaves a truncated snapshot behind
+func saveSnapshot(path string, repos []any) error {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if err := memoryrepo.SaveSnapshot(file, repos...); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
@@ -0,0 +1,118 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+)
+
+func testAccountRepository(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	repo := r.Account
+
+	alice := createPerson(t, r, "Alice", "alice@example.com")
+	bob := createPerson(t, r, "Bob", "bob@example.com")
+	checking := createAccount(t, r, "Checking", alice.ID)
+	savings := createAccount(t, r, "Savings", alice.ID)
+	shared := createAccount(t, r, "Shared", bob.ID)
+	if checking.ID == 0 || checking.Version != 1 || checking.CreatedAt.IsZero() {
+		t.Fatalf("created account %+v", checking)
+	}
+
+	t.Run("Get", func(t *testing.T) {
+		account, err := repo.GetAccountByID(ctx, savings.ID)
+		expectNoError(t, "get", err)
+		if account.Name != "Savings" || account.Currency != "EUR" || account.InitialBalance != 100 ||
+			account.PrimaryOwnerID != alice.ID || account.SecondOwnerID != nil || account.DeletedAt != nil {
+			t.Fatalf("got account %+v", account)
+		}
+
+		_, err = repo.GetAccountByID(ctx, shared.ID+100)
+		expectError(t, "get missing account", err, domain.ErrDataNotFound)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		update := *shared
+		update.Name = "Joint"
+		update.SecondOwnerID = &alice.ID
+		updated, err := repo.UpdateAccount(ctx, &update)
+		expectNoError(t, "update", err)
+		if updated.Name != "Joint" || updated.SecondOwnerID == nil || *updated.SecondOwnerID != alice.ID || updated.Version != 2 {
+			t.Fatalf("got updated account %+v", updated)
+		}
+
+		_, err = repo.UpdateAccount(ctx, &update)
+		expectError(t, "update stale version", err, domain.ErrPreconditionFailed)
+		update.ID = shared.ID + 100
+		_, err = repo.UpdateAccount(ctx, &update)
+		expectError(t, "update missing account", err, domain.ErrDataNotFound)
+	})
+
+	t.Run("SoftDelete", func(t *testing.T) {
+		expectError(t, "delete stale version", repo.DeleteAccount(ctx, savings.ID, 2), domain.ErrPreconditionFailed)
+		expectNoError(t, "delete", repo.DeleteAccount(ctx, savings.ID, 1))
+
+		_, err := repo.GetAccountByID(ctx, savings.ID)
+		expectError(t, "get deleted account", err, domain.ErrDataNotFound)
+		_, err = repo.GetAccountByIDForShare(ctx, savings.ID)
+		expectError(t, "get deleted account for share", err, domain.ErrDataNotFound)
+		expectError(t, "delete deleted account", repo.DeleteAccount(ctx, savings.ID, 0), domain.ErrDataNotFound)
+
+		accounts, err := repo.ListAccounts(ctx, nil, 10, false)
+		expectNoError(t, "list", err)
+		expectIDs(t, "list without deleted", idsOf(accounts, accountID), checking.ID, shared.ID)
+		count, err := repo.CountAccounts(ctx, false)
+		expectCount(t, "count without deleted", count, err, 2)
+
+		accounts, err = repo.ListAccounts(ctx, nil, 10, true)
+		expectNoError(t, "list with deleted", err)
+		expectIDs(t, "list with deleted", idsOf(accounts, accountID), checking.ID, savings.ID, shared.ID)
+		if accounts[1].DeletedAt == nil {
+			t.Fatal("deleted account has no deletion time")
+		}
+		count, err = repo.CountAccounts(ctx, true)
+		expectCount(t, "count with deleted", count, err, 3)
+
+		accounts, err = repo.ListAccounts(ctx, &domain.Cursor{ID: checking.ID}, 1, true)
+		expectNoError(t, "list next page", err)
+		expectIDs(t, "next page", idsOf(accounts, accountID), savings.ID)
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		expectNoError(t, "restore", repo.RestoreAccount(ctx, savings.ID))
+		account, err := repo.GetAccountByID(ctx, savings.ID)
+		expectNoError(t, "get restored account", err)
+		if account.DeletedAt != nil || account.Version != 3 {
+			t.Fatalf("got restored account %+v", account)
+		}
+
+		expectError(t, "restore account not deleted", repo.RestoreAccount(ctx, savings.ID), domain.ErrDataNotFound)
+		expectError(t, "restore missing account", repo.RestoreAccount(ctx, shared.ID+100), domain.ErrDataNotFound)
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		// Accounts still used by expenses are kept
+		category := createCategory(t, r, "Food", baseTime)
+		payee := createPayee(t, r, "Market")
+		createExpense(t, r, &domain.Expense{
+			Amount: 10, CategoryID: category.ID, Date: day(1), PayeeID: payee.ID, AccountID: int(checking.ID),
+			CreatedAt: baseTime, UpdatedAt: baseTime,
+		})
+		expectNoError(t, "delete used account", repo.DeleteAccount(ctx, checking.ID, 0))
+		expectNoError(t, "delete unused account", repo.DeleteAccount(ctx, savings.ID, 0))
+
+		purged, err := repo.PurgeAccounts(ctx, time.Now().Add(-time.Hour))
+		expectCount(t, "purge recently deleted", purged, err, 0)
+		purged, err = repo.PurgeAccounts(ctx, time.Now().Add(time.Hour))
+		expectCount(t, "purge", purged, err, 1)
+
+		accounts, err := repo.ListAccounts(ctx, nil, 10, true)
+		expectNoError(t, "list after purge", err)
+		expectIDs(t, "list after purge", idsOf(accounts, accountID), checking.ID, shared.ID)
+	})
+}
+
+func accountID(account domain.Account) uint64 { return account.ID }
@@ -0,0 +1,112 @@
+package storagetest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+)
+
+func testAttachmentRepository(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	repo := r.Attachment
+	f := newFixture(t, r)
+	receipt := createExpense(t, r, f.expense(10, day(1)))
+	invoice := createExpense(t, r, f.expense(20, day(2)))
+	// Checksums are hex encoded SHA-256 sums
+	checksumA, checksumB := strings.Repeat("a", 64), strings.Repeat("b", 64)
+
+	create := func(expenseID int, fileName, checksum string) *domain.Attachment {
+		attachment := &domain.Attachment{
+			ExpenseID: expenseID, FileName: fileName, ContentType: "application/pdf", Size: 1024,
+			Checksum: checksum, CreatedAt: baseTime,
+		}
+		expectNoError(t, "create attachment "+fileName, repo.Create(ctx, attachment))
+		return attachment
+	}
+	scan := create(receipt.ID, "receipt.pdf", checksumA)
+	photo := create(receipt.ID, "photo.pdf", checksumB)
+	copied := create(invoice.ID, "invoice.pdf", checksumA)
+	if scan.ID == 0 {
+		t.Fatal("created attachment has no ID")
+	}
+
+	t.Run("Get", func(t *testing.T) {
+		attachment, err := repo.GetByID(ctx, photo.ID)
+		expectNoError(t, "get", err)
+		if attachment.ExpenseID != receipt.ID || attachment.FileName != "photo.pdf" || attachment.ContentType != "application/pdf" ||
+			attachment.Size != 1024 || attachment.Checksum != checksumB || !attachment.CreatedAt.Equal(baseTime) {
+			t.Fatalf("got attachment %+v", attachment)
+		}
+
+		attachment, err = repo.GetByChecksum(ctx, invoice.ID, checksumA)
+		expectNoError(t, "get by checksum", err)
+		if attachment.ID != copied.ID {
+			t.Fatalf("got attachment %d by checksum, want %d", attachment.ID, copied.ID)
+		}
+
+		_, err = repo.GetByID(ctx, copied.ID+100)
+		expectError(t, "get missing attachment", err, domain.ErrDataNotFound)
+		_, err = repo.GetByChecksum(ctx, invoice.ID, checksumB)
+		expectError(t, "get checksum of another expense", err, domain.ErrDataNotFound)
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		// The same content is only attached once to an expense
+		duplicate := &domain.Attachment{
+			ExpenseID: receipt.ID, FileName: "copy.pdf", ContentType: "application/pdf", Size: 1024,
+			Checksum: checksumA, CreatedAt: baseTime,
+		}
+		expectError(t, "create duplicate attachment", repo.Create(ctx, duplicate), domain.ErrConflictingData)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		attachments, err := repo.ListByExpense(ctx, receipt.ID)
+		expectNoError(t, "list", err)
+		expectIDs(t, "list", idsOf(attachments, attachmentID), scan.ID, photo.ID)
+
+		count, err := repo.CountByChecksum(ctx, checksumA)
+		expectCount(t, "count by checksum across expenses", count, err, 2)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		expectNoError(t, "delete", repo.Delete(ctx, scan.ID))
+
+		_, err := repo.GetByID(ctx, scan.ID)
+		expectError(t, "get deleted attachment", err, domain.ErrDataNotFound)
+		expectError(t, "delete deleted attachment", repo.Delete(ctx, scan.ID), domain.ErrDataNotFound)
+
+		count, err := repo.CountByChecksum(ctx, checksumA)
+		expectCount(t, "count by checksum after delete", count, err, 1)
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		expectNoError(t, "delete invoice", r.Expense.Delete(ctx, invoice.ID, 0))
+		expectNoError(t, "lock content", r.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+			return repo.LockContent(ctx, checksumA)
+		}))
+
+		// Only the attachments of expenses deleted before the given time go
+		purged, err := repo.Purge(ctx, baseTime)
+		expectNoError(t, "purge before the deletion", err)
+		expectIDs(t, "purged before the deletion", idsOf(purged, attachmentID))
+
+		purged, err = repo.Purge(ctx, time.Now().Add(time.Hour))
+		expectNoError(t, "purge", err)
+		expectIDs(t, "purged", idsOf(purged, attachmentID), copied.ID)
+		if purged[0].Checksum != checksumA {
+			t.Fatalf("got purged attachment %+v", purged[0])
+		}
+
+		count, err := repo.CountByChecksum(ctx, checksumA)
+		expectCount(t, "count by checksum after purge", count, err, 0)
+		attachments, err := repo.ListByExpense(ctx, receipt.ID)
+		expectNoError(t, "list attachments of the kept expense", err)
+		expectIDs(t, "attachments of the kept expense", idsOf(attachments, attachmentID), photo.ID)
+	})
+}
+
+func attachmentID(attachment *domain.Attachment) int { return attachment.ID }
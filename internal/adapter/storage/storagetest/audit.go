@@ -0,0 +1,91 @@
+package storagetest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+func testAuditRepository(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	repo := r.Audit
+
+	create := func(entityType string, entityID uint64, action string, createdAt time.Time) *domain.AuditEntry {
+		entry := &domain.AuditEntry{
+			EntityType: entityType, EntityID: entityID, Action: action, Actor: "alice", CreatedAt: createdAt,
+		}
+		if action != domain.AuditActionDelete {
+			entry.After = json.RawMessage(`{"id":1}`)
+		}
+		if action != domain.AuditActionCreate {
+			entry.Before = json.RawMessage(`{"id":1}`)
+			entry.RequestID = "request-1"
+		}
+		expectNoError(t, "create audit entry", repo.Create(ctx, entry))
+		return entry
+	}
+	created := create(domain.AuditEntityTag, 1, domain.AuditActionCreate, baseTime)
+	updated := create(domain.AuditEntityTag, 1, domain.AuditActionUpdate, baseTime.Add(time.Minute))
+	other := create(domain.AuditEntityTag, 2, domain.AuditActionCreate, baseTime.Add(time.Minute))
+	deleted := create(domain.AuditEntityPayee, 1, domain.AuditActionDelete, baseTime.Add(2*time.Minute))
+	if created.ID == 0 {
+		t.Fatal("created audit entry has no ID")
+	}
+
+	t.Run("List", func(t *testing.T) {
+		// The latest entries come first, entries of the same time by ID
+		entries, err := repo.List(ctx, nil, 10, port.AuditFilters{})
+		expectNoError(t, "list", err)
+		expectIDs(t, "list", idsOf(entries, auditEntryID), deleted.ID, other.ID, updated.ID, created.ID)
+
+		entry := entries[2]
+		if entry.EntityType != domain.AuditEntityTag || entry.EntityID != 1 || entry.Action != domain.AuditActionUpdate ||
+			entry.Actor != "alice" || entry.RequestID != "request-1" || !entry.CreatedAt.Equal(baseTime.Add(time.Minute)) {
+			t.Fatalf("got audit entry %+v", entry)
+		}
+		expectJSON(t, "before", entry.Before, `{"id": 1}`)
+		expectJSON(t, "after", entry.After, `{"id": 1}`)
+
+		if entries[0].After != nil || entries[3].Before != nil || entries[3].RequestID != "" {
+			t.Fatalf("got audit entries %+v and %+v", entries[0], entries[3])
+		}
+
+		entries, err = repo.List(ctx, &domain.Cursor{CreatedAt: &deleted.CreatedAt, ID: deleted.ID}, 2, port.AuditFilters{})
+		expectNoError(t, "list page", err)
+		expectIDs(t, "page", idsOf(entries, auditEntryID), other.ID, updated.ID)
+
+		// Entries of the same time as the cursor come after it by ID
+		entries, err = repo.List(ctx, &domain.Cursor{CreatedAt: &other.CreatedAt, ID: other.ID}, 10, port.AuditFilters{})
+		expectNoError(t, "list page of the same time", err)
+		expectIDs(t, "page of the same time", idsOf(entries, auditEntryID), updated.ID, created.ID)
+
+		count, err := repo.Count(ctx, port.AuditFilters{})
+		expectCount(t, "count", count, err, 4)
+	})
+
+	t.Run("Filters", func(t *testing.T) {
+		entityType, entityID := domain.AuditEntityTag, uint64(1)
+		entries, err := repo.List(ctx, nil, 10, port.AuditFilters{EntityType: &entityType})
+		expectNoError(t, "list by entity type", err)
+		expectIDs(t, "list by entity type", idsOf(entries, auditEntryID), other.ID, updated.ID, created.ID)
+		count, err := repo.Count(ctx, port.AuditFilters{EntityType: &entityType})
+		expectCount(t, "count by entity type", count, err, 3)
+
+		entries, err = repo.List(ctx, nil, 10, port.AuditFilters{EntityID: &entityID})
+		expectNoError(t, "list by entity ID", err)
+		expectIDs(t, "list by entity ID", idsOf(entries, auditEntryID), deleted.ID, updated.ID, created.ID)
+
+		entries, err = repo.List(ctx, nil, 10, port.AuditFilters{EntityType: &entityType, EntityID: &entityID})
+		expectNoError(t, "list by entity", err)
+		expectIDs(t, "list by entity", idsOf(entries, auditEntryID), updated.ID, created.ID)
+		count, err = repo.Count(ctx, port.AuditFilters{EntityType: &entityType, EntityID: &entityID})
+		expectCount(t, "count by entity", count, err, 2)
+	})
+}
+
+func auditEntryID(entry *domain.AuditEntry) uint64 { return entry.ID }
@@ -0,0 +1,433 @@
+package storagetest
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+func testExpenseRepository(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	repo := r.Expense
+	f := newFixture(t, r)
+	groceries := createSubCategory(t, r, "Groceries", f.categories[0].ID, baseTime)
+	travel := createTag(t, r, "Travel")
+	work := createTag(t, r, "Work")
+
+	expense := f.expense(30, day(5))
+	expense.SubCategoryID = &groceries.ID
+	expense.Notes = "bread and milk"
+	expense.TagIDs = []int{work.ID, travel.ID}
+	expense.Splits = []domain.ExpenseSplit{
+		{CategoryID: f.categories[0].ID, SubCategoryID: &groceries.ID, Amount: 20, Notes: "bread"},
+		{CategoryID: f.categories[1].ID, Amount: 10},
+	}
+	expense.Sharing = &domain.ExpenseSharing{
+		PaidByID: int(f.persons[0].ID),
+		Method:   domain.SharingMethodExact,
+		Shares: []domain.ExpenseShare{
+			{PersonID: int(f.persons[0].ID), Amount: 12},
+			{PersonID: int(f.persons[1].ID), Amount: 18},
+		},
+	}
+	createExpense(t, r, expense)
+	if expense.ID == 0 || expense.Version != 1 {
+		t.Fatalf("created expense has ID %d and version %d", expense.ID, expense.Version)
+	}
+	other := createExpense(t, r, f.expense(12.5, day(6)))
+
+	t.Run("Get", func(t *testing.T) {
+		got, err := repo.GetByID(ctx, expense.ID)
+		expectNoError(t, "get", err)
+		if got.Amount != 30 || got.CategoryID != f.categories[0].ID || got.SubCategoryID == nil || *got.SubCategoryID != groceries.ID ||
+			!got.Date.Equal(day(5)) || got.PayeeID != f.payees[0].ID || got.AccountID != int(f.account.ID) ||
+			got.Notes != "bread and milk" || got.DeletedAt != nil {
+			t.Fatalf("got expense %+v", got)
+		}
+
+		// Tags come back sorted
+		expectIDs(t, "tags", got.TagIDs, travel.ID, work.ID)
+
+		if len(got.Splits) != 2 || got.Splits[0].Amount != 20 || got.Splits[0].Notes != "bread" ||
+			got.Splits[0].SubCategoryID == nil || *got.Splits[0].SubCategoryID != groceries.ID ||
+			got.Splits[1].CategoryID != f.categories[1].ID || got.Splits[1].SubCategoryID != nil {
+			t.Fatalf("got split lines %+v", got.Splits)
+		}
+
+		if got.Sharing == nil || got.Sharing.PaidByID != int(f.persons[0].ID) || got.Sharing.Method != domain.SharingMethodExact ||
+			len(got.Sharing.Shares) != 2 || got.Sharing.Shares[1].PersonID != int(f.persons[1].ID) || got.Sharing.Shares[1].Amount != 18 {
+			t.Fatalf("got sharing %+v", got.Sharing)
+		}
+
+		got, err = repo.GetByID(ctx, other.ID)
+		expectNoError(t, "get plain expense", err)
+		if got.SubCategoryID != nil || len(got.TagIDs) != 0 || len(got.Splits) != 0 || got.Sharing != nil {
+			t.Fatalf("got plain expense %+v", got)
+		}
+
+		_, err = repo.GetByID(ctx, other.ID+100)
+		expectError(t, "get missing expense", err, domain.ErrDataNotFound)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		update, err := repo.GetByID(ctx, expense.ID)
+		expectNoError(t, "get", err)
+		update.Amount = 35
+		update.TagIDs = []int{work.ID}
+		update.Splits = nil
+		update.Sharing = nil
+		update.UpdatedAt = baseTime.Add(time.Hour)
+		expectNoError(t, "update", repo.Update(ctx, update))
+		if update.Version != 2 {
+			t.Fatalf("got version %d, want 2", update.Version)
+		}
+
+		got, err := repo.GetByID(ctx, expense.ID)
+		expectNoError(t, "get updated expense", err)
+		if got.Amount != 35 || len(got.Splits) != 0 || got.Sharing != nil || got.Version != 2 {
+			t.Fatalf("got updated expense %+v", got)
+		}
+		expectIDs(t, "updated tags", got.TagIDs, work.ID)
+
+		update.Version = 1
+		expectError(t, "update stale version", repo.Update(ctx, update), domain.ErrPreconditionFailed)
+		update.ID = other.ID + 100
+		expectError(t, "update missing expense", repo.Update(ctx, update), domain.ErrDataNotFound)
+	})
+
+	t.Run("SoftDelete", func(t *testing.T) {
+		expectError(t, "delete stale version", repo.Delete(ctx, other.ID, 2), domain.ErrPreconditionFailed)
+		expectNoError(t, "delete", repo.Delete(ctx, other.ID, 1))
+
+		_, err := repo.GetByID(ctx, other.ID)
+		expectError(t, "get deleted expense", err, domain.ErrDataNotFound)
+		expectError(t, "delete deleted expense", repo.Delete(ctx, other.ID, 0), domain.ErrDataNotFound)
+
+		expenses, err := repo.List(ctx, port.ExpenseFilters{Limit: 10, Sort: domain.DefaultExpenseSort})
+		expectNoError(t, "list without deleted", err)
+		expectIDs(t, "list without deleted", idsOf(expenses, expenseID), expense.ID)
+
+		expenses, err = repo.List(ctx, port.ExpenseFilters{Limit: 10, Sort: domain.DefaultExpenseSort, IncludeDeleted: true})
+		expectNoError(t, "list with deleted", err)
+		expectIDs(t, "list with deleted", idsOf(expenses, expenseID), other.ID, expense.ID)
+		if expenses[0].DeletedAt == nil {
+			t.Fatal("deleted expense has no deletion time")
+		}
+
+		count, err := repo.Count(ctx, port.ExpenseFilters{IncludeDeleted: true})
+		expectCount(t, "count with deleted", count, err, 2)
+
+		expectNoError(t, "restore", repo.Restore(ctx, other.ID))
+		got, err := repo.GetByID(ctx, other.ID)
+		expectNoError(t, "get restored expense", err)
+		if got.Version != 3 {
+			t.Fatalf("got version %d, want 3", got.Version)
+		}
+		expectError(t, "restore expense not deleted", repo.Restore(ctx, other.ID), domain.ErrDataNotFound)
+	})
+
+	t.Run("RemoveTag", func(t *testing.T) {
+		expectNoError(t, "delete", repo.Delete(ctx, expense.ID, 0))
+		expectNoError(t, "remove tag", repo.RemoveTag(ctx, work.ID))
+		expectNoError(t, "restore", repo.Restore(ctx, expense.ID))
+
+		got, err := repo.GetByID(ctx, expense.ID)
+		expectNoError(t, "get", err)
+		if len(got.TagIDs) != 0 {
+			t.Fatalf("got tags %v after removing the tag", got.TagIDs)
+		}
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		expectNoError(t, "delete", repo.Delete(ctx, other.ID, 0))
+
+		purged, err := repo.Purge(ctx, time.Now().Add(-time.Hour))
+		expectCount(t, "purge recently deleted", purged, err, 0)
+		purged, err = repo.Purge(ctx, time.Now().Add(time.Hour))
+		expectCount(t, "purge", purged, err, 1)
+
+		count, err := repo.Count(ctx, port.ExpenseFilters{IncludeDeleted: true})
+		expectCount(t, "count after purge", count, err, 1)
+	})
+}
+
+func testExpenseFilters(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	repo := r.Expense
+	f := newFixture(t, r)
+	food, fun := f.categories[0], f.categories[1]
+	groceries := createSubCategory(t, r, "Groceries", food.ID, baseTime)
+	savings := createAccount(t, r, "Savings", f.persons[1].ID)
+	travel := createTag(t, r, "Travel")
+	work := createTag(t, r, "Work")
+
+	// Plain food expense with a subcategory and both tags
+	groceriesExpense := f.expense(40, day(2))
+	groceriesExpense.SubCategoryID = &groceries.ID
+	groceriesExpense.Notes = "Weekly groceries, milk and bread"
+	groceriesExpense.TagIDs = []int{travel.ID, work.ID}
+	createExpense(t, r, groceriesExpense)
+
+	// Fun expense from the other payee and account, tagged for work
+	movieExpense := f.expense(15, day(10))
+	movieExpense.CategoryID = fun.ID
+	movieExpense.PayeeID = f.payees[1].ID
+	movieExpense.AccountID = int(savings.ID)
+	movieExpense.Notes = "movie night"
+	movieExpense.TagIDs = []int{work.ID}
+	createExpense(t, r, movieExpense)
+
+	// Fun expense split between both categories, only its food line has a subcategory
+	splitExpense := f.expense(100, day(20))
+	splitExpense.CategoryID = fun.ID
+	splitExpense.TagIDs = []int{travel.ID}
+	splitExpense.Splits = []domain.ExpenseSplit{
+		{CategoryID: food.ID, SubCategoryID: &groceries.ID, Amount: 70},
+		{CategoryID: fun.ID, Amount: 30},
+	}
+	createExpense(t, r, splitExpense)
+
+	// Deleted expense matching most filters
+	deletedExpense := createExpense(t, r, f.expense(40, day(2)))
+	expectNoError(t, "delete", repo.Delete(ctx, deletedExpense.ID, 0))
+
+	yes, no := true, false
+	amount := func(value float64) *float64 { return &value }
+	date := func(d int) *time.Time { value := day(d); return &value }
+
+	tests := []struct {
+		name     string
+		filters  port.ExpenseFilters
+		expected []int // Ordered by date, the oldest first
+	}{
+		{"none", port.ExpenseFilters{}, []int{groceriesExpense.ID, movieExpense.ID, splitExpense.ID}},
+		{"category", port.ExpenseFilters{CategoryIDs: []int{food.ID}}, []int{groceriesExpense.ID, splitExpense.ID}},
+		{"category of a split line", port.ExpenseFilters{CategoryIDs: []int{fun.ID}}, []int{movieExpense.ID, splitExpense.ID}},
+		{"several categories", port.ExpenseFilters{CategoryIDs: []int{food.ID, fun.ID}}, []int{groceriesExpense.ID, movieExpense.ID, splitExpense.ID}},
+		{"subcategory", port.ExpenseFilters{SubCategoryID: &groceries.ID}, []int{groceriesExpense.ID, splitExpense.ID}},
+		{"with subcategory", port.ExpenseFilters{HasSubCategory: &yes}, []int{groceriesExpense.ID, splitExpense.ID}},
+		{"without subcategory", port.ExpenseFilters{HasSubCategory: &no}, []int{movieExpense.ID, splitExpense.ID}},
+		{"category and subcategory on the same line", port.ExpenseFilters{CategoryIDs: []int{fun.ID}, HasSubCategory: &yes}, nil},
+		{"payee", port.ExpenseFilters{PayeeIDs: []int{f.payees[1].ID}}, []int{movieExpense.ID}},
+		{"account", port.ExpenseFilters{AccountIDs: []int{int(f.account.ID)}}, []int{groceriesExpense.ID, splitExpense.ID}},
+		{"minimum amount", port.ExpenseFilters{MinAmount: amount(40)}, []int{groceriesExpense.ID, splitExpense.ID}},
+		{"maximum amount", port.ExpenseFilters{MaxAmount: amount(40)}, []int{groceriesExpense.ID, movieExpense.ID}},
+		{"notes word", port.ExpenseFilters{NotesQuery: "milk"}, []int{groceriesExpense.ID}},
+		{"notes words in any order", port.ExpenseFilters{NotesQuery: "bread MILK"}, []int{groceriesExpense.ID}},
+		{"notes missing word", port.ExpenseFilters{NotesQuery: "milk night"}, nil},
+		{"notes partial word", port.ExpenseFilters{NotesQuery: "mil"}, nil},
+		{"any tag", port.ExpenseFilters{TagIDs: []int{travel.ID, work.ID}}, []int{groceriesExpense.ID, movieExpense.ID, splitExpense.ID}},
+		{"all tags", port.ExpenseFilters{TagIDs: []int{travel.ID, work.ID}, MatchAllTags: true}, []int{groceriesExpense.ID}},
+		{"start date", port.ExpenseFilters{StartDate: date(10)}, []int{movieExpense.ID, splitExpense.ID}},
+		{"end date", port.ExpenseFilters{EndDate: date(10)}, []int{groceriesExpense.ID, movieExpense.ID}},
+		{"date range", port.ExpenseFilters{StartDate: date(3), EndDate: date(19)}, []int{movieExpense.ID}},
+		{"combined", port.ExpenseFilters{CategoryIDs: []int{food.ID}, TagIDs: []int{travel.ID}, MinAmount: amount(50)}, []int{splitExpense.ID}},
+		{"deleted", port.ExpenseFilters{PayeeIDs: []int{f.payees[0].ID}, MaxAmount: amount(40), IncludeDeleted: true}, []int{groceriesExpense.ID, deletedExpense.ID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filters := tt.filters
+			filters.Limit = 10
+			filters.Sort = domain.ExpenseSort{Field: domain.ExpenseSortDate}
+			expenses, err := repo.List(ctx, filters)
+			expectNoError(t, "list", err)
+			expectIDs(t, "list", idsOf(expenses, expenseID), tt.expected...)
+
+			count, err := repo.Count(ctx, tt.filters)
+			expectCount(t, "count", count, err, int64(len(tt.expected)))
+		})
+	}
+
+	t.Run("TagTotals", func(t *testing.T) {
+		totals, err := repo.TagTotals(ctx, port.ExpenseFilters{})
+		expectNoError(t, "tag totals", err)
+		expectTagTotals(t, totals, map[int][2]float64{travel.ID: {2, 140}, work.ID: {2, 55}})
+
+		// Only the matching split lines count
+		totals, err = repo.TagTotals(ctx, port.ExpenseFilters{CategoryIDs: []int{food.ID}})
+		expectNoError(t, "tag totals by category", err)
+		expectTagTotals(t, totals, map[int][2]float64{travel.ID: {2, 110}, work.ID: {1, 40}})
+
+		totals, err = repo.TagTotals(ctx, port.ExpenseFilters{PayeeIDs: []int{f.payees[1].ID}})
+		expectNoError(t, "tag totals by payee", err)
+		expectTagTotals(t, totals, map[int][2]float64{work.ID: {1, 15}})
+	})
+}
+
+func testExpenseSort(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	repo := r.Expense
+	f := newFixture(t, r)
+
+	// Ties on the sorted field are ordered by creation time, then by ID
+	create := func(amount float64, date time.Time, createdAt time.Time) *domain.Expense {
+		expense := f.expense(amount, date)
+		expense.CreatedAt = createdAt
+		return createExpense(t, r, expense)
+	}
+	a := create(10, day(3), baseTime.Add(3*time.Minute))
+	b := create(30, day(1), baseTime.Add(2*time.Minute))
+	c := create(20, day(3), baseTime.Add(time.Minute))
+	d := create(20, day(2), baseTime.Add(time.Minute))
+
+	tests := []struct {
+		sort     domain.ExpenseSort
+		expected []int
+	}{
+		{domain.ExpenseSort{Field: domain.ExpenseSortDate, Descending: true}, []int{a.ID, c.ID, d.ID, b.ID}},
+		{domain.ExpenseSort{Field: domain.ExpenseSortDate}, []int{b.ID, d.ID, c.ID, a.ID}},
+		{domain.ExpenseSort{Field: domain.ExpenseSortAmount, Descending: true}, []int{b.ID, d.ID, c.ID, a.ID}},
+		{domain.ExpenseSort{Field: domain.ExpenseSortAmount}, []int{a.ID, c.ID, d.ID, b.ID}},
+		{domain.ExpenseSort{Field: domain.ExpenseSortCreatedAt, Descending: true}, []int{a.ID, b.ID, d.ID, c.ID}},
+		{domain.ExpenseSort{Field: domain.ExpenseSortCreatedAt}, []int{c.ID, d.ID, b.ID, a.ID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort.String(), func(t *testing.T) {
+			expenses, err := repo.List(ctx, port.ExpenseFilters{Limit: 10, Sort: tt.sort})
+			expectNoError(t, "list", err)
+			expectIDs(t, "list", idsOf(expenses, expenseID), tt.expected...)
+
+			// Walking the pages one expense at a time gives the same order
+			var ids []int
+			var after *domain.Cursor
+			for range tt.expected {
+				expenses, err := repo.List(ctx, port.ExpenseFilters{After: after, Limit: 1, Sort: tt.sort})
+				expectNoError(t, "list page", err)
+				if len(expenses) != 1 {
+					t.Fatalf("got %d expenses on page %d", len(expenses), len(ids)+1)
+				}
+				ids = append(ids, expenses[0].ID)
+				after = expenseCursor(expenses[0], tt.sort)
+			}
+			expectIDs(t, "pages", ids, tt.expected...)
+
+			expenses, err = repo.List(ctx, port.ExpenseFilters{After: after, Limit: 1, Sort: tt.sort})
+			expectNoError(t, "list after the last page", err)
+			if len(expenses) != 0 {
+				t.Fatalf("got %d expenses after the last page", len(expenses))
+			}
+		})
+	}
+}
+
+func testExpenseReassign(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	repo := r.Expense
+	f := newFixture(t, r)
+	food, fun := f.categories[0], f.categories[1]
+	groceries := createSubCategory(t, r, "Groceries", food.ID, baseTime)
+	movies := createSubCategory(t, r, "Movies", fun.ID, baseTime)
+
+	plain := f.expense(10, day(1))
+	plain.SubCategoryID = &groceries.ID
+	createExpense(t, r, plain)
+	split := f.expense(30, day(2))
+	split.CategoryID = fun.ID
+	split.Splits = []domain.ExpenseSplit{
+		{CategoryID: food.ID, SubCategoryID: &groceries.ID, Amount: 20},
+		{CategoryID: fun.ID, Amount: 10},
+	}
+	createExpense(t, r, split)
+	deleted := createExpense(t, r, f.expense(5, day(3)))
+	expectNoError(t, "delete", repo.Delete(ctx, deleted.ID, 0))
+
+	t.Run("Payee", func(t *testing.T) {
+		moved, err := repo.ReassignPayee(ctx, f.payees[0].ID, f.payees[1].ID)
+		expectCount(t, "reassign payee", moved, err, 3)
+
+		count, err := repo.Count(ctx, port.ExpenseFilters{PayeeIDs: []int{f.payees[1].ID}, IncludeDeleted: true})
+		expectCount(t, "count moved expenses", count, err, 3)
+	})
+
+	t.Run("SubCategory", func(t *testing.T) {
+		moved, err := repo.ReassignSubCategory(ctx, groceries.ID, movies.ID, fun.ID)
+		expectCount(t, "reassign subcategory", moved, err, 2)
+
+		got, err := repo.GetByID(ctx, plain.ID)
+		expectNoError(t, "get", err)
+		if got.CategoryID != fun.ID || got.SubCategoryID == nil || *got.SubCategoryID != movies.ID || got.Version != 3 {
+			t.Fatalf("got expense %+v", got)
+		}
+
+		got, err = repo.GetByID(ctx, split.ID)
+		expectNoError(t, "get split expense", err)
+		if got.Splits[0].CategoryID != fun.ID || got.Splits[0].SubCategoryID == nil || *got.Splits[0].SubCategoryID != movies.ID {
+			t.Fatalf("got split lines %+v", got.Splits)
+		}
+	})
+
+	t.Run("Category", func(t *testing.T) {
+		moved, err := repo.ReassignCategory(ctx, fun.ID, food.ID)
+		expectCount(t, "reassign category", moved, err, 2)
+
+		count, err := repo.Count(ctx, port.ExpenseFilters{CategoryIDs: []int{fun.ID}, IncludeDeleted: true})
+		expectCount(t, "count expenses left in the category", count, err, 0)
+
+		got, err := repo.GetByID(ctx, split.ID)
+		expectNoError(t, "get split expense", err)
+		if got.CategoryID != food.ID || got.Splits[0].CategoryID != food.ID || got.Splits[1].CategoryID != food.ID {
+			t.Fatalf("got split expense %+v", got)
+		}
+	})
+
+	t.Run("ShareDebts", func(t *testing.T) {
+		alice, bob := int(f.persons[0].ID), int(f.persons[1].ID)
+		carol := int(createPerson(t, r, "Carol", "carol@example.com").ID)
+		shared := func(amount float64, paidByID int, shares ...domain.ExpenseShare) *domain.Expense {
+			expense := f.expense(amount, day(4))
+			expense.Sharing = &domain.ExpenseSharing{PaidByID: paidByID, Method: domain.SharingMethodExact, Shares: shares}
+			return createExpense(t, r, expense)
+		}
+		shared(30, alice, domain.ExpenseShare{PersonID: alice, Amount: 10}, domain.ExpenseShare{PersonID: bob, Amount: 20})
+		shared(10, alice, domain.ExpenseShare{PersonID: bob, Amount: 6}, domain.ExpenseShare{PersonID: carol, Amount: 4})
+		shared(8, bob, domain.ExpenseShare{PersonID: alice, Amount: 8})
+		gone := shared(50, carol, domain.ExpenseShare{PersonID: alice, Amount: 50})
+		expectNoError(t, "delete", repo.Delete(ctx, gone.ID, 0))
+
+		debts, err := repo.ShareDebts(ctx)
+		expectNoError(t, "share debts", err)
+		expected := []domain.PersonDebt{
+			{FromPersonID: alice, ToPersonID: bob, Amount: 8},
+			{FromPersonID: bob, ToPersonID: alice, Amount: 26},
+			{FromPersonID: carol, ToPersonID: alice, Amount: 4},
+		}
+		if !slices.Equal(debts, expected) {
+			t.Fatalf("got debts %+v, want %+v", debts, expected)
+		}
+	})
+}
+
+// expectTagTotals fails the test unless the totals are the expected counts and amounts by tag
+func expectTagTotals(t *testing.T, totals []domain.TagTotal, expected map[int][2]float64) {
+	t.Helper()
+	if len(totals) != len(expected) {
+		t.Fatalf("got tag totals %+v, want %v", totals, expected)
+	}
+	for _, total := range totals {
+		want, ok := expected[total.TagID]
+		if !ok || float64(total.ExpenseCount) != want[0] || total.TotalAmount != want[1] {
+			t.Fatalf("got tag totals %+v, want %v", totals, expected)
+		}
+	}
+}
+
+// expenseCursor returns the cursor continuing after the expense, like the expense service does
+func expenseCursor(expense *domain.Expense, sort domain.ExpenseSort) *domain.Cursor {
+	cursor := &domain.Cursor{Sort: sort.String(), CreatedAt: &expense.CreatedAt, ID: uint64(expense.ID)}
+	switch sort.Field {
+	case domain.ExpenseSortDate:
+		cursor.Date = &expense.Date
+	case domain.ExpenseSortAmount:
+		cursor.Amount = &expense.Amount
+	}
+	return cursor
+}
+
+func expenseID(expense *domain.Expense) int { return expense.ID }
@@ -0,0 +1,207 @@
+package storagetest
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+)
+
+func testExpenseCategoryRepository(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	repo := r.ExpenseCategory
+
+	food := createCategory(t, r, "Food", baseTime)
+	fun := createCategory(t, r, "Fun", baseTime.Add(time.Minute))
+	// Categories created at the same time are ordered by ID
+	home := createCategory(t, r, "Home", baseTime.Add(time.Minute))
+	if food.ID == 0 || food.Version != 1 {
+		t.Fatalf("created expense category %+v", food)
+	}
+
+	t.Run("Get", func(t *testing.T) {
+		category, err := repo.GetByID(ctx, food.ID)
+		expectNoError(t, "get", err)
+		if category.Name != "Food" || category.Aliases == nil || len(category.Aliases) != 0 || !category.CreatedAt.Equal(baseTime) {
+			t.Fatalf("got expense category %+v", category)
+		}
+
+		_, err = repo.GetByID(ctx, home.ID+100)
+		expectError(t, "get missing category", err, domain.ErrDataNotFound)
+
+		// Rows referencing the category read it while locking it, within their transaction
+		err = r.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+			category, err := repo.GetByIDForShare(ctx, food.ID)
+			if err == nil && category.Name != "Food" {
+				t.Errorf("got locked expense category %+v", category)
+			}
+			return err
+		})
+		expectNoError(t, "get for share", err)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		categories, err := repo.List(ctx, nil, 2, false)
+		expectNoError(t, "list first page", err)
+		expectIDs(t, "first page", idsOf(categories, expenseCategoryID), home.ID, fun.ID)
+
+		categories, err = repo.List(ctx, &domain.Cursor{CreatedAt: &fun.CreatedAt, ID: uint64(fun.ID)}, 2, false)
+		expectNoError(t, "list next page", err)
+		expectIDs(t, "next page", idsOf(categories, expenseCategoryID), food.ID)
+
+		count, err := repo.Count(ctx, false)
+		expectCount(t, "count", count, err, 3)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		update := *fun
+		update.Name = "Leisure"
+		update.Aliases = []string{"Fun", "Hobbies"}
+		update.UpdatedAt = baseTime.Add(time.Hour)
+		expectNoError(t, "update", repo.Update(ctx, &update))
+		if update.Version != 2 {
+			t.Fatalf("got version %d, want 2", update.Version)
+		}
+
+		category, err := repo.GetByID(ctx, fun.ID)
+		expectNoError(t, "get updated category", err)
+		if category.Name != "Leisure" || !slices.Equal(category.Aliases, []string{"Fun", "Hobbies"}) || category.Version != 2 {
+			t.Fatalf("got updated expense category %+v", category)
+		}
+
+		update.Version = 1
+		expectError(t, "update stale version", repo.Update(ctx, &update), domain.ErrPreconditionFailed)
+		update.ID = home.ID + 100
+		expectError(t, "update missing category", repo.Update(ctx, &update), domain.ErrDataNotFound)
+	})
+
+	t.Run("SoftDelete", func(t *testing.T) {
+		expectError(t, "delete stale version", repo.Delete(ctx, home.ID, 2), domain.ErrPreconditionFailed)
+		expectNoError(t, "delete", repo.Delete(ctx, home.ID, 1))
+
+		_, err := repo.GetByID(ctx, home.ID)
+		expectError(t, "get deleted category", err, domain.ErrDataNotFound)
+		_, err = repo.GetByIDForShare(ctx, home.ID)
+		expectError(t, "get deleted category for share", err, domain.ErrDataNotFound)
+		expectError(t, "delete deleted category", repo.Delete(ctx, home.ID, 0), domain.ErrDataNotFound)
+
+		categories, err := repo.List(ctx, nil, 10, false)
+		expectNoError(t, "list without deleted", err)
+		expectIDs(t, "list without deleted", idsOf(categories, expenseCategoryID), fun.ID, food.ID)
+		categories, err = repo.List(ctx, nil, 10, true)
+		expectNoError(t, "list with deleted", err)
+		expectIDs(t, "list with deleted", idsOf(categories, expenseCategoryID), home.ID, fun.ID, food.ID)
+		if categories[0].DeletedAt == nil {
+			t.Fatal("deleted category has no deletion time")
+		}
+
+		count, err := repo.Count(ctx, true)
+		expectCount(t, "count with deleted", count, err, 3)
+
+		expectNoError(t, "restore", repo.Restore(ctx, home.ID))
+		_, err = repo.GetByID(ctx, home.ID)
+		expectNoError(t, "get restored category", err)
+		expectError(t, "restore category not deleted", repo.Restore(ctx, home.ID), domain.ErrDataNotFound)
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		// Categories still used by subcategories are kept
+		createSubCategory(t, r, "Groceries", food.ID, baseTime)
+		expectNoError(t, "delete used category", repo.Delete(ctx, food.ID, 0))
+		expectNoError(t, "delete unused category", repo.Delete(ctx, home.ID, 0))
+
+		purged, err := repo.Purge(ctx, time.Now().Add(-time.Hour))
+		expectCount(t, "purge recently deleted", purged, err, 0)
+		purged, err = repo.Purge(ctx, time.Now().Add(time.Hour))
+		expectCount(t, "purge", purged, err, 1)
+
+		categories, err := repo.List(ctx, nil, 10, true)
+		expectNoError(t, "list after purge", err)
+		expectIDs(t, "list after purge", idsOf(categories, expenseCategoryID), fun.ID, food.ID)
+	})
+}
+
+func testExpenseSubCategoryRepository(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	repo := r.ExpenseSubCategory
+
+	food := createCategory(t, r, "Food", baseTime)
+	fun := createCategory(t, r, "Fun", baseTime)
+	groceries := createSubCategory(t, r, "Groceries", food.ID, baseTime)
+	restaurants := createSubCategory(t, r, "Restaurants", food.ID, baseTime.Add(time.Minute))
+	movies := createSubCategory(t, r, "Movies", fun.ID, baseTime.Add(2*time.Minute))
+
+	t.Run("Get", func(t *testing.T) {
+		subcategory, err := repo.GetByID(ctx, groceries.ID)
+		expectNoError(t, "get", err)
+		if subcategory.Name != "Groceries" || subcategory.ExpenseCategoryID != food.ID || subcategory.Version != 1 {
+			t.Fatalf("got expense subcategory %+v", subcategory)
+		}
+
+		_, err = repo.GetByID(ctx, movies.ID+100)
+		expectError(t, "get missing subcategory", err, domain.ErrDataNotFound)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		subcategories, err := repo.List(ctx, nil, 10, nil, false)
+		expectNoError(t, "list", err)
+		expectIDs(t, "list", idsOf(subcategories, expenseSubCategoryID), movies.ID, restaurants.ID, groceries.ID)
+
+		subcategories, err = repo.List(ctx, nil, 1, &food.ID, false)
+		expectNoError(t, "list by category", err)
+		expectIDs(t, "first page by category", idsOf(subcategories, expenseSubCategoryID), restaurants.ID)
+
+		cursor := &domain.Cursor{CreatedAt: &restaurants.CreatedAt, ID: uint64(restaurants.ID)}
+		subcategories, err = repo.List(ctx, cursor, 1, &food.ID, false)
+		expectNoError(t, "list next page by category", err)
+		expectIDs(t, "next page by category", idsOf(subcategories, expenseSubCategoryID), groceries.ID)
+
+		count, err := repo.Count(ctx, &food.ID, false)
+		expectCount(t, "count by category", count, err, 2)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		update := *restaurants
+		update.ExpenseCategoryID = fun.ID
+		update.Aliases = []string{"Dining"}
+		expectNoError(t, "update", repo.Update(ctx, &update))
+
+		subcategory, err := repo.GetByID(ctx, restaurants.ID)
+		expectNoError(t, "get updated subcategory", err)
+		if subcategory.ExpenseCategoryID != fun.ID || !slices.Equal(subcategory.Aliases, []string{"Dining"}) || subcategory.Version != 2 {
+			t.Fatalf("got updated expense subcategory %+v", subcategory)
+		}
+
+		update.Version = 1
+		expectError(t, "update stale version", repo.Update(ctx, &update), domain.ErrPreconditionFailed)
+	})
+
+	t.Run("SoftDelete", func(t *testing.T) {
+		expectNoError(t, "delete", repo.Delete(ctx, movies.ID, 1))
+		_, err := repo.GetByID(ctx, movies.ID)
+		expectError(t, "get deleted subcategory", err, domain.ErrDataNotFound)
+		_, err = repo.GetByIDForShare(ctx, movies.ID)
+		expectError(t, "get deleted subcategory for share", err, domain.ErrDataNotFound)
+
+		count, err := repo.Count(ctx, &fun.ID, false)
+		expectCount(t, "count without deleted", count, err, 1)
+		count, err = repo.Count(ctx, &fun.ID, true)
+		expectCount(t, "count with deleted", count, err, 2)
+
+		expectNoError(t, "restore", repo.Restore(ctx, movies.ID))
+		expectError(t, "restore subcategory not deleted", repo.Restore(ctx, movies.ID), domain.ErrDataNotFound)
+
+		expectNoError(t, "delete again", repo.Delete(ctx, movies.ID, 0))
+		purged, err := repo.Purge(ctx, time.Now().Add(time.Hour))
+		expectCount(t, "purge", purged, err, 1)
+		count, err = repo.Count(ctx, nil, true)
+		expectCount(t, "count after purge", count, err, 2)
+	})
+}
+
+func expenseCategoryID(category *domain.ExpenseCategory) int { return category.ID }
+
+func expenseSubCategoryID(subcategory *domain.ExpenseSubCategory) int { return subcategory.ID }
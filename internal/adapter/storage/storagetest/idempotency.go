@@ -0,0 +1,89 @@
+package storagetest
+
+import (
+	"context"
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+)
+
+func testIdempotencyRepository(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	repo := r.Idempotency
+
+	reserve := func(key, scope, requestHash string, createdAt time.Time) *domain.IdempotencyRecord {
+		existing, err := repo.Reserve(ctx, &domain.IdempotencyRecord{
+			Key: key, Scope: scope, RequestHash: requestHash, CreatedAt: createdAt, ExpiresAt: createdAt.Add(time.Hour),
+		})
+		expectNoError(t, "reserve "+key, err)
+		return existing
+	}
+
+	t.Run("Reserve", func(t *testing.T) {
+		if existing := reserve("key-1", "POST /expenses", "hash-1", baseTime); existing != nil {
+			t.Fatalf("reserving a new key returned %+v", existing)
+		}
+
+		existing := reserve("key-1", "POST /expenses", "hash-2", baseTime.Add(time.Minute))
+		if existing == nil || existing.RequestHash != "hash-1" || existing.Completed() || !existing.ExpiresAt.Equal(baseTime.Add(time.Hour)) {
+			t.Fatalf("reserving a key in progress returned %+v", existing)
+		}
+
+		// Keys are scoped
+		if existing := reserve("key-1", "POST /payees", "hash-1", baseTime); existing != nil {
+			t.Fatalf("reserving a key in another scope returned %+v", existing)
+		}
+	})
+
+	t.Run("Complete", func(t *testing.T) {
+		expectNoError(t, "complete", repo.Complete(ctx, &domain.IdempotencyRecord{
+			Key: "key-1", Scope: "POST /expenses", StatusCode: 201,
+			Headers: map[string]string{"Location": "/expenses/1"}, Body: []byte(`{"id":1}`),
+		}))
+
+		existing := reserve("key-1", "POST /expenses", "hash-1", baseTime.Add(time.Minute))
+		if existing == nil || !existing.Completed() || existing.StatusCode != 201 || string(existing.Body) != `{"id":1}` ||
+			!maps.Equal(existing.Headers, map[string]string{"Location": "/expenses/1"}) {
+			t.Fatalf("reserving a completed key returned %+v", existing)
+		}
+
+		expectError(t, "complete missing key", repo.Complete(ctx, &domain.IdempotencyRecord{
+			Key: "key-2", Scope: "POST /expenses", StatusCode: 201,
+		}), domain.ErrDataNotFound)
+	})
+
+	t.Run("Release", func(t *testing.T) {
+		// Completed records are kept
+		expectNoError(t, "release completed key", repo.Release(ctx, "key-1", "POST /expenses"))
+		if existing := reserve("key-1", "POST /expenses", "hash-1", baseTime.Add(time.Minute)); existing == nil {
+			t.Fatal("releasing a completed key removed it")
+		}
+
+		expectNoError(t, "release", repo.Release(ctx, "key-1", "POST /payees"))
+		if existing := reserve("key-1", "POST /payees", "hash-3", baseTime.Add(time.Minute)); existing != nil {
+			t.Fatalf("reserving a released key returned %+v", existing)
+		}
+		expectNoError(t, "release missing key", repo.Release(ctx, "key-3", "POST /payees"))
+	})
+
+	t.Run("Expiry", func(t *testing.T) {
+		// An expired record is taken over
+		if existing := reserve("key-1", "POST /expenses", "hash-4", baseTime.Add(2*time.Hour)); existing != nil {
+			t.Fatalf("reserving an expired key returned %+v", existing)
+		}
+		existing := reserve("key-1", "POST /expenses", "hash-5", baseTime.Add(2*time.Hour))
+		if existing == nil || existing.RequestHash != "hash-4" || existing.Completed() {
+			t.Fatalf("reserving a key taken over returned %+v", existing)
+		}
+
+		// Only the key of the other scope expired by then
+		purged, err := repo.PurgeExpired(ctx, baseTime.Add(2*time.Hour))
+		expectCount(t, "purge expired", purged, err, 1)
+		if existing := reserve("key-1", "POST /payees", "hash-6", baseTime.Add(2*time.Hour)); existing != nil {
+			t.Fatalf("reserving a purged key returned %+v", existing)
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package storagetest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+func testMergeRepository(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	repo := r.Merge
+
+	create := func(entityType string, sourceID, targetID int, expenseCount int64) *domain.Merge {
+		merge := &domain.Merge{
+			EntityType: entityType, SourceID: sourceID, TargetID: targetID,
+			Source:       json.RawMessage(`{"id":1,"name":"Market"}`),
+			ExpenseCount: expenseCount, CreatedAt: baseTime,
+		}
+		expectNoError(t, "create merge", repo.Create(ctx, merge))
+		return merge
+	}
+	payee := create(domain.AuditEntityPayee, 2, 1, 3)
+	category := create(domain.AuditEntityExpenseCategory, 5, 4, 0)
+	otherPayee := create(domain.AuditEntityPayee, 3, 1, 1)
+	thirdPayee := create(domain.AuditEntityPayee, 6, 4, 2)
+	if payee.ID == 0 {
+		t.Fatal("created merge has no ID")
+	}
+
+	t.Run("List", func(t *testing.T) {
+		merges, err := repo.List(ctx, nil, 10, port.MergeFilters{})
+		expectNoError(t, "list", err)
+		expectIDs(t, "list", idsOf(merges, mergeID), payee.ID, category.ID, otherPayee.ID, thirdPayee.ID)
+
+		merge := merges[0]
+		if merge.EntityType != domain.AuditEntityPayee || merge.SourceID != 2 || merge.TargetID != 1 ||
+			merge.ExpenseCount != 3 || !merge.CreatedAt.Equal(baseTime) {
+			t.Fatalf("got merge %+v", merge)
+		}
+		expectJSON(t, "source", merge.Source, `{"id": 1, "name": "Market"}`)
+
+		merges, err = repo.List(ctx, &domain.Cursor{ID: category.ID}, 1, port.MergeFilters{})
+		expectNoError(t, "list next page", err)
+		expectIDs(t, "next page", idsOf(merges, mergeID), otherPayee.ID)
+	})
+
+	t.Run("Filters", func(t *testing.T) {
+		entityType, targetID := domain.AuditEntityPayee, 4
+		tests := []struct {
+			name     string
+			filters  port.MergeFilters
+			expected []uint64
+		}{
+			{"entity type", port.MergeFilters{EntityType: &entityType}, []uint64{payee.ID, otherPayee.ID, thirdPayee.ID}},
+			{"target", port.MergeFilters{TargetID: &targetID}, []uint64{category.ID, thirdPayee.ID}},
+			{"entity type and target", port.MergeFilters{EntityType: &entityType, TargetID: &targetID}, []uint64{thirdPayee.ID}},
+		}
+		for _, tt := range tests {
+			merges, err := repo.List(ctx, nil, 10, tt.filters)
+			expectNoError(t, "list by "+tt.name, err)
+			expectIDs(t, "list by "+tt.name, idsOf(merges, mergeID), tt.expected...)
+
+			count, err := repo.Count(ctx, tt.filters)
+			expectCount(t, "count by "+tt.name, count, err, int64(len(tt.expected)))
+		}
+	})
+}
+
+// expectJSON fails the test unless the document holds the same values as the expected one, whatever its layout
+func expectJSON(t *testing.T, what string, document json.RawMessage, expected string) {
+	t.Helper()
+	var got, want any
+	if err := json.Unmarshal(document, &got); err != nil {
+		t.Fatalf("%s: invalid JSON %s: %v", what, document, err)
+	}
+	if err := json.Unmarshal([]byte(expected), &want); err != nil {
+		t.Fatalf("%s: invalid expected JSON %s: %v", what, expected, err)
+	}
+	gotJSON, _ := json.Marshal(got)
+	wantJSON, _ := json.Marshal(want)
+	if string(gotJSON) != string(wantJSON) {
+		t.Fatalf("%s: got %s, want %s", what, document, expected)
+	}
+}
+
+func mergeID(merge *domain.Merge) uint64 { return merge.ID }
@@ -0,0 +1,135 @@
+package storagetest
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+)
+
+func testPayeeRepository(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	repo := r.Payee
+
+	market := createPayee(t, r, "Corner Market", "CRNR MKT 042")
+	cinema := createPayee(t, r, "Cinema")
+	bakery := createPayee(t, r, "Bakery 100%")
+	if market.ID == 0 || market.Version != 1 {
+		t.Fatalf("created payee %+v", market)
+	}
+
+	t.Run("Get", func(t *testing.T) {
+		payee, err := repo.GetByID(ctx, market.ID)
+		expectNoError(t, "get", err)
+		if payee.Name != "Corner Market" || !slices.Equal(payee.Aliases, []string{"CRNR MKT 042"}) ||
+			payee.DefaultCategoryID != nil || payee.PersonID != nil {
+			t.Fatalf("got payee %+v", payee)
+		}
+
+		payee, err = repo.GetByID(ctx, cinema.ID)
+		expectNoError(t, "get payee without aliases", err)
+		if payee.Aliases == nil || len(payee.Aliases) != 0 {
+			t.Fatalf("got aliases %#v, want an empty list", payee.Aliases)
+		}
+
+		payee, err = repo.GetByName(ctx, "CINEMA")
+		expectNoError(t, "get by name ignoring case", err)
+		if payee.ID != cinema.ID {
+			t.Fatalf("got payee %d by name, want %d", payee.ID, cinema.ID)
+		}
+
+		_, err = repo.GetByID(ctx, bakery.ID+100)
+		expectError(t, "get missing payee", err, domain.ErrDataNotFound)
+		_, err = repo.GetByName(ctx, "Nobody")
+		expectError(t, "get missing name", err, domain.ErrDataNotFound)
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		tests := []struct {
+			search   string
+			expected []int
+		}{
+			{"", []int{market.ID, cinema.ID, bakery.ID}},
+			{"market", []int{market.ID}},
+			{"mkt 04", []int{market.ID}}, // Aliases match too
+			{"e", []int{market.ID, cinema.ID, bakery.ID}},
+			{"100%", []int{bakery.ID}},
+			{"1_0", nil}, // Wildcards are matched literally
+			{"%", []int{bakery.ID}},
+		}
+		for _, tt := range tests {
+			payees, err := repo.List(ctx, nil, 10, tt.search)
+			expectNoError(t, "search "+tt.search, err)
+			expectIDs(t, "search "+tt.search, idsOf(payees, payeeID), tt.expected...)
+
+			count, err := repo.Count(ctx, tt.search)
+			expectCount(t, "count "+tt.search, count, err, int64(len(tt.expected)))
+		}
+
+		payees, err := repo.List(ctx, &domain.Cursor{ID: uint64(market.ID)}, 1, "e")
+		expectNoError(t, "search next page", err)
+		expectIDs(t, "search next page", idsOf(payees, payeeID), cinema.ID)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		alice := createPerson(t, r, "Alice", "alice@example.com")
+		food := createCategory(t, r, "Food", baseTime)
+		groceries := createSubCategory(t, r, "Groceries", food.ID, baseTime)
+		personID := int(alice.ID)
+
+		update := *market
+		update.Aliases = []string{"CRNR MKT 042", "CORNER MKT"}
+		update.DefaultCategoryID = &food.ID
+		update.DefaultSubCategoryID = &groceries.ID
+		update.PersonID = &personID
+		expectNoError(t, "update", repo.Update(ctx, &update))
+
+		payee, err := repo.GetByID(ctx, market.ID)
+		expectNoError(t, "get updated payee", err)
+		if len(payee.Aliases) != 2 || payee.DefaultCategoryID == nil || *payee.DefaultCategoryID != food.ID ||
+			payee.DefaultSubCategoryID == nil || *payee.DefaultSubCategoryID != groceries.ID ||
+			payee.PersonID == nil || *payee.PersonID != personID || payee.Version != 2 {
+			t.Fatalf("got updated payee %+v", payee)
+		}
+
+		update.Version = 1
+		expectError(t, "update stale version", repo.Update(ctx, &update), domain.ErrPreconditionFailed)
+		update.ID = bakery.ID + 100
+		expectError(t, "update missing payee", repo.Update(ctx, &update), domain.ErrDataNotFound)
+	})
+
+	t.Run("ReassignDefaults", func(t *testing.T) {
+		fun := createCategory(t, r, "Fun", baseTime)
+		movies := createSubCategory(t, r, "Movies", fun.ID, baseTime)
+		payee, err := repo.GetByID(ctx, market.ID)
+		expectNoError(t, "get payee", err)
+
+		expectNoError(t, "reassign subcategory", repo.ReassignDefaultSubCategory(ctx, *payee.DefaultSubCategoryID, movies.ID, fun.ID))
+		payee, err = repo.GetByID(ctx, market.ID)
+		expectNoError(t, "get payee", err)
+		if *payee.DefaultSubCategoryID != movies.ID || *payee.DefaultCategoryID != fun.ID {
+			t.Fatalf("got defaults %d and %d, want %d and %d", *payee.DefaultCategoryID, *payee.DefaultSubCategoryID, fun.ID, movies.ID)
+		}
+
+		other := createCategory(t, r, "Other", baseTime)
+		expectNoError(t, "reassign category", repo.ReassignDefaultCategory(ctx, fun.ID, other.ID))
+		payee, err = repo.GetByID(ctx, market.ID)
+		expectNoError(t, "get payee", err)
+		if *payee.DefaultCategoryID != other.ID || payee.Version != 4 {
+			t.Fatalf("got default category %d at version %d, want %d at version 4", *payee.DefaultCategoryID, payee.Version, other.ID)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		expectError(t, "delete stale version", repo.Delete(ctx, bakery.ID, 2), domain.ErrPreconditionFailed)
+		expectNoError(t, "delete", repo.Delete(ctx, bakery.ID, 1))
+
+		_, err := repo.GetByID(ctx, bakery.ID)
+		expectError(t, "get deleted payee", err, domain.ErrDataNotFound)
+		expectError(t, "delete deleted payee", repo.Delete(ctx, bakery.ID, 0), domain.ErrDataNotFound)
+	})
+}
+
+func payeeID(payee *domain.Payee) int { return payee.ID }
@@ -0,0 +1,86 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+)
+
+func testPersonRepository(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	repo := r.Person
+
+	alice := createPerson(t, r, "Alice", "alice@example.com")
+	bob := createPerson(t, r, "Bob", "bob@example.com")
+	carol := createPerson(t, r, "Carol", "carol@example.com")
+	if alice.ID == 0 || alice.Version != 1 {
+		t.Fatalf("created person has ID %d and version %d", alice.ID, alice.Version)
+	}
+
+	t.Run("Get", func(t *testing.T) {
+		person, err := repo.GetPersonByID(ctx, bob.ID)
+		expectNoError(t, "get by id", err)
+		if person.Name != "Bob" || person.Email != "bob@example.com" || person.Version != 1 {
+			t.Fatalf("got person %+v", person)
+		}
+
+		person, err = repo.GetPersonByEmail(ctx, "carol@example.com")
+		expectNoError(t, "get by email", err)
+		if person.ID != carol.ID {
+			t.Fatalf("got person %d by email, want %d", person.ID, carol.ID)
+		}
+
+		_, err = repo.GetPersonByID(ctx, carol.ID+100)
+		expectError(t, "get missing person", err, domain.ErrDataNotFound)
+		_, err = repo.GetPersonByEmail(ctx, "nobody@example.com")
+		expectError(t, "get missing email", err, domain.ErrDataNotFound)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		persons, err := repo.ListPersons(ctx, nil, 2)
+		expectNoError(t, "list first page", err)
+		expectIDs(t, "first page", idsOf(persons, personID), alice.ID, bob.ID)
+
+		persons, err = repo.ListPersons(ctx, &domain.Cursor{ID: bob.ID}, 2)
+		expectNoError(t, "list next page", err)
+		expectIDs(t, "next page", idsOf(persons, personID), carol.ID)
+
+		count, err := repo.CountPersons(ctx)
+		expectCount(t, "count", count, err, 3)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		updated, err := repo.UpdatePerson(ctx, &domain.Person{ID: alice.ID, Name: "Alice B", Email: "alice@example.com", Version: 1})
+		expectNoError(t, "update", err)
+		if updated.Name != "Alice B" || updated.Version != 2 {
+			t.Fatalf("got updated person %+v", updated)
+		}
+
+		_, err = repo.UpdatePerson(ctx, &domain.Person{ID: alice.ID, Name: "Alice C", Email: "alice@example.com", Version: 1})
+		expectError(t, "update stale version", err, domain.ErrPreconditionFailed)
+		_, err = repo.UpdatePerson(ctx, &domain.Person{ID: carol.ID + 100, Name: "Nobody", Email: "nobody@example.com", Version: 1})
+		expectError(t, "update missing person", err, domain.ErrDataNotFound)
+
+		updated, err = repo.UpdatePerson(ctx, &domain.Person{ID: alice.ID, Name: "Alice C", Email: "alice@example.com"})
+		expectNoError(t, "update skipping the version check", err)
+		if updated.Version != 3 {
+			t.Fatalf("got version %d, want 3", updated.Version)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		expectError(t, "delete stale version", repo.DeletePerson(ctx, carol.ID, 2), domain.ErrPreconditionFailed)
+		expectNoError(t, "delete", repo.DeletePerson(ctx, carol.ID, 1))
+
+		_, err := repo.GetPersonByID(ctx, carol.ID)
+		expectError(t, "get deleted person", err, domain.ErrDataNotFound)
+		expectError(t, "delete deleted person", repo.DeletePerson(ctx, carol.ID, 0), domain.ErrDataNotFound)
+
+		count, err := repo.CountPersons(ctx)
+		expectCount(t, "count after delete", count, err, 2)
+	})
+}
+
+func personID(person domain.Person) uint64 { return person.ID }
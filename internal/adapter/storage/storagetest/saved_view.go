@@ -0,0 +1,109 @@
+package storagetest
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+)
+
+func testSavedViewRepository(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	repo := r.SavedView
+
+	minAmount, hasSubCategory := 12.5, true
+	create := func(name string, filters domain.SavedViewFilters) *domain.SavedView {
+		view := &domain.SavedView{Name: name, Filters: filters, CreatedAt: baseTime, UpdatedAt: baseTime}
+		expectNoError(t, "create saved view "+name, repo.Create(ctx, view))
+		return view
+	}
+	groceries := create("Groceries", domain.SavedViewFilters{
+		CategoryIDs:    []int{1, 2},
+		SubCategoryID:  3,
+		MinAmount:      &minAmount,
+		Notes:          "milk",
+		HasSubCategory: &hasSubCategory,
+		TagIDs:         []int{4},
+		TagMatch:       "all",
+		DateRange:      "this_month",
+		Sort:           "amount:desc",
+	})
+	trips := create("Trips", domain.SavedViewFilters{StartDate: "2024-03-01", EndDate: "2024-03-31"})
+	everything := create("Everything", domain.SavedViewFilters{})
+	if groceries.ID == 0 || groceries.Version != 1 {
+		t.Fatalf("created saved view %+v", groceries)
+	}
+
+	t.Run("Get", func(t *testing.T) {
+		view, err := repo.GetByID(ctx, groceries.ID)
+		expectNoError(t, "get", err)
+		filters := view.Filters
+		if view.Name != "Groceries" || !slices.Equal(filters.CategoryIDs, []int{1, 2}) || filters.SubCategoryID != 3 ||
+			filters.MinAmount == nil || *filters.MinAmount != minAmount || filters.MaxAmount != nil || filters.Notes != "milk" ||
+			filters.HasSubCategory == nil || !*filters.HasSubCategory || !slices.Equal(filters.TagIDs, []int{4}) ||
+			filters.TagMatch != "all" || filters.DateRange != "this_month" || filters.Sort != "amount:desc" {
+			t.Fatalf("got saved view %+v", view)
+		}
+
+		view, err = repo.GetByID(ctx, trips.ID)
+		expectNoError(t, "get view with dates", err)
+		if view.Filters.StartDate != "2024-03-01" || view.Filters.EndDate != "2024-03-31" || view.Filters.CategoryIDs != nil {
+			t.Fatalf("got saved view %+v", view)
+		}
+
+		_, err = repo.GetByID(ctx, everything.ID+100)
+		expectError(t, "get missing saved view", err, domain.ErrDataNotFound)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		views, err := repo.List(ctx, nil, 2)
+		expectNoError(t, "list first page", err)
+		expectIDs(t, "first page", idsOf(views, savedViewID), groceries.ID, trips.ID)
+
+		views, err = repo.List(ctx, &domain.Cursor{ID: uint64(trips.ID)}, 2)
+		expectNoError(t, "list next page", err)
+		expectIDs(t, "next page", idsOf(views, savedViewID), everything.ID)
+
+		count, err := repo.Count(ctx)
+		expectCount(t, "count", count, err, 3)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		update := *trips
+		update.Name = "Holidays"
+		update.Filters = domain.SavedViewFilters{DateRange: "last_month"}
+		update.UpdatedAt = baseTime.Add(time.Hour)
+		expectNoError(t, "update", repo.Update(ctx, &update))
+		if update.Version != 2 {
+			t.Fatalf("got version %d, want 2", update.Version)
+		}
+
+		view, err := repo.GetByID(ctx, trips.ID)
+		expectNoError(t, "get updated saved view", err)
+		if view.Name != "Holidays" || view.Filters.DateRange != "last_month" || view.Filters.StartDate != "" || view.Version != 2 {
+			t.Fatalf("got updated saved view %+v", view)
+		}
+
+		update.Version = 1
+		expectError(t, "update stale version", repo.Update(ctx, &update), domain.ErrPreconditionFailed)
+		update.ID = everything.ID + 100
+		expectError(t, "update missing saved view", repo.Update(ctx, &update), domain.ErrDataNotFound)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		expectError(t, "delete stale version", repo.Delete(ctx, everything.ID, 2), domain.ErrPreconditionFailed)
+		expectNoError(t, "delete", repo.Delete(ctx, everything.ID, 1))
+
+		_, err := repo.GetByID(ctx, everything.ID)
+		expectError(t, "get deleted saved view", err, domain.ErrDataNotFound)
+		expectError(t, "delete deleted saved view", repo.Delete(ctx, everything.ID, 0), domain.ErrDataNotFound)
+
+		count, err := repo.Count(ctx)
+		expectCount(t, "count after delete", count, err, 2)
+	})
+}
+
+func savedViewID(view *domain.SavedView) int { return view.ID }
@@ -0,0 +1,99 @@
+package storagetest
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+)
+
+func testSettlementRepository(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	repo := r.Settlement
+
+	alice := int(createPerson(t, r, "Alice", "alice@example.com").ID)
+	bob := int(createPerson(t, r, "Bob", "bob@example.com").ID)
+	carol := int(createPerson(t, r, "Carol", "carol@example.com").ID)
+	create := func(fromPersonID, toPersonID int, amount float64) *domain.Settlement {
+		settlement := &domain.Settlement{
+			FromPersonID: fromPersonID, ToPersonID: toPersonID, Amount: amount, Date: day(10),
+			CreatedAt: baseTime, UpdatedAt: baseTime,
+		}
+		expectNoError(t, "create settlement", repo.Create(ctx, settlement))
+		return settlement
+	}
+	first := create(bob, alice, 20)
+	second := create(carol, alice, 5)
+	third := create(bob, alice, 7.5)
+	fourth := create(alice, bob, 3)
+	if first.ID == 0 || first.Version != 1 {
+		t.Fatalf("created settlement %+v", first)
+	}
+
+	t.Run("Get", func(t *testing.T) {
+		coffee := &domain.Settlement{
+			FromPersonID: carol, ToPersonID: bob, Amount: 1, Date: day(11), Notes: "coffee",
+			CreatedAt: baseTime, UpdatedAt: baseTime,
+		}
+		expectNoError(t, "create settlement with notes", repo.Create(ctx, coffee))
+		settlement, err := repo.GetByID(ctx, coffee.ID)
+		expectNoError(t, "get", err)
+		if settlement.FromPersonID != carol || settlement.ToPersonID != bob || settlement.Amount != 1 ||
+			!settlement.Date.Equal(day(11)) || settlement.Notes != "coffee" || settlement.Version != 1 {
+			t.Fatalf("got settlement %+v", settlement)
+		}
+		expectNoError(t, "delete", repo.Delete(ctx, coffee.ID, 0))
+
+		_, err = repo.GetByID(ctx, coffee.ID+100)
+		expectError(t, "get missing settlement", err, domain.ErrDataNotFound)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		settlements, err := repo.List(ctx, nil, 10, 0)
+		expectNoError(t, "list", err)
+		expectIDs(t, "list", idsOf(settlements, settlementID), first.ID, second.ID, third.ID, fourth.ID)
+
+		// A person is matched as payer or as payee
+		settlements, err = repo.List(ctx, nil, 10, bob)
+		expectNoError(t, "list by person", err)
+		expectIDs(t, "list by person", idsOf(settlements, settlementID), first.ID, third.ID, fourth.ID)
+
+		settlements, err = repo.List(ctx, &domain.Cursor{ID: uint64(first.ID)}, 1, bob)
+		expectNoError(t, "list next page by person", err)
+		expectIDs(t, "next page by person", idsOf(settlements, settlementID), third.ID)
+
+		count, err := repo.Count(ctx, 0)
+		expectCount(t, "count", count, err, 4)
+		count, err = repo.Count(ctx, carol)
+		expectCount(t, "count by person", count, err, 1)
+	})
+
+	t.Run("Totals", func(t *testing.T) {
+		totals, err := repo.Totals(ctx)
+		expectNoError(t, "totals", err)
+		expected := []domain.PersonDebt{
+			{FromPersonID: alice, ToPersonID: bob, Amount: 3},
+			{FromPersonID: bob, ToPersonID: alice, Amount: 27.5},
+			{FromPersonID: carol, ToPersonID: alice, Amount: 5},
+		}
+		if !slices.Equal(totals, expected) {
+			t.Fatalf("got totals %+v, want %+v", totals, expected)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		expectError(t, "delete stale version", repo.Delete(ctx, fourth.ID, 2), domain.ErrPreconditionFailed)
+		expectNoError(t, "delete", repo.Delete(ctx, fourth.ID, 1))
+
+		_, err := repo.GetByID(ctx, fourth.ID)
+		expectError(t, "get deleted settlement", err, domain.ErrDataNotFound)
+		expectError(t, "delete deleted settlement", repo.Delete(ctx, fourth.ID, 0), domain.ErrDataNotFound)
+
+		count, err := repo.Count(ctx, 0)
+		expectCount(t, "count after delete", count, err, 3)
+	})
+}
+
+func settlementID(settlement *domain.Settlement) int { return settlement.ID }
@@ -0,0 +1,223 @@
+// Package storagetest is a conformance suite for the repositories of the storage backends.
+// Every backend runs the same tests, so that they keep behaving alike.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+)
+
+// Factory creates the repositories of an empty store, released once the test ends
+type Factory func(t *testing.T) *storage.Repositories
+
+// Run runs the whole suite, each test against a store of its own
+func Run(t *testing.T, newRepositories Factory) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, r *storage.Repositories)
+	}{
+		{"Person", testPersonRepository},
+		{"Account", testAccountRepository},
+		{"ExpenseCategory", testExpenseCategoryRepository},
+		{"ExpenseSubCategory", testExpenseSubCategoryRepository},
+		{"Tag", testTagRepository},
+		{"Payee", testPayeeRepository},
+		{"Expense", testExpenseRepository},
+		{"ExpenseFilters", testExpenseFilters},
+		{"ExpenseSort", testExpenseSort},
+		{"ExpenseReassign", testExpenseReassign},
+		{"SavedView", testSavedViewRepository},
+		{"Settlement", testSettlementRepository},
+		{"Merge", testMergeRepository},
+		{"Attachment", testAttachmentRepository},
+		{"Audit", testAuditRepository},
+		{"Idempotency", testIdempotencyRepository},
+		{"TxManager", testTxManager},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newRepositories(t))
+		})
+	}
+}
+
+// baseTime is the creation time of the fixtures, a fixed past time keeps orderings predictable.
+// Stores keep timestamps to the microsecond.
+var baseTime = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// day returns the date of the given day of March 2024
+func day(d int) time.Time {
+	return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC)
+}
+
+// fixture holds the entities most expenses refer to
+type fixture struct {
+	persons    []*domain.Person
+	account    *domain.Account
+	categories []*domain.ExpenseCategory
+	payees     []*domain.Payee
+}
+
+// newFixture creates two persons, an account, two categories and two payees
+func newFixture(t *testing.T, r *storage.Repositories) *fixture {
+	t.Helper()
+
+	f := &fixture{}
+	f.persons = []*domain.Person{
+		createPerson(t, r, "Alice", "alice@example.com"),
+		createPerson(t, r, "Bob", "bob@example.com"),
+	}
+	f.account = createAccount(t, r, "Checking", f.persons[0].ID)
+	f.categories = []*domain.ExpenseCategory{
+		createCategory(t, r, "Food", baseTime),
+		createCategory(t, r, "Fun", baseTime.Add(time.Minute)),
+	}
+	f.payees = []*domain.Payee{
+		createPayee(t, r, "Market"),
+		createPayee(t, r, "Cinema"),
+	}
+	return f
+}
+
+// expense returns an unsaved expense of the fixture, the first category, payee and the account
+func (f *fixture) expense(amount float64, date time.Time) *domain.Expense {
+	return &domain.Expense{
+		Amount:     amount,
+		CategoryID: f.categories[0].ID,
+		Date:       date,
+		PayeeID:    f.payees[0].ID,
+		AccountID:  int(f.account.ID),
+		CreatedAt:  baseTime,
+		UpdatedAt:  baseTime,
+	}
+}
+
+func createPerson(t *testing.T, r *storage.Repositories, name, email string) *domain.Person {
+	t.Helper()
+	person, err := r.Person.CreatePerson(context.Background(), &domain.Person{Name: name, Email: email})
+	if err != nil {
+		t.Fatalf("create person %q: %v", name, err)
+	}
+	return person
+}
+
+func createAccount(t *testing.T, r *storage.Repositories, name string, ownerID uint64) *domain.Account {
+	t.Helper()
+	account, err := r.Account.CreateAccount(context.Background(), &domain.Account{
+		Name:           name,
+		Currency:       "EUR",
+		AccountType:    "checking",
+		InitialBalance: 100,
+		PrimaryOwnerID: ownerID,
+	})
+	if err != nil {
+		t.Fatalf("create account %q: %v", name, err)
+	}
+	return account
+}
+
+func createCategory(t *testing.T, r *storage.Repositories, name string, createdAt time.Time) *domain.ExpenseCategory {
+	t.Helper()
+	category := &domain.ExpenseCategory{Name: name, Aliases: []string{}, CreatedAt: createdAt, UpdatedAt: createdAt}
+	if err := r.ExpenseCategory.Create(context.Background(), category); err != nil {
+		t.Fatalf("create expense category %q: %v", name, err)
+	}
+	return category
+}
+
+func createSubCategory(t *testing.T, r *storage.Repositories, name string, categoryID int, createdAt time.Time) *domain.ExpenseSubCategory {
+	t.Helper()
+	subcategory := &domain.ExpenseSubCategory{
+		Name:              name,
+		Aliases:           []string{},
+		ExpenseCategoryID: categoryID,
+		CreatedAt:         createdAt,
+		UpdatedAt:         createdAt,
+	}
+	if err := r.ExpenseSubCategory.Create(context.Background(), subcategory); err != nil {
+		t.Fatalf("create expense subcategory %q: %v", name, err)
+	}
+	return subcategory
+}
+
+func createPayee(t *testing.T, r *storage.Repositories, name string, aliases ...string) *domain.Payee {
+	t.Helper()
+	if aliases == nil {
+		aliases = []string{}
+	}
+	payee := &domain.Payee{Name: name, Aliases: aliases, CreatedAt: baseTime, UpdatedAt: baseTime}
+	if err := r.Payee.Create(context.Background(), payee); err != nil {
+		t.Fatalf("create payee %q: %v", name, err)
+	}
+	return payee
+}
+
+func createTag(t *testing.T, r *storage.Repositories, name string) *domain.Tag {
+	t.Helper()
+	tag := &domain.Tag{Name: name, CreatedAt: baseTime, UpdatedAt: baseTime}
+	if err := r.Tag.Create(context.Background(), tag); err != nil {
+		t.Fatalf("create tag %q: %v", name, err)
+	}
+	return tag
+}
+
+func createExpense(t *testing.T, r *storage.Repositories, expense *domain.Expense) *domain.Expense {
+	t.Helper()
+	if err := r.Expense.Create(context.Background(), expense); err != nil {
+		t.Fatalf("create expense: %v", err)
+	}
+	return expense
+}
+
+// expectError fails the test unless err is the expected error
+func expectError(t *testing.T, what string, err, expected error) {
+	t.Helper()
+	if !errors.Is(err, expected) {
+		t.Fatalf("%s: got error %v, want %v", what, err, expected)
+	}
+}
+
+// expectNoError fails the test when err is not nil
+func expectNoError(t *testing.T, what string, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("%s: %v", what, err)
+	}
+}
+
+// expectCount fails the test unless count is the expected number
+func expectCount(t *testing.T, what string, count int64, err error, expected int64) {
+	t.Helper()
+	expectNoError(t, what, err)
+	if count != expected {
+		t.Fatalf("%s: got %d, want %d", what, count, expected)
+	}
+}
+
+// expectIDs fails the test unless the IDs are the expected ones, in the same order
+func expectIDs[T ~int | ~uint64](t *testing.T, what string, ids []T, expected ...T) {
+	t.Helper()
+	if len(ids) != len(expected) {
+		t.Fatalf("%s: got IDs %v, want %v", what, ids, expected)
+	}
+	for i := range ids {
+		if ids[i] != expected[i] {
+			t.Fatalf("%s: got IDs %v, want %v", what, ids, expected)
+		}
+	}
+}
+
+// idsOf returns the IDs of the given items
+func idsOf[T any, ID ~int | ~uint64](items []T, id func(T) ID) []ID {
+	ids := make([]ID, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, id(item))
+	}
+	return ids
+}
@@ -0,0 +1,87 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+)
+
+func testTagRepository(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	repo := r.Tag
+
+	travel := createTag(t, r, "Travel")
+	work := createTag(t, r, "Work")
+	gifts := createTag(t, r, "Gifts")
+	if travel.ID == 0 || travel.Version != 1 {
+		t.Fatalf("created tag %+v", travel)
+	}
+
+	t.Run("Get", func(t *testing.T) {
+		tag, err := repo.GetByID(ctx, work.ID)
+		expectNoError(t, "get", err)
+		if tag.Name != "Work" {
+			t.Fatalf("got tag %+v", tag)
+		}
+
+		tag, err = repo.GetByName(ctx, "gIfTs")
+		expectNoError(t, "get by name ignoring case", err)
+		if tag.ID != gifts.ID {
+			t.Fatalf("got tag %d by name, want %d", tag.ID, gifts.ID)
+		}
+
+		_, err = repo.GetByID(ctx, gifts.ID+100)
+		expectError(t, "get missing tag", err, domain.ErrDataNotFound)
+		_, err = repo.GetByName(ctx, "Nothing")
+		expectError(t, "get missing name", err, domain.ErrDataNotFound)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		tags, err := repo.List(ctx, nil, 2)
+		expectNoError(t, "list first page", err)
+		expectIDs(t, "first page", idsOf(tags, tagID), travel.ID, work.ID)
+
+		tags, err = repo.List(ctx, &domain.Cursor{ID: uint64(work.ID)}, 2)
+		expectNoError(t, "list next page", err)
+		expectIDs(t, "next page", idsOf(tags, tagID), gifts.ID)
+
+		count, err := repo.Count(ctx)
+		expectCount(t, "count", count, err, 3)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		update := *work
+		update.Name = "Business"
+		expectNoError(t, "update", repo.Update(ctx, &update))
+		if update.Version != 2 {
+			t.Fatalf("got version %d, want 2", update.Version)
+		}
+
+		tag, err := repo.GetByID(ctx, work.ID)
+		expectNoError(t, "get updated tag", err)
+		if tag.Name != "Business" || tag.Version != 2 {
+			t.Fatalf("got updated tag %+v", tag)
+		}
+
+		update.Version = 1
+		expectError(t, "update stale version", repo.Update(ctx, &update), domain.ErrPreconditionFailed)
+		update.ID = gifts.ID + 100
+		expectError(t, "update missing tag", repo.Update(ctx, &update), domain.ErrDataNotFound)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		expectError(t, "delete stale version", repo.Delete(ctx, gifts.ID, 2), domain.ErrPreconditionFailed)
+		expectNoError(t, "delete", repo.Delete(ctx, gifts.ID, 1))
+
+		_, err := repo.GetByID(ctx, gifts.ID)
+		expectError(t, "get deleted tag", err, domain.ErrDataNotFound)
+		expectError(t, "delete deleted tag", repo.Delete(ctx, gifts.ID, 0), domain.ErrDataNotFound)
+
+		count, err := repo.Count(ctx)
+		expectCount(t, "count after delete", count, err, 2)
+	})
+}
+
+func tagID(tag *domain.Tag) int { return tag.ID }
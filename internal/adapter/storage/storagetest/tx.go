@@ -0,0 +1,99 @@
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+)
+
+func testTxManager(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	errFailed := errors.New("unit of work failed")
+
+	t.Run("Commit", func(t *testing.T) {
+		err := r.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+			_, err := r.Person.CreatePerson(ctx, &domain.Person{Name: "Alice", Email: "alice@example.com"})
+			return err
+		})
+		expectNoError(t, "commit", err)
+
+		count, err := r.Person.CountPersons(ctx)
+		expectCount(t, "count committed persons", count, err, 1)
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		err := r.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+			person, err := r.Person.CreatePerson(ctx, &domain.Person{Name: "Bob", Email: "bob@example.com"})
+			if err != nil {
+				return err
+			}
+			if _, err := r.Account.CreateAccount(ctx, &domain.Account{
+				Name: "Checking", Currency: "EUR", AccountType: "checking", PrimaryOwnerID: person.ID,
+			}); err != nil {
+				return err
+			}
+			return errFailed
+		})
+		expectError(t, "rollback", err, errFailed)
+
+		count, err := r.Person.CountPersons(ctx)
+		expectCount(t, "count persons after rollback", count, err, 1)
+		count, err = r.Account.CountAccounts(ctx, true)
+		expectCount(t, "count accounts after rollback", count, err, 0)
+	})
+
+	t.Run("RollbackChanges", func(t *testing.T) {
+		// Rows changed and deleted by a failing unit of work are put back as they were
+		person, err := r.Person.CreatePerson(ctx, &domain.Person{Name: "Carol", Email: "carol@example.com"})
+		expectNoError(t, "create person", err)
+
+		err = r.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+			updated, err := r.Person.UpdatePerson(ctx, &domain.Person{
+				ID: person.ID, Name: "Caroline", Email: person.Email, Version: person.Version,
+			})
+			if err != nil {
+				return err
+			}
+			if err := r.Person.DeletePerson(ctx, person.ID, updated.Version); err != nil {
+				return err
+			}
+			return errFailed
+		})
+		expectError(t, "rollback", err, errFailed)
+
+		got, err := r.Person.GetPersonByID(ctx, person.ID)
+		expectNoError(t, "get person after rollback", err)
+		if got.Name != person.Name || got.Version != person.Version {
+			t.Errorf("got person %+v after rollback, want %+v", got, person)
+		}
+	})
+
+	t.Run("NestedRollback", func(t *testing.T) {
+		// A failing nested unit of work only undoes its own writes, the outer one still commits
+		err := r.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+			if err := r.Tag.Create(ctx, &domain.Tag{Name: "kept", CreatedAt: baseTime, UpdatedAt: baseTime}); err != nil {
+				return err
+			}
+			err := r.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+				if err := r.Tag.Create(ctx, &domain.Tag{Name: "undone", CreatedAt: baseTime, UpdatedAt: baseTime}); err != nil {
+					return err
+				}
+				return errFailed
+			})
+			if !errors.Is(err, errFailed) {
+				t.Errorf("nested unit of work returned %v, want %v", err, errFailed)
+			}
+			return nil
+		})
+		expectNoError(t, "commit outer unit of work", err)
+
+		tags, err := r.Tag.List(ctx, nil, 10)
+		expectNoError(t, "list tags", err)
+		if len(tags) != 1 || tags[0].Name != "kept" {
+			t.Fatalf("got tags %+v, want only the tag of the outer unit of work", tags)
+		}
+	})
+}
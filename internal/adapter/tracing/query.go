@@ -0,0 +1,39 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// rowCountKey is the number of rows a statement returned or affected
+const rowCountKey = attribute.Key("db.row_count")
+
+// StartQuery starts a client span for an SQL statement run against the database system,
+// recording the statement text. Statements are built with placeholders, so no value reaches the span.
+func StartQuery(ctx context.Context, system attribute.KeyValue, statement string) (context.Context, trace.Span) {
+	statement = strings.TrimSpace(statement)
+	operation := "QUERY"
+	if fields := strings.Fields(statement); len(fields) > 0 {
+		operation = strings.ToUpper(fields[0])
+	}
+
+	return tracer().Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			system,
+			semconv.DBOperationName(operation),
+			semconv.DBQueryText(statement),
+		),
+	)
+}
+
+// EndQuery ends the span of a statement which returned or affected rows rows
+func EndQuery(span trace.Span, rows int64, err error) {
+	span.SetAttributes(rowCountKey.Int64(rows))
+	recordError(span, err)
+	span.End()
+}
@@ -0,0 +1,457 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+// PersonService traces the calls of a PersonService
+func PersonService(next port.PersonService) port.PersonService {
+	return personService{next}
+}
+
+// AccountService traces the calls of an AccountService
+func AccountService(next port.AccountService) port.AccountService {
+	return accountService{next}
+}
+
+// ExpenseCategoryService traces the calls of an ExpenseCategoryService
+func ExpenseCategoryService(next port.ExpenseCategoryService) port.ExpenseCategoryService {
+	return expenseCategoryService{next}
+}
+
+// ExpenseSubCategoryService traces the calls of an ExpenseSubCategoryService
+func ExpenseSubCategoryService(next port.ExpenseSubCategoryService) port.ExpenseSubCategoryService {
+	return expenseSubCategoryService{next}
+}
+
+// ExpenseService traces the calls of an ExpenseService
+func ExpenseService(next port.ExpenseService) port.ExpenseService {
+	return expenseService{next}
+}
+
+// TagService traces the calls of a TagService
+func TagService(next port.TagService) port.TagService {
+	return tagService{next}
+}
+
+// PayeeService traces the calls of a PayeeService
+func PayeeService(next port.PayeeService) port.PayeeService {
+	return payeeService{next}
+}
+
+// MergeService traces the calls of a MergeService
+func MergeService(next port.MergeService) port.MergeService {
+	return mergeService{next}
+}
+
+// SavedViewService traces the calls of a SavedViewService
+func SavedViewService(next port.SavedViewService) port.SavedViewService {
+	return savedViewService{next}
+}
+
+// SettlementService traces the calls of a SettlementService
+func SettlementService(next port.SettlementService) port.SettlementService {
+	return settlementService{next}
+}
+
+// AttachmentService traces the calls of an AttachmentService
+func AttachmentService(next port.AttachmentService) port.AttachmentService {
+	return attachmentService{next}
+}
+
+// AuditService traces the calls of an AuditService
+func AuditService(next port.AuditService) port.AuditService {
+	return auditService{next}
+}
+
+// IdempotencyService traces the calls of an IdempotencyService
+func IdempotencyService(next port.IdempotencyService) port.IdempotencyService {
+	return idempotencyService{next}
+}
+
+// PurgeService traces the calls of a PurgeService
+func PurgeService(next port.PurgeService) port.PurgeService {
+	return purgeService{next}
+}
+
+// personService starts a span for each call of a PersonService
+type personService struct {
+	next port.PersonService
+}
+
+func (s personService) Create(ctx context.Context, person *domain.Person) (*domain.Person, error) {
+	return traced(ctx, "PersonService.Create", func(ctx context.Context) (*domain.Person, error) { return s.next.Create(ctx, person) })
+}
+
+func (s personService) GetPerson(ctx context.Context, id uint64) (*domain.Person, error) {
+	return traced(ctx, "PersonService.GetPerson", func(ctx context.Context) (*domain.Person, error) { return s.next.GetPerson(ctx, id) })
+}
+
+func (s personService) ListPersons(ctx context.Context, req *domain.PageRequest) (*domain.Page[domain.Person], error) {
+	return traced(ctx, "PersonService.ListPersons", func(ctx context.Context) (*domain.Page[domain.Person], error) { return s.next.ListPersons(ctx, req) })
+}
+
+func (s personService) UpdatePerson(ctx context.Context, person *domain.Person) (*domain.Person, error) {
+	return traced(ctx, "PersonService.UpdatePerson", func(ctx context.Context) (*domain.Person, error) { return s.next.UpdatePerson(ctx, person) })
+}
+
+func (s personService) PatchPerson(ctx context.Context, id uint64, req *domain.PatchPersonRequest) (*domain.Person, error) {
+	return traced(ctx, "PersonService.PatchPerson", func(ctx context.Context) (*domain.Person, error) { return s.next.PatchPerson(ctx, id, req) })
+}
+
+func (s personService) DeletePerson(ctx context.Context, id uint64, version int) error {
+	return tracedErr(ctx, "PersonService.DeletePerson", func(ctx context.Context) error { return s.next.DeletePerson(ctx, id, version) })
+}
+
+// accountService starts a span for each call of an AccountService
+type accountService struct {
+	next port.AccountService
+}
+
+func (s accountService) Create(ctx context.Context, account *domain.Account) (*domain.Account, error) {
+	return traced(ctx, "AccountService.Create", func(ctx context.Context) (*domain.Account, error) { return s.next.Create(ctx, account) })
+}
+
+func (s accountService) GetAccount(ctx context.Context, id uint64) (*domain.Account, error) {
+	return traced(ctx, "AccountService.GetAccount", func(ctx context.Context) (*domain.Account, error) { return s.next.GetAccount(ctx, id) })
+}
+
+func (s accountService) ListAccounts(ctx context.Context, req *domain.PageRequest, includeDeleted bool) (*domain.Page[domain.Account], error) {
+	return traced(ctx, "AccountService.ListAccounts", func(ctx context.Context) (*domain.Page[domain.Account], error) {
+		return s.next.ListAccounts(ctx, req, includeDeleted)
+	})
+}
+
+func (s accountService) UpdateAccount(ctx context.Context, account *domain.Account) (*domain.Account, error) {
+	return traced(ctx, "AccountService.UpdateAccount", func(ctx context.Context) (*domain.Account, error) { return s.next.UpdateAccount(ctx, account) })
+}
+
+func (s accountService) PatchAccount(ctx context.Context, id uint64, req *domain.PatchAccountRequest) (*domain.Account, error) {
+	return traced(ctx, "AccountService.PatchAccount", func(ctx context.Context) (*domain.Account, error) { return s.next.PatchAccount(ctx, id, req) })
+}
+
+func (s accountService) DeleteAccount(ctx context.Context, id uint64, version int) error {
+	return tracedErr(ctx, "AccountService.DeleteAccount", func(ctx context.Context) error { return s.next.DeleteAccount(ctx, id, version) })
+}
+
+func (s accountService) RestoreAccount(ctx context.Context, id uint64) (*domain.Account, error) {
+	return traced(ctx, "AccountService.RestoreAccount", func(ctx context.Context) (*domain.Account, error) { return s.next.RestoreAccount(ctx, id) })
+}
+
+// expenseCategoryService starts a span for each call of an ExpenseCategoryService
+type expenseCategoryService struct {
+	next port.ExpenseCategoryService
+}
+
+func (s expenseCategoryService) Create(ctx context.Context, req *domain.CreateExpenseCategoryRequest) (*domain.ExpenseCategory, error) {
+	return traced(ctx, "ExpenseCategoryService.Create", func(ctx context.Context) (*domain.ExpenseCategory, error) { return s.next.Create(ctx, req) })
+}
+
+func (s expenseCategoryService) GetByID(ctx context.Context, id int) (*domain.ExpenseCategory, error) {
+	return traced(ctx, "ExpenseCategoryService.GetByID", func(ctx context.Context) (*domain.ExpenseCategory, error) { return s.next.GetByID(ctx, id) })
+}
+
+func (s expenseCategoryService) List(ctx context.Context, req *domain.ListExpenseCategoriesRequest) (*domain.Page[*domain.ExpenseCategory], error) {
+	return traced(ctx, "ExpenseCategoryService.List", func(ctx context.Context) (*domain.Page[*domain.ExpenseCategory], error) { return s.next.List(ctx, req) })
+}
+
+func (s expenseCategoryService) Update(ctx context.Context, id int, req *domain.UpdateExpenseCategoryRequest) (*domain.ExpenseCategory, error) {
+	return traced(ctx, "ExpenseCategoryService.Update", func(ctx context.Context) (*domain.ExpenseCategory, error) { return s.next.Update(ctx, id, req) })
+}
+
+func (s expenseCategoryService) Patch(ctx context.Context, id int, req *domain.PatchExpenseCategoryRequest) (*domain.ExpenseCategory, error) {
+	return traced(ctx, "ExpenseCategoryService.Patch", func(ctx context.Context) (*domain.ExpenseCategory, error) { return s.next.Patch(ctx, id, req) })
+}
+
+func (s expenseCategoryService) Delete(ctx context.Context, id int, version int) error {
+	return tracedErr(ctx, "ExpenseCategoryService.Delete", func(ctx context.Context) error { return s.next.Delete(ctx, id, version) })
+}
+
+func (s expenseCategoryService) Restore(ctx context.Context, id int) (*domain.ExpenseCategory, error) {
+	return traced(ctx, "ExpenseCategoryService.Restore", func(ctx context.Context) (*domain.ExpenseCategory, error) { return s.next.Restore(ctx, id) })
+}
+
+// expenseSubCategoryService starts a span for each call of an ExpenseSubCategoryService
+type expenseSubCategoryService struct {
+	next port.ExpenseSubCategoryService
+}
+
+func (s expenseSubCategoryService) Create(ctx context.Context, req *domain.CreateExpenseSubCategoryRequest) (*domain.ExpenseSubCategory, error) {
+	return traced(ctx, "ExpenseSubCategoryService.Create", func(ctx context.Context) (*domain.ExpenseSubCategory, error) { return s.next.Create(ctx, req) })
+}
+
+func (s expenseSubCategoryService) GetByID(ctx context.Context, id int) (*domain.ExpenseSubCategory, error) {
+	return traced(ctx, "ExpenseSubCategoryService.GetByID", func(ctx context.Context) (*domain.ExpenseSubCategory, error) { return s.next.GetByID(ctx, id) })
+}
+
+func (s expenseSubCategoryService) List(ctx context.Context, req *domain.ListExpenseSubCategoriesRequest) (*domain.Page[*domain.ExpenseSubCategory], error) {
+	return traced(ctx, "ExpenseSubCategoryService.List", func(ctx context.Context) (*domain.Page[*domain.ExpenseSubCategory], error) {
+		return s.next.List(ctx, req)
+	})
+}
+
+func (s expenseSubCategoryService) Update(ctx context.Context, id int, req *domain.UpdateExpenseSubCategoryRequest) (*domain.ExpenseSubCategory, error) {
+	return traced(ctx, "ExpenseSubCategoryService.Update", func(ctx context.Context) (*domain.ExpenseSubCategory, error) { return s.next.Update(ctx, id, req) })
+}
+
+func (s expenseSubCategoryService) Patch(ctx context.Context, id int, req *domain.PatchExpenseSubCategoryRequest) (*domain.ExpenseSubCategory, error) {
+	return traced(ctx, "ExpenseSubCategoryService.Patch", func(ctx context.Context) (*domain.ExpenseSubCategory, error) { return s.next.Patch(ctx, id, req) })
+}
+
+func (s expenseSubCategoryService) Delete(ctx context.Context, id int, version int) error {
+	return tracedErr(ctx, "ExpenseSubCategoryService.Delete", func(ctx context.Context) error { return s.next.Delete(ctx, id, version) })
+}
+
+func (s expenseSubCategoryService) Restore(ctx context.Context, id int) (*domain.ExpenseSubCategory, error) {
+	return traced(ctx, "ExpenseSubCategoryService.Restore", func(ctx context.Context) (*domain.ExpenseSubCategory, error) { return s.next.Restore(ctx, id) })
+}
+
+// expenseService starts a span for each call of an ExpenseService
+type expenseService struct {
+	next port.ExpenseService
+}
+
+func (s expenseService) Create(ctx context.Context, req *domain.CreateExpenseRequest) (*domain.Expense, error) {
+	return traced(ctx, "ExpenseService.Create", func(ctx context.Context) (*domain.Expense, error) { return s.next.Create(ctx, req) })
+}
+
+func (s expenseService) GetByID(ctx context.Context, id int) (*domain.Expense, error) {
+	return traced(ctx, "ExpenseService.GetByID", func(ctx context.Context) (*domain.Expense, error) { return s.next.GetByID(ctx, id) })
+}
+
+func (s expenseService) List(ctx context.Context, req *domain.ListExpensesRequest) (*domain.Page[*domain.Expense], error) {
+	return traced(ctx, "ExpenseService.List", func(ctx context.Context) (*domain.Page[*domain.Expense], error) { return s.next.List(ctx, req) })
+}
+
+func (s expenseService) Update(ctx context.Context, id int, req *domain.UpdateExpenseRequest) (*domain.Expense, error) {
+	return traced(ctx, "ExpenseService.Update", func(ctx context.Context) (*domain.Expense, error) { return s.next.Update(ctx, id, req) })
+}
+
+func (s expenseService) Patch(ctx context.Context, id int, req *domain.PatchExpenseRequest) (*domain.Expense, error) {
+	return traced(ctx, "ExpenseService.Patch", func(ctx context.Context) (*domain.Expense, error) { return s.next.Patch(ctx, id, req) })
+}
+
+func (s expenseService) Bulk(ctx context.Context, req *domain.BulkExpenseRequest) (*domain.BulkExpenseResult, error) {
+	return traced(ctx, "ExpenseService.Bulk", func(ctx context.Context) (*domain.BulkExpenseResult, error) { return s.next.Bulk(ctx, req) })
+}
+
+func (s expenseService) Delete(ctx context.Context, id int, version int) error {
+	return tracedErr(ctx, "ExpenseService.Delete", func(ctx context.Context) error { return s.next.Delete(ctx, id, version) })
+}
+
+func (s expenseService) Restore(ctx context.Context, id int) (*domain.Expense, error) {
+	return traced(ctx, "ExpenseService.Restore", func(ctx context.Context) (*domain.Expense, error) { return s.next.Restore(ctx, id) })
+}
+
+func (s expenseService) TagTotals(ctx context.Context, req *domain.ListExpensesRequest) ([]domain.TagTotal, error) {
+	return traced(ctx, "ExpenseService.TagTotals", func(ctx context.Context) ([]domain.TagTotal, error) { return s.next.TagTotals(ctx, req) })
+}
+
+// tagService starts a span for each call of a TagService
+type tagService struct {
+	next port.TagService
+}
+
+func (s tagService) Create(ctx context.Context, req *domain.CreateTagRequest) (*domain.Tag, error) {
+	return traced(ctx, "TagService.Create", func(ctx context.Context) (*domain.Tag, error) { return s.next.Create(ctx, req) })
+}
+
+func (s tagService) GetByID(ctx context.Context, id int) (*domain.Tag, error) {
+	return traced(ctx, "TagService.GetByID", func(ctx context.Context) (*domain.Tag, error) { return s.next.GetByID(ctx, id) })
+}
+
+func (s tagService) List(ctx context.Context, req *domain.ListTagsRequest) (*domain.Page[*domain.Tag], error) {
+	return traced(ctx, "TagService.List", func(ctx context.Context) (*domain.Page[*domain.Tag], error) { return s.next.List(ctx, req) })
+}
+
+func (s tagService) Update(ctx context.Context, id int, req *domain.UpdateTagRequest) (*domain.Tag, error) {
+	return traced(ctx, "TagService.Update", func(ctx context.Context) (*domain.Tag, error) { return s.next.Update(ctx, id, req) })
+}
+
+func (s tagService) Delete(ctx context.Context, id int, version int) error {
+	return tracedErr(ctx, "TagService.Delete", func(ctx context.Context) error { return s.next.Delete(ctx, id, version) })
+}
+
+// payeeService starts a span for each call of a PayeeService
+type payeeService struct {
+	next port.PayeeService
+}
+
+func (s payeeService) Create(ctx context.Context, req *domain.CreatePayeeRequest) (*domain.Payee, error) {
+	return traced(ctx, "PayeeService.Create", func(ctx context.Context) (*domain.Payee, error) { return s.next.Create(ctx, req) })
+}
+
+func (s payeeService) GetByID(ctx context.Context, id int) (*domain.Payee, error) {
+	return traced(ctx, "PayeeService.GetByID", func(ctx context.Context) (*domain.Payee, error) { return s.next.GetByID(ctx, id) })
+}
+
+func (s payeeService) List(ctx context.Context, req *domain.ListPayeesRequest) (*domain.Page[*domain.Payee], error) {
+	return traced(ctx, "PayeeService.List", func(ctx context.Context) (*domain.Page[*domain.Payee], error) { return s.next.List(ctx, req) })
+}
+
+func (s payeeService) Update(ctx context.Context, id int, req *domain.UpdatePayeeRequest) (*domain.Payee, error) {
+	return traced(ctx, "PayeeService.Update", func(ctx context.Context) (*domain.Payee, error) { return s.next.Update(ctx, id, req) })
+}
+
+func (s payeeService) Delete(ctx context.Context, id int, version int) error {
+	return tracedErr(ctx, "PayeeService.Delete", func(ctx context.Context) error { return s.next.Delete(ctx, id, version) })
+}
+
+// mergeService starts a span for each call of a MergeService
+type mergeService struct {
+	next port.MergeService
+}
+
+func (s mergeService) MergePayees(ctx context.Context, targetID int, req *domain.MergeRequest) (*domain.MergeResult[*domain.Payee], error) {
+	return traced(ctx, "MergeService.MergePayees", func(ctx context.Context) (*domain.MergeResult[*domain.Payee], error) {
+		return s.next.MergePayees(ctx, targetID, req)
+	})
+}
+
+func (s mergeService) MergeExpenseCategories(ctx context.Context, targetID int, req *domain.MergeRequest) (*domain.MergeResult[*domain.ExpenseCategory], error) {
+	return traced(ctx, "MergeService.MergeExpenseCategories", func(ctx context.Context) (*domain.MergeResult[*domain.ExpenseCategory], error) {
+		return s.next.MergeExpenseCategories(ctx, targetID, req)
+	})
+}
+
+func (s mergeService) MergeExpenseSubCategories(ctx context.Context, targetID int, req *domain.MergeRequest) (*domain.MergeResult[*domain.ExpenseSubCategory], error) {
+	return traced(ctx, "MergeService.MergeExpenseSubCategories", func(ctx context.Context) (*domain.MergeResult[*domain.ExpenseSubCategory], error) {
+		return s.next.MergeExpenseSubCategories(ctx, targetID, req)
+	})
+}
+
+func (s mergeService) List(ctx context.Context, req *domain.ListMergesRequest) (*domain.Page[*domain.Merge], error) {
+	return traced(ctx, "MergeService.List", func(ctx context.Context) (*domain.Page[*domain.Merge], error) { return s.next.List(ctx, req) })
+}
+
+// savedViewService starts a span for each call of a SavedViewService
+type savedViewService struct {
+	next port.SavedViewService
+}
+
+func (s savedViewService) Create(ctx context.Context, req *domain.CreateSavedViewRequest) (*domain.SavedView, error) {
+	return traced(ctx, "SavedViewService.Create", func(ctx context.Context) (*domain.SavedView, error) { return s.next.Create(ctx, req) })
+}
+
+func (s savedViewService) GetByID(ctx context.Context, id int) (*domain.SavedView, error) {
+	return traced(ctx, "SavedViewService.GetByID", func(ctx context.Context) (*domain.SavedView, error) { return s.next.GetByID(ctx, id) })
+}
+
+func (s savedViewService) List(ctx context.Context, req *domain.ListSavedViewsRequest) (*domain.Page[*domain.SavedView], error) {
+	return traced(ctx, "SavedViewService.List", func(ctx context.Context) (*domain.Page[*domain.SavedView], error) { return s.next.List(ctx, req) })
+}
+
+func (s savedViewService) Update(ctx context.Context, id int, req *domain.UpdateSavedViewRequest) (*domain.SavedView, error) {
+	return traced(ctx, "SavedViewService.Update", func(ctx context.Context) (*domain.SavedView, error) { return s.next.Update(ctx, id, req) })
+}
+
+func (s savedViewService) Delete(ctx context.Context, id int, version int) error {
+	return tracedErr(ctx, "SavedViewService.Delete", func(ctx context.Context) error { return s.next.Delete(ctx, id, version) })
+}
+
+func (s savedViewService) ListExpenses(ctx context.Context, id int, req *domain.PageRequest) (*domain.Page[*domain.Expense], error) {
+	return traced(ctx, "SavedViewService.ListExpenses", func(ctx context.Context) (*domain.Page[*domain.Expense], error) {
+		return s.next.ListExpenses(ctx, id, req)
+	})
+}
+
+// settlementService starts a span for each call of a SettlementService
+type settlementService struct {
+	next port.SettlementService
+}
+
+func (s settlementService) Create(ctx context.Context, req *domain.CreateSettlementRequest) (*domain.Settlement, error) {
+	return traced(ctx, "SettlementService.Create", func(ctx context.Context) (*domain.Settlement, error) { return s.next.Create(ctx, req) })
+}
+
+func (s settlementService) GetByID(ctx context.Context, id int) (*domain.Settlement, error) {
+	return traced(ctx, "SettlementService.GetByID", func(ctx context.Context) (*domain.Settlement, error) { return s.next.GetByID(ctx, id) })
+}
+
+func (s settlementService) List(ctx context.Context, req *domain.ListSettlementsRequest) (*domain.Page[*domain.Settlement], error) {
+	return traced(ctx, "SettlementService.List", func(ctx context.Context) (*domain.Page[*domain.Settlement], error) { return s.next.List(ctx, req) })
+}
+
+func (s settlementService) Delete(ctx context.Context, id int, version int) error {
+	return tracedErr(ctx, "SettlementService.Delete", func(ctx context.Context) error { return s.next.Delete(ctx, id, version) })
+}
+
+func (s settlementService) Balances(ctx context.Context) (*domain.Balances, error) {
+	return traced(ctx, "SettlementService.Balances", func(ctx context.Context) (*domain.Balances, error) { return s.next.Balances(ctx) })
+}
+
+// attachmentService starts a span for each call of an AttachmentService
+type attachmentService struct {
+	next port.AttachmentService
+}
+
+func (s attachmentService) Upload(ctx context.Context, expenseID int, req *domain.UploadAttachmentRequest) (*domain.Attachment, error) {
+	return traced(ctx, "AttachmentService.Upload", func(ctx context.Context) (*domain.Attachment, error) { return s.next.Upload(ctx, expenseID, req) })
+}
+
+func (s attachmentService) List(ctx context.Context, expenseID int) ([]*domain.Attachment, error) {
+	return traced(ctx, "AttachmentService.List", func(ctx context.Context) ([]*domain.Attachment, error) { return s.next.List(ctx, expenseID) })
+}
+
+func (s attachmentService) GetByID(ctx context.Context, expenseID int, id int) (*domain.Attachment, error) {
+	return traced(ctx, "AttachmentService.GetByID", func(ctx context.Context) (*domain.Attachment, error) { return s.next.GetByID(ctx, expenseID, id) })
+}
+
+func (s attachmentService) Download(ctx context.Context, expenseID int, id int) (*domain.Attachment, io.ReadCloser, error) {
+	ctx, span := tracer().Start(ctx, "AttachmentService.Download")
+	defer span.End()
+
+	attachment, content, err := s.next.Download(ctx, expenseID, id)
+	recordError(span, err)
+	return attachment, content, err
+}
+
+func (s attachmentService) Delete(ctx context.Context, expenseID int, id int) error {
+	return tracedErr(ctx, "AttachmentService.Delete", func(ctx context.Context) error { return s.next.Delete(ctx, expenseID, id) })
+}
+
+// auditService starts a span for each call of an AuditService
+type auditService struct {
+	next port.AuditService
+}
+
+func (s auditService) List(ctx context.Context, req *domain.ListAuditEntriesRequest) (*domain.Page[*domain.AuditEntry], error) {
+	return traced(ctx, "AuditService.List", func(ctx context.Context) (*domain.Page[*domain.AuditEntry], error) { return s.next.List(ctx, req) })
+}
+
+// idempotencyService starts a span for each call of an IdempotencyService
+type idempotencyService struct {
+	next port.IdempotencyService
+}
+
+func (s idempotencyService) Begin(ctx context.Context, key, scope, requestHash string) (*domain.IdempotencyRecord, error) {
+	return traced(ctx, "IdempotencyService.Begin", func(ctx context.Context) (*domain.IdempotencyRecord, error) {
+		return s.next.Begin(ctx, key, scope, requestHash)
+	})
+}
+
+func (s idempotencyService) Complete(ctx context.Context, key, scope string, statusCode int, headers map[string]string, body []byte) error {
+	return tracedErr(ctx, "IdempotencyService.Complete", func(ctx context.Context) error { return s.next.Complete(ctx, key, scope, statusCode, headers, body) })
+}
+
+func (s idempotencyService) Release(ctx context.Context, key, scope string) error {
+	return tracedErr(ctx, "IdempotencyService.Release", func(ctx context.Context) error { return s.next.Release(ctx, key, scope) })
+}
+
+func (s idempotencyService) PurgeExpired(ctx context.Context) (int64, error) {
+	return traced(ctx, "IdempotencyService.PurgeExpired", func(ctx context.Context) (int64, error) { return s.next.PurgeExpired(ctx) })
+}
+
+// purgeService starts a span for each call of a PurgeService
+type purgeService struct {
+	next port.PurgeService
+}
+
+func (s purgeService) Purge(ctx context.Context, retention time.Duration) (*domain.PurgeResult, error) {
+	return traced(ctx, "PurgeService.Purge", func(ctx context.Context) (*domain.PurgeResult, error) { return s.next.Purge(ctx, retention) })
+}
@@ -0,0 +1,110 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the spans started by the application
+const instrumentationName = "github.com/edwins-leonardi/finaid-api"
+
+// Exporters the spans can be sent to
+const (
+	ExporterNone = "none"
+	ExporterOTLP = "otlp"
+)
+
+// New installs the global tracer provider and the W3C trace-context propagator, sending the spans
+// to the exporter selected by config. The returned function flushes the pending spans and stops the exporter.
+func New(ctx context.Context, app *config.App, config *config.Tracing) (func(context.Context) error, error) {
+	var options []sdktrace.TracerProviderOption
+	switch config.Exporter {
+	case ExporterNone:
+		// Spans are still started so that their IDs reach the logs and the downstream services
+	case ExporterOTLP:
+		var exporterOptions []otlptracehttp.Option
+		if config.OTLPEndpoint != "" {
+			exporterOptions = append(exporterOptions, otlptracehttp.WithEndpoint(config.OTLPEndpoint))
+		}
+		if config.OTLPInsecure {
+			exporterOptions = append(exporterOptions, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(ctx, exporterOptions...)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, sdktrace.WithBatcher(exporter))
+	default:
+		return nil, errors.New("unknown tracing exporter " + config.Exporter)
+	}
+
+	options = append(options, sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))))
+	provider, err := NewTracerProvider(app, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
+
+// NewTracerProvider creates a tracer provider describing the application in the resource of its spans.
+// Tests pass an in-memory exporter through sdktrace.WithSyncer to inspect the recorded spans.
+func NewTracerProvider(app *config.App, options ...sdktrace.TracerProviderOption) (*sdktrace.TracerProvider, error) {
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName(app.Name),
+		semconv.DeploymentEnvironment(app.Env),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	return sdktrace.NewTracerProvider(append([]sdktrace.TracerProviderOption{sdktrace.WithResource(res)}, options...)...), nil
+}
+
+// tracer returns the tracer of the global provider, resolved on each call so that
+// replacing the provider also applies to the spans started from then on
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// recordError marks span as failed with err, if any
+func recordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// traced runs fn inside a span named name, returning its result
+func traced[T any](ctx context.Context, name string, fn func(ctx context.Context) (T, error)) (T, error) {
+	ctx, span := tracer().Start(ctx, name)
+	defer span.End()
+
+	result, err := fn(ctx)
+	recordError(span, err)
+	return result, err
+}
+
+// tracedErr runs fn inside a span named name, returning its error
+func tracedErr(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	ctx, span := tracer().Start(ctx, name)
+	defer span.End()
+
+	err := fn(ctx)
+	recordError(span, err)
+	return err
+}
@@ -0,0 +1,172 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/config"
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage"
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/tracing"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+	"github.com/edwins-leonardi/finaid-api/internal/core/service"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// Trace and parent span of the traceparent header sent by the caller
+const (
+	callerTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	callerSpanID  = "00f067aa0ba902b7"
+)
+
+func TestRequestSpans(t *testing.T) {
+	exporter := newExporter(t)
+	persons := newPersonService(t)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(tracing.Middleware())
+	router.POST("/persons", func(c *gin.Context) {
+		var person domain.Person
+		if err := c.ShouldBindJSON(&person); err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		if _, err := persons.Create(c.Request.Context(), &person); err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Status(http.StatusCreated)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/persons", bytes.NewBufferString(`{"name":"Ada","email":"ada@example.com"}`))
+	req.Header.Set("traceparent", "00-"+callerTraceID+"-"+callerSpanID+"-01")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+
+	spans := exporter.GetSpans()
+	server := findSpan(t, spans, "POST /persons")
+	if got := server.SpanContext.TraceID().String(); got != callerTraceID {
+		t.Errorf("server span trace = %s, want the trace of the caller %s", got, callerTraceID)
+	}
+	if got := server.Parent.SpanID().String(); got != callerSpanID {
+		t.Errorf("server span parent = %s, want the span of the caller %s", got, callerSpanID)
+	}
+	if got := attributeValue(server, "http.response.status_code"); got.AsInt64() != http.StatusCreated {
+		t.Errorf("server span status code = %v, want %d", got.Emit(), http.StatusCreated)
+	}
+
+	create := findSpan(t, spans, "PersonService.Create")
+	if create.Parent.SpanID() != server.SpanContext.SpanID() {
+		t.Errorf("service span is not a child of the server span")
+	}
+
+	insert := findSpan(t, spans, "INSERT")
+	if insert.Parent.SpanID() != create.SpanContext.SpanID() {
+		t.Errorf("query span is not a child of the service span")
+	}
+	if got := attributeValue(insert, "db.query.text").AsString(); !strings.HasPrefix(got, "INSERT INTO person ") {
+		t.Errorf("query span statement = %q, want the insert of the person", got)
+	}
+	if got := attributeValue(insert, "db.row_count").AsInt64(); got != 1 {
+		t.Errorf("query span row count = %d, want 1", got)
+	}
+}
+
+func TestServiceErrorSpan(t *testing.T) {
+	exporter := newExporter(t)
+	persons := newPersonService(t)
+
+	if _, err := persons.GetPerson(context.Background(), 42); !errors.Is(err, domain.ErrDataNotFound) {
+		t.Fatalf("GetPerson error = %v, want %v", err, domain.ErrDataNotFound)
+	}
+
+	spans := exporter.GetSpans()
+	get := findSpan(t, spans, "PersonService.GetPerson")
+	if get.Status.Code != codes.Error {
+		t.Errorf("service span status = %v, want %v", get.Status.Code, codes.Error)
+	}
+
+	// Finding no row is the answer of the statement, the service decides it is an error
+	query := findSpan(t, spans, "SELECT")
+	if query.Status.Code == codes.Error {
+		t.Errorf("query span status = %v, want no error", query.Status.Code)
+	}
+	if got := attributeValue(query, "db.row_count").AsInt64(); got != 0 {
+		t.Errorf("query span row count = %d, want 0", got)
+	}
+}
+
+// newExporter installs a global tracer provider recording the spans in memory until the test ends
+func newExporter(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider, err := tracing.NewTracerProvider(&config.App{Name: "finaid-test"}, sdktrace.WithSyncer(exporter))
+	if err != nil {
+		t.Fatalf("create tracer provider: %v", err)
+	}
+
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+		provider.Shutdown(context.Background())
+	})
+	return exporter
+}
+
+// newPersonService creates a traced person service storing the persons in a migrated SQLite database
+func newPersonService(t *testing.T) port.PersonService {
+	t.Helper()
+	storageConfig := &config.Storage{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "finaid.db")}
+	r, err := storage.New(context.Background(), storageConfig, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("create repositories: %v", err)
+	}
+	t.Cleanup(func() { r.Close() })
+	if err := r.Migrator.Up(context.Background()); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return tracing.PersonService(service.NewPersonService(r.Person, r.TxManager, r.Audit))
+}
+
+// findSpan returns the first recorded span named name
+func findSpan(t *testing.T, spans tracetest.SpanStubs, name string) tracetest.SpanStub {
+	t.Helper()
+	for _, span := range spans {
+		if span.Name == name {
+			return span
+		}
+	}
+	t.Fatalf("no span named %q", name)
+	return tracetest.SpanStub{}
+}
+
+// attributeValue returns the value of the attribute key of span, or an empty value without it
+func attributeValue(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
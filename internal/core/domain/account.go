@@ -3,13 +3,26 @@ package domain
 import "time"
 
 type Account struct {
-	ID             uint64
-	Name           string
-	Currency       string
-	AccountType    string
-	InitialBalance float64
-	PrimaryOwnerID uint64
-	SecondOwnerID  *uint64 // Optional - pointer to allow nil
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
+	ID             uint64     `json:"id"`
+	Name           string     `json:"name"`
+	Currency       string     `json:"currency"`
+	AccountType    string     `json:"account_type"`
+	InitialBalance float64    `json:"initial_balance"`
+	PrimaryOwnerID uint64     `json:"primary_owner_id"`
+	SecondOwnerID  *uint64    `json:"second_owner_id,omitempty"` // Optional - pointer to allow nil
+	Version        int        `json:"version"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"` // Set when the account is soft deleted
+}
+
+// PatchAccountRequest represents a JSON merge patch of an Account, only the present members are changed
+type PatchAccountRequest struct {
+	Version        int            `json:"-"` // Version the patch is based on, 0 skips the check
+	Name           Patch[string]  `json:"name" swaggertype:"string"`
+	Currency       Patch[string]  `json:"currency" swaggertype:"string"`
+	AccountType    Patch[string]  `json:"account_type" swaggertype:"string"`
+	InitialBalance Patch[float64] `json:"initial_balance" swaggertype:"number"`
+	PrimaryOwnerID Patch[uint64]  `json:"primary_owner_id" swaggertype:"integer"`
+	SecondOwnerID  Patch[uint64]  `json:"second_owner_id" swaggertype:"integer"` // null removes the second owner
 }
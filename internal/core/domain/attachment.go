@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"io"
+	"time"
+)
+
+// MaxAttachmentSize is the largest attachment accepted, in bytes
+const MaxAttachmentSize = 10 << 20
+
+// AttachmentContentTypes are the content types an attachment can have, sniffed from its content
+var AttachmentContentTypes = []string{
+	"application/pdf",
+	"image/jpeg",
+	"image/png",
+	"image/gif",
+	"image/webp",
+}
+
+// Attachment represents a scanned receipt or invoice kept with an expense
+type Attachment struct {
+	ID          int       `json:"id"`
+	ExpenseID   int       `json:"expense_id"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type" example:"application/pdf"`
+	Size        int64     `json:"size"`                   // In bytes
+	Checksum    string    `json:"checksum"`               // Hex encoded SHA-256 of the content
+	DownloadURL string    `json:"download_url,omitempty"` // Signed link, valid for a limited time
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// BlobKey returns the key of the attachment content in the blob store.
+// Content is stored once per checksum, attachments with the same content share it.
+func (a *Attachment) BlobKey() string {
+	return "attachments/" + a.Checksum[:2] + "/" + a.Checksum
+}
+
+// UploadAttachmentRequest represents the request to attach a file to an expense
+type UploadAttachmentRequest struct {
+	FileName string
+	Size     int64
+	Content  io.Reader
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audited entity types
+const (
+	AuditEntityPerson             = "person"
+	AuditEntityAccount            = "account"
+	AuditEntityExpenseCategory    = "expense_category"
+	AuditEntityExpenseSubCategory = "expense_subcategory"
+	AuditEntityExpense            = "expense"
+	AuditEntitySavedView          = "saved_view"
+	AuditEntityTag                = "tag"
+	AuditEntitySettlement         = "settlement"
+	AuditEntityAttachment         = "attachment"
+	AuditEntityPayee              = "payee"
+)
+
+// Audited actions
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore"
+	AuditActionMerge   = "merge" // The record was merged into another one
+)
+
+// AuditEntry represents a single append-only record of a mutation
+type AuditEntry struct {
+	ID         uint64          `json:"id"`
+	EntityType string          `json:"entity_type"`
+	EntityID   uint64          `json:"entity_id"`
+	Action     string          `json:"action"`
+	Actor      string          `json:"actor"`
+	Before     json.RawMessage `json:"before,omitempty"` // Snapshot before the mutation, nil on create
+	After      json.RawMessage `json:"after,omitempty"`  // Snapshot after the mutation, nil on delete
+	RequestID  string          `json:"request_id,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// ListAuditEntriesRequest represents the request to browse the audit trail
+type ListAuditEntriesRequest struct {
+	PageRequest
+	EntityType string `form:"entity"` // Optional filter by entity type
+	EntityID   uint64 `form:"id"`     // Optional filter by entity id, requires entity
+}
+
+// IsAuditEntityType reports whether the given value is a known audited entity type
+func IsAuditEntityType(entityType string) bool {
+	switch entityType {
+	case AuditEntityPerson, AuditEntityAccount, AuditEntityExpenseCategory, AuditEntityExpenseSubCategory, AuditEntityExpense,
+		AuditEntitySavedView, AuditEntityTag, AuditEntitySettlement, AuditEntityAttachment,
+		AuditEntityPayee:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"context"
+	"log/slog"
+)
+
+type (
+	actorContextKey     struct{}
+	requestIDContextKey struct{}
+	loggerContextKey    struct{}
+)
+
+// DefaultActor is recorded when a request does not identify who performed it
+const DefaultActor = "anonymous"
+
+// WithActor returns a copy of ctx carrying the identity performing the request
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext returns the identity performing the request, or DefaultActor
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorContextKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return DefaultActor
+}
+
+// WithRequestID returns a copy of ctx carrying the id of the current request
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+// RequestIDFromContext returns the id of the current request, if any
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
+
+// WithLogger returns a copy of ctx carrying the logger of the current request or job
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+// LoggerFromContext returns the logger of the current request or job, or the default logger.
+// The logger of a request carries its id, route and actor, so that every line logged while
+// serving it can be correlated.
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerContextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"errors"
+	"strings"
 )
 
 var (
@@ -15,4 +16,71 @@ var (
 	ErrConflictingData = errors.New("data conflicts with existing data in unique column")
 	// ErrInvalidInput is an error for when input validation fails
 	ErrInvalidInput = errors.New("invalid input")
+	// ErrPreconditionFailed is an error for when data was modified since the version the caller based its change on
+	ErrPreconditionFailed = errors.New("data has been modified since it was read")
+	// ErrPreconditionRequired is an error for when a change does not state the version it is based on
+	ErrPreconditionRequired = errors.New("the version of the data being changed is required")
+	// ErrIdempotencyKeyReused is an error for when an idempotency key is sent again with a different request
+	ErrIdempotencyKeyReused = errors.New("idempotency key was already used with a different request")
+	// ErrIdempotencyRequestInProgress is an error for when a request with the same idempotency key has not finished yet
+	ErrIdempotencyRequestInProgress = errors.New("a request with the same idempotency key is still in progress")
+	// ErrPayloadTooLarge is an error for when uploaded content is larger than allowed
+	ErrPayloadTooLarge = errors.New("content is larger than allowed")
+	// ErrUnsupportedMediaType is an error for when uploaded content is not of an accepted type
+	ErrUnsupportedMediaType = errors.New("content type is not supported")
+	// ErrForbidden is an error for when a request is not allowed to access the data
+	ErrForbidden = errors.New("access to the data is not allowed")
+	// ErrReferencedData is an error for when data cannot be removed because other data still refers to it
+	ErrReferencedData = errors.New("data is still referenced by other data")
+	// ErrInvalidReference is an error for when data refers to other data that does not exist
+	ErrInvalidReference = errors.New("data refers to other data that does not exist")
 )
+
+// ConstraintError is an error for when a change violates a constraint of the stored data.
+// It wraps the domain error the violation stands for and names the constraint.
+type ConstraintError struct {
+	Err        error
+	Constraint string // Name of the violated constraint, empty when the storage does not tell
+}
+
+// Error returns the message of the wrapped domain error
+func (e *ConstraintError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped domain error
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}
+
+// FieldError describes an input field that failed validation
+type FieldError struct {
+	Field   string `json:"field" example:"amount"`                   // Name of the field as sent by the client, with the path to it for nested fields
+	Rule    string `json:"rule" example:"gt"`                        // Validation rule the value broke
+	Message string `json:"message" example:"must be greater than 0"` // Human readable explanation
+}
+
+// ValidationError is an error for when input fields fail validation.
+// It wraps ErrInvalidInput and lists the fields at fault.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// Error returns the invalid input message followed by the fields at fault
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Field + " " + f.Message
+	}
+	return ErrInvalidInput.Error() + ": " + strings.Join(msgs, "; ")
+}
+
+// Unwrap returns ErrInvalidInput
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidInput
+}
+
+// InvalidField returns a validation error for a single field breaking a rule
+func InvalidField(field, rule, message string) error {
+	return &ValidationError{Fields: []FieldError{{Field: field, Rule: rule, Message: message}}}
+}
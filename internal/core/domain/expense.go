@@ -1,51 +1,150 @@
 package domain
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // Expense represents an expense in the system
 type Expense struct {
-	ID            int       `json:"id"`
-	Amount        float64   `json:"amount"`
-	CategoryID    int       `json:"category_id"`
-	SubCategoryID *int      `json:"subcategory_id,omitempty"` // Optional
-	Date          time.Time `json:"date"`
-	PayeeID       int       `json:"payee_id"`   // Person who received the payment
-	AccountID     int       `json:"account_id"` // Account from which the expense was paid
-	Notes         string    `json:"notes,omitempty"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID            int             `json:"id"`
+	Amount        float64         `json:"amount"`
+	CategoryID    int             `json:"category_id"`
+	SubCategoryID *int            `json:"subcategory_id,omitempty"` // Optional
+	Date          time.Time       `json:"date"`
+	PayeeID       int             `json:"payee_id"`   // Payee who received the payment
+	AccountID     int             `json:"account_id"` // Account from which the expense was paid
+	Notes         string          `json:"notes,omitempty"`
+	TagIDs        []int           `json:"tag_ids,omitempty"`
+	Splits        []ExpenseSplit  `json:"splits,omitempty"`  // Set when the amount is shared between several categories
+	Sharing       *ExpenseSharing `json:"sharing,omitempty"` // Set when the cost is shared between persons
+	Version       int             `json:"version"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+	DeletedAt     *time.Time      `json:"deleted_at,omitempty"` // Set when the expense is soft deleted
 }
 
 // CreateExpenseRequest represents the request to create an expense
 type CreateExpenseRequest struct {
-	Amount        float64 `json:"amount" binding:"required,min=0"`
-	CategoryID    int     `json:"category_id" binding:"required,min=1"`
-	SubCategoryID *int    `json:"subcategory_id,omitempty"`
-	Date          string  `json:"date" binding:"required"` // Format: YYYY-MM-DD
-	PayeeID       int     `json:"payee_id" binding:"required,min=1"`
-	AccountID     int     `json:"account_id" binding:"required,min=1"`
-	Notes         string  `json:"notes,omitempty"`
+	Amount        float64         `json:"amount" binding:"required,min=0"`
+	CategoryID    int             `json:"category_id" binding:"omitempty,min=1"` // Defaults to the default category of the payee
+	SubCategoryID *int            `json:"subcategory_id,omitempty"`
+	Date          string          `json:"date" binding:"required"` // Format: YYYY-MM-DD
+	PayeeID       int             `json:"payee_id" binding:"required,min=1"`
+	AccountID     int             `json:"account_id" binding:"required,min=1"`
+	Notes         string          `json:"notes,omitempty"`
+	TagIDs        []int           `json:"tag_ids,omitempty"`
+	Splits        []ExpenseSplit  `json:"splits,omitempty"` // Amounts must add up to the expense amount
+	Sharing       *ExpenseSharing `json:"sharing,omitempty"`
 }
 
 // UpdateExpenseRequest represents the request to update an expense
 type UpdateExpenseRequest struct {
-	Amount        float64 `json:"amount" binding:"required,min=0"`
-	CategoryID    int     `json:"category_id" binding:"required,min=1"`
-	SubCategoryID *int    `json:"subcategory_id,omitempty"`
-	Date          string  `json:"date" binding:"required"` // Format: YYYY-MM-DD
-	PayeeID       int     `json:"payee_id" binding:"required,min=1"`
-	AccountID     int     `json:"account_id" binding:"required,min=1"`
+	Version       int             `json:"-"` // Version the update is based on, 0 skips the check
+	Amount        float64         `json:"amount" binding:"required,min=0"`
+	CategoryID    int             `json:"category_id" binding:"omitempty,min=1"` // Defaults to the default category of the payee
+	SubCategoryID *int            `json:"subcategory_id,omitempty"`
+	Date          string          `json:"date" binding:"required"` // Format: YYYY-MM-DD
+	PayeeID       int             `json:"payee_id" binding:"required,min=1"`
+	AccountID     int             `json:"account_id" binding:"required,min=1"`
+	Notes         string          `json:"notes,omitempty"`
+	TagIDs        []int           `json:"tag_ids,omitempty"`
+	Splits        []ExpenseSplit  `json:"splits,omitempty"` // Amounts must add up to the expense amount
+	Sharing       *ExpenseSharing `json:"sharing,omitempty"`
+}
+
+// PatchExpenseRequest represents a JSON merge patch of an expense, only the present members are changed
+type PatchExpenseRequest struct {
+	Version       int                        `json:"-"` // Version the patch is based on, 0 skips the check
+	Amount        Patch[float64]             `json:"amount" swaggertype:"number"`
+	CategoryID    Patch[int]                 `json:"category_id" swaggertype:"integer"`
+	SubCategoryID Patch[int]                 `json:"subcategory_id" swaggertype:"integer"` // null removes the subcategory
+	Date          Patch[string]              `json:"date" swaggertype:"string"`            // Format: YYYY-MM-DD
+	PayeeID       Patch[int]                 `json:"payee_id" swaggertype:"integer"`
+	AccountID     Patch[int]                 `json:"account_id" swaggertype:"integer"`
+	Notes         Patch[string]              `json:"notes" swaggertype:"string"`          // null clears the notes
+	TagIDs        Patch[[]int]               `json:"tag_ids" swaggertype:"array,integer"` // Replaces every tag, null removes them
+	Splits        Patch[[]ExpenseSplit]      `json:"splits" swaggertype:"array,object"`   // Replaces every split line, null removes them
+	Sharing       Patch[PatchExpenseSharing] `json:"sharing" swaggertype:"object"`        // Merged into the sharing, null stops sharing the cost
+}
+
+// ExpenseSplit represents the share of an expense spent in a category.
+// When an expense is split, its lines rather than its own category are used to filter and total it by category.
+type ExpenseSplit struct {
+	CategoryID    int     `json:"category_id"`
+	SubCategoryID *int    `json:"subcategory_id,omitempty"` // Optional
+	Amount        float64 `json:"amount"`
 	Notes         string  `json:"notes,omitempty"`
 }
 
+// MinExpenseSplits is the smallest number of lines of a split expense
+const MinExpenseSplits = 2
+
 // ListExpensesRequest represents the request to list expenses
 type ListExpensesRequest struct {
-	Skip          int    `form:"skip"`
-	Limit         int    `form:"limit"`
-	CategoryID    int    `form:"category_id"`    // Optional filter by category
-	SubCategoryID int    `form:"subcategory_id"` // Optional filter by subcategory
-	PayeeID       int    `form:"payee_id"`       // Optional filter by payee
-	AccountID     int    `form:"account_id"`     // Optional filter by account
-	StartDate     string `form:"start_date"`     // Optional filter by date range (YYYY-MM-DD)
-	EndDate       string `form:"end_date"`       // Optional filter by date range (YYYY-MM-DD)
+	PageRequest
+	CategoryIDs    []int    `form:"category_id"`     // Optional filter by categories, repeat to match any of them
+	SubCategoryID  int      `form:"subcategory_id"`  // Optional filter by subcategory
+	PayeeIDs       []int    `form:"payee_id"`        // Optional filter by payees, repeat to match any of them
+	AccountIDs     []int    `form:"account_id"`      // Optional filter by accounts, repeat to match any of them
+	MinAmount      *float64 `form:"min_amount"`      // Optional filter by amount range, inclusive
+	MaxAmount      *float64 `form:"max_amount"`      // Optional filter by amount range, inclusive
+	Notes          string   `form:"notes"`           // Optional full-text search in the notes
+	HasSubCategory *bool    `form:"has_subcategory"` // Optional filter on whether a subcategory is set
+	TagIDs         []int    `form:"tag_id"`          // Optional filter by tags, repeat to match several of them
+	TagMatch       string   `form:"tag_match"`       // Whether any (default) or all of the tags must be set
+	StartDate      string   `form:"start_date"`      // Optional filter by date range (YYYY-MM-DD)
+	EndDate        string   `form:"end_date"`        // Optional filter by date range (YYYY-MM-DD)
+	Sort           string   `form:"sort"`            // Field and optional direction, e.g. amount:asc, defaults to date:desc
+	IncludeDeleted bool     `form:"include_deleted"` // Include soft-deleted expenses
+}
+
+// Fields expenses can be sorted by
+const (
+	ExpenseSortDate      = "date"
+	ExpenseSortAmount    = "amount"
+	ExpenseSortCreatedAt = "created_at"
+)
+
+// ExpenseSort represents the ordering of an expense list.
+// Expenses with the same value are ordered by creation time then ID, in the same direction.
+type ExpenseSort struct {
+	Field      string
+	Descending bool
+}
+
+// DefaultExpenseSort lists the most recent expenses first
+var DefaultExpenseSort = ExpenseSort{Field: ExpenseSortDate, Descending: true}
+
+// ParseExpenseSort parses a sort parameter made of a field and an optional asc or desc direction,
+// separated by a colon. The direction defaults to desc and an empty value to DefaultExpenseSort.
+func ParseExpenseSort(value string) (ExpenseSort, error) {
+	if value == "" {
+		return DefaultExpenseSort, nil
+	}
+
+	field, direction, _ := strings.Cut(value, ":")
+	sort := ExpenseSort{Field: field, Descending: true}
+	switch field {
+	case ExpenseSortDate, ExpenseSortAmount, ExpenseSortCreatedAt:
+	default:
+		return sort, InvalidField("sort", "oneof", "must be one of date, amount, created_at")
+	}
+	switch direction {
+	case "", "desc":
+	case "asc":
+		sort.Descending = false
+	default:
+		return sort, InvalidField("sort", "oneof", "must have a direction of asc or desc")
+	}
+
+	return sort, nil
+}
+
+// String returns the sort in the format accepted by ParseExpenseSort
+func (s ExpenseSort) String() string {
+	if s.Descending {
+		return s.Field + ":desc"
+	}
+	return s.Field + ":asc"
 }
@@ -0,0 +1,76 @@
+package domain
+
+// MaxBulkExpenseItems caps the number of expenses a single bulk request may touch
+const MaxBulkExpenseItems = 1000
+
+// Bulk operation names reported in the per-item results
+const (
+	BulkOperationCreate = "create"
+	BulkOperationUpdate = "update"
+	BulkOperationDelete = "delete"
+)
+
+// Bulk item statuses reported in the per-item results
+const (
+	BulkStatusSucceeded  = "succeeded"
+	BulkStatusFailed     = "failed"
+	BulkStatusRolledBack = "rolled_back" // Succeeded but undone because another item failed
+)
+
+// BulkExpenseRequest represents a batch of expense operations executed in a single transaction
+type BulkExpenseRequest struct {
+	AllOrNothing bool                   `json:"all_or_nothing"`   // Roll back every item when any of them fails
+	Create       []CreateExpenseRequest `json:"create,omitempty"` // Expenses to create
+	Update       *BulkExpenseUpdate     `json:"update,omitempty"` // Changes applied to every matching expense
+	Delete       []int                  `json:"delete,omitempty"` // IDs of the expenses to delete
+}
+
+// BulkExpenseUpdate represents changes applied to every expense matching a filter
+type BulkExpenseUpdate struct {
+	Filter BulkExpenseFilter  `json:"filter"`
+	Set    BulkExpenseChanges `json:"set"`
+}
+
+// BulkExpenseFilter selects the expenses of a bulk update, at least one criterion is required
+type BulkExpenseFilter struct {
+	CategoryID    int    `json:"category_id,omitempty"`
+	SubCategoryID int    `json:"subcategory_id,omitempty"`
+	PayeeID       int    `json:"payee_id,omitempty"`
+	AccountID     int    `json:"account_id,omitempty"`
+	StartDate     string `json:"start_date,omitempty"` // Format: YYYY-MM-DD
+	EndDate       string `json:"end_date,omitempty"`   // Format: YYYY-MM-DD
+}
+
+// IsEmpty reports whether the filter would match every expense
+func (f BulkExpenseFilter) IsEmpty() bool {
+	return f == BulkExpenseFilter{}
+}
+
+// BulkExpenseChanges represents the fields a bulk update sets, with merge patch semantics
+type BulkExpenseChanges struct {
+	CategoryID    Patch[int] `json:"category_id" swaggertype:"integer"`
+	SubCategoryID Patch[int] `json:"subcategory_id" swaggertype:"integer"` // null removes the subcategory
+	AccountID     Patch[int] `json:"account_id" swaggertype:"integer"`
+}
+
+// IsEmpty reports whether the changes leave every field untouched
+func (c BulkExpenseChanges) IsEmpty() bool {
+	return !c.CategoryID.Set && !c.SubCategoryID.Set && !c.AccountID.Set
+}
+
+// BulkExpenseItemResult represents the outcome of a single item of a bulk request
+type BulkExpenseItemResult struct {
+	Operation string `json:"operation"`       // create, update or delete
+	Index     int    `json:"index"`           // Position of the item in its operation list, or among the matched expenses for updates
+	ID        int    `json:"id,omitempty"`    // ID of the affected expense
+	Status    string `json:"status"`          // succeeded, failed or rolled_back
+	Error     string `json:"error,omitempty"` // Reason of the failure
+}
+
+// BulkExpenseResult represents the outcome of a bulk request
+type BulkExpenseResult struct {
+	Committed bool                    `json:"committed"` // Whether the transaction was committed
+	Succeeded int                     `json:"succeeded"`
+	Failed    int                     `json:"failed"`
+	Results   []BulkExpenseItemResult `json:"results"`
+}
@@ -2,26 +2,42 @@ package domain
 
 import "time"
 
+// MaxCategoryAliases is the largest number of aliases an expense category or subcategory can have
+const MaxCategoryAliases = 50
+
 // ExpenseCategory represents an expense category in the system
 type ExpenseCategory struct {
-	ID        int       `json:"id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	Aliases   []string   `json:"aliases"` // Other names the category goes by, e.g. those of categories merged into it
+	Version   int        `json:"version"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"` // Set when the category is soft deleted
 }
 
 // CreateExpenseCategoryRequest represents the request to create an expense category
 type CreateExpenseCategoryRequest struct {
-	Name string `json:"name" binding:"required,min=1,max=100"`
+	Name    string   `json:"name" binding:"required,min=1,max=100"`
+	Aliases []string `json:"aliases,omitempty" binding:"max=50,dive,max=100"`
 }
 
 // UpdateExpenseCategoryRequest represents the request to update an expense category
 type UpdateExpenseCategoryRequest struct {
-	Name string `json:"name" binding:"required,min=1,max=100"`
+	Version int      `json:"-"` // Version the update is based on, 0 skips the check
+	Name    string   `json:"name" binding:"required,min=1,max=100"`
+	Aliases []string `json:"aliases,omitempty" binding:"max=50,dive,max=100"` // Omitted aliases are kept
+}
+
+// PatchExpenseCategoryRequest represents a JSON merge patch of an expense category, only the present members are changed
+type PatchExpenseCategoryRequest struct {
+	Version int             `json:"-"` // Version the patch is based on, 0 skips the check
+	Name    Patch[string]   `json:"name" swaggertype:"string"`
+	Aliases Patch[[]string] `json:"aliases" swaggertype:"array,string"` // Replaces every alias, null removes them
 }
 
 // ListExpenseCategoriesRequest represents the request to list expense categories
 type ListExpenseCategoriesRequest struct {
-	Skip  int `form:"skip"`
-	Limit int `form:"limit"`
+	PageRequest
+	IncludeDeleted bool `form:"include_deleted"` // Include soft-deleted categories
 }
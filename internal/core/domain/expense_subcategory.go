@@ -4,28 +4,42 @@ import "time"
 
 // ExpenseSubCategory represents an expense subcategory in the system
 type ExpenseSubCategory struct {
-	ID                int       `json:"id"`
-	Name              string    `json:"name"`
-	ExpenseCategoryID int       `json:"expense_category_id"`
-	CreatedAt         time.Time `json:"created_at"`
-	UpdatedAt         time.Time `json:"updated_at"`
+	ID                int        `json:"id"`
+	Name              string     `json:"name"`
+	Aliases           []string   `json:"aliases"` // Other names the subcategory goes by, e.g. those of subcategories merged into it
+	ExpenseCategoryID int        `json:"expense_category_id"`
+	Version           int        `json:"version"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	DeletedAt         *time.Time `json:"deleted_at,omitempty"` // Set when the subcategory is soft deleted
 }
 
 // CreateExpenseSubCategoryRequest represents the request to create an expense subcategory
 type CreateExpenseSubCategoryRequest struct {
-	Name              string `json:"name" binding:"required,min=1,max=100"`
-	ExpenseCategoryID int    `json:"expense_category_id" binding:"required,min=1"`
+	Name              string   `json:"name" binding:"required,min=1,max=100"`
+	Aliases           []string `json:"aliases,omitempty" binding:"max=50,dive,max=100"`
+	ExpenseCategoryID int      `json:"expense_category_id" binding:"required,min=1"`
 }
 
 // UpdateExpenseSubCategoryRequest represents the request to update an expense subcategory
 type UpdateExpenseSubCategoryRequest struct {
-	Name              string `json:"name" binding:"required,min=1,max=100"`
-	ExpenseCategoryID int    `json:"expense_category_id" binding:"required,min=1"`
+	Version           int      `json:"-"` // Version the update is based on, 0 skips the check
+	Name              string   `json:"name" binding:"required,min=1,max=100"`
+	Aliases           []string `json:"aliases,omitempty" binding:"max=50,dive,max=100"` // Omitted aliases are kept
+	ExpenseCategoryID int      `json:"expense_category_id" binding:"required,min=1"`
+}
+
+// PatchExpenseSubCategoryRequest represents a JSON merge patch of an expense subcategory, only the present members are changed
+type PatchExpenseSubCategoryRequest struct {
+	Version           int             `json:"-"` // Version the patch is based on, 0 skips the check
+	Name              Patch[string]   `json:"name" swaggertype:"string"`
+	Aliases           Patch[[]string] `json:"aliases" swaggertype:"array,string"` // Replaces every alias, null removes them
+	ExpenseCategoryID Patch[int]      `json:"expense_category_id" swaggertype:"integer"`
 }
 
 // ListExpenseSubCategoriesRequest represents the request to list expense subcategories
 type ListExpenseSubCategoriesRequest struct {
-	Skip              int `form:"skip"`
-	Limit             int `form:"limit"`
-	ExpenseCategoryID int `form:"expense_category_id"` // Optional filter by category
+	PageRequest
+	ExpenseCategoryID int  `form:"expense_category_id"` // Optional filter by category
+	IncludeDeleted    bool `form:"include_deleted"`     // Include soft-deleted subcategories
 }
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// IdempotencyRecord stores the outcome of a request sent with an idempotency key,
+// so that retries of the same request replay it instead of repeating its effects
+type IdempotencyRecord struct {
+	Key         string
+	Scope       string            // Method and route the key was used on
+	RequestHash string            // Fingerprint of the request body
+	StatusCode  int               // 0 while the original request is still in progress
+	Headers     map[string]string // Response headers replayed along with the body
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+// Completed reports whether the response of the original request has been stored
+func (r *IdempotencyRecord) Completed() bool {
+	return r.StatusCode != 0
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// MaxMergeSources is the largest number of records merged at once
+const MaxMergeSources = 50
+
+// Merge records a payee, expense category or subcategory merged into another one of the same kind
+type Merge struct {
+	ID           uint64          `json:"id"`
+	EntityType   string          `json:"entity_type"` // Audited entity type of the merged records
+	SourceID     int             `json:"source_id"`
+	TargetID     int             `json:"target_id"`
+	Source       json.RawMessage `json:"source" swaggertype:"object"` // Snapshot of the merged record
+	ExpenseCount int64           `json:"expense_count"`               // Number of expenses moved over to the target
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// MergeRequest represents the request to merge records into the one of the path
+type MergeRequest struct {
+	Version   int   `json:"-"` // Version of the target the merge is based on, 0 skips the check
+	SourceIDs []int `json:"source_ids" binding:"required,min=1,max=50,dive,min=1"`
+}
+
+// MergeResult represents the target of a merge once the sources are merged into it
+type MergeResult[T any] struct {
+	Target T        `json:"target"`
+	Merges []*Merge `json:"merges"` // Subcategories merged along with their categories come after them
+}
+
+// ListMergesRequest represents the request to list past merges
+type ListMergesRequest struct {
+	PageRequest
+	EntityType string `form:"entity"`    // Optional filter by entity type
+	TargetID   int    `form:"target_id"` // Optional filter by target, requires entity
+}
+
+// IsMergeEntityType reports whether records of the given audited entity type can be merged
+func IsMergeEntityType(entityType string) bool {
+	switch entityType {
+	case AuditEntityPayee, AuditEntityExpenseCategory, AuditEntityExpenseSubCategory:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+const (
+	// DefaultPageSize is the number of items returned when a list request sets no limit
+	DefaultPageSize = 10
+	// MaxPageSize is the largest number of items a list request can ask for
+	MaxPageSize = 100
+)
+
+// PageRequest represents the cursor pagination parameters of a list request
+type PageRequest struct {
+	Cursor       string `form:"cursor"`        // Opaque cursor returned as next_cursor by the previous page
+	Limit        int    `form:"limit"`         // Defaults to DefaultPageSize, at most MaxPageSize
+	IncludeTotal bool   `form:"include_total"` // Count every matching item, which costs an extra query
+}
+
+// Page represents a page of a list read with cursor (keyset) pagination
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"` // Empty on the last page
+	HasMore    bool   `json:"has_more"`
+	TotalCount *int64 `json:"total_count,omitempty"` // Only set when requested
+}
+
+// Cursor is the position of the last item of a page in the ordering of a list.
+// Each list only uses the fields of its own ordering, the ID always breaks ties.
+type Cursor struct {
+	Sort      string     `json:"s,omitempty"` // Ordering the cursor belongs to, for lists that can be sorted
+	Date      *time.Time `json:"d,omitempty"`
+	Amount    *float64   `json:"a,omitempty"`
+	CreatedAt *time.Time `json:"c,omitempty"`
+	ID        uint64     `json:"i"`
+}
+
+// Encode returns the opaque string form of the cursor
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// errInvalidCursor is the validation error of a cursor that was not returned by a previous page
+var errInvalidCursor = InvalidField("cursor", "cursor", "must be a next_cursor returned by a previous page")
+
+// DecodeCursor parses a cursor returned by Encode, an empty string means the first page
+func DecodeCursor(value string) (*Cursor, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == 0 {
+		return nil, errInvalidCursor
+	}
+
+	return &cursor, nil
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "encoding/json"
+
+// Patch is a field of a JSON merge patch (RFC 7396).
+// It tells apart a member that is absent, explicitly null or set to a value, including its zero value.
+type Patch[T any] struct {
+	Set   bool // The member is present in the patch
+	Null  bool // The member is present and null, which removes the value
+	Value T    // The new value, meaningful when Set and not Null
+}
+
+// UnmarshalJSON marks the field as present and decodes its value unless it is null
+func (p *Patch[T]) UnmarshalJSON(data []byte) error {
+	p.Set = true
+	if string(data) == "null" {
+		p.Null = true
+		return nil
+	}
+	return json.Unmarshal(data, &p.Value)
+}
+
+// HasValue reports whether the patch sets the field to a non-null value
+func (p Patch[T]) HasValue() bool {
+	return p.Set && !p.Null
+}
@@ -0,0 +1,47 @@
+package domain_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+)
+
+func TestPatchUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		want     domain.Patch[int]
+		hasValue bool
+	}{
+		{"absent", `{}`, domain.Patch[int]{}, false},
+		{"null", `{"value":null}`, domain.Patch[int]{Set: true, Null: true}, false},
+		{"zero value", `{"value":0}`, domain.Patch[int]{Set: true}, true},
+		{"value", `{"value":42}`, domain.Patch[int]{Set: true, Value: 42}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch struct {
+				Value domain.Patch[int] `json:"value"`
+			}
+			if err := json.Unmarshal([]byte(tt.body), &patch); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if patch.Value != tt.want {
+				t.Errorf("patch = %+v, want %+v", patch.Value, tt.want)
+			}
+			if got := patch.Value.HasValue(); got != tt.hasValue {
+				t.Errorf("HasValue() = %v, want %v", got, tt.hasValue)
+			}
+		})
+	}
+}
+
+func TestPatchUnmarshalJSONInvalid(t *testing.T) {
+	var patch struct {
+		Value domain.Patch[int] `json:"value"`
+	}
+	if err := json.Unmarshal([]byte(`{"value":"42"}`), &patch); err == nil {
+		t.Error("expected an error for a value of the wrong type")
+	}
+}
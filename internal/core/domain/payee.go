@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// MaxPayeeAliases is the largest number of aliases a payee can have
+const MaxPayeeAliases = 50
+
+// Payee represents a merchant, utility or anyone else expenses are paid to
+type Payee struct {
+	ID                   int       `json:"id"`
+	Name                 string    `json:"name"`
+	Aliases              []string  `json:"aliases"`                          // Other names the payee appears under, e.g. on bank statements
+	DefaultCategoryID    *int      `json:"default_category_id,omitempty"`    // Category of expenses created without one
+	DefaultSubCategoryID *int      `json:"default_subcategory_id,omitempty"` // Subcategory of expenses given the default category
+	PersonID             *int      `json:"person_id,omitempty"`              // Household person the payee is, if any
+	Version              int       `json:"version"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+// CreatePayeeRequest represents the request to create a payee
+type CreatePayeeRequest struct {
+	Name                 string   `json:"name" binding:"required,min=1,max=255"`
+	Aliases              []string `json:"aliases,omitempty" binding:"max=50,dive,max=255"`
+	DefaultCategoryID    *int     `json:"default_category_id,omitempty"`
+	DefaultSubCategoryID *int     `json:"default_subcategory_id,omitempty"` // Must belong to the default category
+	PersonID             *int     `json:"person_id,omitempty"`
+}
+
+// UpdatePayeeRequest represents the request to update a payee
+type UpdatePayeeRequest struct {
+	Version              int      `json:"-"` // Version the update is based on, 0 skips the check
+	Name                 string   `json:"name" binding:"required,min=1,max=255"`
+	Aliases              []string `json:"aliases,omitempty" binding:"max=50,dive,max=255"`
+	DefaultCategoryID    *int     `json:"default_category_id,omitempty"`
+	DefaultSubCategoryID *int     `json:"default_subcategory_id,omitempty"` // Must belong to the default category
+	PersonID             *int     `json:"person_id,omitempty"`
+}
+
+// ListPayeesRequest represents the request to list payees
+type ListPayeesRequest struct {
+	PageRequest
+	Search string `form:"q"` // Optional filter on the name or an alias containing the text, ignoring case
+}
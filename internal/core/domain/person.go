@@ -3,9 +3,17 @@ package domain
 import "time"
 
 type Person struct {
-	ID        uint64
-	Name      string
-	Email     string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID        uint64    `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PatchPersonRequest represents a JSON merge patch of a Person, only the present members are changed
+type PatchPersonRequest struct {
+	Version int           `json:"-"` // Version the patch is based on, 0 skips the check
+	Name    Patch[string] `json:"name" swaggertype:"string"`
+	Email   Patch[string] `json:"email" swaggertype:"string"`
 }
@@ -0,0 +1,10 @@
+package domain
+
+// PurgeResult reports how many soft-deleted rows were permanently removed per entity
+type PurgeResult struct {
+	Expenses      int64 `json:"expenses"`
+	Attachments   int64 `json:"attachments"` // Attachments of the purged expenses
+	SubCategories int64 `json:"subcategories"`
+	Categories    int64 `json:"categories"`
+	Accounts      int64 `json:"accounts"`
+}
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SavedView represents a named expense search that can be run again at any time
+type SavedView struct {
+	ID        int              `json:"id"`
+	Name      string           `json:"name"`
+	Filters   SavedViewFilters `json:"filters"`
+	Version   int              `json:"version"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// SavedViewFilters represents the stored expense filters of a saved view.
+// A date range is either relative, resolved each time the view is run, or fixed.
+type SavedViewFilters struct {
+	CategoryIDs    []int    `json:"category_ids,omitempty"`
+	SubCategoryID  int      `json:"subcategory_id,omitempty"`
+	PayeeIDs       []int    `json:"payee_ids,omitempty"`
+	AccountIDs     []int    `json:"account_ids,omitempty"`
+	MinAmount      *float64 `json:"min_amount,omitempty"`
+	MaxAmount      *float64 `json:"max_amount,omitempty"`
+	Notes          string   `json:"notes,omitempty"` // Full-text search in the notes
+	HasSubCategory *bool    `json:"has_subcategory,omitempty"`
+	TagIDs         []int    `json:"tag_ids,omitempty"`
+	TagMatch       string   `json:"tag_match,omitempty" example:"all"`         // any (default) or all of the tags
+	DateRange      string   `json:"date_range,omitempty" example:"this_month"` // Relative range, see ResolveDateRange
+	StartDate      string   `json:"start_date,omitempty"`                      // Fixed range (YYYY-MM-DD), not combined with date_range
+	EndDate        string   `json:"end_date,omitempty"`                        // Fixed range (YYYY-MM-DD), not combined with date_range
+	Sort           string   `json:"sort,omitempty" example:"amount:desc"`
+}
+
+// CreateSavedViewRequest represents the request to create a saved view
+type CreateSavedViewRequest struct {
+	Name    string           `json:"name" binding:"required,min=1,max=100"`
+	Filters SavedViewFilters `json:"filters"`
+}
+
+// UpdateSavedViewRequest represents the request to update a saved view
+type UpdateSavedViewRequest struct {
+	Version int              `json:"-"` // Version the update is based on, 0 skips the check
+	Name    string           `json:"name" binding:"required,min=1,max=100"`
+	Filters SavedViewFilters `json:"filters"`
+}
+
+// ListSavedViewsRequest represents the request to list saved views
+type ListSavedViewsRequest struct {
+	PageRequest
+}
+
+// Relative date ranges of a saved view, besides last_<n>_days
+const (
+	DateRangeThisMonth   = "this_month"
+	DateRangeLastMonth   = "last_month"
+	DateRangeThisQuarter = "this_quarter"
+	DateRangeLastQuarter = "last_quarter"
+	DateRangeThisYear    = "this_year"
+	DateRangeLastYear    = "last_year"
+	DateRangeYearToDate  = "year_to_date"
+)
+
+// maxRelativeDays is the largest n accepted in a last_<n>_days date range
+const maxRelativeDays = 3660
+
+// ResolveDateRange returns the first and last day of a relative date range, on the day of now.
+// Besides the DateRange constants, last_<n>_days covers the n days up to and including today.
+func ResolveDateRange(expression string, now time.Time) (start, end time.Time, err error) {
+	year, month, day := now.Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+	monthStart := time.Date(year, month, 1, 0, 0, 0, 0, now.Location())
+	quarterStart := time.Date(year, month-(month-1)%3, 1, 0, 0, 0, 0, now.Location())
+	yearStart := time.Date(year, time.January, 1, 0, 0, 0, 0, now.Location())
+
+	switch expression {
+	case DateRangeThisMonth:
+		return monthStart, monthStart.AddDate(0, 1, -1), nil
+	case DateRangeLastMonth:
+		return monthStart.AddDate(0, -1, 0), monthStart.AddDate(0, 0, -1), nil
+	case DateRangeThisQuarter:
+		return quarterStart, quarterStart.AddDate(0, 3, -1), nil
+	case DateRangeLastQuarter:
+		return quarterStart.AddDate(0, -3, 0), quarterStart.AddDate(0, 0, -1), nil
+	case DateRangeThisYear:
+		return yearStart, yearStart.AddDate(1, 0, -1), nil
+	case DateRangeLastYear:
+		return yearStart.AddDate(-1, 0, 0), yearStart.AddDate(0, 0, -1), nil
+	case DateRangeYearToDate:
+		return yearStart, today, nil
+	}
+
+	// last_<n>_days, with n written without sign or leading zeros
+	if digits, ok := strings.CutPrefix(expression, "last_"); ok {
+		if digits, ok = strings.CutSuffix(digits, "_days"); ok {
+			days, err := strconv.Atoi(digits)
+			if err == nil && days > 0 && days <= maxRelativeDays && strconv.Itoa(days) == digits {
+				return today.AddDate(0, 0, 1-days), today, nil
+			}
+		}
+	}
+
+	return start, end, InvalidField("date_range", "date_range", "must be a relative date range such as this_month or last_30_days")
+}
@@ -0,0 +1,57 @@
+package domain_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+)
+
+func TestResolveDateRange(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		expression string
+		now        time.Time
+		start, end time.Time
+	}{
+		{domain.DateRangeThisMonth, time.Date(2024, time.February, 10, 15, 30, 0, 0, time.UTC), date(2024, time.February, 1), date(2024, time.February, 29)},
+		{domain.DateRangeThisMonth, date(2023, time.December, 31), date(2023, time.December, 1), date(2023, time.December, 31)},
+		{domain.DateRangeLastMonth, date(2024, time.March, 31), date(2024, time.February, 1), date(2024, time.February, 29)},
+		{domain.DateRangeLastMonth, date(2024, time.January, 1), date(2023, time.December, 1), date(2023, time.December, 31)},
+		{domain.DateRangeThisQuarter, date(2024, time.January, 1), date(2024, time.January, 1), date(2024, time.March, 31)},
+		{domain.DateRangeThisQuarter, date(2024, time.June, 30), date(2024, time.April, 1), date(2024, time.June, 30)},
+		{domain.DateRangeThisQuarter, date(2024, time.December, 31), date(2024, time.October, 1), date(2024, time.December, 31)},
+		{domain.DateRangeLastQuarter, date(2024, time.July, 1), date(2024, time.April, 1), date(2024, time.June, 30)},
+		{domain.DateRangeLastQuarter, date(2024, time.February, 15), date(2023, time.October, 1), date(2023, time.December, 31)},
+		{domain.DateRangeThisYear, date(2024, time.May, 5), date(2024, time.January, 1), date(2024, time.December, 31)},
+		{domain.DateRangeLastYear, date(2024, time.May, 5), date(2023, time.January, 1), date(2023, time.December, 31)},
+		{domain.DateRangeYearToDate, time.Date(2024, time.May, 5, 23, 59, 0, 0, time.UTC), date(2024, time.January, 1), date(2024, time.May, 5)},
+		{"last_1_days", date(2024, time.March, 1), date(2024, time.March, 1), date(2024, time.March, 1)},
+		{"last_30_days", date(2024, time.March, 1), date(2024, time.February, 1), date(2024, time.March, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression+" "+tt.now.Format(time.DateOnly), func(t *testing.T) {
+			start, end, err := domain.ResolveDateRange(tt.expression, tt.now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !start.Equal(tt.start) || !end.Equal(tt.end) {
+				t.Errorf("range = %s to %s, want %s to %s", start.Format(time.DateOnly), end.Format(time.DateOnly),
+					tt.start.Format(time.DateOnly), tt.end.Format(time.DateOnly))
+			}
+		})
+	}
+}
+
+func TestResolveDateRangeInvalid(t *testing.T) {
+	for _, expression := range []string{"", "today", "last_0_days", "last_-1_days", "last_07_days", "last_+7_days", "last_3661_days", "last_days"} {
+		t.Run(expression, func(t *testing.T) {
+			if _, _, err := domain.ResolveDateRange(expression, time.Now()); !errors.Is(err, domain.ErrInvalidInput) {
+				t.Errorf("error = %v, want %v", err, domain.ErrInvalidInput)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package domain
+
+// SchemaStatus reports the schema version of the storage against the newest migration the binary carries
+type SchemaStatus struct {
+	Version uint `json:"version"` // Version applied to the storage, 0 when none is
+	Latest  uint `json:"latest"`  // Version of the newest migration embedded in the binary
+	Dirty   bool `json:"dirty"`   // Whether a migration failed half way and needs repairing by hand
+}
+
+// Ready tells whether the storage schema can serve this binary. A newer schema is accepted
+// so replicas still running the previous release keep serving while a rollout migrates.
+func (s *SchemaStatus) Ready() bool {
+	return !s.Dirty && s.Version >= s.Latest
+}
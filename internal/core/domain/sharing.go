@@ -0,0 +1,79 @@
+package domain
+
+import "time"
+
+// How the cost of a shared expense is divided between persons
+const (
+	SharingMethodEqual      = "equal"      // Every person owes the same amount
+	SharingMethodPercentage = "percentage" // Every person owes a percentage of the amount, adding up to 100
+	SharingMethodExact      = "exact"      // Every person owes a given amount, adding up to the expense amount
+)
+
+// ExpenseSharing represents who paid an expense and how its cost is shared between persons
+type ExpenseSharing struct {
+	PaidByID int            `json:"paid_by_id"`             // Person who paid, who does not have to share the cost
+	Method   string         `json:"method" example:"equal"` // equal, percentage or exact
+	Shares   []ExpenseShare `json:"shares"`                 // Persons sharing the cost
+}
+
+// PatchExpenseSharing represents a JSON merge patch of the sharing of an expense, only the present members are changed
+type PatchExpenseSharing struct {
+	PaidByID Patch[int]            `json:"paid_by_id" swaggertype:"integer"`
+	Method   Patch[string]         `json:"method" swaggertype:"string"`
+	Shares   Patch[[]ExpenseShare] `json:"shares" swaggertype:"array,object"` // Replaces every share
+}
+
+// ExpenseShare represents the part of a shared expense owed by a person
+type ExpenseShare struct {
+	PersonID   int     `json:"person_id"`
+	Percentage float64 `json:"percentage,omitempty"` // Required by the percentage method
+	Amount     float64 `json:"amount"`               // Required by the exact method, computed by the other ones
+}
+
+// Settlement represents a payment between two persons settling shared expenses
+type Settlement struct {
+	ID           int       `json:"id"`
+	FromPersonID int       `json:"from_person_id"` // Person who paid back
+	ToPersonID   int       `json:"to_person_id"`   // Person who was paid back
+	Amount       float64   `json:"amount"`
+	Date         time.Time `json:"date"`
+	Notes        string    `json:"notes,omitempty"`
+	Version      int       `json:"version"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// CreateSettlementRequest represents the request to record a settlement
+type CreateSettlementRequest struct {
+	FromPersonID int     `json:"from_person_id" binding:"required,min=1"`
+	ToPersonID   int     `json:"to_person_id" binding:"required,min=1"`
+	Amount       float64 `json:"amount" binding:"required,gt=0"`
+	Date         string  `json:"date" binding:"required"` // Format: YYYY-MM-DD
+	Notes        string  `json:"notes,omitempty"`
+}
+
+// ListSettlementsRequest represents the request to list settlements
+type ListSettlementsRequest struct {
+	PageRequest
+	PersonID int `form:"person_id"` // Optional filter on either side of the settlement
+}
+
+// PersonDebt represents an amount owed by a person to another one
+type PersonDebt struct {
+	FromPersonID int     `json:"from_person_id"` // Person who owes
+	ToPersonID   int     `json:"to_person_id"`   // Person who is owed
+	Amount       float64 `json:"amount"`
+}
+
+// PersonBalance represents the overall position of a person, positive when others owe them
+type PersonBalance struct {
+	PersonID int     `json:"person_id"`
+	Balance  float64 `json:"balance"`
+}
+
+// Balances represents who owes whom once shared expenses and settlements are accounted for
+type Balances struct {
+	Debts          []PersonDebt    `json:"debts"`           // Outstanding debt between each pair of persons
+	Persons        []PersonBalance `json:"persons"`         // Overall position of each person
+	SettlementPlan []PersonDebt    `json:"settlement_plan"` // Fewest payments settling every balance
+}
@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+// Tag represents a free-form label attached to any number of expenses, across categories
+type Tag struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreateTagRequest represents the request to create a tag
+type CreateTagRequest struct {
+	Name string `json:"name" binding:"required,min=1,max=50"`
+}
+
+// UpdateTagRequest represents the request to update a tag
+type UpdateTagRequest struct {
+	Version int    `json:"-"` // Version the update is based on, 0 skips the check
+	Name    string `json:"name" binding:"required,min=1,max=50"`
+}
+
+// ListTagsRequest represents the request to list tags
+type ListTagsRequest struct {
+	PageRequest
+}
+
+// TagTotal represents the expenses carrying a tag, an expense with several tags counts towards each of them
+type TagTotal struct {
+	TagID        int     `json:"tag_id"`
+	Name         string  `json:"name"`
+	ExpenseCount int64   `json:"expense_count"`
+	TotalAmount  float64 `json:"total_amount"`
+}
+
+// How the tags of an expense filter are matched
+const (
+	TagMatchAny = "any" // The expense carries at least one of the tags
+	TagMatchAll = "all" // The expense carries every tag
+)
@@ -2,6 +2,7 @@ package port
 
 import (
 	"context"
+	"time"
 
 	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
 )
@@ -11,12 +12,22 @@ type AccountRepository interface {
 	CreateAccount(ctx context.Context, Account *domain.Account) (*domain.Account, error)
 	// GetAccountByID selects a Account by id
 	GetAccountByID(ctx context.Context, id uint64) (*domain.Account, error)
-	// ListAccounts selects a list of Accounts with pagination
-	ListAccounts(ctx context.Context, skip, limit uint64) ([]domain.Account, error)
-	// UpdateAccount updates a Account
+	// GetAccountByIDForShare selects a Account by id, keeping it from being changed or deleted
+	// until the transaction ends, when referencing it from another row
+	GetAccountByIDForShare(ctx context.Context, id uint64) (*domain.Account, error)
+	// ListAccounts selects up to limit Accounts ordered by id, starting after the cursor when given,
+	// optionally including soft-deleted ones
+	ListAccounts(ctx context.Context, after *domain.Cursor, limit int, includeDeleted bool) ([]domain.Account, error)
+	// CountAccounts counts the Accounts, optionally including soft-deleted ones
+	CountAccounts(ctx context.Context, includeDeleted bool) (int64, error)
+	// UpdateAccount updates a Account whose version matches Account.Version, 0 skips the check
 	UpdateAccount(ctx context.Context, Account *domain.Account) (*domain.Account, error)
-	// DeleteAccount deletes a Account
-	DeleteAccount(ctx context.Context, id uint64) error
+	// DeleteAccount soft deletes a Account whose version matches, 0 skips the check
+	DeleteAccount(ctx context.Context, id uint64, version int) error
+	// RestoreAccount restores a soft-deleted Account
+	RestoreAccount(ctx context.Context, id uint64) error
+	// PurgeAccounts permanently removes Accounts soft deleted before the given time
+	PurgeAccounts(ctx context.Context, deletedBefore time.Time) (int64, error)
 }
 
 // AccountService is an interface for interacting with Account-related business logic
@@ -25,10 +36,14 @@ type AccountService interface {
 	Create(ctx context.Context, Account *domain.Account) (*domain.Account, error)
 	// GetAccount returns a Account by id
 	GetAccount(ctx context.Context, id uint64) (*domain.Account, error)
-	// ListAccounts returns a list of Accounts with pagination
-	ListAccounts(ctx context.Context, skip, limit uint64) ([]domain.Account, error)
-	// UpdateAccount updates a Account
+	// ListAccounts returns a page of Accounts, optionally including soft-deleted ones
+	ListAccounts(ctx context.Context, req *domain.PageRequest, includeDeleted bool) (*domain.Page[domain.Account], error)
+	// UpdateAccount updates a Account based on the version in Account.Version, 0 skips the check
 	UpdateAccount(ctx context.Context, Account *domain.Account) (*domain.Account, error)
-	// DeleteAccount deletes a Account
-	DeleteAccount(ctx context.Context, id uint64) error
+	// PatchAccount applies a JSON merge patch to a Account, changing only the present members
+	PatchAccount(ctx context.Context, id uint64, req *domain.PatchAccountRequest) (*domain.Account, error)
+	// DeleteAccount soft deletes a Account based on the given version, 0 skips the check
+	DeleteAccount(ctx context.Context, id uint64, version int) error
+	// RestoreAccount restores a soft-deleted Account
+	RestoreAccount(ctx context.Context, id uint64) (*domain.Account, error)
 }
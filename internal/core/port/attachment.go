@@ -0,0 +1,46 @@
+package port
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+)
+
+// BlobStore defines the interface for storing the content of attachments
+// Get returns ErrDataNotFound for a missing key, Delete ignores it
+type BlobStore interface {
+	Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// AttachmentRepository defines the interface for attachment data operations
+// ListByExpense orders attachments by ID
+type AttachmentRepository interface {
+	Create(ctx context.Context, attachment *domain.Attachment) error
+	GetByID(ctx context.Context, id int) (*domain.Attachment, error)
+	GetByChecksum(ctx context.Context, expenseID int, checksum string) (*domain.Attachment, error)
+	ListByExpense(ctx context.Context, expenseID int) ([]*domain.Attachment, error)
+	// CountByChecksum counts the attachments sharing the same content, across expenses
+	CountByChecksum(ctx context.Context, checksum string) (int64, error)
+	// LockContent keeps other units of work from adding or releasing the content with the checksum
+	// until the transaction ends, so that its attachments can be counted before storing or deleting it
+	LockContent(ctx context.Context, checksum string) error
+	Delete(ctx context.Context, id int) error
+	// Purge permanently removes the attachments of the expenses soft deleted before the given time,
+	// returning them so that their content can be released
+	Purge(ctx context.Context, expensesDeletedBefore time.Time) ([]*domain.Attachment, error)
+}
+
+// AttachmentService defines the interface for attachment business logic
+// Every method checks that the attachment belongs to the given expense
+type AttachmentService interface {
+	Upload(ctx context.Context, expenseID int, req *domain.UploadAttachmentRequest) (*domain.Attachment, error)
+	List(ctx context.Context, expenseID int) ([]*domain.Attachment, error)
+	GetByID(ctx context.Context, expenseID int, id int) (*domain.Attachment, error)
+	// Download returns the content of the attachment, the caller closes it
+	Download(ctx context.Context, expenseID int, id int) (*domain.Attachment, io.ReadCloser, error)
+	Delete(ctx context.Context, expenseID int, id int) error
+}
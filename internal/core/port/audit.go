@@ -0,0 +1,26 @@
+package port
+
+import (
+	"context"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+)
+
+// AuditRepository defines the interface for the append-only audit trail
+// List orders entries newest first, by creation time then ID, and starts after the cursor when given
+type AuditRepository interface {
+	Create(ctx context.Context, entry *domain.AuditEntry) error
+	List(ctx context.Context, after *domain.Cursor, limit int, filters AuditFilters) ([]*domain.AuditEntry, error)
+	Count(ctx context.Context, filters AuditFilters) (int64, error)
+}
+
+// AuditFilters represents filters for listing audit entries
+type AuditFilters struct {
+	EntityType *string
+	EntityID   *uint64
+}
+
+// AuditService defines the interface for browsing the audit trail
+type AuditService interface {
+	List(ctx context.Context, req *domain.ListAuditEntriesRequest) (*domain.Page[*domain.AuditEntry], error)
+}
@@ -8,31 +8,64 @@ import (
 )
 
 // ExpenseRepository defines the interface for expense data operations
+// Update and Delete only apply when the stored version matches, a version of 0 skips the check
+// List orders expenses by the field of filters.Sort, then creation time and ID in the same direction
+// Create and Update replace the tags of the expense with Expense.TagIDs
 type ExpenseRepository interface {
 	Create(ctx context.Context, expense *domain.Expense) error
 	GetByID(ctx context.Context, id int) (*domain.Expense, error)
 	List(ctx context.Context, filters ExpenseFilters) ([]*domain.Expense, error)
+	Count(ctx context.Context, filters ExpenseFilters) (int64, error)                 // Ignores After and Limit
+	TagTotals(ctx context.Context, filters ExpenseFilters) ([]domain.TagTotal, error) // Ignores After and Limit, leaves Name empty, only sums the matching split lines
 	Update(ctx context.Context, expense *domain.Expense) error
-	Delete(ctx context.Context, id int) error
+	Delete(ctx context.Context, id int, version int) error
+	Restore(ctx context.Context, id int) error
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
+	// RemoveTag removes a tag from every expense carrying it, including soft-deleted ones
+	RemoveTag(ctx context.Context, tagID int) error
+	// ReassignPayee moves every expense, including soft-deleted ones, from one payee to another and returns how many moved
+	ReassignPayee(ctx context.Context, fromID, toID int) (int64, error)
+	// ReassignCategory moves every expense and split line, including soft-deleted ones, from one category to another
+	// and returns how many expenses changed
+	ReassignCategory(ctx context.Context, fromID, toID int) (int64, error)
+	// ReassignSubCategory moves every expense and split line, including soft-deleted ones, from one subcategory to another
+	// and to the category of that subcategory, it returns how many expenses changed
+	ReassignSubCategory(ctx context.Context, fromID, toID, toCategoryID int) (int64, error)
+	// ShareDebts sums the shares owed by each person to the payers of the shared expenses that are not deleted
+	ShareDebts(ctx context.Context) ([]domain.PersonDebt, error)
 }
 
 // ExpenseFilters represents filters for listing expenses
+// The category filters of a split expense must all match one of its split lines instead of the expense itself
 type ExpenseFilters struct {
-	Skip          int
-	Limit         int
-	CategoryID    *int
-	SubCategoryID *int
-	PayeeID       *int
-	AccountID     *int
-	StartDate     *time.Time
-	EndDate       *time.Time
+	After          *domain.Cursor // Start after this position, nil for the first page
+	Limit          int
+	Sort           domain.ExpenseSort
+	CategoryIDs    []int // Matches any of the categories
+	SubCategoryID  *int
+	PayeeIDs       []int // Matches any of the payees
+	AccountIDs     []int // Matches any of the accounts
+	MinAmount      *float64
+	MaxAmount      *float64
+	NotesQuery     string // Full-text search, every word must appear in the notes
+	HasSubCategory *bool
+	TagIDs         []int // Matches any of the tags, or all of them with MatchAllTags
+	MatchAllTags   bool
+	StartDate      *time.Time
+	EndDate        *time.Time
+	IncludeDeleted bool
 }
 
 // ExpenseService defines the interface for expense business logic
 type ExpenseService interface {
 	Create(ctx context.Context, req *domain.CreateExpenseRequest) (*domain.Expense, error)
 	GetByID(ctx context.Context, id int) (*domain.Expense, error)
-	List(ctx context.Context, req *domain.ListExpensesRequest) ([]*domain.Expense, error)
+	List(ctx context.Context, req *domain.ListExpensesRequest) (*domain.Page[*domain.Expense], error)
 	Update(ctx context.Context, id int, req *domain.UpdateExpenseRequest) (*domain.Expense, error)
-	Delete(ctx context.Context, id int) error
+	Patch(ctx context.Context, id int, req *domain.PatchExpenseRequest) (*domain.Expense, error)
+	Bulk(ctx context.Context, req *domain.BulkExpenseRequest) (*domain.BulkExpenseResult, error)
+	Delete(ctx context.Context, id int, version int) error
+	Restore(ctx context.Context, id int) (*domain.Expense, error)
+	// TagTotals sums the expenses matching the filters of the request for each of their tags
+	TagTotals(ctx context.Context, req *domain.ListExpensesRequest) ([]domain.TagTotal, error)
 }
@@ -2,24 +2,35 @@ package port
 
 import (
 	"context"
+	"time"
 
 	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
 )
 
 // ExpenseCategoryRepository defines the interface for expense category data operations
+// Update and Delete only apply when the stored version matches, a version of 0 skips the check
+// GetByIDForShare keeps the category from being changed or deleted until the transaction ends,
+// for rows referencing it
+// List orders categories by creation time then ID, newest first, and starts after the cursor when given
 type ExpenseCategoryRepository interface {
 	Create(ctx context.Context, category *domain.ExpenseCategory) error
 	GetByID(ctx context.Context, id int) (*domain.ExpenseCategory, error)
-	List(ctx context.Context, skip, limit int) ([]*domain.ExpenseCategory, error)
+	GetByIDForShare(ctx context.Context, id int) (*domain.ExpenseCategory, error)
+	List(ctx context.Context, after *domain.Cursor, limit int, includeDeleted bool) ([]*domain.ExpenseCategory, error)
+	Count(ctx context.Context, includeDeleted bool) (int64, error)
 	Update(ctx context.Context, category *domain.ExpenseCategory) error
-	Delete(ctx context.Context, id int) error
+	Delete(ctx context.Context, id int, version int) error
+	Restore(ctx context.Context, id int) error
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
 }
 
 // ExpenseCategoryService defines the interface for expense category business logic
 type ExpenseCategoryService interface {
 	Create(ctx context.Context, req *domain.CreateExpenseCategoryRequest) (*domain.ExpenseCategory, error)
 	GetByID(ctx context.Context, id int) (*domain.ExpenseCategory, error)
-	List(ctx context.Context, req *domain.ListExpenseCategoriesRequest) ([]*domain.ExpenseCategory, error)
+	List(ctx context.Context, req *domain.ListExpenseCategoriesRequest) (*domain.Page[*domain.ExpenseCategory], error)
 	Update(ctx context.Context, id int, req *domain.UpdateExpenseCategoryRequest) (*domain.ExpenseCategory, error)
-	Delete(ctx context.Context, id int) error
+	Patch(ctx context.Context, id int, req *domain.PatchExpenseCategoryRequest) (*domain.ExpenseCategory, error)
+	Delete(ctx context.Context, id int, version int) error
+	Restore(ctx context.Context, id int) (*domain.ExpenseCategory, error)
 }
@@ -2,24 +2,35 @@ package port
 
 import (
 	"context"
+	"time"
 
 	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
 )
 
 // ExpenseSubCategoryRepository defines the interface for expense subcategory data operations
+// Update and Delete only apply when the stored version matches, a version of 0 skips the check
+// GetByIDForShare keeps the subcategory from being changed or deleted until the transaction ends,
+// for rows referencing it
+// List orders subcategories by creation time then ID, newest first, and starts after the cursor when given
 type ExpenseSubCategoryRepository interface {
 	Create(ctx context.Context, subcategory *domain.ExpenseSubCategory) error
 	GetByID(ctx context.Context, id int) (*domain.ExpenseSubCategory, error)
-	List(ctx context.Context, skip, limit int, expenseCategoryID *int) ([]*domain.ExpenseSubCategory, error)
+	GetByIDForShare(ctx context.Context, id int) (*domain.ExpenseSubCategory, error)
+	List(ctx context.Context, after *domain.Cursor, limit int, expenseCategoryID *int, includeDeleted bool) ([]*domain.ExpenseSubCategory, error)
+	Count(ctx context.Context, expenseCategoryID *int, includeDeleted bool) (int64, error)
 	Update(ctx context.Context, subcategory *domain.ExpenseSubCategory) error
-	Delete(ctx context.Context, id int) error
+	Delete(ctx context.Context, id int, version int) error
+	Restore(ctx context.Context, id int) error
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
 }
 
 // ExpenseSubCategoryService defines the interface for expense subcategory business logic
 type ExpenseSubCategoryService interface {
 	Create(ctx context.Context, req *domain.CreateExpenseSubCategoryRequest) (*domain.ExpenseSubCategory, error)
 	GetByID(ctx context.Context, id int) (*domain.ExpenseSubCategory, error)
-	List(ctx context.Context, req *domain.ListExpenseSubCategoriesRequest) ([]*domain.ExpenseSubCategory, error)
+	List(ctx context.Context, req *domain.ListExpenseSubCategoriesRequest) (*domain.Page[*domain.ExpenseSubCategory], error)
 	Update(ctx context.Context, id int, req *domain.UpdateExpenseSubCategoryRequest) (*domain.ExpenseSubCategory, error)
-	Delete(ctx context.Context, id int) error
+	Patch(ctx context.Context, id int, req *domain.PatchExpenseSubCategoryRequest) (*domain.ExpenseSubCategory, error)
+	Delete(ctx context.Context, id int, version int) error
+	Restore(ctx context.Context, id int) (*domain.ExpenseSubCategory, error)
 }
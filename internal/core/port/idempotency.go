@@ -0,0 +1,32 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+)
+
+// IdempotencyRepository is an interface for storing the outcome of idempotent requests
+type IdempotencyRepository interface {
+	// Reserve stores record unless an unexpired record with the same key and scope exists, which is returned instead
+	Reserve(ctx context.Context, record *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error)
+	// Complete stores the response of a reserved record
+	Complete(ctx context.Context, record *domain.IdempotencyRecord) error
+	// Release removes a reserved record so that the request can be retried
+	Release(ctx context.Context, key, scope string) error
+	// PurgeExpired permanently removes the records that expired before the given time
+	PurgeExpired(ctx context.Context, expiredBefore time.Time) (int64, error)
+}
+
+// IdempotencyService is an interface for replaying requests sent again with the same idempotency key
+type IdempotencyService interface {
+	// Begin reserves key for a request, returning the stored record to replay when the same request already completed
+	Begin(ctx context.Context, key, scope, requestHash string) (*domain.IdempotencyRecord, error)
+	// Complete stores the response of the request that reserved key
+	Complete(ctx context.Context, key, scope string, statusCode int, headers map[string]string, body []byte) error
+	// Release gives up the reservation of key so that the request can be retried
+	Release(ctx context.Context, key, scope string) error
+	// PurgeExpired permanently removes the expired keys along with their stored responses
+	PurgeExpired(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,34 @@
+package port
+
+import (
+	"context"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+)
+
+// MergeRepository defines the interface for merge record operations, merges are never changed once recorded
+// List orders merges by ID and starts after the cursor when given
+type MergeRepository interface {
+	Create(ctx context.Context, merge *domain.Merge) error
+	List(ctx context.Context, after *domain.Cursor, limit int, filters MergeFilters) ([]*domain.Merge, error)
+	Count(ctx context.Context, filters MergeFilters) (int64, error)
+}
+
+// MergeFilters represents filters for listing merges
+type MergeFilters struct {
+	EntityType *string
+	TargetID   *int
+}
+
+// MergeService defines the interface for merging duplicate payees, expense categories and subcategories.
+// A merge moves every expense of the sources over to the target, keeps the names of the sources as aliases of the target
+// and removes the sources, all in one transaction.
+type MergeService interface {
+	MergePayees(ctx context.Context, targetID int, req *domain.MergeRequest) (*domain.MergeResult[*domain.Payee], error)
+	// MergeExpenseCategories also moves the subcategories of the sources to the target,
+	// merging them into the subcategory of the target with the same name if any
+	MergeExpenseCategories(ctx context.Context, targetID int, req *domain.MergeRequest) (*domain.MergeResult[*domain.ExpenseCategory], error)
+	// MergeExpenseSubCategories also moves the expenses of the sources to the category of the target
+	MergeExpenseSubCategories(ctx context.Context, targetID int, req *domain.MergeRequest) (*domain.MergeResult[*domain.ExpenseSubCategory], error)
+	List(ctx context.Context, req *domain.ListMergesRequest) (*domain.Page[*domain.Merge], error)
+}
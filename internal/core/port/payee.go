@@ -0,0 +1,34 @@
+package port
+
+import (
+	"context"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+)
+
+// PayeeRepository defines the interface for payee data operations
+// Update and Delete only apply when the stored version matches, a version of 0 skips the check
+// List orders payees by ID and starts after the cursor when given, an empty search lists every payee
+type PayeeRepository interface {
+	Create(ctx context.Context, payee *domain.Payee) error
+	GetByID(ctx context.Context, id int) (*domain.Payee, error)
+	GetByName(ctx context.Context, name string) (*domain.Payee, error) // Ignores case
+	List(ctx context.Context, after *domain.Cursor, limit int, search string) ([]*domain.Payee, error)
+	Count(ctx context.Context, search string) (int64, error)
+	Update(ctx context.Context, payee *domain.Payee) error
+	Delete(ctx context.Context, id int, version int) error
+	// ReassignDefaultCategory replaces a default category of payees by another one
+	ReassignDefaultCategory(ctx context.Context, fromID, toID int) error
+	// ReassignDefaultSubCategory replaces a default subcategory of payees by another one along with its category
+	ReassignDefaultSubCategory(ctx context.Context, fromID, toID, toCategoryID int) error
+}
+
+// PayeeService defines the interface for payee business logic
+type PayeeService interface {
+	Create(ctx context.Context, req *domain.CreatePayeeRequest) (*domain.Payee, error)
+	GetByID(ctx context.Context, id int) (*domain.Payee, error)
+	List(ctx context.Context, req *domain.ListPayeesRequest) (*domain.Page[*domain.Payee], error)
+	Update(ctx context.Context, id int, req *domain.UpdatePayeeRequest) (*domain.Payee, error)
+	// Delete fails with ErrConflictingData while expenses, even soft deleted ones, are paid to the payee
+	Delete(ctx context.Context, id int, version int) error
+}
@@ -11,14 +11,19 @@ type PersonRepository interface {
 	CreatePerson(ctx context.Context, Person *domain.Person) (*domain.Person, error)
 	// GetPersonByID selects a Person by id
 	GetPersonByID(ctx context.Context, id uint64) (*domain.Person, error)
+	// GetPersonByIDForShare selects a Person by id, keeping it from being changed or deleted
+	// until the transaction ends, when referencing it from another row
+	GetPersonByIDForShare(ctx context.Context, id uint64) (*domain.Person, error)
 	// GetPersonByEmail selects a Person by email
 	GetPersonByEmail(ctx context.Context, email string) (*domain.Person, error)
-	// ListPersons selects a list of Persons with pagination
-	ListPersons(ctx context.Context, skip, limit uint64) ([]domain.Person, error)
-	// UpdatePerson updates a Person
+	// ListPersons selects up to limit Persons ordered by id, starting after the cursor when given
+	ListPersons(ctx context.Context, after *domain.Cursor, limit int) ([]domain.Person, error)
+	// CountPersons counts every Person
+	CountPersons(ctx context.Context) (int64, error)
+	// UpdatePerson updates a Person whose version matches Person.Version, 0 skips the check
 	UpdatePerson(ctx context.Context, Person *domain.Person) (*domain.Person, error)
-	// DeletePerson deletes a Person
-	DeletePerson(ctx context.Context, id uint64) error
+	// DeletePerson deletes a Person whose version matches, 0 skips the check
+	DeletePerson(ctx context.Context, id uint64, version int) error
 }
 
 // PersonService is an interface for interacting with Person-related business logic
@@ -27,10 +32,12 @@ type PersonService interface {
 	Create(ctx context.Context, Person *domain.Person) (*domain.Person, error)
 	// GetPerson returns a Person by id
 	GetPerson(ctx context.Context, id uint64) (*domain.Person, error)
-	// ListPersons returns a list of Persons with pagination
-	ListPersons(ctx context.Context, skip, limit uint64) ([]domain.Person, error)
-	// UpdatePerson updates a Person
+	// ListPersons returns a page of Persons
+	ListPersons(ctx context.Context, req *domain.PageRequest) (*domain.Page[domain.Person], error)
+	// UpdatePerson updates a Person based on the version in Person.Version, 0 skips the check
 	UpdatePerson(ctx context.Context, Person *domain.Person) (*domain.Person, error)
-	// DeletePerson deletes a Person
-	DeletePerson(ctx context.Context, id uint64) error
+	// PatchPerson applies a JSON merge patch to a Person, changing only the present members
+	PatchPerson(ctx context.Context, id uint64, req *domain.PatchPersonRequest) (*domain.Person, error)
+	// DeletePerson deletes a Person based on the given version, 0 skips the check
+	DeletePerson(ctx context.Context, id uint64, version int) error
 }
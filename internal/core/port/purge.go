@@ -0,0 +1,15 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+)
+
+// PurgeService defines the interface for permanently removing soft-deleted data
+type PurgeService interface {
+	// Purge hard deletes every row soft deleted longer ago than the retention period,
+	// along with the idempotency keys that have expired
+	Purge(ctx context.Context, retention time.Duration) (*domain.PurgeResult, error)
+}
@@ -0,0 +1,30 @@
+package port
+
+import (
+	"context"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+)
+
+// SavedViewRepository defines the interface for saved view data operations
+// Update and Delete only apply when the stored version matches, a version of 0 skips the check
+// List orders views by ID and starts after the cursor when given
+type SavedViewRepository interface {
+	Create(ctx context.Context, view *domain.SavedView) error
+	GetByID(ctx context.Context, id int) (*domain.SavedView, error)
+	List(ctx context.Context, after *domain.Cursor, limit int) ([]*domain.SavedView, error)
+	Count(ctx context.Context) (int64, error)
+	Update(ctx context.Context, view *domain.SavedView) error
+	Delete(ctx context.Context, id int, version int) error
+}
+
+// SavedViewService defines the interface for saved view business logic
+type SavedViewService interface {
+	Create(ctx context.Context, req *domain.CreateSavedViewRequest) (*domain.SavedView, error)
+	GetByID(ctx context.Context, id int) (*domain.SavedView, error)
+	List(ctx context.Context, req *domain.ListSavedViewsRequest) (*domain.Page[*domain.SavedView], error)
+	Update(ctx context.Context, id int, req *domain.UpdateSavedViewRequest) (*domain.SavedView, error)
+	Delete(ctx context.Context, id int, version int) error
+	// ListExpenses runs the search of a view, resolving its relative date range on the current day
+	ListExpenses(ctx context.Context, id int, req *domain.PageRequest) (*domain.Page[*domain.Expense], error)
+}
@@ -0,0 +1,19 @@
+package port
+
+import (
+	"context"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+)
+
+// SchemaMigrator applies the versioned schema migrations embedded in the binary
+type SchemaMigrator interface {
+	// Up applies every pending migration
+	Up(ctx context.Context) error
+	// Down rolls back the given number of applied migrations
+	Down(ctx context.Context, steps int) error
+	// Goto migrates up or down to the given version, 0 rolls back every migration
+	Goto(ctx context.Context, version uint) error
+	// Status returns the applied schema version
+	Status(ctx context.Context) (*domain.SchemaStatus, error)
+}
@@ -0,0 +1,30 @@
+package port
+
+import (
+	"context"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+)
+
+// SettlementRepository defines the interface for settlement data operations
+// Delete only applies when the stored version matches, a version of 0 skips the check
+// List orders settlements by ID and starts after the cursor when given, a person ID of 0 lists every settlement
+type SettlementRepository interface {
+	Create(ctx context.Context, settlement *domain.Settlement) error
+	GetByID(ctx context.Context, id int) (*domain.Settlement, error)
+	List(ctx context.Context, after *domain.Cursor, limit int, personID int) ([]*domain.Settlement, error)
+	Count(ctx context.Context, personID int) (int64, error)
+	Delete(ctx context.Context, id int, version int) error
+	// Totals sums the settlements paid by each person to each other person
+	Totals(ctx context.Context) ([]domain.PersonDebt, error)
+}
+
+// SettlementService defines the interface for settlement and balance business logic
+type SettlementService interface {
+	Create(ctx context.Context, req *domain.CreateSettlementRequest) (*domain.Settlement, error)
+	GetByID(ctx context.Context, id int) (*domain.Settlement, error)
+	List(ctx context.Context, req *domain.ListSettlementsRequest) (*domain.Page[*domain.Settlement], error)
+	Delete(ctx context.Context, id int, version int) error
+	// Balances nets the shares of shared expenses against the settlements
+	Balances(ctx context.Context) (*domain.Balances, error)
+}
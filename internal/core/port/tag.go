@@ -0,0 +1,30 @@
+package port
+
+import (
+	"context"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+)
+
+// TagRepository defines the interface for tag data operations
+// Update and Delete only apply when the stored version matches, a version of 0 skips the check
+// List orders tags by ID and starts after the cursor when given
+type TagRepository interface {
+	Create(ctx context.Context, tag *domain.Tag) error
+	GetByID(ctx context.Context, id int) (*domain.Tag, error)
+	GetByName(ctx context.Context, name string) (*domain.Tag, error) // Ignores case
+	List(ctx context.Context, after *domain.Cursor, limit int) ([]*domain.Tag, error)
+	Count(ctx context.Context) (int64, error)
+	Update(ctx context.Context, tag *domain.Tag) error
+	Delete(ctx context.Context, id int, version int) error
+}
+
+// TagService defines the interface for tag business logic
+type TagService interface {
+	Create(ctx context.Context, req *domain.CreateTagRequest) (*domain.Tag, error)
+	GetByID(ctx context.Context, id int) (*domain.Tag, error)
+	List(ctx context.Context, req *domain.ListTagsRequest) (*domain.Page[*domain.Tag], error)
+	Update(ctx context.Context, id int, req *domain.UpdateTagRequest) (*domain.Tag, error)
+	// Delete removes the tag from every expense carrying it
+	Delete(ctx context.Context, id int, version int) error
+}
@@ -0,0 +1,11 @@
+package port
+
+import "context"
+
+// TxManager runs a unit of work atomically across repositories
+type TxManager interface {
+	// WithinTx runs fn inside a transaction carried by the context passed to it.
+	// The transaction is committed when fn returns nil and rolled back otherwise.
+	// Nested calls only roll back their own work, leaving the outcome to the outer call.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -2,7 +2,7 @@ package service
 
 import (
 	"context"
-	"log/slog"
+	"errors"
 
 	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
 	"github.com/edwins-leonardi/finaid-api/internal/core/port"
@@ -11,12 +11,16 @@ import (
 type AccountService struct {
 	repo       port.AccountRepository
 	personRepo port.PersonRepository
+	txManager  port.TxManager
+	auditRepo  port.AuditRepository
 }
 
-func NewAccountService(repo port.AccountRepository, personRepo port.PersonRepository) *AccountService {
+func NewAccountService(repo port.AccountRepository, personRepo port.PersonRepository, txManager port.TxManager, auditRepo port.AuditRepository) *AccountService {
 	return &AccountService{
 		repo:       repo,
 		personRepo: personRepo,
+		txManager:  txManager,
+		auditRepo:  auditRepo,
 	}
 }
 
@@ -25,37 +29,25 @@ func (svc *AccountService) Create(ctx context.Context, Account *domain.Account)
 	// Validate the Account data here if needed
 	// For example, check if account type is valid, currency format, etc.
 
-	// Validate that primary owner exists
-	_, err := svc.personRepo.GetPersonByID(ctx, Account.PrimaryOwnerID)
-	if err != nil {
-		if err == domain.ErrDataNotFound {
-			slog.Error("Primary owner not found", "primary_owner_id", Account.PrimaryOwnerID)
-			return nil, domain.ErrDataNotFound
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Creating new account", "name", Account.Name, "type", Account.AccountType, "primary_owner_id", Account.PrimaryOwnerID)
+
+	// Call the repository to create the Account and record it in the audit trail
+	var account *domain.Account
+	err := svc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Validate that the primary owner and the second owner (if provided) exist
+		if err := svc.validateOwners(ctx, Account.PrimaryOwnerID, Account.SecondOwnerID); err != nil {
+			return err
 		}
-		return nil, domain.ErrInternal
-	}
 
-	// Validate that second owner exists (if provided)
-	if Account.SecondOwnerID != nil {
-		_, err := svc.personRepo.GetPersonByID(ctx, *Account.SecondOwnerID)
+		var err error
+		account, err = svc.repo.CreateAccount(ctx, Account)
 		if err != nil {
-			if err == domain.ErrDataNotFound {
-				slog.Error("Second owner not found", "second_owner_id", *Account.SecondOwnerID)
-				return nil, domain.ErrDataNotFound
-			}
-			return nil, domain.ErrInternal
+			return err
 		}
-	}
-
-	slog.Info("Creating new account", "name", Account.Name, "type", Account.AccountType, "primary_owner_id", Account.PrimaryOwnerID)
-
-	// Call the repository to create the Account
-	account, err := svc.repo.CreateAccount(ctx, Account)
+		return recordAudit(ctx, svc.auditRepo, domain.AuditEntityAccount, account.ID, domain.AuditActionCreate, nil, account)
+	})
 	if err != nil {
-		if err == domain.ErrConflictingData {
-			return nil, err
-		}
-		return nil, domain.ErrInternal
+		return nil, domainError(err)
 	}
 
 	return account, nil
@@ -65,7 +57,7 @@ func (svc *AccountService) Create(ctx context.Context, Account *domain.Account)
 func (svc *AccountService) GetAccount(ctx context.Context, id uint64) (*domain.Account, error) {
 	account, err := svc.repo.GetAccountByID(ctx, id)
 	if err != nil {
-		if err == domain.ErrDataNotFound {
+		if errors.Is(err, domain.ErrDataNotFound) {
 			return nil, err
 		}
 		return nil, domain.ErrInternal
@@ -73,84 +65,249 @@ func (svc *AccountService) GetAccount(ctx context.Context, id uint64) (*domain.A
 	return account, nil
 }
 
-// ListAccounts returns a list of Accounts with pagination
-func (svc *AccountService) ListAccounts(ctx context.Context, skip, limit uint64) ([]domain.Account, error) {
-	slog.Info("Listing accounts", "skip", skip, "limit", limit)
-	accounts, err := svc.repo.ListAccounts(ctx, skip, limit)
-	slog.Info("SERVICE Accounts found", "count", len(accounts))
+// ListAccounts returns a page of Accounts, optionally including soft-deleted ones
+func (svc *AccountService) ListAccounts(ctx context.Context, req *domain.PageRequest, includeDeleted bool) (*domain.Page[domain.Account], error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Listing accounts", "cursor", req.Cursor, "limit", req.Limit, "include_deleted", includeDeleted)
+	limit, err := pageLimit(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	after, err := domain.DecodeCursor(req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read one more Account than asked to tell whether another page follows
+	accounts, err := svc.repo.ListAccounts(ctx, after, limit+1, includeDeleted)
 	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to list accounts", "error", err)
 		return nil, domain.ErrInternal
 	}
-	return accounts, nil
+	page := newPage(accounts, limit, accountCursor)
+
+	if req.IncludeTotal {
+		total, err := svc.repo.CountAccounts(ctx, includeDeleted)
+		if err != nil {
+			return nil, domain.ErrInternal
+		}
+		page.TotalCount = &total
+	}
+
+	return page, nil
 }
 
 // UpdateAccount updates a Account
 func (svc *AccountService) UpdateAccount(ctx context.Context, account *domain.Account) (*domain.Account, error) {
-	existingAccount, err := svc.repo.GetAccountByID(ctx, account.ID)
-	if err != nil {
-		if err == domain.ErrDataNotFound {
-			return nil, err
+	// The account and its owners are read within the transaction changing it
+	var updatedAccount *domain.Account
+	err := svc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		existingAccount, err := svc.repo.GetAccountByID(ctx, account.ID)
+		if err != nil {
+			if errors.Is(err, domain.ErrDataNotFound) {
+				return err
+			}
+			return domain.ErrInternal
+		}
+
+		// Reject updates based on a stale version before validating anything else
+		if account.Version != 0 && existingAccount.Version != account.Version {
+			return domain.ErrPreconditionFailed
+		}
+
+		// Every descriptive field is required, a zero initial balance is a legitimate value
+		switch {
+		case account.Name == "":
+			return requiredField("name")
+		case account.Currency == "":
+			return requiredField("currency")
+		case account.AccountType == "":
+			return requiredField("account_type")
+		}
+
+		if err := svc.validateOwners(ctx, account.PrimaryOwnerID, account.SecondOwnerID); err != nil {
+			return err
+		}
+
+		updatedAccount, err = svc.saveAccount(ctx, account, existingAccount)
+		return err
+	})
+	return updatedAccount, err
+}
+
+// PatchAccount applies a JSON merge patch to a Account, changing only the present members
+func (svc *AccountService) PatchAccount(ctx context.Context, id uint64, req *domain.PatchAccountRequest) (*domain.Account, error) {
+	// The account and its owners are read within the transaction changing it
+	var updatedAccount *domain.Account
+	err := svc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		existingAccount, err := svc.repo.GetAccountByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, domain.ErrDataNotFound) {
+				return err
+			}
+			return domain.ErrInternal
+		}
+
+		// Reject patches based on a stale version
+		if req.Version != 0 && existingAccount.Version != req.Version {
+			return domain.ErrPreconditionFailed
+		}
+
+		// Apply and validate only the members present in the patch,
+		// the second owner is the only one that can be removed
+		account := *existingAccount
+		account.Version = req.Version
+		if req.Name.Set {
+			if req.Name.Null {
+				return nullField("name")
+			}
+			if req.Name.Value == "" {
+				return requiredField("name")
+			}
+			account.Name = req.Name.Value
+		}
+		if req.Currency.Set {
+			if req.Currency.Null {
+				return nullField("currency")
+			}
+			if req.Currency.Value == "" {
+				return requiredField("currency")
+			}
+			account.Currency = req.Currency.Value
+		}
+		if req.AccountType.Set {
+			if req.AccountType.Null {
+				return nullField("account_type")
+			}
+			if req.AccountType.Value == "" {
+				return requiredField("account_type")
+			}
+			account.AccountType = req.AccountType.Value
+		}
+		if req.InitialBalance.Set {
+			if req.InitialBalance.Null {
+				return nullField("initial_balance")
+			}
+			account.InitialBalance = req.InitialBalance.Value
 		}
-		return nil, domain.ErrInternal
-	}
 
-	// Validate that primary owner exists
-	_, err = svc.personRepo.GetPersonByID(ctx, account.PrimaryOwnerID)
+		if req.PrimaryOwnerID.Set {
+			if req.PrimaryOwnerID.Null {
+				return nullField("primary_owner_id")
+			}
+			if req.PrimaryOwnerID.Value == 0 {
+				return invalidID("primary_owner_id")
+			}
+			account.PrimaryOwnerID = req.PrimaryOwnerID.Value
+		}
+		if req.SecondOwnerID.Set {
+			if req.SecondOwnerID.Null {
+				account.SecondOwnerID = nil
+			} else {
+				account.SecondOwnerID = &req.SecondOwnerID.Value
+			}
+		}
+
+		// Validate the owners when the patch assigns any of them
+		if req.PrimaryOwnerID.Set || req.SecondOwnerID.HasValue() {
+			if err := svc.validateOwners(ctx, account.PrimaryOwnerID, account.SecondOwnerID); err != nil {
+				return err
+			}
+		}
+
+		domain.LoggerFromContext(ctx).InfoContext(ctx, "Patching account", "id", id)
+		updatedAccount, err = svc.saveAccount(ctx, &account, existingAccount)
+		return err
+	})
+	return updatedAccount, err
+}
+
+// validateOwners checks that the primary owner and the optional second owner exist
+func (svc *AccountService) validateOwners(ctx context.Context, primaryOwnerID uint64, secondOwnerID *uint64) error {
+	_, err := svc.personRepo.GetPersonByIDForShare(ctx, primaryOwnerID)
 	if err != nil {
-		if err == domain.ErrDataNotFound {
-			slog.Error("Primary owner not found", "primary_owner_id", account.PrimaryOwnerID)
-			return nil, domain.ErrDataNotFound
+		if errors.Is(err, domain.ErrDataNotFound) {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Primary owner not found", "primary_owner_id", primaryOwnerID)
+			return domain.ErrDataNotFound
 		}
-		return nil, domain.ErrInternal
+		return domain.ErrInternal
 	}
 
-	// Validate that second owner exists (if provided)
-	if account.SecondOwnerID != nil {
-		_, err := svc.personRepo.GetPersonByID(ctx, *account.SecondOwnerID)
+	if secondOwnerID != nil {
+		_, err := svc.personRepo.GetPersonByIDForShare(ctx, *secondOwnerID)
 		if err != nil {
-			if err == domain.ErrDataNotFound {
-				slog.Error("Second owner not found", "second_owner_id", *account.SecondOwnerID)
-				return nil, domain.ErrDataNotFound
+			if errors.Is(err, domain.ErrDataNotFound) {
+				domain.LoggerFromContext(ctx).ErrorContext(ctx, "Second owner not found", "second_owner_id", *secondOwnerID)
+				return domain.ErrDataNotFound
 			}
-			return nil, domain.ErrInternal
+			return domain.ErrInternal
 		}
 	}
 
-	// Check if there's actually something to update
-	emptyData := account.Name == "" && account.Currency == "" && account.AccountType == "" && account.InitialBalance == 0
-	sameData := existingAccount.Name == account.Name &&
-		existingAccount.Currency == account.Currency &&
-		existingAccount.AccountType == account.AccountType &&
-		existingAccount.InitialBalance == account.InitialBalance &&
-		existingAccount.PrimaryOwnerID == account.PrimaryOwnerID &&
-		((existingAccount.SecondOwnerID == nil && account.SecondOwnerID == nil) ||
-			(existingAccount.SecondOwnerID != nil && account.SecondOwnerID != nil && *existingAccount.SecondOwnerID == *account.SecondOwnerID))
-
-	if emptyData || sameData {
-		return nil, domain.ErrNoUpdatedData
-	}
+	return nil
+}
 
-	// Call the repository to update the Account
-	updatedAccount, err := svc.repo.UpdateAccount(ctx, account)
-	if err != nil {
-		if err == domain.ErrNoUpdatedData {
-			return nil, err
+// saveAccount stores the new state of an Account and records the change in the audit trail
+func (svc *AccountService) saveAccount(ctx context.Context, account *domain.Account, existingAccount *domain.Account) (*domain.Account, error) {
+	// Snapshot the current state before the repository touches it
+	before := *existingAccount
+
+	var updatedAccount *domain.Account
+	err := svc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		updatedAccount, err = svc.repo.UpdateAccount(ctx, account)
+		if err != nil {
+			return err
 		}
-		return nil, domain.ErrInternal
+		return recordAudit(ctx, svc.auditRepo, domain.AuditEntityAccount, account.ID, domain.AuditActionUpdate, before, updatedAccount)
+	})
+	if err != nil {
+		return nil, domainError(err)
 	}
 
 	return updatedAccount, nil
 }
 
-// DeleteAccount deletes a Account
-func (svc *AccountService) DeleteAccount(ctx context.Context, id uint64) error {
-	_, err := svc.repo.GetAccountByID(ctx, id)
-	if err != nil {
-		if err == domain.ErrDataNotFound {
+// DeleteAccount deletes a Account based on the given version, 0 skips the check
+func (svc *AccountService) DeleteAccount(ctx context.Context, id uint64, version int) error {
+	// The account is read within the transaction deleting it
+	return svc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		existingAccount, err := svc.repo.GetAccountByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, domain.ErrDataNotFound) {
+				return err
+			}
+			return domain.ErrInternal
+		}
+		if version != 0 && existingAccount.Version != version {
+			return domain.ErrPreconditionFailed
+		}
+
+		if err := svc.repo.DeleteAccount(ctx, id, version); err != nil {
 			return err
 		}
-		return domain.ErrInternal
+		return recordAudit(ctx, svc.auditRepo, domain.AuditEntityAccount, id, domain.AuditActionDelete, *existingAccount, nil)
+	})
+}
+
+// RestoreAccount restores a soft-deleted Account
+func (svc *AccountService) RestoreAccount(ctx context.Context, id uint64) (*domain.Account, error) {
+	var account *domain.Account
+	err := svc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := svc.repo.RestoreAccount(ctx, id); err != nil {
+			return err
+		}
+
+		var err error
+		account, err = svc.repo.GetAccountByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, svc.auditRepo, domain.AuditEntityAccount, id, domain.AuditActionRestore, nil, account)
+	})
+	if err != nil {
+		return nil, domainError(err)
 	}
 
-	return svc.repo.DeleteAccount(ctx, id)
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Account restored", "id", id)
+	return account, nil
 }
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+// maxAttachmentFileNameLength is the longest file name kept for an attachment, in characters
+const maxAttachmentFileNameLength = 255
+
+type attachmentService struct {
+	repo        port.AttachmentRepository
+	expenseRepo port.ExpenseRepository
+	blobStore   port.BlobStore
+	txManager   port.TxManager
+	auditRepo   port.AuditRepository
+}
+
+// NewAttachmentService creates a new attachment service
+func NewAttachmentService(
+	repo port.AttachmentRepository,
+	expenseRepo port.ExpenseRepository,
+	blobStore port.BlobStore,
+	txManager port.TxManager,
+	auditRepo port.AuditRepository,
+) port.AttachmentService {
+	return &attachmentService{
+		repo:        repo,
+		expenseRepo: expenseRepo,
+		blobStore:   blobStore,
+		txManager:   txManager,
+		auditRepo:   auditRepo,
+	}
+}
+
+func (s *attachmentService) Upload(ctx context.Context, expenseID int, req *domain.UploadAttachmentRequest) (*domain.Attachment, error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Uploading attachment", "expense_id", expenseID, "file_name", req.FileName, "size", req.Size)
+
+	if expenseID <= 0 {
+		return nil, invalidID("id")
+	}
+	if req.Content == nil {
+		return nil, requiredField("file")
+	}
+	if req.Size > domain.MaxAttachmentSize {
+		return nil, domain.ErrPayloadTooLarge
+	}
+	if err := s.checkExpense(ctx, expenseID); err != nil {
+		return nil, err
+	}
+
+	// Read the content once to check its size, sniff its type and compute its checksum
+	content, err := io.ReadAll(io.LimitReader(req.Content, domain.MaxAttachmentSize+1))
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to read attachment", "error", err, "expense_id", expenseID)
+		return nil, err
+	}
+	if len(content) == 0 {
+		return nil, domain.InvalidField("file", "required", "must not be empty")
+	}
+	if len(content) > domain.MaxAttachmentSize {
+		return nil, domain.ErrPayloadTooLarge
+	}
+
+	// The declared type of an upload is not trusted, only the content tells
+	contentType, _, _ := strings.Cut(http.DetectContentType(content), ";")
+	if !slices.Contains(domain.AttachmentContentTypes, contentType) {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Unsupported attachment content type", "content_type", contentType, "expense_id", expenseID)
+		return nil, domain.ErrUnsupportedMediaType
+	}
+
+	sum := sha256.Sum256(content)
+	attachment := &domain.Attachment{
+		ExpenseID:   expenseID,
+		FileName:    attachmentFileName(req.FileName),
+		ContentType: contentType,
+		Size:        int64(len(content)),
+		Checksum:    hex.EncodeToString(sum[:]),
+		CreatedAt:   time.Now(),
+	}
+
+	// The attachments sharing the content are counted, the content stored and the attachment created
+	// while holding the lock on the content, so that no deletion releases it in between
+	var shared int64
+	var stored bool
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// The expense may have been deleted while the content was read
+		if err := s.checkExpense(ctx, expenseID); err != nil {
+			return err
+		}
+		if err := s.repo.LockContent(ctx, attachment.Checksum); err != nil {
+			return err
+		}
+
+		// The same file is only attached once to an expense
+		_, err := s.repo.GetByChecksum(ctx, expenseID, attachment.Checksum)
+		if err == nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "File already attached to the expense", "expense_id", expenseID, "checksum", attachment.Checksum)
+			return domain.ErrConflictingData
+		}
+		if !errors.Is(err, domain.ErrDataNotFound) {
+			return err
+		}
+
+		// Content already kept for another expense is not stored again
+		if shared, err = s.repo.CountByChecksum(ctx, attachment.Checksum); err != nil {
+			return err
+		}
+		if shared == 0 {
+			err = s.blobStore.Put(ctx, attachment.BlobKey(), bytes.NewReader(content), attachment.Size, attachment.ContentType)
+			if err != nil {
+				domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to store attachment content", "error", err, "expense_id", expenseID)
+				return err
+			}
+			stored = true
+		}
+
+		if err := s.repo.Create(ctx, attachment); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditEntityAttachment, uint64(attachment.ID), domain.AuditActionCreate, nil, attachment)
+	})
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to create attachment", "error", err, "expense_id", expenseID)
+		if stored {
+			s.releaseContent(ctx, attachment)
+		}
+		return nil, err
+	}
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Attachment uploaded successfully", "id", attachment.ID, "expense_id", expenseID, "deduplicated", shared > 0)
+	return attachment, nil
+}
+
+func (s *attachmentService) List(ctx context.Context, expenseID int) ([]*domain.Attachment, error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Listing attachments", "expense_id", expenseID)
+
+	if expenseID <= 0 {
+		return nil, invalidID("id")
+	}
+	if err := s.checkExpense(ctx, expenseID); err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.repo.ListByExpense(ctx, expenseID)
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to list attachments", "error", err, "expense_id", expenseID)
+		return nil, err
+	}
+	if attachments == nil {
+		attachments = []*domain.Attachment{}
+	}
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Attachments retrieved successfully", "expense_id", expenseID, "count", len(attachments))
+	return attachments, nil
+}
+
+func (s *attachmentService) GetByID(ctx context.Context, expenseID int, id int) (*domain.Attachment, error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Getting attachment by ID", "expense_id", expenseID, "id", id)
+
+	attachment, err := s.attachment(ctx, expenseID, id)
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to get attachment", "error", err, "expense_id", expenseID, "id", id)
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+func (s *attachmentService) Download(ctx context.Context, expenseID int, id int) (*domain.Attachment, io.ReadCloser, error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Downloading attachment", "expense_id", expenseID, "id", id)
+
+	attachment, err := s.attachment(ctx, expenseID, id)
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to get attachment for download", "error", err, "expense_id", expenseID, "id", id)
+		return nil, nil, err
+	}
+
+	content, err := s.blobStore.Get(ctx, attachment.BlobKey())
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to read attachment content", "error", err, "id", id, "checksum", attachment.Checksum)
+		return nil, nil, err
+	}
+
+	return attachment, content, nil
+}
+
+func (s *attachmentService) Delete(ctx context.Context, expenseID int, id int) error {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Deleting attachment", "expense_id", expenseID, "id", id)
+
+	existingAttachment, err := s.attachment(ctx, expenseID, id)
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to get attachment for deletion", "error", err, "expense_id", expenseID, "id", id)
+		return err
+	}
+
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditEntityAttachment, uint64(id), domain.AuditActionDelete, existingAttachment, nil)
+	})
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to delete attachment", "error", err, "expense_id", expenseID, "id", id)
+		return err
+	}
+
+	// The content goes with the last attachment using it, once the deletion is committed
+	s.releaseContent(ctx, existingAttachment)
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Attachment deleted successfully", "expense_id", expenseID, "id", id)
+	return nil
+}
+
+// attachment returns an attachment of an existing expense, attachments of other expenses are not found
+func (s *attachmentService) attachment(ctx context.Context, expenseID int, id int) (*domain.Attachment, error) {
+	if expenseID <= 0 {
+		return nil, invalidID("id")
+	}
+	if id <= 0 {
+		return nil, invalidID("attachment_id")
+	}
+	if err := s.checkExpense(ctx, expenseID); err != nil {
+		return nil, err
+	}
+
+	attachment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if attachment.ExpenseID != expenseID {
+		return nil, domain.ErrDataNotFound
+	}
+
+	return attachment, nil
+}
+
+// checkExpense validates that the expense exists and is not soft deleted
+func (s *attachmentService) checkExpense(ctx context.Context, expenseID int) error {
+	if _, err := s.expenseRepo.GetByID(ctx, expenseID); err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Expense not found", "error", err, "expense_id", expenseID)
+		return err
+	}
+	return nil
+}
+
+// releaseContent deletes the stored content of an attachment once no attachment uses it anymore
+func (s *attachmentService) releaseContent(ctx context.Context, attachment *domain.Attachment) {
+	releaseAttachmentContent(ctx, s.repo, s.blobStore, s.txManager, attachment)
+}
+
+// releaseAttachmentContent deletes the stored content of an attachment once no attachment uses it anymore,
+// counting them while holding the lock on the content so that no upload starts using it meanwhile.
+// Failures are only logged, leftover content is harmless.
+func releaseAttachmentContent(ctx context.Context, repo port.AttachmentRepository, blobStore port.BlobStore, txManager port.TxManager, attachment *domain.Attachment) {
+	err := txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := repo.LockContent(ctx, attachment.Checksum); err != nil {
+			return err
+		}
+		shared, err := repo.CountByChecksum(ctx, attachment.Checksum)
+		if err != nil || shared > 0 {
+			return err
+		}
+		return blobStore.Delete(ctx, attachment.BlobKey())
+	})
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to release attachment content", "error", err, "checksum", attachment.Checksum)
+	}
+}
+
+// attachmentFileName keeps the base name of an uploaded file, clients may send a full path
+func attachmentFileName(name string) string {
+	name = strings.TrimSpace(path.Base(strings.ReplaceAll(name, `\`, "/")))
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+	if runes := []rune(name); len(runes) > maxAttachmentFileNameLength {
+		name = string(runes[:maxAttachmentFileNameLength])
+	}
+	return name
+}
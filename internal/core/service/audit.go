@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+type auditService struct {
+	repo port.AuditRepository
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService(repo port.AuditRepository) port.AuditService {
+	return &auditService{
+		repo: repo,
+	}
+}
+
+func (s *auditService) List(ctx context.Context, req *domain.ListAuditEntriesRequest) (*domain.Page[*domain.AuditEntry], error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Listing audit entries", "entity", req.EntityType, "id", req.EntityID, "cursor", req.Cursor, "limit", req.Limit)
+
+	limit, err := pageLimit(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	after, err := domain.DecodeCursor(req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// Optional filters
+	var filters port.AuditFilters
+	if req.EntityType != "" {
+		if !domain.IsAuditEntityType(req.EntityType) {
+			return nil, domain.InvalidField("entity", "oneof", "must be an audited entity type")
+		}
+		filters.EntityType = &req.EntityType
+	}
+	if req.EntityID > 0 {
+		// An id is only meaningful together with the entity type it belongs to
+		if filters.EntityType == nil {
+			return nil, domain.InvalidField("id", "required_with", "requires an entity")
+		}
+		filters.EntityID = &req.EntityID
+	}
+
+	// Read one more entry than asked to tell whether another page follows
+	entries, err := s.repo.List(ctx, after, limit+1, filters)
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to list audit entries", "error", err)
+		return nil, err
+	}
+	page := newPage(entries, limit, auditEntryCursor)
+
+	if req.IncludeTotal {
+		total, err := s.repo.Count(ctx, filters)
+		if err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to count audit entries", "error", err)
+			return nil, err
+		}
+		page.TotalCount = &total
+	}
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Audit entries retrieved successfully", "count", len(page.Items), "has_more", page.HasMore)
+	return page, nil
+}
+
+// recordAudit appends an entry to the audit trail describing a mutation of an entity.
+// before is nil for creations and after is nil for deletions.
+func recordAudit(ctx context.Context, repo port.AuditRepository, entityType string, entityID uint64, action string, before, after any) error {
+	entry := &domain.AuditEntry{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Actor:      domain.ActorFromContext(ctx),
+		RequestID:  domain.RequestIDFromContext(ctx),
+		CreatedAt:  time.Now(),
+	}
+
+	var err error
+	if entry.Before, err = auditSnapshot(before); err != nil {
+		return err
+	}
+	if entry.After, err = auditSnapshot(after); err != nil {
+		return err
+	}
+
+	return repo.Create(ctx, entry)
+}
+
+// auditSnapshot serializes an entity state, keeping nil states as nil
+func auditSnapshot(state any) (json.RawMessage, error) {
+	if state == nil {
+		return nil, nil
+	}
+	return json.Marshal(state)
+}
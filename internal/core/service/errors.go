@@ -0,0 +1,31 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+)
+
+// domainErrors are the errors callers are told about, any other failure is internal
+var domainErrors = []error{
+	domain.ErrDataNotFound,
+	domain.ErrNoUpdatedData,
+	domain.ErrConflictingData,
+	domain.ErrInvalidInput,
+	domain.ErrPreconditionFailed,
+	domain.ErrPreconditionRequired,
+	domain.ErrForbidden,
+	domain.ErrReferencedData,
+	domain.ErrInvalidReference,
+}
+
+// domainError returns err unchanged when it is a domain error, such as a constraint violation mapped
+// by the storage or a validation error, and domain.ErrInternal otherwise
+func domainError(err error) error {
+	for _, domainErr := range domainErrors {
+		if errors.Is(err, domainErr) {
+			return err
+		}
+	}
+	return domain.ErrInternal
+}
@@ -2,7 +2,10 @@ package service
 
 import (
 	"context"
-	"log/slog"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
@@ -14,9 +17,12 @@ type expenseService struct {
 	repo            port.ExpenseRepository
 	categoryRepo    port.ExpenseCategoryRepository
 	subCategoryRepo port.ExpenseSubCategoryRepository
+	payeeRepo       port.PayeeRepository
 	personRepo      port.PersonRepository
 	accountRepo     port.AccountRepository
-	logger          *slog.Logger
+	tagRepo         port.TagRepository
+	txManager       port.TxManager
+	auditRepo       port.AuditRepository
 }
 
 // NewExpenseService creates a new expense service
@@ -24,153 +30,535 @@ func NewExpenseService(
 	repo port.ExpenseRepository,
 	categoryRepo port.ExpenseCategoryRepository,
 	subCategoryRepo port.ExpenseSubCategoryRepository,
+	payeeRepo port.PayeeRepository,
 	personRepo port.PersonRepository,
 	accountRepo port.AccountRepository,
-	logger *slog.Logger,
+	tagRepo port.TagRepository,
+	txManager port.TxManager,
+	auditRepo port.AuditRepository,
 ) port.ExpenseService {
 	return &expenseService{
 		repo:            repo,
 		categoryRepo:    categoryRepo,
 		subCategoryRepo: subCategoryRepo,
+		payeeRepo:       payeeRepo,
 		personRepo:      personRepo,
 		accountRepo:     accountRepo,
-		logger:          logger,
+		tagRepo:         tagRepo,
+		txManager:       txManager,
+		auditRepo:       auditRepo,
 	}
 }
 
 func (s *expenseService) Create(ctx context.Context, req *domain.CreateExpenseRequest) (*domain.Expense, error) {
-	s.logger.Info("Creating expense", "amount", req.Amount, "category_id", req.CategoryID, "payee_id", req.PayeeID)
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Creating expense", "amount", req.Amount, "category_id", req.CategoryID, "payee_id", req.PayeeID)
 
 	// Validate amount
 	if req.Amount < 0 {
-		return nil, domain.ErrInvalidInput
+		return nil, domain.InvalidField("amount", "min", "must be at least 0")
 	}
 
 	// Validate and parse date
 	date, err := time.Parse("2006-01-02", req.Date)
 	if err != nil {
-		s.logger.Error("Invalid date format", "error", err, "date", req.Date)
-		return nil, domain.ErrInvalidInput
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Invalid date format", "error", err, "date", req.Date)
+		return nil, invalidDate("date")
 	}
 
-	// Validate that the expense category exists
-	_, err = s.categoryRepo.GetByID(ctx, req.CategoryID)
+	// The referenced entities are checked within the transaction creating the expense
+	var expense *domain.Expense
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Validate that the payee exists, its default category applies to expenses given none
+		categoryID, subCategoryID, err := s.payeeDefaults(ctx, req.PayeeID, req.CategoryID, req.SubCategoryID)
+		if err != nil {
+			return err
+		}
+
+		// Validate that the expense category exists
+		_, err = s.categoryRepo.GetByIDForShare(ctx, categoryID)
+		if err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Expense category not found", "error", err, "category_id", categoryID)
+			return err
+		}
+
+		// Validate subcategory if provided
+		if subCategoryID != nil {
+			subCategory, err := s.subCategoryRepo.GetByIDForShare(ctx, *subCategoryID)
+			if err != nil {
+				domain.LoggerFromContext(ctx).ErrorContext(ctx, "Expense subcategory not found", "error", err, "subcategory_id", *subCategoryID)
+				return err
+			}
+
+			// Ensure subcategory belongs to the specified category
+			if subCategory.ExpenseCategoryID != categoryID {
+				domain.LoggerFromContext(ctx).ErrorContext(ctx, "Subcategory does not belong to the specified category",
+					"subcategory_id", *subCategoryID, "category_id", categoryID,
+					"subcategory_category_id", subCategory.ExpenseCategoryID)
+				return errSubCategoryOutsideCategory
+			}
+		}
+
+		// Validate that the account exists
+		_, err = s.accountRepo.GetAccountByIDForShare(ctx, uint64(req.AccountID))
+		if err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Account not found", "error", err, "account_id", req.AccountID)
+			return err
+		}
+
+		// Validate that every tag exists
+		tagIDs, err := s.expenseTagIDs(ctx, req.TagIDs)
+		if err != nil {
+			return err
+		}
+
+		// Validate the split lines against the amount
+		splits, err := s.expenseSplits(ctx, req.Amount, req.Splits)
+		if err != nil {
+			return err
+		}
+
+		// Validate the sharing and compute the share of each person
+		sharing, err := s.expenseSharing(ctx, req.Amount, req.Sharing)
+		if err != nil {
+			return err
+		}
+
+		// Sanitize notes
+		notes := strings.TrimSpace(req.Notes)
+
+		expense = &domain.Expense{
+			Amount:        req.Amount,
+			CategoryID:    categoryID,
+			SubCategoryID: subCategoryID,
+			Date:          date,
+			PayeeID:       req.PayeeID,
+			AccountID:     req.AccountID,
+			Notes:         notes,
+			TagIDs:        tagIDs,
+			Splits:        splits,
+			Sharing:       sharing,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		}
+
+		if err := s.repo.Create(ctx, expense); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditEntityExpense, uint64(expense.ID), domain.AuditActionCreate, nil, expense)
+	})
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to create expense", "error", err)
+		return nil, err
+	}
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Expense created successfully", "id", expense.ID, "amount", expense.Amount)
+	return expense, nil
+}
+
+func (s *expenseService) GetByID(ctx context.Context, id int) (*domain.Expense, error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Getting expense by ID", "id", id)
+
+	if id <= 0 {
+		return nil, invalidID("id")
+	}
+
+	expense, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to get expense", "error", err, "id", id)
+		return nil, err
+	}
+
+	return expense, nil
+}
+
+func (s *expenseService) List(ctx context.Context, req *domain.ListExpensesRequest) (*domain.Page[*domain.Expense], error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Listing expenses", "cursor", req.Cursor, "limit", req.Limit)
+
+	limit, err := pageLimit(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build filters
+	filters, err := s.expenseFilters(ctx, req)
 	if err != nil {
-		s.logger.Error("Expense category not found", "error", err, "category_id", req.CategoryID)
 		return nil, err
 	}
+	if filters.After, err = domain.DecodeCursor(req.Cursor); err != nil {
+		return nil, err
+	}
+	// A cursor only makes sense in the ordering of the page it was taken from
+	if filters.After != nil && filters.After.Sort != filters.Sort.String() {
+		return nil, domain.InvalidField("cursor", "sort", "must come from a page with the same sort")
+	}
+	// Read one more expense than asked to tell whether another page follows
+	filters.Limit = limit + 1
 
-	// Validate subcategory if provided
-	if req.SubCategoryID != nil {
-		subCategory, err := s.subCategoryRepo.GetByID(ctx, *req.SubCategoryID)
+	expenses, err := s.repo.List(ctx, filters)
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to list expenses", "error", err)
+		return nil, err
+	}
+	page := newPage(expenses, limit, func(expense *domain.Expense) domain.Cursor {
+		return expenseCursor(expense, filters.Sort)
+	})
+
+	if req.IncludeTotal {
+		total, err := s.repo.Count(ctx, filters)
 		if err != nil {
-			s.logger.Error("Expense subcategory not found", "error", err, "subcategory_id", *req.SubCategoryID)
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to count expenses", "error", err)
 			return nil, err
 		}
-
-		// Ensure subcategory belongs to the specified category
-		if subCategory.ExpenseCategoryID != req.CategoryID {
-			s.logger.Error("Subcategory does not belong to the specified category",
-				"subcategory_id", *req.SubCategoryID, "category_id", req.CategoryID,
-				"subcategory_category_id", subCategory.ExpenseCategoryID)
-			return nil, domain.ErrInvalidInput
-		}
+		page.TotalCount = &total
 	}
 
-	// Validate that the payee (person) exists
-	_, err = s.personRepo.GetPersonByID(ctx, uint64(req.PayeeID))
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Expenses retrieved successfully", "count", len(page.Items), "has_more", page.HasMore)
+	return page, nil
+}
+
+// TagTotals sums the matching expenses of each tag, ordered by tag name
+func (s *expenseService) TagTotals(ctx context.Context, req *domain.ListExpensesRequest) ([]domain.TagTotal, error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Summing expenses by tag")
+
+	filters, err := s.expenseFilters(ctx, req)
 	if err != nil {
-		s.logger.Error("Payee not found", "error", err, "payee_id", req.PayeeID)
 		return nil, err
 	}
 
-	// Validate that the account exists
-	_, err = s.accountRepo.GetAccountByID(ctx, uint64(req.AccountID))
+	totals, err := s.repo.TagTotals(ctx, filters)
 	if err != nil {
-		s.logger.Error("Account not found", "error", err, "account_id", req.AccountID)
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to sum expenses by tag", "error", err)
 		return nil, err
 	}
 
-	// Sanitize notes
-	notes := strings.TrimSpace(req.Notes)
+	// Name the tags
+	for i := range totals {
+		tag, err := s.tagRepo.GetByID(ctx, totals[i].TagID)
+		if err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to get tag", "error", err, "tag_id", totals[i].TagID)
+			return nil, err
+		}
+		totals[i].Name = tag.Name
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		return strings.ToLower(totals[i].Name) < strings.ToLower(totals[j].Name)
+	})
 
-	expense := &domain.Expense{
-		Amount:        req.Amount,
-		CategoryID:    req.CategoryID,
-		SubCategoryID: req.SubCategoryID,
-		Date:          date,
-		PayeeID:       req.PayeeID,
-		AccountID:     req.AccountID,
-		Notes:         notes,
-		CreatedAt:     time.Now(),
-		UpdatedAt:     time.Now(),
+	if totals == nil {
+		totals = []domain.TagTotal{}
 	}
 
-	if err := s.repo.Create(ctx, expense); err != nil {
-		s.logger.Error("Failed to create expense", "error", err)
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Expenses summed by tag successfully", "count", len(totals))
+	return totals, nil
+}
+
+// expenseTagIDs validates the tags of an expense and returns their IDs without duplicates
+func (s *expenseService) expenseTagIDs(ctx context.Context, ids []int) ([]int, error) {
+	if err := checkIDs("tag_ids", ids); err != nil {
 		return nil, err
 	}
 
-	s.logger.Info("Expense created successfully", "id", expense.ID, "amount", expense.Amount)
-	return expense, nil
+	ids = uniqueIDs(ids)
+	for _, id := range ids {
+		if _, err := s.tagRepo.GetByID(ctx, id); err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Tag not found", "error", err, "tag_id", id)
+			return nil, err
+		}
+	}
+
+	return ids, nil
 }
 
-func (s *expenseService) GetByID(ctx context.Context, id int) (*domain.Expense, error) {
-	s.logger.Info("Getting expense by ID", "id", id)
+// expenseSplits validates the split lines of an expense, whose amounts must add up to the expense amount
+func (s *expenseService) expenseSplits(ctx context.Context, amount float64, splits []domain.ExpenseSplit) ([]domain.ExpenseSplit, error) {
+	if len(splits) == 0 {
+		return nil, nil
+	}
+	if len(splits) < domain.MinExpenseSplits {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Too few split lines", "count", len(splits), "min", domain.MinExpenseSplits)
+		return nil, domain.InvalidField("splits", "min", fmt.Sprintf("must have at least %d items", domain.MinExpenseSplits))
+	}
 
-	if id <= 0 {
-		return nil, domain.ErrInvalidInput
+	splits = slices.Clone(splits)
+	var total int64
+	for i := range splits {
+		line := &splits[i]
+		if line.CategoryID <= 0 {
+			return nil, invalidID(fmt.Sprintf("splits[%d].category_id", i))
+		}
+		if line.Amount <= 0 {
+			return nil, domain.InvalidField(fmt.Sprintf("splits[%d].amount", i), "gt", "must be greater than 0")
+		}
+
+		// Validate that the expense category exists
+		if _, err := s.categoryRepo.GetByIDForShare(ctx, line.CategoryID); err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Split expense category not found", "error", err, "category_id", line.CategoryID)
+			return nil, err
+		}
+
+		// Ensure the subcategory belongs to the category of the line
+		if line.SubCategoryID != nil {
+			subCategory, err := s.subCategoryRepo.GetByIDForShare(ctx, *line.SubCategoryID)
+			if err != nil {
+				domain.LoggerFromContext(ctx).ErrorContext(ctx, "Split expense subcategory not found", "error", err, "subcategory_id", *line.SubCategoryID)
+				return nil, err
+			}
+			if subCategory.ExpenseCategoryID != line.CategoryID {
+				domain.LoggerFromContext(ctx).ErrorContext(ctx, "Subcategory does not belong to the split category",
+					"subcategory_id", *line.SubCategoryID, "category_id", line.CategoryID,
+					"subcategory_category_id", subCategory.ExpenseCategoryID)
+				return nil, domain.InvalidField(fmt.Sprintf("splits[%d].subcategory_id", i), "category", "must belong to the category of the split line")
+			}
+		}
+
+		line.Notes = strings.TrimSpace(line.Notes)
+		total += amountCents(line.Amount)
 	}
 
-	expense, err := s.repo.GetByID(ctx, id)
+	// Compare whole cents, floating point sums are not exact
+	if total != amountCents(amount) {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Split lines do not add up to the expense amount", "amount", amount, "split_total", float64(total)/100)
+		return nil, domain.InvalidField("splits", "sum", "must add up to the expense amount")
+	}
+
+	return splits, nil
+}
+
+// payeeDefaults validates that the payee of an expense exists and returns the category and subcategory of the expense,
+// the default ones of the payee when the expense sets no category
+func (s *expenseService) payeeDefaults(ctx context.Context, payeeID int, categoryID int, subCategoryID *int) (int, *int, error) {
+	payee, err := s.payeeRepo.GetByID(ctx, payeeID)
 	if err != nil {
-		s.logger.Error("Failed to get expense", "error", err, "id", id)
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Payee not found", "error", err, "payee_id", payeeID)
+		return 0, nil, err
+	}
+
+	if categoryID == 0 && payee.DefaultCategoryID != nil {
+		categoryID = *payee.DefaultCategoryID
+		if subCategoryID == nil {
+			subCategoryID = payee.DefaultSubCategoryID
+		}
+	}
+	if categoryID <= 0 {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Expense category missing and payee has no default category", "payee_id", payeeID)
+		return 0, nil, domain.InvalidField("category_id", "required", "is required when the payee has no default category")
+	}
+
+	return categoryID, subCategoryID, nil
+}
+
+// expenseSharing validates how the cost of an expense is shared and computes the amount owed by each person,
+// rounding to cents so that the shares always add up to the expense amount
+func (s *expenseService) expenseSharing(ctx context.Context, amount float64, sharing *domain.ExpenseSharing) (*domain.ExpenseSharing, error) {
+	if sharing == nil {
+		return nil, nil
+	}
+	if sharing.PaidByID <= 0 {
+		return nil, invalidID("sharing.paid_by_id")
+	}
+	if len(sharing.Shares) == 0 {
+		return nil, requiredField("sharing.shares")
+	}
+
+	// Validate that the payer and every sharing person exist, each person sharing once
+	if _, err := s.personRepo.GetPersonByIDForShare(ctx, uint64(sharing.PaidByID)); err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Payer not found", "error", err, "paid_by_id", sharing.PaidByID)
 		return nil, err
 	}
+	result := &domain.ExpenseSharing{
+		PaidByID: sharing.PaidByID,
+		Method:   sharing.Method,
+		Shares:   slices.Clone(sharing.Shares),
+	}
+	for i, share := range result.Shares {
+		if share.PersonID <= 0 {
+			return nil, invalidID(fmt.Sprintf("sharing.shares[%d].person_id", i))
+		}
+		if slices.ContainsFunc(result.Shares[:i], func(other domain.ExpenseShare) bool {
+			return other.PersonID == share.PersonID
+		}) {
+			return nil, domain.InvalidField(fmt.Sprintf("sharing.shares[%d].person_id", i), "unique", "must not share the cost twice")
+		}
+		if _, err := s.personRepo.GetPersonByIDForShare(ctx, uint64(share.PersonID)); err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Sharing person not found", "error", err, "person_id", share.PersonID)
+			return nil, err
+		}
+	}
 
-	return expense, nil
+	total := amountCents(amount)
+	weights := make([]float64, len(result.Shares))
+	switch result.Method {
+	case domain.SharingMethodEqual:
+		for i := range result.Shares {
+			weights[i] = 1
+			result.Shares[i].Percentage = 0
+		}
+	case domain.SharingMethodPercentage:
+		var percentages float64
+		for i, share := range result.Shares {
+			if share.Percentage <= 0 {
+				return nil, domain.InvalidField(fmt.Sprintf("sharing.shares[%d].percentage", i), "gt", "must be greater than 0")
+			}
+			weights[i] = share.Percentage
+			percentages += share.Percentage
+		}
+		if math.Abs(percentages-100) > 1e-6 {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Sharing percentages do not add up to 100", "total", percentages)
+			return nil, domain.InvalidField("sharing.shares", "sum", "must add up to 100")
+		}
+	case domain.SharingMethodExact:
+		var shared int64
+		for i, share := range result.Shares {
+			if share.Amount < 0 {
+				return nil, domain.InvalidField(fmt.Sprintf("sharing.shares[%d].amount", i), "min", "must be at least 0")
+			}
+			result.Shares[i].Percentage = 0
+			result.Shares[i].Amount = float64(amountCents(share.Amount)) / 100
+			shared += amountCents(share.Amount)
+		}
+		if shared != total {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Shares do not add up to the expense amount", "amount", amount, "shared", float64(shared)/100)
+			return nil, domain.InvalidField("sharing.shares", "sum", "must add up to the expense amount")
+		}
+		return result, nil
+	default:
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Invalid sharing method", "method", result.Method)
+		return nil, domain.InvalidField("sharing.method", "oneof", "must be one of equal, percentage, exact")
+	}
+
+	for i, cents := range allocateCents(total, weights) {
+		result.Shares[i].Amount = float64(cents) / 100
+	}
+	return result, nil
 }
 
-func (s *expenseService) List(ctx context.Context, req *domain.ListExpensesRequest) ([]*domain.Expense, error) {
-	s.logger.Info("Listing expenses", "skip", req.Skip, "limit", req.Limit)
+// mergeSharing applies a merge patch to the sharing of an expense, which may not be shared yet.
+// Every member is required, so none may be null, and the shares are replaced as a whole like any array.
+func mergeSharing(sharing *domain.ExpenseSharing, patch *domain.PatchExpenseSharing) (*domain.ExpenseSharing, error) {
+	merged := &domain.ExpenseSharing{}
+	if sharing != nil {
+		*merged = *sharing
+	}
 
-	// Set default values
-	skip := req.Skip
-	if skip < 0 {
-		skip = 0
+	if patch.PaidByID.Set {
+		if patch.PaidByID.Null {
+			return nil, nullField("sharing.paid_by_id")
+		}
+		merged.PaidByID = patch.PaidByID.Value
+	}
+	if patch.Method.Set {
+		if patch.Method.Null {
+			return nil, nullField("sharing.method")
+		}
+		merged.Method = patch.Method.Value
+	}
+	if patch.Shares.Set {
+		if patch.Shares.Null {
+			return nil, nullField("sharing.shares")
+		}
+		merged.Shares = patch.Shares.Value
 	}
 
-	limit := req.Limit
-	if limit <= 0 || limit > 100 {
-		limit = 10
+	return merged, nil
+}
+
+// allocateCents divides a total in proportion to the weights, giving the cents left over by rounding down
+// to the first ones so that the parts always add up to the total
+func allocateCents(total int64, weights []float64) []int64 {
+	var sum float64
+	for _, weight := range weights {
+		sum += weight
 	}
 
-	// Build filters
+	parts := make([]int64, len(weights))
+	remaining := total
+	for i, weight := range weights {
+		parts[i] = int64(math.Floor(float64(total) * weight / sum))
+		remaining -= parts[i]
+	}
+	for i := 0; remaining > 0; i = (i + 1) % len(parts) {
+		parts[i]++
+		remaining--
+	}
+
+	return parts
+}
+
+// amountCents returns an amount in whole cents
+func amountCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// uniqueIDs returns the sorted IDs without duplicates, nil when there are none
+func uniqueIDs(ids []int) []int {
+	if len(ids) == 0 {
+		return nil
+	}
+	ids = slices.Clone(ids)
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
+
+// expenseFilters builds the repository filters of a list request, without pagination
+func (s *expenseService) expenseFilters(ctx context.Context, req *domain.ListExpensesRequest) (port.ExpenseFilters, error) {
 	filters := port.ExpenseFilters{
-		Skip:  skip,
-		Limit: limit,
+		IncludeDeleted: req.IncludeDeleted,
+	}
+
+	sort, err := domain.ParseExpenseSort(req.Sort)
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Invalid expense sort", "sort", req.Sort)
+		return filters, err
 	}
+	filters.Sort = sort
 
-	// Optional filters
-	if req.CategoryID > 0 {
-		filters.CategoryID = &req.CategoryID
+	// Optional filters, every ID of a list must be valid
+	if err := checkIDs("category_id", req.CategoryIDs); err != nil {
+		return filters, err
+	}
+	if err := checkIDs("payee_id", req.PayeeIDs); err != nil {
+		return filters, err
 	}
+	if err := checkIDs("account_id", req.AccountIDs); err != nil {
+		return filters, err
+	}
+	filters.CategoryIDs = req.CategoryIDs
+	filters.PayeeIDs = req.PayeeIDs
+	filters.AccountIDs = req.AccountIDs
 	if req.SubCategoryID > 0 {
 		filters.SubCategoryID = &req.SubCategoryID
 	}
-	if req.PayeeID > 0 {
-		filters.PayeeID = &req.PayeeID
+	filters.HasSubCategory = req.HasSubCategory
+	filters.NotesQuery = strings.TrimSpace(req.Notes)
+
+	// Tags, matching any of them unless all are required
+	if err := checkIDs("tag_id", req.TagIDs); err != nil {
+		return filters, err
 	}
-	if req.AccountID > 0 {
-		filters.AccountID = &req.AccountID
+	filters.TagIDs = uniqueIDs(req.TagIDs)
+	switch req.TagMatch {
+	case "", domain.TagMatchAny:
+	case domain.TagMatchAll:
+		filters.MatchAllTags = true
+	default:
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Invalid tag match", "tag_match", req.TagMatch)
+		return filters, domain.InvalidField("tag_match", "oneof", "must be one of any, all")
 	}
 
+	// Amount range
+	if req.MinAmount != nil && req.MaxAmount != nil && *req.MinAmount > *req.MaxAmount {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Invalid amount range", "min_amount", *req.MinAmount, "max_amount", *req.MaxAmount)
+		return filters, domain.InvalidField("min_amount", "lte", "must not be greater than max_amount")
+	}
+	filters.MinAmount = req.MinAmount
+	filters.MaxAmount = req.MaxAmount
+
 	// Parse date filters
 	if req.StartDate != "" {
 		startDate, err := time.Parse("2006-01-02", req.StartDate)
 		if err != nil {
-			s.logger.Error("Invalid start date format", "error", err, "start_date", req.StartDate)
-			return nil, domain.ErrInvalidInput
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Invalid start date format", "error", err, "start_date", req.StartDate)
+			return filters, invalidDate("start_date")
 		}
 		filters.StartDate = &startDate
 	}
@@ -178,129 +566,396 @@ func (s *expenseService) List(ctx context.Context, req *domain.ListExpensesReque
 	if req.EndDate != "" {
 		endDate, err := time.Parse("2006-01-02", req.EndDate)
 		if err != nil {
-			s.logger.Error("Invalid end date format", "error", err, "end_date", req.EndDate)
-			return nil, domain.ErrInvalidInput
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Invalid end date format", "error", err, "end_date", req.EndDate)
+			return filters, invalidDate("end_date")
 		}
 		// Set end date to end of day
 		endOfDay := endDate.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
 		filters.EndDate = &endOfDay
 	}
 
-	expenses, err := s.repo.List(ctx, filters)
-	if err != nil {
-		s.logger.Error("Failed to list expenses", "error", err)
-		return nil, err
-	}
-
-	s.logger.Info("Expenses retrieved successfully", "count", len(expenses))
-	return expenses, nil
+	return filters, nil
 }
 
 func (s *expenseService) Update(ctx context.Context, id int, req *domain.UpdateExpenseRequest) (*domain.Expense, error) {
-	s.logger.Info("Updating expense", "id", id, "amount", req.Amount, "category_id", req.CategoryID)
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Updating expense", "id", id, "amount", req.Amount, "category_id", req.CategoryID)
 
 	if id <= 0 {
-		return nil, domain.ErrInvalidInput
+		return nil, invalidID("id")
 	}
 
 	// Validate amount
 	if req.Amount < 0 {
-		return nil, domain.ErrInvalidInput
+		return nil, domain.InvalidField("amount", "min", "must be at least 0")
 	}
 
 	// Validate and parse date
 	date, err := time.Parse("2006-01-02", req.Date)
 	if err != nil {
-		s.logger.Error("Invalid date format", "error", err, "date", req.Date)
-		return nil, domain.ErrInvalidInput
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Invalid date format", "error", err, "date", req.Date)
+		return nil, invalidDate("date")
 	}
 
-	// Check if expense exists
-	existingExpense, err := s.repo.GetByID(ctx, id)
-	if err != nil {
-		s.logger.Error("Failed to get expense for update", "error", err, "id", id)
-		return nil, err
-	}
+	// The expense and the entities it references are read within the transaction changing it
+	var existingExpense *domain.Expense
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Check if expense exists
+		var err error
+		existingExpense, err = s.repo.GetByID(ctx, id)
+		if err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to get expense for update", "error", err, "id", id)
+			return err
+		}
 
-	// Validate that the expense category exists
-	_, err = s.categoryRepo.GetByID(ctx, req.CategoryID)
-	if err != nil {
-		s.logger.Error("Expense category not found", "error", err, "category_id", req.CategoryID)
-		return nil, err
-	}
+		// Reject updates based on a stale version
+		if req.Version != 0 && existingExpense.Version != req.Version {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Stale expense version", "id", id, "version", req.Version, "current_version", existingExpense.Version)
+			return domain.ErrPreconditionFailed
+		}
 
-	// Validate subcategory if provided
-	if req.SubCategoryID != nil {
-		subCategory, err := s.subCategoryRepo.GetByID(ctx, *req.SubCategoryID)
+		// Validate that the payee exists, its default category applies to expenses given none
+		categoryID, subCategoryID, err := s.payeeDefaults(ctx, req.PayeeID, req.CategoryID, req.SubCategoryID)
 		if err != nil {
-			s.logger.Error("Expense subcategory not found", "error", err, "subcategory_id", *req.SubCategoryID)
-			return nil, err
+			return err
 		}
 
-		// Ensure subcategory belongs to the specified category
-		if subCategory.ExpenseCategoryID != req.CategoryID {
-			s.logger.Error("Subcategory does not belong to the specified category",
-				"subcategory_id", *req.SubCategoryID, "category_id", req.CategoryID,
-				"subcategory_category_id", subCategory.ExpenseCategoryID)
-			return nil, domain.ErrInvalidInput
+		// Validate that the expense category exists
+		_, err = s.categoryRepo.GetByIDForShare(ctx, categoryID)
+		if err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Expense category not found", "error", err, "category_id", categoryID)
+			return err
 		}
-	}
 
-	// Validate that the payee (person) exists
-	_, err = s.personRepo.GetPersonByID(ctx, uint64(req.PayeeID))
+		// Validate subcategory if provided
+		if subCategoryID != nil {
+			subCategory, err := s.subCategoryRepo.GetByIDForShare(ctx, *subCategoryID)
+			if err != nil {
+				domain.LoggerFromContext(ctx).ErrorContext(ctx, "Expense subcategory not found", "error", err, "subcategory_id", *subCategoryID)
+				return err
+			}
+
+			// Ensure subcategory belongs to the specified category
+			if subCategory.ExpenseCategoryID != categoryID {
+				domain.LoggerFromContext(ctx).ErrorContext(ctx, "Subcategory does not belong to the specified category",
+					"subcategory_id", *subCategoryID, "category_id", categoryID,
+					"subcategory_category_id", subCategory.ExpenseCategoryID)
+				return errSubCategoryOutsideCategory
+			}
+		}
+
+		// Validate that the account exists
+		_, err = s.accountRepo.GetAccountByIDForShare(ctx, uint64(req.AccountID))
+		if err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Account not found", "error", err, "account_id", req.AccountID)
+			return err
+		}
+
+		// Validate that every tag exists
+		tagIDs, err := s.expenseTagIDs(ctx, req.TagIDs)
+		if err != nil {
+			return err
+		}
+
+		// Validate the split lines against the amount
+		splits, err := s.expenseSplits(ctx, req.Amount, req.Splits)
+		if err != nil {
+			return err
+		}
+
+		// Validate the sharing and compute the share of each person
+		sharing, err := s.expenseSharing(ctx, req.Amount, req.Sharing)
+		if err != nil {
+			return err
+		}
+
+		// Sanitize notes
+		notes := strings.TrimSpace(req.Notes)
+
+		// Snapshot the current state before changing it
+		before := *existingExpense
+
+		// Update fields
+		existingExpense.Amount = req.Amount
+		existingExpense.CategoryID = categoryID
+		existingExpense.SubCategoryID = subCategoryID
+		existingExpense.Date = date
+		existingExpense.PayeeID = req.PayeeID
+		existingExpense.AccountID = req.AccountID
+		existingExpense.Notes = notes
+		existingExpense.TagIDs = tagIDs
+		existingExpense.Splits = splits
+		existingExpense.Sharing = sharing
+		existingExpense.UpdatedAt = time.Now()
+		existingExpense.Version = req.Version
+
+		if err := s.repo.Update(ctx, existingExpense); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditEntityExpense, uint64(id), domain.AuditActionUpdate, before, existingExpense)
+	})
 	if err != nil {
-		s.logger.Error("Payee not found", "error", err, "payee_id", req.PayeeID)
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to update expense", "error", err, "id", id)
 		return nil, err
 	}
 
-	// Validate that the account exists
-	_, err = s.accountRepo.GetAccountByID(ctx, uint64(req.AccountID))
-	if err != nil {
-		s.logger.Error("Account not found", "error", err, "account_id", req.AccountID)
-		return nil, err
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Expense updated successfully", "id", id, "amount", req.Amount)
+	return existingExpense, nil
+}
+
+func (s *expenseService) Patch(ctx context.Context, id int, req *domain.PatchExpenseRequest) (*domain.Expense, error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Patching expense", "id", id)
+
+	if id <= 0 {
+		return nil, invalidID("id")
 	}
 
-	// Sanitize notes
-	notes := strings.TrimSpace(req.Notes)
+	// The expense and the entities it references are read within the transaction changing it
+	var existingExpense *domain.Expense
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Check if expense exists
+		var err error
+		existingExpense, err = s.repo.GetByID(ctx, id)
+		if err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to get expense for patch", "error", err, "id", id)
+			return err
+		}
+
+		// Reject patches based on a stale version
+		if req.Version != 0 && existingExpense.Version != req.Version {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Stale expense version", "id", id, "version", req.Version, "current_version", existingExpense.Version)
+			return domain.ErrPreconditionFailed
+		}
+
+		// Snapshot the current state before changing it
+		before := *existingExpense
+
+		// Apply and validate only the members present in the patch,
+		// required members can be changed but never removed
+		if req.Amount.Set {
+			if req.Amount.Null {
+				return nullField("amount")
+			}
+			if req.Amount.Value < 0 {
+				return domain.InvalidField("amount", "min", "must be at least 0")
+			}
+			existingExpense.Amount = req.Amount.Value
+		}
+
+		if req.Date.Set {
+			if req.Date.Null {
+				return nullField("date")
+			}
+			date, err := time.Parse("2006-01-02", req.Date.Value)
+			if err != nil {
+				domain.LoggerFromContext(ctx).ErrorContext(ctx, "Invalid date format", "error", err, "date", req.Date.Value)
+				return invalidDate("date")
+			}
+			existingExpense.Date = date
+		}
+
+		if req.CategoryID.Set {
+			if req.CategoryID.Null {
+				return nullField("category_id")
+			}
+			if req.CategoryID.Value <= 0 {
+				return invalidID("category_id")
+			}
+
+			// Validate that the expense category exists
+			_, err = s.categoryRepo.GetByIDForShare(ctx, req.CategoryID.Value)
+			if err != nil {
+				domain.LoggerFromContext(ctx).ErrorContext(ctx, "Expense category not found", "error", err, "category_id", req.CategoryID.Value)
+				return err
+			}
+			existingExpense.CategoryID = req.CategoryID.Value
+		}
+
+		if req.SubCategoryID.Set {
+			if req.SubCategoryID.Null {
+				existingExpense.SubCategoryID = nil
+			} else {
+				subCategoryID := req.SubCategoryID.Value
+				existingExpense.SubCategoryID = &subCategoryID
+			}
+		}
+
+		// A subcategory must keep belonging to the category whenever either of them changes
+		if (req.CategoryID.Set || req.SubCategoryID.Set) && existingExpense.SubCategoryID != nil {
+			subCategory, err := s.subCategoryRepo.GetByIDForShare(ctx, *existingExpense.SubCategoryID)
+			if err != nil {
+				domain.LoggerFromContext(ctx).ErrorContext(ctx, "Expense subcategory not found", "error", err, "subcategory_id", *existingExpense.SubCategoryID)
+				return err
+			}
+
+			if subCategory.ExpenseCategoryID != existingExpense.CategoryID {
+				domain.LoggerFromContext(ctx).ErrorContext(ctx, "Subcategory does not belong to the specified category",
+					"subcategory_id", *existingExpense.SubCategoryID, "category_id", existingExpense.CategoryID,
+					"subcategory_category_id", subCategory.ExpenseCategoryID)
+				return errSubCategoryOutsideCategory
+			}
+		}
+
+		if req.PayeeID.Set {
+			if req.PayeeID.Null {
+				return nullField("payee_id")
+			}
+			if req.PayeeID.Value <= 0 {
+				return invalidID("payee_id")
+			}
+
+			// Validate that the payee exists
+			_, err = s.payeeRepo.GetByID(ctx, req.PayeeID.Value)
+			if err != nil {
+				domain.LoggerFromContext(ctx).ErrorContext(ctx, "Payee not found", "error", err, "payee_id", req.PayeeID.Value)
+				return err
+			}
+			existingExpense.PayeeID = req.PayeeID.Value
+		}
+
+		if req.AccountID.Set {
+			if req.AccountID.Null {
+				return nullField("account_id")
+			}
+			if req.AccountID.Value <= 0 {
+				return invalidID("account_id")
+			}
+
+			// Validate that the account exists
+			_, err = s.accountRepo.GetAccountByIDForShare(ctx, uint64(req.AccountID.Value))
+			if err != nil {
+				domain.LoggerFromContext(ctx).ErrorContext(ctx, "Account not found", "error", err, "account_id", req.AccountID.Value)
+				return err
+			}
+			existingExpense.AccountID = req.AccountID.Value
+		}
 
-	// Update fields
-	existingExpense.Amount = req.Amount
-	existingExpense.CategoryID = req.CategoryID
-	existingExpense.SubCategoryID = req.SubCategoryID
-	existingExpense.Date = date
-	existingExpense.PayeeID = req.PayeeID
-	existingExpense.AccountID = req.AccountID
-	existingExpense.Notes = notes
-	existingExpense.UpdatedAt = time.Now()
+		if req.Notes.Set {
+			// Sanitize notes, null clears them
+			existingExpense.Notes = strings.TrimSpace(req.Notes.Value)
+		}
+
+		if req.TagIDs.Set {
+			// Validate that every tag exists, null removes them all
+			tagIDs, err := s.expenseTagIDs(ctx, req.TagIDs.Value)
+			if err != nil {
+				return err
+			}
+			existingExpense.TagIDs = tagIDs
+		}
+
+		if req.Splits.Set {
+			// null removes the split
+			existingExpense.Splits = req.Splits.Value
+		}
+
+		// The split lines must keep adding up to the amount whenever either of them changes
+		if req.Amount.Set || req.Splits.Set {
+			splits, err := s.expenseSplits(ctx, existingExpense.Amount, existingExpense.Splits)
+			if err != nil {
+				return err
+			}
+			existingExpense.Splits = splits
+		}
 
-	if err := s.repo.Update(ctx, existingExpense); err != nil {
-		s.logger.Error("Failed to update expense", "error", err, "id", id)
+		if req.Sharing.Set {
+			// null stops sharing the cost
+			if req.Sharing.Null {
+				existingExpense.Sharing = nil
+			} else {
+				sharing, err := mergeSharing(existingExpense.Sharing, &req.Sharing.Value)
+				if err != nil {
+					return err
+				}
+				existingExpense.Sharing = sharing
+			}
+		}
+
+		// The shares are computed again from the new amount
+		if req.Amount.Set || req.Sharing.Set {
+			sharing, err := s.expenseSharing(ctx, existingExpense.Amount, existingExpense.Sharing)
+			if err != nil {
+				return err
+			}
+			existingExpense.Sharing = sharing
+		}
+
+		existingExpense.UpdatedAt = time.Now()
+		existingExpense.Version = req.Version
+
+		if err := s.repo.Update(ctx, existingExpense); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditEntityExpense, uint64(id), domain.AuditActionUpdate, before, existingExpense)
+	})
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to patch expense", "error", err, "id", id)
 		return nil, err
 	}
 
-	s.logger.Info("Expense updated successfully", "id", id, "amount", req.Amount)
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Expense patched successfully", "id", id)
 	return existingExpense, nil
 }
 
-func (s *expenseService) Delete(ctx context.Context, id int) error {
-	s.logger.Info("Deleting expense", "id", id)
+func (s *expenseService) Delete(ctx context.Context, id int, version int) error {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Deleting expense", "id", id)
 
 	if id <= 0 {
-		return domain.ErrInvalidInput
+		return invalidID("id")
 	}
 
-	// Check if expense exists
-	_, err := s.repo.GetByID(ctx, id)
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Check if expense exists, within the transaction deleting it
+		existingExpense, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to get expense for deletion", "error", err, "id", id)
+			return err
+		}
+
+		// Reject deletions based on a stale version
+		if version != 0 && existingExpense.Version != version {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Stale expense version", "id", id, "version", version, "current_version", existingExpense.Version)
+			return domain.ErrPreconditionFailed
+		}
+
+		if err := s.repo.Delete(ctx, id, version); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditEntityExpense, uint64(id), domain.AuditActionDelete, existingExpense, nil)
+	})
 	if err != nil {
-		s.logger.Error("Failed to get expense for deletion", "error", err, "id", id)
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to delete expense", "error", err, "id", id)
 		return err
 	}
 
-	if err := s.repo.Delete(ctx, id); err != nil {
-		s.logger.Error("Failed to delete expense", "error", err, "id", id)
-		return err
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Expense deleted successfully", "id", id)
+	return nil
+}
+
+func (s *expenseService) Restore(ctx context.Context, id int) (*domain.Expense, error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Restoring expense", "id", id)
+
+	if id <= 0 {
+		return nil, invalidID("id")
 	}
 
-	s.logger.Info("Expense deleted successfully", "id", id)
-	return nil
+	var expense *domain.Expense
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Restore(ctx, id); err != nil {
+			return err
+		}
+
+		var err error
+		expense, err = s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditEntityExpense, uint64(id), domain.AuditActionRestore, nil, expense)
+	})
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to restore expense", "error", err, "id", id)
+		return nil, err
+	}
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Expense restored successfully", "id", id)
+	return expense, nil
 }
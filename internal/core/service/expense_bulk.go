@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+// bulkPageSize is the page size used to collect the expenses matched by a bulk update
+const bulkPageSize = 100
+
+// errBulkAborted stops an all-or-nothing bulk request at its first failing item
+var errBulkAborted = errors.New("bulk request aborted")
+
+func (s *expenseService) Bulk(ctx context.Context, req *domain.BulkExpenseRequest) (*domain.BulkExpenseResult, error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Running bulk expense operations",
+		"create", len(req.Create), "update", req.Update != nil, "delete", len(req.Delete), "all_or_nothing", req.AllOrNothing)
+
+	// Validate the shape of the request before touching any data
+	if len(req.Create) == 0 && req.Update == nil && len(req.Delete) == 0 {
+		return nil, domain.InvalidField("create", "required_without_all", "requires at least one create, update or delete operation")
+	}
+	if len(req.Create)+len(req.Delete) > domain.MaxBulkExpenseItems {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Too many bulk expense items", "count", len(req.Create)+len(req.Delete), "max", domain.MaxBulkExpenseItems)
+		return nil, domain.InvalidField("create", "max", fmt.Sprintf("must have at most %d items along with delete", domain.MaxBulkExpenseItems))
+	}
+
+	var updateFilters port.ExpenseFilters
+	if req.Update != nil {
+		// An update must select expenses explicitly and change at least one field
+		if req.Update.Filter.IsEmpty() {
+			return nil, domain.InvalidField("update.filter", "required", "requires at least one criterion")
+		}
+		if req.Update.Set.IsEmpty() {
+			return nil, domain.InvalidField("update.set", "required", "requires at least one field to change")
+		}
+
+		var err error
+		updateFilters, err = s.expenseFilters(ctx, &domain.ListExpensesRequest{
+			CategoryIDs:   bulkFilterIDs(req.Update.Filter.CategoryID),
+			SubCategoryID: req.Update.Filter.SubCategoryID,
+			PayeeIDs:      bulkFilterIDs(req.Update.Filter.PayeeID),
+			AccountIDs:    bulkFilterIDs(req.Update.Filter.AccountID),
+			StartDate:     req.Update.Filter.StartDate,
+			EndDate:       req.Update.Filter.EndDate,
+		})
+		if err != nil {
+			return nil, nestedFields("update.filter", err)
+		}
+	}
+
+	result := &domain.BulkExpenseResult{}
+
+	// runItem executes a single item in its own savepoint, so a failure only undoes that item
+	runItem := func(ctx context.Context, operation string, index, id int, fn func(ctx context.Context) (int, error)) error {
+		err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+			var err error
+			id, err = fn(ctx)
+			return err
+		})
+
+		item := domain.BulkExpenseItemResult{
+			Operation: operation,
+			Index:     index,
+			ID:        id,
+			Status:    domain.BulkStatusSucceeded,
+		}
+		if err != nil {
+			item.Status = domain.BulkStatusFailed
+			item.Error = err.Error()
+			result.Failed++
+		} else {
+			result.Succeeded++
+		}
+		result.Results = append(result.Results, item)
+
+		if err != nil && req.AllOrNothing {
+			return errBulkAborted
+		}
+		return nil
+	}
+
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		for i := range req.Create {
+			err := runItem(ctx, domain.BulkOperationCreate, i, 0, func(ctx context.Context) (int, error) {
+				expense, err := s.Create(ctx, &req.Create[i])
+				if err != nil {
+					return 0, err
+				}
+				return expense.ID, nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if req.Update != nil {
+			ids, err := s.matchingExpenseIDs(ctx, updateFilters)
+			if err != nil {
+				return err
+			}
+
+			patch := &domain.PatchExpenseRequest{
+				CategoryID:    req.Update.Set.CategoryID,
+				SubCategoryID: req.Update.Set.SubCategoryID,
+				AccountID:     req.Update.Set.AccountID,
+			}
+			for i, id := range ids {
+				err := runItem(ctx, domain.BulkOperationUpdate, i, id, func(ctx context.Context) (int, error) {
+					_, err := s.Patch(ctx, id, patch)
+					return id, err
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		for i, id := range req.Delete {
+			err := runItem(ctx, domain.BulkOperationDelete, i, id, func(ctx context.Context) (int, error) {
+				return id, s.Delete(ctx, id, 0)
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBulkAborted) {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to run bulk expense operations", "error", err)
+		return nil, err
+	}
+
+	result.Committed = err == nil
+	if !result.Committed {
+		// Nothing was persisted, so the items that went through were undone as well
+		for i := range result.Results {
+			if result.Results[i].Status == domain.BulkStatusSucceeded {
+				result.Results[i].Status = domain.BulkStatusRolledBack
+			}
+		}
+		result.Succeeded = 0
+	}
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Bulk expense operations finished",
+		"committed", result.Committed, "succeeded", result.Succeeded, "failed", result.Failed)
+	return result, nil
+}
+
+// matchingExpenseIDs collects the IDs of every expense matching the filters,
+// before any of them is changed so that updates cannot shift the pages
+func (s *expenseService) matchingExpenseIDs(ctx context.Context, filters port.ExpenseFilters) ([]int, error) {
+	var ids []int
+	filters.Limit = bulkPageSize
+	for {
+		expenses, err := s.repo.List(ctx, filters)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, expense := range expenses {
+			ids = append(ids, expense.ID)
+		}
+		if len(ids) > domain.MaxBulkExpenseItems {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Bulk update matches too many expenses", "max", domain.MaxBulkExpenseItems)
+			return nil, domain.InvalidField("update.filter", "max", fmt.Sprintf("must match at most %d expenses", domain.MaxBulkExpenseItems))
+		}
+		if len(expenses) < bulkPageSize {
+			return ids, nil
+		}
+
+		cursor := expenseCursor(expenses[len(expenses)-1], filters.Sort)
+		filters.After = &cursor
+	}
+}
+
+// bulkFilterIDs turns an optional ID of a bulk filter into a list filter, 0 matches every expense
+func bulkFilterIDs(id int) []int {
+	if id == 0 {
+		return nil
+	}
+	return []int{id}
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/edwins-leonardi/finaid-api/internal/adapter/storage/memory"
+	memoryrepo "github.com/edwins-leonardi/finaid-api/internal/adapter/storage/memory/repository"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+func TestExpenseBulkMaxItems(t *testing.T) {
+	ctx := context.Background()
+	expenseRepo := memoryrepo.NewExpenseRepository()
+	subCategoryRepo := memoryrepo.NewExpenseSubCategoryRepository()
+	categoryRepo := memoryrepo.NewExpenseCategoryRepository(expenseRepo, subCategoryRepo)
+	payeeRepo := memoryrepo.NewPayeeRepository()
+	personRepo := memoryrepo.NewPersonRepository()
+	accountRepo := memoryrepo.NewAccountRepository(expenseRepo)
+	auditRepo := memoryrepo.NewAuditRepository()
+	svc := NewExpenseService(expenseRepo, categoryRepo, subCategoryRepo, payeeRepo, personRepo, accountRepo,
+		memoryrepo.NewTagRepository(), memory.NewTxManager(), auditRepo)
+
+	person, err := personRepo.CreatePerson(ctx, &domain.Person{Name: "Alice", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatalf("create person: %v", err)
+	}
+	account, err := accountRepo.CreateAccount(ctx, &domain.Account{
+		Name: "Checking", Currency: "EUR", AccountType: "checking", PrimaryOwnerID: person.ID,
+	})
+	if err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	category := &domain.ExpenseCategory{Name: "Food"}
+	if err := categoryRepo.Create(ctx, category); err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+	payee := &domain.Payee{Name: "Grocer"}
+	if err := payeeRepo.Create(ctx, payee); err != nil {
+		t.Fatalf("create payee: %v", err)
+	}
+
+	// Items referencing a missing account fail in their own savepoint
+	tests := []struct {
+		name          string
+		allOrNothing  bool
+		fails         func(index int) bool
+		wantSucceeded int
+		wantExpenses  int64
+	}{
+		{"all or nothing", true, func(index int) bool { return index == domain.MaxBulkExpenseItems-1 }, 0, 0},
+		{"partial", false, func(index int) bool { return index%2 == 1 }, domain.MaxBulkExpenseItems / 2, domain.MaxBulkExpenseItems / 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := make([]domain.CreateExpenseRequest, domain.MaxBulkExpenseItems)
+			for i := range items {
+				items[i] = domain.CreateExpenseRequest{
+					Amount: 10, CategoryID: category.ID, Date: "2024-03-01", PayeeID: payee.ID, AccountID: int(account.ID),
+				}
+				if tt.fails(i) {
+					items[i].AccountID = 999
+				}
+			}
+
+			result, err := svc.Bulk(ctx, &domain.BulkExpenseRequest{AllOrNothing: tt.allOrNothing, Create: items})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Succeeded != tt.wantSucceeded || result.Committed == tt.allOrNothing {
+				t.Errorf("got %d succeeded, committed %v, want %d, committed %v",
+					result.Succeeded, result.Committed, tt.wantSucceeded, !tt.allOrNothing)
+			}
+
+			// The expenses and audit entries of the undone items are gone
+			count, err := expenseRepo.Count(ctx, port.ExpenseFilters{})
+			if err != nil || count != tt.wantExpenses {
+				t.Errorf("counted %d expenses (error %v), want %d", count, err, tt.wantExpenses)
+			}
+			count, err = auditRepo.Count(ctx, port.AuditFilters{})
+			if err != nil || count != tt.wantExpenses {
+				t.Errorf("counted %d audit entries (error %v), want %d", count, err, tt.wantExpenses)
+			}
+		})
+	}
+}
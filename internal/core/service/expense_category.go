@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"log/slog"
 	"strings"
 	"time"
 
@@ -11,134 +10,287 @@ import (
 )
 
 type expenseCategoryService struct {
-	repo   port.ExpenseCategoryRepository
-	logger *slog.Logger
+	repo      port.ExpenseCategoryRepository
+	txManager port.TxManager
+	auditRepo port.AuditRepository
 }
 
 // NewExpenseCategoryService creates a new expense category service
-func NewExpenseCategoryService(repo port.ExpenseCategoryRepository, logger *slog.Logger) port.ExpenseCategoryService {
+func NewExpenseCategoryService(
+	repo port.ExpenseCategoryRepository,
+	txManager port.TxManager,
+	auditRepo port.AuditRepository,
+) port.ExpenseCategoryService {
 	return &expenseCategoryService{
-		repo:   repo,
-		logger: logger,
+		repo:      repo,
+		txManager: txManager,
+		auditRepo: auditRepo,
 	}
 }
 
 func (s *expenseCategoryService) Create(ctx context.Context, req *domain.CreateExpenseCategoryRequest) (*domain.ExpenseCategory, error) {
-	s.logger.Info("Creating expense category", "name", req.Name)
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Creating expense category", "name", req.Name)
 
 	// Validate and sanitize input
 	name := strings.TrimSpace(req.Name)
 	if name == "" {
-		return nil, domain.ErrInvalidInput
+		return nil, requiredField("name")
+	}
+	aliases, err := aliasesOf(name, req.Aliases, domain.MaxCategoryAliases)
+	if err != nil {
+		return nil, err
 	}
 
 	category := &domain.ExpenseCategory{
 		Name:      name,
+		Aliases:   aliases,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 
-	if err := s.repo.Create(ctx, category); err != nil {
-		s.logger.Error("Failed to create expense category", "error", err, "name", name)
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, category); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditEntityExpenseCategory, uint64(category.ID), domain.AuditActionCreate, nil, category)
+	})
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to create expense category", "error", err, "name", name)
 		return nil, err
 	}
 
-	s.logger.Info("Expense category created successfully", "id", category.ID, "name", category.Name)
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Expense category created successfully", "id", category.ID, "name", category.Name)
 	return category, nil
 }
 
 func (s *expenseCategoryService) GetByID(ctx context.Context, id int) (*domain.ExpenseCategory, error) {
-	s.logger.Info("Getting expense category by ID", "id", id)
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Getting expense category by ID", "id", id)
 
 	if id <= 0 {
-		return nil, domain.ErrInvalidInput
+		return nil, invalidID("id")
 	}
 
 	category, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		s.logger.Error("Failed to get expense category", "error", err, "id", id)
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to get expense category", "error", err, "id", id)
 		return nil, err
 	}
 
 	return category, nil
 }
 
-func (s *expenseCategoryService) List(ctx context.Context, req *domain.ListExpenseCategoriesRequest) ([]*domain.ExpenseCategory, error) {
-	s.logger.Info("Listing expense categories", "skip", req.Skip, "limit", req.Limit)
+func (s *expenseCategoryService) List(ctx context.Context, req *domain.ListExpenseCategoriesRequest) (*domain.Page[*domain.ExpenseCategory], error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Listing expense categories", "cursor", req.Cursor, "limit", req.Limit)
 
-	// Set default values
-	skip := req.Skip
-	if skip < 0 {
-		skip = 0
+	limit, err := pageLimit(req.Limit)
+	if err != nil {
+		return nil, err
 	}
-
-	limit := req.Limit
-	if limit <= 0 || limit > 100 {
-		limit = 10
+	after, err := domain.DecodeCursor(req.Cursor)
+	if err != nil {
+		return nil, err
 	}
 
-	categories, err := s.repo.List(ctx, skip, limit)
+	// Read one more category than asked to tell whether another page follows
+	categories, err := s.repo.List(ctx, after, limit+1, req.IncludeDeleted)
 	if err != nil {
-		s.logger.Error("Failed to list expense categories", "error", err)
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to list expense categories", "error", err)
 		return nil, err
 	}
+	page := newPage(categories, limit, expenseCategoryCursor)
+
+	if req.IncludeTotal {
+		total, err := s.repo.Count(ctx, req.IncludeDeleted)
+		if err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to count expense categories", "error", err)
+			return nil, err
+		}
+		page.TotalCount = &total
+	}
 
-	s.logger.Info("Expense categories retrieved successfully", "count", len(categories))
-	return categories, nil
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Expense categories retrieved successfully", "count", len(page.Items), "has_more", page.HasMore)
+	return page, nil
 }
 
 func (s *expenseCategoryService) Update(ctx context.Context, id int, req *domain.UpdateExpenseCategoryRequest) (*domain.ExpenseCategory, error) {
-	s.logger.Info("Updating expense category", "id", id, "name", req.Name)
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Updating expense category", "id", id, "name", req.Name)
 
 	if id <= 0 {
-		return nil, domain.ErrInvalidInput
+		return nil, invalidID("id")
 	}
 
 	// Validate and sanitize input
 	name := strings.TrimSpace(req.Name)
 	if name == "" {
-		return nil, domain.ErrInvalidInput
+		return nil, requiredField("name")
 	}
 
 	// Check if category exists
 	existingCategory, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		s.logger.Error("Failed to get expense category for update", "error", err, "id", id)
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to get expense category for update", "error", err, "id", id)
+		return nil, err
+	}
+
+	// Reject updates based on a stale version
+	if req.Version != 0 && existingCategory.Version != req.Version {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Stale expense category version", "id", id, "version", req.Version, "current_version", existingCategory.Version)
+		return nil, domain.ErrPreconditionFailed
+	}
+
+	// Snapshot the current state before changing it
+	before := *existingCategory
+
+	// Omitted aliases are kept, they must still differ from the new name
+	aliases := existingCategory.Aliases
+	if req.Aliases != nil {
+		aliases = req.Aliases
+	}
+	existingCategory.Aliases, err = aliasesOf(name, aliases, domain.MaxCategoryAliases)
+	if err != nil {
 		return nil, err
 	}
 
 	// Update fields
 	existingCategory.Name = name
 	existingCategory.UpdatedAt = time.Now()
+	existingCategory.Version = req.Version
 
-	if err := s.repo.Update(ctx, existingCategory); err != nil {
-		s.logger.Error("Failed to update expense category", "error", err, "id", id)
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, existingCategory); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditEntityExpenseCategory, uint64(id), domain.AuditActionUpdate, before, existingCategory)
+	})
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to update expense category", "error", err, "id", id)
 		return nil, err
 	}
 
-	s.logger.Info("Expense category updated successfully", "id", id, "name", name)
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Expense category updated successfully", "id", id, "name", name)
 	return existingCategory, nil
 }
 
-func (s *expenseCategoryService) Delete(ctx context.Context, id int) error {
-	s.logger.Info("Deleting expense category", "id", id)
+func (s *expenseCategoryService) Patch(ctx context.Context, id int, req *domain.PatchExpenseCategoryRequest) (*domain.ExpenseCategory, error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Patching expense category", "id", id)
 
 	if id <= 0 {
-		return domain.ErrInvalidInput
+		return nil, invalidID("id")
 	}
 
 	// Check if category exists
-	_, err := s.repo.GetByID(ctx, id)
+	existingCategory, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		s.logger.Error("Failed to get expense category for deletion", "error", err, "id", id)
-		return err
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to get expense category for patch", "error", err, "id", id)
+		return nil, err
 	}
 
-	if err := s.repo.Delete(ctx, id); err != nil {
-		s.logger.Error("Failed to delete expense category", "error", err, "id", id)
+	// Reject patches based on a stale version
+	if req.Version != 0 && existingCategory.Version != req.Version {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Stale expense category version", "id", id, "version", req.Version, "current_version", existingCategory.Version)
+		return nil, domain.ErrPreconditionFailed
+	}
+
+	// Snapshot the current state before changing it
+	before := *existingCategory
+
+	// Apply and validate only the members present in the patch
+	if req.Name.Set {
+		name := strings.TrimSpace(req.Name.Value)
+		if req.Name.Null {
+			return nil, nullField("name")
+		}
+		if name == "" {
+			return nil, requiredField("name")
+		}
+		existingCategory.Name = name
+	}
+	if req.Aliases.Set {
+		existingCategory.Aliases = req.Aliases.Value
+	}
+	// Aliases must differ from the name, which the patch may change
+	existingCategory.Aliases, err = aliasesOf(existingCategory.Name, existingCategory.Aliases, domain.MaxCategoryAliases)
+	if err != nil {
+		return nil, err
+	}
+	existingCategory.UpdatedAt = time.Now()
+	existingCategory.Version = req.Version
+
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, existingCategory); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditEntityExpenseCategory, uint64(id), domain.AuditActionUpdate, before, existingCategory)
+	})
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to patch expense category", "error", err, "id", id)
+		return nil, err
+	}
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Expense category patched successfully", "id", id)
+	return existingCategory, nil
+}
+
+func (s *expenseCategoryService) Delete(ctx context.Context, id int, version int) error {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Deleting expense category", "id", id)
+
+	if id <= 0 {
+		return invalidID("id")
+	}
+
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Check if category exists, within the transaction deleting it
+		existingCategory, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to get expense category for deletion", "error", err, "id", id)
+			return err
+		}
+
+		// Reject deletions based on a stale version
+		if version != 0 && existingCategory.Version != version {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Stale expense category version", "id", id, "version", version, "current_version", existingCategory.Version)
+			return domain.ErrPreconditionFailed
+		}
+
+		if err := s.repo.Delete(ctx, id, version); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditEntityExpenseCategory, uint64(id), domain.AuditActionDelete, existingCategory, nil)
+	})
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to delete expense category", "error", err, "id", id)
 		return err
 	}
 
-	s.logger.Info("Expense category deleted successfully", "id", id)
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Expense category deleted successfully", "id", id)
 	return nil
 }
+
+func (s *expenseCategoryService) Restore(ctx context.Context, id int) (*domain.ExpenseCategory, error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Restoring expense category", "id", id)
+
+	if id <= 0 {
+		return nil, invalidID("id")
+	}
+
+	var category *domain.ExpenseCategory
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Restore(ctx, id); err != nil {
+			return err
+		}
+
+		var err error
+		category, err = s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditEntityExpenseCategory, uint64(id), domain.AuditActionRestore, nil, category)
+	})
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to restore expense category", "error", err, "id", id)
+		return nil, err
+	}
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Expense category restored successfully", "id", id)
+	return category, nil
+}
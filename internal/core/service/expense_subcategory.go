@@ -2,7 +2,7 @@ package service
 
 import (
 	"context"
-	"log/slog"
+	"errors"
 	"strings"
 	"time"
 
@@ -13,86 +13,100 @@ import (
 type expenseSubCategoryService struct {
 	repo         port.ExpenseSubCategoryRepository
 	categoryRepo port.ExpenseCategoryRepository
-	logger       *slog.Logger
+	txManager    port.TxManager
+	auditRepo    port.AuditRepository
 }
 
 // NewExpenseSubCategoryService creates a new expense subcategory service
 func NewExpenseSubCategoryService(
 	repo port.ExpenseSubCategoryRepository,
 	categoryRepo port.ExpenseCategoryRepository,
-	logger *slog.Logger,
+	txManager port.TxManager,
+	auditRepo port.AuditRepository,
 ) port.ExpenseSubCategoryService {
 	return &expenseSubCategoryService{
 		repo:         repo,
 		categoryRepo: categoryRepo,
-		logger:       logger,
+		txManager:    txManager,
+		auditRepo:    auditRepo,
 	}
 }
 
 func (s *expenseSubCategoryService) Create(ctx context.Context, req *domain.CreateExpenseSubCategoryRequest) (*domain.ExpenseSubCategory, error) {
-	s.logger.Info("Creating expense subcategory", "name", req.Name, "expense_category_id", req.ExpenseCategoryID)
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Creating expense subcategory", "name", req.Name, "expense_category_id", req.ExpenseCategoryID)
 
 	// Validate and sanitize input
 	name := strings.TrimSpace(req.Name)
 	if name == "" {
-		return nil, domain.ErrInvalidInput
+		return nil, requiredField("name")
 	}
 
 	if req.ExpenseCategoryID <= 0 {
-		return nil, domain.ErrInvalidInput
+		return nil, invalidID("expense_category_id")
 	}
-
-	// Validate that the expense category exists
-	_, err := s.categoryRepo.GetByID(ctx, req.ExpenseCategoryID)
+	aliases, err := aliasesOf(name, req.Aliases, domain.MaxCategoryAliases)
 	if err != nil {
-		s.logger.Error("Expense category not found", "error", err, "expense_category_id", req.ExpenseCategoryID)
 		return nil, err
 	}
 
-	subcategory := &domain.ExpenseSubCategory{
-		Name:              name,
-		ExpenseCategoryID: req.ExpenseCategoryID,
-		CreatedAt:         time.Now(),
-		UpdatedAt:         time.Now(),
-	}
-
-	if err := s.repo.Create(ctx, subcategory); err != nil {
-		s.logger.Error("Failed to create expense subcategory", "error", err, "name", name)
+	// The expense category is checked within the transaction creating the subcategory
+	var subcategory *domain.ExpenseSubCategory
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Validate that the expense category exists
+		_, err = s.categoryRepo.GetByIDForShare(ctx, req.ExpenseCategoryID)
+		if err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Expense category not found", "error", err, "expense_category_id", req.ExpenseCategoryID)
+			return err
+		}
+
+		subcategory = &domain.ExpenseSubCategory{
+			Name:              name,
+			Aliases:           aliases,
+			ExpenseCategoryID: req.ExpenseCategoryID,
+			CreatedAt:         time.Now(),
+			UpdatedAt:         time.Now(),
+		}
+
+		if err := s.repo.Create(ctx, subcategory); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditEntityExpenseSubCategory, uint64(subcategory.ID), domain.AuditActionCreate, nil, subcategory)
+	})
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to create expense subcategory", "error", err, "name", name)
 		return nil, err
 	}
 
-	s.logger.Info("Expense subcategory created successfully", "id", subcategory.ID, "name", subcategory.Name)
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Expense subcategory created successfully", "id", subcategory.ID, "name", subcategory.Name)
 	return subcategory, nil
 }
 
 func (s *expenseSubCategoryService) GetByID(ctx context.Context, id int) (*domain.ExpenseSubCategory, error) {
-	s.logger.Info("Getting expense subcategory by ID", "id", id)
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Getting expense subcategory by ID", "id", id)
 
 	if id <= 0 {
-		return nil, domain.ErrInvalidInput
+		return nil, invalidID("id")
 	}
 
 	subcategory, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		s.logger.Error("Failed to get expense subcategory", "error", err, "id", id)
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to get expense subcategory", "error", err, "id", id)
 		return nil, err
 	}
 
 	return subcategory, nil
 }
 
-func (s *expenseSubCategoryService) List(ctx context.Context, req *domain.ListExpenseSubCategoriesRequest) ([]*domain.ExpenseSubCategory, error) {
-	s.logger.Info("Listing expense subcategories", "skip", req.Skip, "limit", req.Limit, "expense_category_id", req.ExpenseCategoryID)
+func (s *expenseSubCategoryService) List(ctx context.Context, req *domain.ListExpenseSubCategoriesRequest) (*domain.Page[*domain.ExpenseSubCategory], error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Listing expense subcategories", "cursor", req.Cursor, "limit", req.Limit, "expense_category_id", req.ExpenseCategoryID)
 
-	// Set default values
-	skip := req.Skip
-	if skip < 0 {
-		skip = 0
+	limit, err := pageLimit(req.Limit)
+	if err != nil {
+		return nil, err
 	}
-
-	limit := req.Limit
-	if limit <= 0 || limit > 100 {
-		limit = 10
+	after, err := domain.DecodeCursor(req.Cursor)
+	if err != nil {
+		return nil, err
 	}
 
 	// Optional filter by expense category
@@ -101,80 +115,250 @@ func (s *expenseSubCategoryService) List(ctx context.Context, req *domain.ListEx
 		expenseCategoryID = &req.ExpenseCategoryID
 	}
 
-	subcategories, err := s.repo.List(ctx, skip, limit, expenseCategoryID)
+	// Read one more subcategory than asked to tell whether another page follows
+	subcategories, err := s.repo.List(ctx, after, limit+1, expenseCategoryID, req.IncludeDeleted)
 	if err != nil {
-		s.logger.Error("Failed to list expense subcategories", "error", err)
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to list expense subcategories", "error", err)
 		return nil, err
 	}
+	page := newPage(subcategories, limit, expenseSubCategoryCursor)
+
+	if req.IncludeTotal {
+		total, err := s.repo.Count(ctx, expenseCategoryID, req.IncludeDeleted)
+		if err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to count expense subcategories", "error", err)
+			return nil, err
+		}
+		page.TotalCount = &total
+	}
 
-	s.logger.Info("Expense subcategories retrieved successfully", "count", len(subcategories))
-	return subcategories, nil
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Expense subcategories retrieved successfully", "count", len(page.Items), "has_more", page.HasMore)
+	return page, nil
 }
 
 func (s *expenseSubCategoryService) Update(ctx context.Context, id int, req *domain.UpdateExpenseSubCategoryRequest) (*domain.ExpenseSubCategory, error) {
-	s.logger.Info("Updating expense subcategory", "id", id, "name", req.Name, "expense_category_id", req.ExpenseCategoryID)
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Updating expense subcategory", "id", id, "name", req.Name, "expense_category_id", req.ExpenseCategoryID)
 
 	if id <= 0 {
-		return nil, domain.ErrInvalidInput
+		return nil, invalidID("id")
 	}
 
 	// Validate and sanitize input
 	name := strings.TrimSpace(req.Name)
 	if name == "" {
-		return nil, domain.ErrInvalidInput
+		return nil, requiredField("name")
 	}
 
 	if req.ExpenseCategoryID <= 0 {
-		return nil, domain.ErrInvalidInput
+		return nil, invalidID("expense_category_id")
 	}
 
-	// Check if subcategory exists
-	existingSubCategory, err := s.repo.GetByID(ctx, id)
+	// The subcategory and its expense category are read within the transaction changing it
+	var existingSubCategory *domain.ExpenseSubCategory
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Check if subcategory exists
+		var err error
+		existingSubCategory, err = s.repo.GetByID(ctx, id)
+		if err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to get expense subcategory for update", "error", err, "id", id)
+			return err
+		}
+
+		// Reject updates based on a stale version
+		if req.Version != 0 && existingSubCategory.Version != req.Version {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Stale expense subcategory version", "id", id, "version", req.Version, "current_version", existingSubCategory.Version)
+			return domain.ErrPreconditionFailed
+		}
+
+		// Validate that the expense category exists
+		_, err = s.categoryRepo.GetByIDForShare(ctx, req.ExpenseCategoryID)
+		if err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Expense category not found", "error", err, "expense_category_id", req.ExpenseCategoryID)
+			return err
+		}
+
+		// Snapshot the current state before changing it
+		before := *existingSubCategory
+
+		// Omitted aliases are kept, they must still differ from the new name
+		aliases := existingSubCategory.Aliases
+		if req.Aliases != nil {
+			aliases = req.Aliases
+		}
+		existingSubCategory.Aliases, err = aliasesOf(name, aliases, domain.MaxCategoryAliases)
+		if err != nil {
+			return err
+		}
+
+		// Update fields
+		existingSubCategory.Name = name
+		existingSubCategory.ExpenseCategoryID = req.ExpenseCategoryID
+		existingSubCategory.UpdatedAt = time.Now()
+		existingSubCategory.Version = req.Version
+
+		if err := s.repo.Update(ctx, existingSubCategory); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditEntityExpenseSubCategory, uint64(id), domain.AuditActionUpdate, before, existingSubCategory)
+	})
 	if err != nil {
-		s.logger.Error("Failed to get expense subcategory for update", "error", err, "id", id)
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to update expense subcategory", "error", err, "id", id)
 		return nil, err
 	}
 
-	// Validate that the expense category exists
-	_, err = s.categoryRepo.GetByID(ctx, req.ExpenseCategoryID)
-	if err != nil {
-		s.logger.Error("Expense category not found", "error", err, "expense_category_id", req.ExpenseCategoryID)
-		return nil, err
-	}
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Expense subcategory updated successfully", "id", id, "name", name)
+	return existingSubCategory, nil
+}
 
-	// Update fields
-	existingSubCategory.Name = name
-	existingSubCategory.ExpenseCategoryID = req.ExpenseCategoryID
-	existingSubCategory.UpdatedAt = time.Now()
+func (s *expenseSubCategoryService) Patch(ctx context.Context, id int, req *domain.PatchExpenseSubCategoryRequest) (*domain.ExpenseSubCategory, error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Patching expense subcategory", "id", id)
 
-	if err := s.repo.Update(ctx, existingSubCategory); err != nil {
-		s.logger.Error("Failed to update expense subcategory", "error", err, "id", id)
+	if id <= 0 {
+		return nil, invalidID("id")
+	}
+
+	// The subcategory and its expense category are read within the transaction changing it
+	var existingSubCategory *domain.ExpenseSubCategory
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Check if subcategory exists
+		var err error
+		existingSubCategory, err = s.repo.GetByID(ctx, id)
+		if err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to get expense subcategory for patch", "error", err, "id", id)
+			return err
+		}
+
+		// Reject patches based on a stale version
+		if req.Version != 0 && existingSubCategory.Version != req.Version {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Stale expense subcategory version", "id", id, "version", req.Version, "current_version", existingSubCategory.Version)
+			return domain.ErrPreconditionFailed
+		}
+
+		// Snapshot the current state before changing it
+		before := *existingSubCategory
+
+		// Apply and validate only the members present in the patch
+		if req.Name.Set {
+			name := strings.TrimSpace(req.Name.Value)
+			if req.Name.Null {
+				return nullField("name")
+			}
+			if name == "" {
+				return requiredField("name")
+			}
+			existingSubCategory.Name = name
+		}
+		if req.Aliases.Set {
+			existingSubCategory.Aliases = req.Aliases.Value
+		}
+		// Aliases must differ from the name, which the patch may change
+		existingSubCategory.Aliases, err = aliasesOf(existingSubCategory.Name, existingSubCategory.Aliases, domain.MaxCategoryAliases)
+		if err != nil {
+			return err
+		}
+		if req.ExpenseCategoryID.Set {
+			if req.ExpenseCategoryID.Null {
+				return nullField("expense_category_id")
+			}
+			if req.ExpenseCategoryID.Value <= 0 {
+				return invalidID("expense_category_id")
+			}
+
+			// Validate that the expense category exists
+			_, err = s.categoryRepo.GetByIDForShare(ctx, req.ExpenseCategoryID.Value)
+			if err != nil {
+				domain.LoggerFromContext(ctx).ErrorContext(ctx, "Expense category not found", "error", err, "expense_category_id", req.ExpenseCategoryID.Value)
+				return err
+			}
+			existingSubCategory.ExpenseCategoryID = req.ExpenseCategoryID.Value
+		}
+		existingSubCategory.UpdatedAt = time.Now()
+		existingSubCategory.Version = req.Version
+
+		if err := s.repo.Update(ctx, existingSubCategory); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditEntityExpenseSubCategory, uint64(id), domain.AuditActionUpdate, before, existingSubCategory)
+	})
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to patch expense subcategory", "error", err, "id", id)
 		return nil, err
 	}
 
-	s.logger.Info("Expense subcategory updated successfully", "id", id, "name", name)
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Expense subcategory patched successfully", "id", id)
 	return existingSubCategory, nil
 }
 
-func (s *expenseSubCategoryService) Delete(ctx context.Context, id int) error {
-	s.logger.Info("Deleting expense subcategory", "id", id)
+func (s *expenseSubCategoryService) Delete(ctx context.Context, id int, version int) error {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Deleting expense subcategory", "id", id)
 
 	if id <= 0 {
-		return domain.ErrInvalidInput
+		return invalidID("id")
 	}
 
-	// Check if subcategory exists
-	_, err := s.repo.GetByID(ctx, id)
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Check if subcategory exists, within the transaction deleting it
+		existingSubCategory, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to get expense subcategory for deletion", "error", err, "id", id)
+			return err
+		}
+
+		// Reject deletions based on a stale version
+		if version != 0 && existingSubCategory.Version != version {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Stale expense subcategory version", "id", id, "version", version, "current_version", existingSubCategory.Version)
+			return domain.ErrPreconditionFailed
+		}
+
+		if err := s.repo.Delete(ctx, id, version); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditEntityExpenseSubCategory, uint64(id), domain.AuditActionDelete, existingSubCategory, nil)
+	})
 	if err != nil {
-		s.logger.Error("Failed to get expense subcategory for deletion", "error", err, "id", id)
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to delete expense subcategory", "error", err, "id", id)
 		return err
 	}
 
-	if err := s.repo.Delete(ctx, id); err != nil {
-		s.logger.Error("Failed to delete expense subcategory", "error", err, "id", id)
-		return err
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Expense subcategory deleted successfully", "id", id)
+	return nil
+}
+
+func (s *expenseSubCategoryService) Restore(ctx context.Context, id int) (*domain.ExpenseSubCategory, error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Restoring expense subcategory", "id", id)
+
+	if id <= 0 {
+		return nil, invalidID("id")
 	}
 
-	s.logger.Info("Expense subcategory deleted successfully", "id", id)
-	return nil
+	var subcategory *domain.ExpenseSubCategory
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Restore(ctx, id); err != nil {
+			return err
+		}
+
+		var err error
+		subcategory, err = s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// A subcategory cannot be restored under a category that is still deleted
+		if _, err := s.categoryRepo.GetByIDForShare(ctx, subcategory.ExpenseCategoryID); err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Expense category not found", "error", err, "expense_category_id", subcategory.ExpenseCategoryID)
+			if errors.Is(err, domain.ErrDataNotFound) {
+				return domain.InvalidField("expense_category_id", "restored", "must be restored before its subcategories")
+			}
+			return err
+		}
+
+		return recordAudit(ctx, s.auditRepo, domain.AuditEntityExpenseSubCategory, uint64(id), domain.AuditActionRestore, nil, subcategory)
+	})
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to restore expense subcategory", "error", err, "id", id)
+		return nil, err
+	}
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Expense subcategory restored successfully", "id", id)
+	return subcategory, nil
 }
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	memoryrepo "github.com/edwins-leonardi/finaid-api/internal/adapter/storage/memory/repository"
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+)
+
+func TestAllocateCents(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   int64
+		weights []float64
+		want    []int64
+	}{
+		{"even", 900, []float64{1, 1, 1}, []int64{300, 300, 300}},
+		{"remainder to the first parts", 1000, []float64{1, 1, 1}, []int64{334, 333, 333}},
+		{"two cents left over", 101, []float64{1, 1, 1}, []int64{34, 34, 33}},
+		{"percentages", 1001, []float64{50, 50}, []int64{501, 500}},
+		{"uneven weights", 1000, []float64{70, 20, 10}, []int64{700, 200, 100}},
+		{"exact amounts", 1234, []float64{1000, 234}, []int64{1000, 234}},
+		{"single part", 99, []float64{3}, []int64{99}},
+		{"fewer cents than parts", 2, []float64{1, 1, 1}, []int64{1, 1, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := allocateCents(tt.total, tt.weights)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("allocateCents(%d, %v) = %v, want %v", tt.total, tt.weights, got, tt.want)
+			}
+			var sum int64
+			for _, part := range got {
+				sum += part
+			}
+			if sum != tt.total {
+				t.Errorf("parts add up to %d, want %d", sum, tt.total)
+			}
+		})
+	}
+}
+
+func TestExpenseSplits(t *testing.T) {
+	ctx := context.Background()
+	expenseRepo := memoryrepo.NewExpenseRepository()
+	subCategoryRepo := memoryrepo.NewExpenseSubCategoryRepository()
+	categoryRepo := memoryrepo.NewExpenseCategoryRepository(expenseRepo, subCategoryRepo)
+	svc := &expenseService{repo: expenseRepo, categoryRepo: categoryRepo, subCategoryRepo: subCategoryRepo}
+
+	food, home := &domain.ExpenseCategory{Name: "Food"}, &domain.ExpenseCategory{Name: "Home"}
+	for _, category := range []*domain.ExpenseCategory{food, home} {
+		if err := categoryRepo.Create(ctx, category); err != nil {
+			t.Fatalf("create category: %v", err)
+		}
+	}
+	groceries := &domain.ExpenseSubCategory{Name: "Groceries", ExpenseCategoryID: food.ID}
+	if err := subCategoryRepo.Create(ctx, groceries); err != nil {
+		t.Fatalf("create subcategory: %v", err)
+	}
+	missingID := 999
+
+	tests := []struct {
+		name      string
+		amount    float64
+		splits    []domain.ExpenseSplit
+		wantField string // Field of the validation error, empty for no validation error
+		wantErr   error
+	}{
+		{name: "no split", amount: 10},
+		{
+			name:   "adds up",
+			amount: 30.3,
+			splits: []domain.ExpenseSplit{
+				{CategoryID: food.ID, SubCategoryID: &groceries.ID, Amount: 10.1, Notes: "  bread  "},
+				{CategoryID: home.ID, Amount: 20.2},
+			},
+		},
+		{
+			name:      "single line",
+			amount:    10,
+			splits:    []domain.ExpenseSplit{{CategoryID: food.ID, Amount: 10}},
+			wantField: "splits",
+		},
+		{
+			name:      "does not add up",
+			amount:    30,
+			splits:    []domain.ExpenseSplit{{CategoryID: food.ID, Amount: 10}, {CategoryID: home.ID, Amount: 10.01}},
+			wantField: "splits",
+		},
+		{
+			name:      "invalid category id",
+			amount:    20,
+			splits:    []domain.ExpenseSplit{{CategoryID: food.ID, Amount: 10}, {Amount: 10}},
+			wantField: "splits[1].category_id",
+		},
+		{
+			name:      "amount not positive",
+			amount:    10,
+			splits:    []domain.ExpenseSplit{{CategoryID: food.ID, Amount: 10}, {CategoryID: home.ID, Amount: 0}},
+			wantField: "splits[1].amount",
+		},
+		{
+			name:    "missing category",
+			amount:  20,
+			splits:  []domain.ExpenseSplit{{CategoryID: food.ID, Amount: 10}, {CategoryID: missingID, Amount: 10}},
+			wantErr: domain.ErrDataNotFound,
+		},
+		{
+			name:      "subcategory of another category",
+			amount:    20,
+			splits:    []domain.ExpenseSplit{{CategoryID: food.ID, Amount: 10}, {CategoryID: home.ID, SubCategoryID: &groceries.ID, Amount: 10}},
+			wantField: "splits[1].subcategory_id",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := svc.expenseSplits(ctx, tt.amount, tt.splits)
+			switch {
+			case tt.wantField != "":
+				var validationErr *domain.ValidationError
+				if !errors.As(err, &validationErr) || validationErr.Fields[0].Field != tt.wantField {
+					t.Fatalf("error = %v, want a validation error of %s", err, tt.wantField)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case len(got) != len(tt.splits):
+				t.Fatalf("got %d split lines, want %d", len(got), len(tt.splits))
+			}
+			for _, line := range got {
+				if line.Notes != "" && line.Notes != "bread" {
+					t.Errorf("notes = %q, want them trimmed", line.Notes)
+				}
+			}
+		})
+	}
+}
+
+func TestMergeSharing(t *testing.T) {
+	shared := &domain.ExpenseSharing{
+		PaidByID: 1,
+		Method:   domain.SharingMethodPercentage,
+		Shares:   []domain.ExpenseShare{{PersonID: 1, Percentage: 60}, {PersonID: 2, Percentage: 40}},
+	}
+	equalShares := []domain.ExpenseShare{{PersonID: 2}, {PersonID: 3}}
+
+	tests := []struct {
+		name      string
+		sharing   *domain.ExpenseSharing
+		patch     string
+		want      *domain.ExpenseSharing
+		wantField string // Field of the validation error, empty for no error
+	}{
+		{
+			name:    "empty patch keeps every member",
+			sharing: shared,
+			patch:   `{}`,
+			want:    shared,
+		},
+		{
+			name:    "unknown member keeps every member",
+			sharing: shared,
+			patch:   `{"split":50}`,
+			want:    shared,
+		},
+		{
+			name:    "payer only",
+			sharing: shared,
+			patch:   `{"paid_by_id":2}`,
+			want:    &domain.ExpenseSharing{PaidByID: 2, Method: shared.Method, Shares: shared.Shares},
+		},
+		{
+			name:    "method and shares",
+			sharing: shared,
+			patch:   `{"method":"equal","shares":[{"person_id":2},{"person_id":3}]}`,
+			want:    &domain.ExpenseSharing{PaidByID: 1, Method: domain.SharingMethodEqual, Shares: equalShares},
+		},
+		{
+			name:  "not shared yet",
+			patch: `{"paid_by_id":1,"method":"equal","shares":[{"person_id":2},{"person_id":3}]}`,
+			want:  &domain.ExpenseSharing{PaidByID: 1, Method: domain.SharingMethodEqual, Shares: equalShares},
+		},
+		{
+			name:      "null member",
+			sharing:   shared,
+			patch:     `{"method":null}`,
+			wantField: "sharing.method",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch domain.PatchExpenseSharing
+			if err := json.Unmarshal([]byte(tt.patch), &patch); err != nil {
+				t.Fatalf("decode patch: %v", err)
+			}
+			got, err := mergeSharing(tt.sharing, &patch)
+			if tt.wantField != "" {
+				var validationErr *domain.ValidationError
+				if !errors.As(err, &validationErr) || validationErr.Fields[0].Field != tt.wantField {
+					t.Fatalf("error = %v, want a validation error of %s", err, tt.wantField)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.PaidByID != tt.want.PaidByID || got.Method != tt.want.Method || !slices.Equal(got.Shares, tt.want.Shares) {
+				t.Errorf("merged sharing = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+type idempotencyService struct {
+	repo port.IdempotencyRepository
+	ttl  time.Duration
+}
+
+// NewIdempotencyService creates a new idempotency service keeping responses for ttl
+func NewIdempotencyService(repo port.IdempotencyRepository, ttl time.Duration) port.IdempotencyService {
+	return &idempotencyService{
+		repo: repo,
+		ttl:  ttl,
+	}
+}
+
+func (s *idempotencyService) Begin(ctx context.Context, key, scope, requestHash string) (*domain.IdempotencyRecord, error) {
+	now := time.Now()
+	record := &domain.IdempotencyRecord{
+		Key:         key,
+		Scope:       scope,
+		RequestHash: requestHash,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.ttl),
+	}
+
+	existing, err := s.repo.Reserve(ctx, record)
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to reserve idempotency key", "error", err, "scope", scope)
+		return nil, err
+	}
+	if existing == nil {
+		return nil, nil
+	}
+
+	// The key was used before, which is only allowed to replay the very same request
+	if existing.RequestHash != requestHash {
+		domain.LoggerFromContext(ctx).WarnContext(ctx, "Idempotency key reused with a different request", "scope", scope)
+		return nil, domain.ErrIdempotencyKeyReused
+	}
+	if !existing.Completed() {
+		return nil, domain.ErrIdempotencyRequestInProgress
+	}
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Replaying idempotent request", "scope", scope, "status", existing.StatusCode)
+	return existing, nil
+}
+
+func (s *idempotencyService) Complete(ctx context.Context, key, scope string, statusCode int, headers map[string]string, body []byte) error {
+	err := s.repo.Complete(ctx, &domain.IdempotencyRecord{
+		Key:        key,
+		Scope:      scope,
+		StatusCode: statusCode,
+		Headers:    headers,
+		Body:       body,
+	})
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to store idempotent response", "error", err, "scope", scope)
+		return err
+	}
+	return nil
+}
+
+func (s *idempotencyService) Release(ctx context.Context, key, scope string) error {
+	if err := s.repo.Release(ctx, key, scope); err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to release idempotency key", "error", err, "scope", scope)
+		return err
+	}
+	return nil
+}
+
+func (s *idempotencyService) PurgeExpired(ctx context.Context) (int64, error) {
+	purged, err := s.repo.PurgeExpired(ctx, time.Now())
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to purge expired idempotency keys", "error", err)
+		return 0, err
+	}
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Expired idempotency keys purged successfully", "idempotency_keys", purged)
+	return purged, nil
+}
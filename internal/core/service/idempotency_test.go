@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	memoryrepo "github.com/edwins-leonardi/finaid-api/internal/adapter/storage/memory/repository"
+)
+
+func TestIdempotencyPurgeExpired(t *testing.T) {
+	ctx := context.Background()
+	repo := memoryrepo.NewIdempotencyRepository()
+
+	// Keys reserved with a negative lifetime are expired at once
+	expired := NewIdempotencyService(repo, -time.Minute)
+	live := NewIdempotencyService(repo, time.Hour)
+	if _, err := expired.Begin(ctx, "expired", "POST /api/v1/persons", "hash"); err != nil {
+		t.Fatalf("begin expired key: %v", err)
+	}
+	if _, err := live.Begin(ctx, "live", "POST /api/v1/persons", "hash"); err != nil {
+		t.Fatalf("begin live key: %v", err)
+	}
+
+	purged, err := live.PurgeExpired(ctx)
+	if err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("purged %d keys, want 1", purged)
+	}
+	if purged, _ := live.PurgeExpired(ctx); purged != 0 {
+		t.Errorf("purged %d keys again, want 0", purged)
+	}
+}
@@ -0,0 +1,479 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+type mergeService struct {
+	repo            port.MergeRepository
+	payeeRepo       port.PayeeRepository
+	categoryRepo    port.ExpenseCategoryRepository
+	subCategoryRepo port.ExpenseSubCategoryRepository
+	expenseRepo     port.ExpenseRepository
+	txManager       port.TxManager
+	auditRepo       port.AuditRepository
+}
+
+// NewMergeService creates a new merge service
+func NewMergeService(
+	repo port.MergeRepository,
+	payeeRepo port.PayeeRepository,
+	categoryRepo port.ExpenseCategoryRepository,
+	subCategoryRepo port.ExpenseSubCategoryRepository,
+	expenseRepo port.ExpenseRepository,
+	txManager port.TxManager,
+	auditRepo port.AuditRepository,
+) port.MergeService {
+	return &mergeService{
+		repo:            repo,
+		payeeRepo:       payeeRepo,
+		categoryRepo:    categoryRepo,
+		subCategoryRepo: subCategoryRepo,
+		expenseRepo:     expenseRepo,
+		txManager:       txManager,
+		auditRepo:       auditRepo,
+	}
+}
+
+func (s *mergeService) MergePayees(ctx context.Context, targetID int, req *domain.MergeRequest) (*domain.MergeResult[*domain.Payee], error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Merging payees", "target_id", targetID, "source_ids", req.SourceIDs)
+
+	if err := checkMergeSources(targetID, req.SourceIDs); err != nil {
+		return nil, err
+	}
+
+	result := &domain.MergeResult[*domain.Payee]{Merges: []*domain.Merge{}}
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		target, err := s.payeeRepo.GetByID(ctx, targetID)
+		if err != nil {
+			return err
+		}
+
+		// Reject merges based on a stale version of the target
+		if req.Version != 0 && target.Version != req.Version {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Stale payee version", "id", targetID, "version", req.Version, "current_version", target.Version)
+			return domain.ErrPreconditionFailed
+		}
+
+		// Snapshot the current state before changing it
+		before := *target
+
+		// Every source must exist before anything is changed
+		sources := make([]*domain.Payee, len(req.SourceIDs))
+		for i, sourceID := range req.SourceIDs {
+			if sources[i], err = s.payeeRepo.GetByID(ctx, sourceID); err != nil {
+				return err
+			}
+		}
+
+		aliases := target.Aliases
+		for _, source := range sources {
+			sourceID := source.ID
+			moved, err := s.expenseRepo.ReassignPayee(ctx, sourceID, targetID)
+			if err != nil {
+				return err
+			}
+			if err := s.payeeRepo.Delete(ctx, sourceID, 0); err != nil {
+				return err
+			}
+
+			merge, err := s.record(ctx, domain.AuditEntityPayee, sourceID, targetID, source, moved)
+			if err != nil {
+				return err
+			}
+			result.Merges = append(result.Merges, merge)
+			aliases = mergedAliases(aliases, source.Name, source.Aliases)
+		}
+
+		// The target keeps the names of the merged payees for future matching
+		target.Aliases, err = aliasesOf(target.Name, aliases, domain.MaxPayeeAliases)
+		if err != nil {
+			return err
+		}
+		target.UpdatedAt = time.Now()
+		target.Version = req.Version
+		if err := s.payeeRepo.Update(ctx, target); err != nil {
+			return err
+		}
+		result.Target = target
+		return recordAudit(ctx, s.auditRepo, domain.AuditEntityPayee, uint64(targetID), domain.AuditActionUpdate, before, target)
+	})
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to merge payees", "error", err, "target_id", targetID)
+		return nil, err
+	}
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Payees merged successfully", "target_id", targetID, "merged", len(result.Merges))
+	return result, nil
+}
+
+func (s *mergeService) MergeExpenseCategories(ctx context.Context, targetID int, req *domain.MergeRequest) (*domain.MergeResult[*domain.ExpenseCategory], error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Merging expense categories", "target_id", targetID, "source_ids", req.SourceIDs)
+
+	if err := checkMergeSources(targetID, req.SourceIDs); err != nil {
+		return nil, err
+	}
+
+	result := &domain.MergeResult[*domain.ExpenseCategory]{Merges: []*domain.Merge{}}
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		target, err := s.categoryRepo.GetByID(ctx, targetID)
+		if err != nil {
+			return err
+		}
+
+		// Reject merges based on a stale version of the target
+		if req.Version != 0 && target.Version != req.Version {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Stale expense category version", "id", targetID, "version", req.Version, "current_version", target.Version)
+			return domain.ErrPreconditionFailed
+		}
+
+		targetSubCategories, err := s.subCategories(ctx, targetID)
+		if err != nil {
+			return err
+		}
+
+		// Snapshot the current state before changing it
+		before := *target
+
+		// Every source must exist before anything is changed
+		sources := make([]*domain.ExpenseCategory, len(req.SourceIDs))
+		for i, sourceID := range req.SourceIDs {
+			if sources[i], err = s.categoryRepo.GetByID(ctx, sourceID); err != nil {
+				return err
+			}
+		}
+
+		aliases := target.Aliases
+		var subCategoryMerges []*domain.Merge
+		subCategoriesBefore := make(map[int]domain.ExpenseSubCategory)
+		for _, source := range sources {
+			sourceID := source.ID
+			moved, err := s.expenseRepo.ReassignCategory(ctx, sourceID, targetID)
+			if err != nil {
+				return err
+			}
+			if err := s.payeeRepo.ReassignDefaultCategory(ctx, sourceID, targetID); err != nil {
+				return err
+			}
+
+			// Move the subcategories of the source over, merging those named like a subcategory of the target into it
+			subCategories, err := s.subCategories(ctx, sourceID)
+			if err != nil {
+				return err
+			}
+			for _, subCategory := range subCategories {
+				i := slices.IndexFunc(targetSubCategories, func(other *domain.ExpenseSubCategory) bool {
+					return strings.EqualFold(other.Name, subCategory.Name)
+				})
+				if i < 0 {
+					if err := s.moveSubCategory(ctx, subCategory, targetID); err != nil {
+						return err
+					}
+					targetSubCategories = append(targetSubCategories, subCategory)
+					continue
+				}
+
+				targetSubCategory := targetSubCategories[i]
+				if _, ok := subCategoriesBefore[targetSubCategory.ID]; !ok {
+					subCategoriesBefore[targetSubCategory.ID] = *targetSubCategory
+				}
+				merge, err := s.mergeSubCategory(ctx, subCategory, targetSubCategory)
+				if err != nil {
+					return err
+				}
+				subCategoryMerges = append(subCategoryMerges, merge)
+			}
+
+			if err := s.categoryRepo.Delete(ctx, sourceID, 0); err != nil {
+				return err
+			}
+
+			merge, err := s.record(ctx, domain.AuditEntityExpenseCategory, sourceID, targetID, source, moved)
+			if err != nil {
+				return err
+			}
+			result.Merges = append(result.Merges, merge)
+			aliases = mergedAliases(aliases, source.Name, source.Aliases)
+		}
+		result.Merges = append(result.Merges, subCategoryMerges...)
+
+		// Subcategories of the target that others were merged into keep their names
+		for _, subCategory := range targetSubCategories {
+			subCategoryBefore, ok := subCategoriesBefore[subCategory.ID]
+			if !ok {
+				continue
+			}
+			subCategory.Aliases, err = aliasesOf(subCategory.Name, subCategory.Aliases, domain.MaxCategoryAliases)
+			if err != nil {
+				return err
+			}
+			subCategory.UpdatedAt = time.Now()
+			if err := s.subCategoryRepo.Update(ctx, subCategory); err != nil {
+				return err
+			}
+			err = recordAudit(ctx, s.auditRepo, domain.AuditEntityExpenseSubCategory, uint64(subCategory.ID), domain.AuditActionUpdate, subCategoryBefore, subCategory)
+			if err != nil {
+				return err
+			}
+		}
+
+		// The target keeps the names of the merged categories for future matching
+		target.Aliases, err = aliasesOf(target.Name, aliases, domain.MaxCategoryAliases)
+		if err != nil {
+			return err
+		}
+		target.UpdatedAt = time.Now()
+		target.Version = req.Version
+		if err := s.categoryRepo.Update(ctx, target); err != nil {
+			return err
+		}
+		result.Target = target
+		return recordAudit(ctx, s.auditRepo, domain.AuditEntityExpenseCategory, uint64(targetID), domain.AuditActionUpdate, before, target)
+	})
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to merge expense categories", "error", err, "target_id", targetID)
+		return nil, err
+	}
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Expense categories merged successfully", "target_id", targetID, "merged", len(result.Merges))
+	return result, nil
+}
+
+func (s *mergeService) MergeExpenseSubCategories(ctx context.Context, targetID int, req *domain.MergeRequest) (*domain.MergeResult[*domain.ExpenseSubCategory], error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Merging expense subcategories", "target_id", targetID, "source_ids", req.SourceIDs)
+
+	if err := checkMergeSources(targetID, req.SourceIDs); err != nil {
+		return nil, err
+	}
+
+	result := &domain.MergeResult[*domain.ExpenseSubCategory]{Merges: []*domain.Merge{}}
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		target, err := s.subCategoryRepo.GetByID(ctx, targetID)
+		if err != nil {
+			return err
+		}
+
+		// Reject merges based on a stale version of the target
+		if req.Version != 0 && target.Version != req.Version {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Stale expense subcategory version", "id", targetID, "version", req.Version, "current_version", target.Version)
+			return domain.ErrPreconditionFailed
+		}
+
+		// Snapshot the current state before changing it
+		before := *target
+
+		// Every source must exist before anything is changed
+		sources := make([]*domain.ExpenseSubCategory, len(req.SourceIDs))
+		for i, sourceID := range req.SourceIDs {
+			if sources[i], err = s.subCategoryRepo.GetByID(ctx, sourceID); err != nil {
+				return err
+			}
+		}
+
+		for _, source := range sources {
+			merge, err := s.mergeSubCategory(ctx, source, target)
+			if err != nil {
+				return err
+			}
+			result.Merges = append(result.Merges, merge)
+		}
+
+		// The target keeps the names of the merged subcategories for future matching
+		target.Aliases, err = aliasesOf(target.Name, target.Aliases, domain.MaxCategoryAliases)
+		if err != nil {
+			return err
+		}
+		target.UpdatedAt = time.Now()
+		target.Version = req.Version
+		if err := s.subCategoryRepo.Update(ctx, target); err != nil {
+			return err
+		}
+		result.Target = target
+		return recordAudit(ctx, s.auditRepo, domain.AuditEntityExpenseSubCategory, uint64(targetID), domain.AuditActionUpdate, before, target)
+	})
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to merge expense subcategories", "error", err, "target_id", targetID)
+		return nil, err
+	}
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Expense subcategories merged successfully", "target_id", targetID, "merged", len(result.Merges))
+	return result, nil
+}
+
+func (s *mergeService) List(ctx context.Context, req *domain.ListMergesRequest) (*domain.Page[*domain.Merge], error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Listing merges", "entity", req.EntityType, "target_id", req.TargetID, "cursor", req.Cursor, "limit", req.Limit)
+
+	limit, err := pageLimit(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	after, err := domain.DecodeCursor(req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// Optional filters
+	var filters port.MergeFilters
+	if req.EntityType != "" {
+		if !domain.IsMergeEntityType(req.EntityType) {
+			return nil, domain.InvalidField("entity", "oneof", "must be a merged entity type")
+		}
+		filters.EntityType = &req.EntityType
+	}
+	if req.TargetID > 0 {
+		// A target is only meaningful together with the entity type it belongs to
+		if filters.EntityType == nil {
+			return nil, domain.InvalidField("target_id", "required_with", "requires an entity")
+		}
+		filters.TargetID = &req.TargetID
+	}
+
+	// Read one more merge than asked to tell whether another page follows
+	merges, err := s.repo.List(ctx, after, limit+1, filters)
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to list merges", "error", err)
+		return nil, err
+	}
+	page := newPage(merges, limit, mergeCursor)
+
+	if req.IncludeTotal {
+		total, err := s.repo.Count(ctx, filters)
+		if err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to count merges", "error", err)
+			return nil, err
+		}
+		page.TotalCount = &total
+	}
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Merges retrieved successfully", "count", len(page.Items), "has_more", page.HasMore)
+	return page, nil
+}
+
+// mergeSubCategory merges a subcategory into another one, the caller saves the aliases it adds to the target
+func (s *mergeService) mergeSubCategory(ctx context.Context, source, target *domain.ExpenseSubCategory) (*domain.Merge, error) {
+	moved, err := s.expenseRepo.ReassignSubCategory(ctx, source.ID, target.ID, target.ExpenseCategoryID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.payeeRepo.ReassignDefaultSubCategory(ctx, source.ID, target.ID, target.ExpenseCategoryID); err != nil {
+		return nil, err
+	}
+	if err := s.subCategoryRepo.Delete(ctx, source.ID, 0); err != nil {
+		return nil, err
+	}
+
+	target.Aliases = mergedAliases(target.Aliases, source.Name, source.Aliases)
+	return s.record(ctx, domain.AuditEntityExpenseSubCategory, source.ID, target.ID, source, moved)
+}
+
+// moveSubCategory moves a subcategory to another category
+func (s *mergeService) moveSubCategory(ctx context.Context, subCategory *domain.ExpenseSubCategory, categoryID int) error {
+	before := *subCategory
+	subCategory.ExpenseCategoryID = categoryID
+	subCategory.UpdatedAt = time.Now()
+	if err := s.subCategoryRepo.Update(ctx, subCategory); err != nil {
+		return err
+	}
+	return recordAudit(ctx, s.auditRepo, domain.AuditEntityExpenseSubCategory, uint64(subCategory.ID), domain.AuditActionUpdate, before, subCategory)
+}
+
+// subCategories reads every subcategory of a category that is not deleted
+func (s *mergeService) subCategories(ctx context.Context, categoryID int) ([]*domain.ExpenseSubCategory, error) {
+	var subCategories []*domain.ExpenseSubCategory
+	var after *domain.Cursor
+	for {
+		page, err := s.subCategoryRepo.List(ctx, after, domain.MaxPageSize, &categoryID, false)
+		if err != nil {
+			return nil, err
+		}
+		subCategories = append(subCategories, page...)
+		if len(page) < domain.MaxPageSize {
+			return subCategories, nil
+		}
+		cursor := expenseSubCategoryCursor(page[len(page)-1])
+		after = &cursor
+	}
+}
+
+// record keeps the merge of a source into a target for inspection and records it in the audit trail of the source
+func (s *mergeService) record(ctx context.Context, entityType string, sourceID, targetID int, source any, expenseCount int64) (*domain.Merge, error) {
+	snapshot, err := json.Marshal(source)
+	if err != nil {
+		return nil, err
+	}
+
+	merge := &domain.Merge{
+		EntityType:   entityType,
+		SourceID:     sourceID,
+		TargetID:     targetID,
+		Source:       snapshot,
+		ExpenseCount: expenseCount,
+		CreatedAt:    time.Now(),
+	}
+	if err := s.repo.Create(ctx, merge); err != nil {
+		return nil, err
+	}
+
+	if err := recordAudit(ctx, s.auditRepo, entityType, uint64(sourceID), domain.AuditActionMerge, source, nil); err != nil {
+		return nil, err
+	}
+	return merge, nil
+}
+
+// checkMergeSources validates the records merged into a target, each one must be given once and differ from the target
+func checkMergeSources(targetID int, sourceIDs []int) error {
+	if targetID <= 0 {
+		return invalidID("id")
+	}
+	if len(sourceIDs) == 0 {
+		return requiredField("source_ids")
+	}
+	if len(sourceIDs) > domain.MaxMergeSources {
+		return domain.InvalidField("source_ids", "max", fmt.Sprintf("must have at most %d items", domain.MaxMergeSources))
+	}
+	for i, sourceID := range sourceIDs {
+		field := fmt.Sprintf("source_ids[%d]", i)
+		switch {
+		case sourceID <= 0:
+			return invalidID(field)
+		case sourceID == targetID:
+			return domain.InvalidField(field, "nefield", "must differ from the merge target")
+		case slices.Contains(sourceIDs[:i], sourceID):
+			return domain.InvalidField(field, "unique", "must not repeat a source")
+		}
+	}
+	return nil
+}
+
+// mergedAliases adds the name and aliases of a merged record to the aliases of its target
+func mergedAliases(aliases []string, name string, sourceAliases []string) []string {
+	merged := append(slices.Clone(aliases), name)
+	return append(merged, sourceAliases...)
+}
+
+// aliasesOf trims the aliases of a record, dropping empty ones and repeats of its name or of another alias, ignoring case,
+// and fails when more than limit remain
+func aliasesOf(name string, aliases []string, limit int) ([]string, error) {
+	result := []string{}
+	for _, alias := range aliases {
+		alias = strings.TrimSpace(alias)
+		if alias == "" || strings.EqualFold(alias, name) || slices.ContainsFunc(result, func(other string) bool {
+			return strings.EqualFold(other, alias)
+		}) {
+			continue
+		}
+		result = append(result, alias)
+	}
+	if len(result) > limit {
+		return nil, domain.InvalidField("aliases", "max", fmt.Sprintf("must have at most %d items", limit))
+	}
+	return result, nil
+}
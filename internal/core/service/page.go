@@ -0,0 +1,86 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+)
+
+// pageLimit validates the page size of a list request, 0 selects the default size
+func pageLimit(limit int) (int, error) {
+	if limit == 0 {
+		return domain.DefaultPageSize, nil
+	}
+	if limit < 0 || limit > domain.MaxPageSize {
+		return 0, domain.InvalidField("limit", "max", fmt.Sprintf("must be between 0 and %d", domain.MaxPageSize))
+	}
+	return limit, nil
+}
+
+// newPage builds a page from up to limit+1 items read from a repository,
+// the extra item only tells that another page follows
+func newPage[T any](items []T, limit int, cursor func(T) domain.Cursor) *domain.Page[T] {
+	page := &domain.Page[T]{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		page.HasMore = true
+		page.NextCursor = cursor(page.Items[limit-1]).Encode()
+	}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	return page
+}
+
+// Cursors matching the ordering of each repository list
+
+func personCursor(person domain.Person) domain.Cursor {
+	return domain.Cursor{ID: person.ID}
+}
+
+func accountCursor(account domain.Account) domain.Cursor {
+	return domain.Cursor{ID: account.ID}
+}
+
+func expenseCategoryCursor(category *domain.ExpenseCategory) domain.Cursor {
+	return domain.Cursor{CreatedAt: &category.CreatedAt, ID: uint64(category.ID)}
+}
+
+func expenseSubCategoryCursor(subcategory *domain.ExpenseSubCategory) domain.Cursor {
+	return domain.Cursor{CreatedAt: &subcategory.CreatedAt, ID: uint64(subcategory.ID)}
+}
+
+func expenseCursor(expense *domain.Expense, sort domain.ExpenseSort) domain.Cursor {
+	cursor := domain.Cursor{Sort: sort.String(), CreatedAt: &expense.CreatedAt, ID: uint64(expense.ID)}
+	switch sort.Field {
+	case domain.ExpenseSortDate:
+		cursor.Date = &expense.Date
+	case domain.ExpenseSortAmount:
+		cursor.Amount = &expense.Amount
+	}
+	return cursor
+}
+
+func savedViewCursor(view *domain.SavedView) domain.Cursor {
+	return domain.Cursor{ID: uint64(view.ID)}
+}
+
+func tagCursor(tag *domain.Tag) domain.Cursor {
+	return domain.Cursor{ID: uint64(tag.ID)}
+}
+
+func settlementCursor(settlement *domain.Settlement) domain.Cursor {
+	return domain.Cursor{ID: uint64(settlement.ID)}
+}
+
+func payeeCursor(payee *domain.Payee) domain.Cursor {
+	return domain.Cursor{ID: uint64(payee.ID)}
+}
+
+func mergeCursor(merge *domain.Merge) domain.Cursor {
+	return domain.Cursor{ID: merge.ID}
+}
+
+func auditEntryCursor(entry *domain.AuditEntry) domain.Cursor {
+	return domain.Cursor{CreatedAt: &entry.CreatedAt, ID: entry.ID}
+}
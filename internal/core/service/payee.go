@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+type payeeService struct {
+	repo            port.PayeeRepository
+	expenseRepo     port.ExpenseRepository
+	categoryRepo    port.ExpenseCategoryRepository
+	subCategoryRepo port.ExpenseSubCategoryRepository
+	personRepo      port.PersonRepository
+	txManager       port.TxManager
+	auditRepo       port.AuditRepository
+}
+
+// NewPayeeService creates a new payee service
+func NewPayeeService(
+	repo port.PayeeRepository,
+	expenseRepo port.ExpenseRepository,
+	categoryRepo port.ExpenseCategoryRepository,
+	subCategoryRepo port.ExpenseSubCategoryRepository,
+	personRepo port.PersonRepository,
+	txManager port.TxManager,
+	auditRepo port.AuditRepository,
+) port.PayeeService {
+	return &payeeService{
+		repo:            repo,
+		expenseRepo:     expenseRepo,
+		categoryRepo:    categoryRepo,
+		subCategoryRepo: subCategoryRepo,
+		personRepo:      personRepo,
+		txManager:       txManager,
+		auditRepo:       auditRepo,
+	}
+}
+
+func (s *payeeService) Create(ctx context.Context, req *domain.CreatePayeeRequest) (*domain.Payee, error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Creating payee", "name", req.Name)
+
+	// Validate and sanitize input
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, requiredField("name")
+	}
+	aliases, err := aliasesOf(name, req.Aliases, domain.MaxPayeeAliases)
+	if err != nil {
+		return nil, err
+	}
+
+	payee := &domain.Payee{
+		Name:                 name,
+		Aliases:              aliases,
+		DefaultCategoryID:    req.DefaultCategoryID,
+		DefaultSubCategoryID: req.DefaultSubCategoryID,
+		PersonID:             req.PersonID,
+		CreatedAt:            time.Now(),
+		UpdatedAt:            time.Now(),
+	}
+
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.checkReferences(ctx, req.DefaultCategoryID, req.DefaultSubCategoryID, req.PersonID); err != nil {
+			return err
+		}
+		if err := s.checkNameAvailable(ctx, name, 0); err != nil {
+			return err
+		}
+		if err := s.repo.Create(ctx, payee); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditEntityPayee, uint64(payee.ID), domain.AuditActionCreate, nil, payee)
+	})
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to create payee", "error", err, "name", name)
+		return nil, err
+	}
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Payee created successfully", "id", payee.ID, "name", payee.Name)
+	return payee, nil
+}
+
+func (s *payeeService) GetByID(ctx context.Context, id int) (*domain.Payee, error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Getting payee by ID", "id", id)
+
+	if id <= 0 {
+		return nil, invalidID("id")
+	}
+
+	payee, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to get payee", "error", err, "id", id)
+		return nil, err
+	}
+
+	return payee, nil
+}
+
+func (s *payeeService) List(ctx context.Context, req *domain.ListPayeesRequest) (*domain.Page[*domain.Payee], error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Listing payees", "cursor", req.Cursor, "limit", req.Limit, "search", req.Search)
+
+	limit, err := pageLimit(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	after, err := domain.DecodeCursor(req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	search := strings.TrimSpace(req.Search)
+
+	// Read one more payee than asked to tell whether another page follows
+	payees, err := s.repo.List(ctx, after, limit+1, search)
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to list payees", "error", err)
+		return nil, err
+	}
+	page := newPage(payees, limit, payeeCursor)
+
+	if req.IncludeTotal {
+		total, err := s.repo.Count(ctx, search)
+		if err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to count payees", "error", err)
+			return nil, err
+		}
+		page.TotalCount = &total
+	}
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Payees retrieved successfully", "count", len(page.Items), "has_more", page.HasMore)
+	return page, nil
+}
+
+func (s *payeeService) Update(ctx context.Context, id int, req *domain.UpdatePayeeRequest) (*domain.Payee, error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Updating payee", "id", id, "name", req.Name)
+
+	if id <= 0 {
+		return nil, invalidID("id")
+	}
+
+	// Validate and sanitize input
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, requiredField("name")
+	}
+	aliases, err := aliasesOf(name, req.Aliases, domain.MaxPayeeAliases)
+	if err != nil {
+		return nil, err
+	}
+
+	// The payee and the entities it references are read within the transaction changing it
+	var existingPayee *domain.Payee
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.checkReferences(ctx, req.DefaultCategoryID, req.DefaultSubCategoryID, req.PersonID); err != nil {
+			return err
+		}
+
+		// Check if payee exists
+		var err error
+		existingPayee, err = s.repo.GetByID(ctx, id)
+		if err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to get payee for update", "error", err, "id", id)
+			return err
+		}
+
+		// Reject updates based on a stale version
+		if req.Version != 0 && existingPayee.Version != req.Version {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Stale payee version", "id", id, "version", req.Version, "current_version", existingPayee.Version)
+			return domain.ErrPreconditionFailed
+		}
+
+		// Snapshot the current state before changing it
+		before := *existingPayee
+
+		// Update fields
+		existingPayee.Name = name
+		existingPayee.Aliases = aliases
+		existingPayee.DefaultCategoryID = req.DefaultCategoryID
+		existingPayee.DefaultSubCategoryID = req.DefaultSubCategoryID
+		existingPayee.PersonID = req.PersonID
+		existingPayee.UpdatedAt = time.Now()
+		existingPayee.Version = req.Version
+
+		if err := s.checkNameAvailable(ctx, name, id); err != nil {
+			return err
+		}
+		if err := s.repo.Update(ctx, existingPayee); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditEntityPayee, uint64(id), domain.AuditActionUpdate, before, existingPayee)
+	})
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to update payee", "error", err, "id", id)
+		return nil, err
+	}
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Payee updated successfully", "id", id, "name", name)
+	return existingPayee, nil
+}
+
+func (s *payeeService) Delete(ctx context.Context, id int, version int) error {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Deleting payee", "id", id)
+
+	if id <= 0 {
+		return invalidID("id")
+	}
+
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Check if payee exists, within the transaction deleting it
+		existingPayee, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to get payee for deletion", "error", err, "id", id)
+			return err
+		}
+
+		// Reject deletions based on a stale version
+		if version != 0 && existingPayee.Version != version {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Stale payee version", "id", id, "version", version, "current_version", existingPayee.Version)
+			return domain.ErrPreconditionFailed
+		}
+
+		// Expenses keep their payee, even once soft deleted since they can be restored
+		used, err := s.expenseRepo.Count(ctx, port.ExpenseFilters{PayeeIDs: []int{id}, IncludeDeleted: true})
+		if err != nil {
+			return err
+		}
+		if used > 0 {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Payee still has expenses", "id", id, "expenses", used)
+			return domain.ErrConflictingData
+		}
+
+		if err := s.repo.Delete(ctx, id, version); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditEntityPayee, uint64(id), domain.AuditActionDelete, existingPayee, nil)
+	})
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to delete payee", "error", err, "id", id)
+		return err
+	}
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Payee deleted successfully", "id", id)
+	return nil
+}
+
+// checkReferences validates the default category and subcategory and the person of a payee
+func (s *payeeService) checkReferences(ctx context.Context, categoryID, subCategoryID, personID *int) error {
+	if categoryID != nil {
+		if _, err := s.categoryRepo.GetByIDForShare(ctx, *categoryID); err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Default category not found", "error", err, "category_id", *categoryID)
+			return err
+		}
+	}
+
+	// A default subcategory only applies along with its category
+	if subCategoryID != nil {
+		if categoryID == nil {
+			return domain.InvalidField("default_subcategory_id", "required_with", "requires a default_category_id")
+		}
+		subCategory, err := s.subCategoryRepo.GetByIDForShare(ctx, *subCategoryID)
+		if err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Default subcategory not found", "error", err, "subcategory_id", *subCategoryID)
+			return err
+		}
+		if subCategory.ExpenseCategoryID != *categoryID {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Default subcategory does not belong to the default category",
+				"subcategory_id", *subCategoryID, "category_id", *categoryID)
+			return domain.InvalidField("default_subcategory_id", "category", "must belong to the default category")
+		}
+	}
+
+	if personID != nil {
+		if _, err := s.personRepo.GetPersonByIDForShare(ctx, uint64(*personID)); err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Person not found", "error", err, "person_id", *personID)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkNameAvailable rejects a payee name already used by another payee, ignoring case
+func (s *payeeService) checkNameAvailable(ctx context.Context, name string, id int) error {
+	payee, err := s.repo.GetByName(ctx, name)
+	if errors.Is(err, domain.ErrDataNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if payee.ID != id {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Payee name already used", "name", name, "payee_id", payee.ID)
+		return domain.ErrConflictingData
+	}
+	return nil
+}
@@ -2,19 +2,25 @@ package service
 
 import (
 	"context"
-	"log/slog"
+	"errors"
+	"net/mail"
+	"strings"
 
 	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
 	"github.com/edwins-leonardi/finaid-api/internal/core/port"
 )
 
 type PersonService struct {
-	repo port.PersonRepository
+	repo      port.PersonRepository
+	txManager port.TxManager
+	auditRepo port.AuditRepository
 }
 
-func NewPersonService(repo port.PersonRepository) *PersonService {
+func NewPersonService(repo port.PersonRepository, txManager port.TxManager, auditRepo port.AuditRepository) *PersonService {
 	return &PersonService{
-		repo: repo,
+		repo:      repo,
+		txManager: txManager,
+		auditRepo: auditRepo,
 	}
 }
 
@@ -23,15 +29,20 @@ func (svc *PersonService) Create(ctx context.Context, Person *domain.Person) (*d
 	// Validate the Person data here if needed
 	// For example, check if email is valid, etc.
 
-	slog.Info("Creating new user", "name", Person.Name)
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Creating new user", "name", Person.Name)
 
-	// Call the repository to create the Person
-	person, err := svc.repo.CreatePerson(ctx, Person)
-	if err != nil {
-		if err == domain.ErrConflictingData {
-			return nil, err
+	// Call the repository to create the Person and record it in the audit trail
+	var person *domain.Person
+	err := svc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		person, err = svc.repo.CreatePerson(ctx, Person)
+		if err != nil {
+			return err
 		}
-		return nil, domain.ErrInternal
+		return recordAudit(ctx, svc.auditRepo, domain.AuditEntityPerson, person.ID, domain.AuditActionCreate, nil, person)
+	})
+	if err != nil {
+		return nil, domainError(err)
 	}
 
 	return person, nil
@@ -41,7 +52,7 @@ func (svc *PersonService) Create(ctx context.Context, Person *domain.Person) (*d
 func (svc *PersonService) GetPerson(ctx context.Context, id uint64) (*domain.Person, error) {
 	person, err := svc.repo.GetPersonByID(ctx, id)
 	if err != nil {
-		if err == domain.ErrDataNotFound {
+		if errors.Is(err, domain.ErrDataNotFound) {
 			return nil, err
 		}
 		return nil, domain.ErrInternal
@@ -49,55 +60,155 @@ func (svc *PersonService) GetPerson(ctx context.Context, id uint64) (*domain.Per
 	return person, nil
 }
 
-// ListPersons returns a list of Persons with pagination
-func (svc *PersonService) ListPersons(ctx context.Context, skip, limit uint64) ([]domain.Person, error) {
-	slog.Info("Listing persons", "skip", skip, "limit", limit)
-	persons, err := svc.repo.ListPersons(ctx, skip, limit)
-	slog.Info("SERVICE Persons found", "count", len(persons))
+// ListPersons returns a page of Persons
+func (svc *PersonService) ListPersons(ctx context.Context, req *domain.PageRequest) (*domain.Page[domain.Person], error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Listing persons", "cursor", req.Cursor, "limit", req.Limit)
+	limit, err := pageLimit(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	after, err := domain.DecodeCursor(req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read one more Person than asked to tell whether another page follows
+	persons, err := svc.repo.ListPersons(ctx, after, limit+1)
 	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to list persons", "error", err)
 		return nil, domain.ErrInternal
 	}
-	return persons, nil
+	page := newPage(persons, limit, personCursor)
+
+	if req.IncludeTotal {
+		total, err := svc.repo.CountPersons(ctx)
+		if err != nil {
+			return nil, domain.ErrInternal
+		}
+		page.TotalCount = &total
+	}
+
+	return page, nil
 }
 
 // UpdatePerson updates a Person
 func (svc *PersonService) UpdatePerson(ctx context.Context, person *domain.Person) (*domain.Person, error) {
 	existingPerson, err := svc.repo.GetPersonByID(ctx, person.ID)
 	if err != nil {
-		if err == domain.ErrDataNotFound {
+		if errors.Is(err, domain.ErrDataNotFound) {
 			return nil, err
 		}
 		return nil, domain.ErrInternal
 	}
 
+	// Reject updates based on a stale version before comparing any data
+	if person.Version != 0 && existingPerson.Version != person.Version {
+		return nil, domain.ErrPreconditionFailed
+	}
+
 	emptyData := person.Name == "" && person.Email == ""
 	sameData := existingPerson.Name == person.Name && existingPerson.Email == person.Email
 	if emptyData || sameData {
 		return nil, domain.ErrNoUpdatedData
 	}
 
-	// Call the repository to update the Person
-	updatedPerson, err := svc.repo.UpdatePerson(ctx, person)
+	// Snapshot the current state before the repository touches it
+	before := *existingPerson
+
+	// Call the repository to update the Person and record it in the audit trail
+	var updatedPerson *domain.Person
+	err = svc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		updatedPerson, err = svc.repo.UpdatePerson(ctx, person)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, svc.auditRepo, domain.AuditEntityPerson, person.ID, domain.AuditActionUpdate, before, updatedPerson)
+	})
+	if err != nil {
+		return nil, domainError(err)
+	}
+
+	return updatedPerson, nil
+}
+
+// PatchPerson applies a JSON merge patch to a Person, changing only the present members
+func (svc *PersonService) PatchPerson(ctx context.Context, id uint64, req *domain.PatchPersonRequest) (*domain.Person, error) {
+	existingPerson, err := svc.repo.GetPersonByID(ctx, id)
 	if err != nil {
-		if err == domain.ErrNoUpdatedData {
+		if errors.Is(err, domain.ErrDataNotFound) {
 			return nil, err
 		}
 		return nil, domain.ErrInternal
 	}
 
-	return updatedPerson, nil
-}
+	// Reject patches based on a stale version
+	if req.Version != 0 && existingPerson.Version != req.Version {
+		return nil, domain.ErrPreconditionFailed
+	}
 
-// DeletePerson deletes a Person
-func (svc *PersonService) DeletePerson(ctx context.Context, id uint64) error {
+	// Snapshot the current state before changing it
+	before := *existingPerson
 
-	_, err := svc.repo.GetPersonByID(ctx, id)
-	if err != nil {
-		if err == domain.ErrDataNotFound {
+	// Apply and validate only the members present in the patch, none of them can be removed
+	person := *existingPerson
+	person.Version = req.Version
+	if req.Name.Set {
+		if req.Name.Null {
+			return nil, nullField("name")
+		}
+		if strings.TrimSpace(req.Name.Value) == "" {
+			return nil, requiredField("name")
+		}
+		person.Name = req.Name.Value
+	}
+	if req.Email.Set {
+		if req.Email.Null {
+			return nil, nullField("email")
+		}
+		if _, err := mail.ParseAddress(req.Email.Value); err != nil {
+			return nil, domain.InvalidField("email", "email", "must be a valid email address")
+		}
+		person.Email = req.Email.Value
+	}
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Patching person", "id", id)
+
+	// Call the repository to update the Person and record it in the audit trail
+	var updatedPerson *domain.Person
+	err = svc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		updatedPerson, err = svc.repo.UpdatePerson(ctx, &person)
+		if err != nil {
 			return err
 		}
-		return domain.ErrInternal
+		return recordAudit(ctx, svc.auditRepo, domain.AuditEntityPerson, id, domain.AuditActionUpdate, before, updatedPerson)
+	})
+	if err != nil {
+		return nil, domainError(err)
 	}
 
-	return svc.repo.DeletePerson(ctx, id)
+	return updatedPerson, nil
+}
+
+// DeletePerson deletes a Person based on the given version, 0 skips the check
+func (svc *PersonService) DeletePerson(ctx context.Context, id uint64, version int) error {
+	// The person is read within the transaction deleting it
+	return svc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		existingPerson, err := svc.repo.GetPersonByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, domain.ErrDataNotFound) {
+				return err
+			}
+			return domain.ErrInternal
+		}
+		if version != 0 && existingPerson.Version != version {
+			return domain.ErrPreconditionFailed
+		}
+
+		if err := svc.repo.DeletePerson(ctx, id, version); err != nil {
+			return err
+		}
+		return recordAudit(ctx, svc.auditRepo, domain.AuditEntityPerson, id, domain.AuditActionDelete, *existingPerson, nil)
+	})
 }
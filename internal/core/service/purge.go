@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+type purgeService struct {
+	expenseRepo     port.ExpenseRepository
+	attachmentRepo  port.AttachmentRepository
+	blobStore       port.BlobStore
+	subCategoryRepo port.ExpenseSubCategoryRepository
+	categoryRepo    port.ExpenseCategoryRepository
+	accountRepo     port.AccountRepository
+	txManager       port.TxManager
+}
+
+// NewPurgeService creates a new service that hard deletes expired soft-deleted data.
+// The attachments of purged expenses go with them, their content too when no other attachment uses it.
+func NewPurgeService(
+	expenseRepo port.ExpenseRepository,
+	attachmentRepo port.AttachmentRepository,
+	blobStore port.BlobStore,
+	subCategoryRepo port.ExpenseSubCategoryRepository,
+	categoryRepo port.ExpenseCategoryRepository,
+	accountRepo port.AccountRepository,
+	txManager port.TxManager,
+) port.PurgeService {
+	return &purgeService{
+		expenseRepo:     expenseRepo,
+		attachmentRepo:  attachmentRepo,
+		blobStore:       blobStore,
+		subCategoryRepo: subCategoryRepo,
+		categoryRepo:    categoryRepo,
+		accountRepo:     accountRepo,
+		txManager:       txManager,
+	}
+}
+
+func (s *purgeService) Purge(ctx context.Context, retention time.Duration) (*domain.PurgeResult, error) {
+	if retention <= 0 {
+		return nil, domain.ErrInvalidInput
+	}
+
+	deletedBefore := time.Now().Add(-retention)
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Purging soft-deleted data", "deleted_before", deletedBefore)
+
+	// Expenses go first so the rows they reference can be purged in the same run,
+	// preceded by their attachments whose content is released once the purge is committed
+	result := &domain.PurgeResult{}
+	var attachments []*domain.Attachment
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if attachments, err = s.attachmentRepo.Purge(ctx, deletedBefore); err != nil {
+			return err
+		}
+		result.Attachments = int64(len(attachments))
+		if result.Expenses, err = s.expenseRepo.Purge(ctx, deletedBefore); err != nil {
+			return err
+		}
+		if result.SubCategories, err = s.subCategoryRepo.Purge(ctx, deletedBefore); err != nil {
+			return err
+		}
+		if result.Categories, err = s.categoryRepo.Purge(ctx, deletedBefore); err != nil {
+			return err
+		}
+		if result.Accounts, err = s.accountRepo.PurgeAccounts(ctx, deletedBefore); err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to purge soft-deleted data", "error", err)
+		return nil, err
+	}
+
+	released := make(map[string]bool, len(attachments))
+	for _, attachment := range attachments {
+		if !released[attachment.Checksum] {
+			releaseAttachmentContent(ctx, s.attachmentRepo, s.blobStore, s.txManager, attachment)
+			released[attachment.Checksum] = true
+		}
+	}
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Soft-deleted data purged successfully",
+		"expenses", result.Expenses, "attachments", result.Attachments, "subcategories", result.SubCategories,
+		"categories", result.Categories, "accounts", result.Accounts)
+	return result, nil
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+type savedViewService struct {
+	repo           port.SavedViewRepository
+	expenseService port.ExpenseService
+	txManager      port.TxManager
+	auditRepo      port.AuditRepository
+}
+
+// NewSavedViewService creates a new saved view service
+func NewSavedViewService(
+	repo port.SavedViewRepository,
+	expenseService port.ExpenseService,
+	txManager port.TxManager,
+	auditRepo port.AuditRepository,
+) port.SavedViewService {
+	return &savedViewService{
+		repo:           repo,
+		expenseService: expenseService,
+		txManager:      txManager,
+		auditRepo:      auditRepo,
+	}
+}
+
+func (s *savedViewService) Create(ctx context.Context, req *domain.CreateSavedViewRequest) (*domain.SavedView, error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Creating saved view", "name", req.Name)
+
+	// Validate and sanitize input
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, requiredField("name")
+	}
+	filters, err := s.sanitizeFilters(ctx, req.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &domain.SavedView{
+		Name:      name,
+		Filters:   filters,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, view); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditEntitySavedView, uint64(view.ID), domain.AuditActionCreate, nil, view)
+	})
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to create saved view", "error", err, "name", name)
+		return nil, err
+	}
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Saved view created successfully", "id", view.ID, "name", view.Name)
+	return view, nil
+}
+
+func (s *savedViewService) GetByID(ctx context.Context, id int) (*domain.SavedView, error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Getting saved view by ID", "id", id)
+
+	if id <= 0 {
+		return nil, invalidID("id")
+	}
+
+	view, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to get saved view", "error", err, "id", id)
+		return nil, err
+	}
+
+	return view, nil
+}
+
+func (s *savedViewService) List(ctx context.Context, req *domain.ListSavedViewsRequest) (*domain.Page[*domain.SavedView], error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Listing saved views", "cursor", req.Cursor, "limit", req.Limit)
+
+	limit, err := pageLimit(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	after, err := domain.DecodeCursor(req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read one more view than asked to tell whether another page follows
+	views, err := s.repo.List(ctx, after, limit+1)
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to list saved views", "error", err)
+		return nil, err
+	}
+	page := newPage(views, limit, savedViewCursor)
+
+	if req.IncludeTotal {
+		total, err := s.repo.Count(ctx)
+		if err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to count saved views", "error", err)
+			return nil, err
+		}
+		page.TotalCount = &total
+	}
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Saved views retrieved successfully", "count", len(page.Items), "has_more", page.HasMore)
+	return page, nil
+}
+
+func (s *savedViewService) Update(ctx context.Context, id int, req *domain.UpdateSavedViewRequest) (*domain.SavedView, error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Updating saved view", "id", id, "name", req.Name)
+
+	if id <= 0 {
+		return nil, invalidID("id")
+	}
+
+	// Validate and sanitize input
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, requiredField("name")
+	}
+	filters, err := s.sanitizeFilters(ctx, req.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if view exists
+	existingView, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to get saved view for update", "error", err, "id", id)
+		return nil, err
+	}
+
+	// Reject updates based on a stale version
+	if req.Version != 0 && existingView.Version != req.Version {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Stale saved view version", "id", id, "version", req.Version, "current_version", existingView.Version)
+		return nil, domain.ErrPreconditionFailed
+	}
+
+	// Snapshot the current state before changing it
+	before := *existingView
+
+	// Update fields
+	existingView.Name = name
+	existingView.Filters = filters
+	existingView.UpdatedAt = time.Now()
+	existingView.Version = req.Version
+
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, existingView); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditEntitySavedView, uint64(id), domain.AuditActionUpdate, before, existingView)
+	})
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to update saved view", "error", err, "id", id)
+		return nil, err
+	}
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Saved view updated successfully", "id", id, "name", name)
+	return existingView, nil
+}
+
+func (s *savedViewService) Delete(ctx context.Context, id int, version int) error {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Deleting saved view", "id", id)
+
+	if id <= 0 {
+		return invalidID("id")
+	}
+
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Check if view exists, within the transaction deleting it
+		existingView, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to get saved view for deletion", "error", err, "id", id)
+			return err
+		}
+
+		// Reject deletions based on a stale version
+		if version != 0 && existingView.Version != version {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Stale saved view version", "id", id, "version", version, "current_version", existingView.Version)
+			return domain.ErrPreconditionFailed
+		}
+
+		if err := s.repo.Delete(ctx, id, version); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditEntitySavedView, uint64(id), domain.AuditActionDelete, existingView, nil)
+	})
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to delete saved view", "error", err, "id", id)
+		return err
+	}
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Saved view deleted successfully", "id", id)
+	return nil
+}
+
+func (s *savedViewService) ListExpenses(ctx context.Context, id int, req *domain.PageRequest) (*domain.Page[*domain.Expense], error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Listing expenses of saved view", "id", id, "cursor", req.Cursor, "limit", req.Limit)
+
+	view, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := view.Filters
+	listReq := &domain.ListExpensesRequest{
+		PageRequest:    *req,
+		CategoryIDs:    filters.CategoryIDs,
+		SubCategoryID:  filters.SubCategoryID,
+		PayeeIDs:       filters.PayeeIDs,
+		AccountIDs:     filters.AccountIDs,
+		MinAmount:      filters.MinAmount,
+		MaxAmount:      filters.MaxAmount,
+		Notes:          filters.Notes,
+		HasSubCategory: filters.HasSubCategory,
+		TagIDs:         filters.TagIDs,
+		TagMatch:       filters.TagMatch,
+		StartDate:      filters.StartDate,
+		EndDate:        filters.EndDate,
+		Sort:           filters.Sort,
+	}
+
+	// Resolve the relative date range on the current day
+	if filters.DateRange != "" {
+		start, end, err := domain.ResolveDateRange(filters.DateRange, time.Now())
+		if err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Invalid saved view date range", "id", id, "date_range", filters.DateRange)
+			return nil, err
+		}
+		listReq.StartDate = start.Format("2006-01-02")
+		listReq.EndDate = end.Format("2006-01-02")
+	}
+
+	return s.expenseService.List(ctx, listReq)
+}
+
+// sanitizeFilters validates the filters of a saved view, so that running it can only fail on the current data
+func (s *savedViewService) sanitizeFilters(ctx context.Context, filters domain.SavedViewFilters) (domain.SavedViewFilters, error) {
+	for _, list := range []struct {
+		field string
+		ids   []int
+	}{
+		{"category_ids", filters.CategoryIDs},
+		{"payee_ids", filters.PayeeIDs},
+		{"account_ids", filters.AccountIDs},
+		{"tag_ids", filters.TagIDs},
+	} {
+		if err := checkIDs(list.field, list.ids); err != nil {
+			return filters, nestedFields("filters", err)
+		}
+	}
+	if filters.SubCategoryID < 0 {
+		return filters, invalidID("filters.subcategory_id")
+	}
+	if filters.MinAmount != nil && filters.MaxAmount != nil && *filters.MinAmount > *filters.MaxAmount {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Invalid amount range", "min_amount", *filters.MinAmount, "max_amount", *filters.MaxAmount)
+		return filters, domain.InvalidField("filters.min_amount", "lte", "must not be greater than max_amount")
+	}
+	filters.Notes = strings.TrimSpace(filters.Notes)
+	if filters.TagMatch != "" && filters.TagMatch != domain.TagMatchAny && filters.TagMatch != domain.TagMatchAll {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Invalid tag match", "tag_match", filters.TagMatch)
+		return filters, domain.InvalidField("filters.tag_match", "oneof", "must be one of any, all")
+	}
+
+	if _, err := domain.ParseExpenseSort(filters.Sort); err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Invalid expense sort", "sort", filters.Sort)
+		return filters, nestedFields("filters", err)
+	}
+
+	// A view either has a relative or a fixed date range
+	if filters.DateRange != "" {
+		if filters.StartDate != "" || filters.EndDate != "" {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Relative date range combined with fixed dates", "date_range", filters.DateRange)
+			return filters, domain.InvalidField("filters.date_range", "excluded_with", "must not be combined with start_date or end_date")
+		}
+		if _, _, err := domain.ResolveDateRange(filters.DateRange, time.Now()); err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Invalid date range", "date_range", filters.DateRange)
+			return filters, nestedFields("filters", err)
+		}
+	}
+	for _, date := range []struct{ field, value string }{{"start_date", filters.StartDate}, {"end_date", filters.EndDate}} {
+		if date.value == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date.value); err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Invalid date format", "error", err, "date", date.value)
+			return filters, invalidDate("filters." + date.field)
+		}
+	}
+
+	return filters, nil
+}
@@ -0,0 +1,377 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"math/bits"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+type settlementService struct {
+	repo        port.SettlementRepository
+	expenseRepo port.ExpenseRepository
+	personRepo  port.PersonRepository
+	txManager   port.TxManager
+	auditRepo   port.AuditRepository
+}
+
+// NewSettlementService creates a new settlement service
+func NewSettlementService(
+	repo port.SettlementRepository,
+	expenseRepo port.ExpenseRepository,
+	personRepo port.PersonRepository,
+	txManager port.TxManager,
+	auditRepo port.AuditRepository,
+) port.SettlementService {
+	return &settlementService{
+		repo:        repo,
+		expenseRepo: expenseRepo,
+		personRepo:  personRepo,
+		txManager:   txManager,
+		auditRepo:   auditRepo,
+	}
+}
+
+func (s *settlementService) Create(ctx context.Context, req *domain.CreateSettlementRequest) (*domain.Settlement, error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Creating settlement", "from_person_id", req.FromPersonID, "to_person_id", req.ToPersonID, "amount", req.Amount)
+
+	// A person cannot settle with themselves
+	switch {
+	case req.FromPersonID <= 0:
+		return nil, invalidID("from_person_id")
+	case req.ToPersonID <= 0:
+		return nil, invalidID("to_person_id")
+	case req.FromPersonID == req.ToPersonID:
+		return nil, domain.InvalidField("to_person_id", "nefield", "must differ from from_person_id")
+	case req.Amount <= 0:
+		return nil, domain.InvalidField("amount", "gt", "must be greater than 0")
+	}
+
+	// Validate and parse date
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Invalid date format", "error", err, "date", req.Date)
+		return nil, invalidDate("date")
+	}
+
+	// The persons are checked within the transaction creating the settlement
+	var settlement *domain.Settlement
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Validate that both persons exist
+		for _, personID := range []int{req.FromPersonID, req.ToPersonID} {
+			if _, err := s.personRepo.GetPersonByIDForShare(ctx, uint64(personID)); err != nil {
+				domain.LoggerFromContext(ctx).ErrorContext(ctx, "Person not found", "error", err, "person_id", personID)
+				return err
+			}
+		}
+
+		settlement = &domain.Settlement{
+			FromPersonID: req.FromPersonID,
+			ToPersonID:   req.ToPersonID,
+			Amount:       float64(amountCents(req.Amount)) / 100,
+			Date:         date,
+			Notes:        strings.TrimSpace(req.Notes),
+			CreatedAt:    time.Now(),
+			UpdatedAt:    time.Now(),
+		}
+
+		if err := s.repo.Create(ctx, settlement); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditEntitySettlement, uint64(settlement.ID), domain.AuditActionCreate, nil, settlement)
+	})
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to create settlement", "error", err)
+		return nil, err
+	}
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Settlement created successfully", "id", settlement.ID)
+	return settlement, nil
+}
+
+func (s *settlementService) GetByID(ctx context.Context, id int) (*domain.Settlement, error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Getting settlement by ID", "id", id)
+
+	if id <= 0 {
+		return nil, invalidID("id")
+	}
+
+	settlement, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to get settlement", "error", err, "id", id)
+		return nil, err
+	}
+
+	return settlement, nil
+}
+
+func (s *settlementService) List(ctx context.Context, req *domain.ListSettlementsRequest) (*domain.Page[*domain.Settlement], error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Listing settlements", "cursor", req.Cursor, "limit", req.Limit, "person_id", req.PersonID)
+
+	if req.PersonID < 0 {
+		return nil, invalidID("person_id")
+	}
+	limit, err := pageLimit(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	after, err := domain.DecodeCursor(req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read one more settlement than asked to tell whether another page follows
+	settlements, err := s.repo.List(ctx, after, limit+1, req.PersonID)
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to list settlements", "error", err)
+		return nil, err
+	}
+	page := newPage(settlements, limit, settlementCursor)
+
+	if req.IncludeTotal {
+		total, err := s.repo.Count(ctx, req.PersonID)
+		if err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to count settlements", "error", err)
+			return nil, err
+		}
+		page.TotalCount = &total
+	}
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Settlements retrieved successfully", "count", len(page.Items), "has_more", page.HasMore)
+	return page, nil
+}
+
+func (s *settlementService) Delete(ctx context.Context, id int, version int) error {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Deleting settlement", "id", id)
+
+	if id <= 0 {
+		return invalidID("id")
+	}
+
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Check if settlement exists, within the transaction deleting it
+		existingSettlement, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to get settlement for deletion", "error", err, "id", id)
+			return err
+		}
+
+		// Reject deletions based on a stale version
+		if version != 0 && existingSettlement.Version != version {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Stale settlement version", "id", id, "version", version, "current_version", existingSettlement.Version)
+			return domain.ErrPreconditionFailed
+		}
+
+		if err := s.repo.Delete(ctx, id, version); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditEntitySettlement, uint64(id), domain.AuditActionDelete, existingSettlement, nil)
+	})
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to delete settlement", "error", err, "id", id)
+		return err
+	}
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Settlement deleted successfully", "id", id)
+	return nil
+}
+
+func (s *settlementService) Balances(ctx context.Context) (*domain.Balances, error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Computing balances")
+
+	var shares, settled []domain.PersonDebt
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if shares, err = s.expenseRepo.ShareDebts(ctx); err != nil {
+			return err
+		}
+		settled, err = s.repo.Totals(ctx)
+		return err
+	})
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to compute balances", "error", err)
+		return nil, err
+	}
+
+	// Amounts owed by the first person of each pair to the second one, in cents
+	owed := make(map[[2]int]int64)
+	for _, debt := range shares {
+		owed[[2]int{debt.FromPersonID, debt.ToPersonID}] += amountCents(debt.Amount)
+	}
+	for _, settlement := range settled {
+		owed[[2]int{settlement.FromPersonID, settlement.ToPersonID}] -= amountCents(settlement.Amount)
+	}
+
+	// Net the two directions of each pair into a single debt
+	netted := make(map[[2]int]bool)
+	positions := make(map[int]int64)
+	var debts []debtCents
+	for pair := range owed {
+		from, to := min(pair[0], pair[1]), max(pair[0], pair[1])
+		if netted[[2]int{from, to}] {
+			continue
+		}
+		netted[[2]int{from, to}] = true
+
+		amount := owed[[2]int{from, to}] - owed[[2]int{to, from}]
+		if amount < 0 {
+			from, to, amount = to, from, -amount
+		}
+		positions[from] -= amount
+		positions[to] += amount
+		if amount > 0 {
+			debts = append(debts, debtCents{from: from, to: to, amount: amount})
+		}
+	}
+
+	balances := &domain.Balances{
+		Debts:          personDebts(debts),
+		Persons:        []domain.PersonBalance{},
+		SettlementPlan: personDebts(settlementPlan(positions)),
+	}
+	for personID, position := range positions {
+		balances.Persons = append(balances.Persons, domain.PersonBalance{PersonID: personID, Balance: float64(position) / 100})
+	}
+	slices.SortFunc(balances.Persons, func(a, b domain.PersonBalance) int {
+		return cmp.Compare(a.PersonID, b.PersonID)
+	})
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Balances computed successfully", "debts", len(balances.Debts), "payments", len(balances.SettlementPlan))
+	return balances, nil
+}
+
+// debtCents is an amount in cents owed by a person to another one
+type debtCents struct {
+	from, to int
+	amount   int64
+}
+
+// maxExactSettlementPersons is the largest number of unsettled persons whose settlement plan is
+// searched exhaustively, the search takes 2^n steps
+const maxExactSettlementPersons = 16
+
+// position is the amount in cents a person is owed, negative when the person owes it
+type position struct {
+	personID int
+	amount   int64
+}
+
+// settlementPlan returns the fewest payments bringing every position back to zero.
+// Persons whose positions add up to zero can settle among themselves in one payment less than their number,
+// so the plan splits the unsettled persons into the largest number of such groups, then settles each group.
+// Beyond maxExactSettlementPersons unsettled persons, the plan settles everyone as a single group.
+func settlementPlan(positions map[int]int64) []debtCents {
+	var unsettled []position
+	for personID, amount := range positions {
+		if amount != 0 {
+			unsettled = append(unsettled, position{personID: personID, amount: amount})
+		}
+	}
+	slices.SortFunc(unsettled, func(a, b position) int {
+		return cmp.Compare(a.personID, b.personID)
+	})
+	if len(unsettled) > maxExactSettlementPersons {
+		return settleGroup(unsettled)
+	}
+
+	// sums[set] is the sum of the positions of a set of persons, groups[set] the largest number of groups
+	// adding up to zero the set splits into
+	n := len(unsettled)
+	sums := make([]int64, 1<<n)
+	groups := make([]int, 1<<n)
+	for set := 1; set < 1<<n; set++ {
+		i := bits.TrailingZeros(uint(set))
+		sums[set] = sums[set&^(1<<i)] + unsettled[i].amount
+	}
+	for set := 1; set < 1<<n; set++ {
+		for i := 0; i < n; i++ {
+			if set&(1<<i) != 0 {
+				groups[set] = max(groups[set], groups[set&^(1<<i)])
+			}
+		}
+		if sums[set] == 0 {
+			groups[set]++
+		}
+	}
+
+	// Take persons out of the whole set without losing a group, each time the remaining set adds up to zero
+	// again, the persons taken out since the previous time form a group
+	var plan []debtCents
+	var group []position
+	for set := 1<<n - 1; set != 0; {
+		for i := 0; i < n; i++ {
+			if set&(1<<i) == 0 {
+				continue
+			}
+			rest := set &^ (1 << i)
+			closed := 0
+			if sums[set] == 0 {
+				closed = 1
+			}
+			if groups[rest]+closed == groups[set] {
+				group = append(group, unsettled[i])
+				set = rest
+				break
+			}
+		}
+		if sums[set] == 0 {
+			plan = append(plan, settleGroup(group)...)
+			group = nil
+		}
+	}
+
+	return plan
+}
+
+// settleGroup returns payments bringing positions adding up to zero back to zero, the largest debtor
+// repeatedly pays the largest creditor so that each payment clears at least one of them, and the last one both
+func settleGroup(positions []position) []debtCents {
+	var debtors, creditors []position
+	for _, p := range positions {
+		if p.amount < 0 {
+			debtors = append(debtors, position{personID: p.personID, amount: -p.amount})
+		} else if p.amount > 0 {
+			creditors = append(creditors, p)
+		}
+	}
+	largestFirst := func(a, b position) int {
+		return cmp.Or(cmp.Compare(b.amount, a.amount), cmp.Compare(a.personID, b.personID))
+	}
+
+	var plan []debtCents
+	for len(debtors) > 0 && len(creditors) > 0 {
+		slices.SortFunc(debtors, largestFirst)
+		slices.SortFunc(creditors, largestFirst)
+
+		amount := min(debtors[0].amount, creditors[0].amount)
+		plan = append(plan, debtCents{from: debtors[0].personID, to: creditors[0].personID, amount: amount})
+		debtors[0].amount -= amount
+		creditors[0].amount -= amount
+		if debtors[0].amount == 0 {
+			debtors = debtors[1:]
+		}
+		if creditors[0].amount == 0 {
+			creditors = creditors[1:]
+		}
+	}
+
+	return plan
+}
+
+// personDebts converts debts in cents to the domain type, ordered by persons
+func personDebts(debts []debtCents) []domain.PersonDebt {
+	slices.SortFunc(debts, func(a, b debtCents) int {
+		return cmp.Or(cmp.Compare(a.from, b.from), cmp.Compare(a.to, b.to))
+	})
+
+	result := make([]domain.PersonDebt, len(debts))
+	for i, debt := range debts {
+		result[i] = domain.PersonDebt{FromPersonID: debt.from, ToPersonID: debt.to, Amount: float64(debt.amount) / 100}
+	}
+	return result
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"testing"
+)
+
+func TestSettlementPlan(t *testing.T) {
+	tests := []struct {
+		name      string
+		positions map[int]int64
+		payments  int
+	}{
+		{"settled", map[int]int64{1: 0, 2: 0}, 0},
+		{"one debt", map[int]int64{1: -500, 2: 500}, 1},
+		{"one creditor", map[int]int64{1: -300, 2: -200, 3: 500}, 2},
+		{"one debtor", map[int]int64{1: -900, 2: 400, 3: 500}, 2},
+		{"chain", map[int]int64{1: -1000, 2: 0, 3: 300, 4: 700}, 2},
+		// Paying the largest creditor first takes 4 payments, settling 1, 3, 4 and 2, 5 apart takes 3
+		{"two groups", map[int]int64{1: -700, 2: -500, 3: 400, 4: 300, 5: 500}, 3},
+		{"three groups", map[int]int64{1: -100, 2: 100, 3: -250, 4: 200, 5: 50, 6: -900, 7: 900}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := settlementPlan(tt.positions)
+			if len(plan) != tt.payments {
+				t.Errorf("got %d payments %v, want %d", len(plan), plan, tt.payments)
+			}
+			expectSettled(t, tt.positions, plan)
+		})
+	}
+}
+
+func TestSettlementPlanManyPersons(t *testing.T) {
+	// Beyond the exhaustive search, the plan still settles everyone
+	positions := make(map[int]int64)
+	for personID := 1; personID <= maxExactSettlementPersons+2; personID += 2 {
+		positions[personID] = -int64(personID) * 100
+		positions[personID+1] = int64(personID) * 100
+	}
+	plan := settlementPlan(positions)
+	if len(plan) >= len(positions) {
+		t.Errorf("got %d payments for %d persons, want fewer", len(plan), len(positions))
+	}
+	expectSettled(t, positions, plan)
+}
+
+// expectSettled checks that the payments of plan are positive and bring every position back to zero
+func expectSettled(t *testing.T, positions map[int]int64, plan []debtCents) {
+	t.Helper()
+	remaining := make(map[int]int64, len(positions))
+	for personID, amount := range positions {
+		remaining[personID] = amount
+	}
+	for _, payment := range plan {
+		if payment.amount <= 0 {
+			t.Errorf("payment %v is not positive", payment)
+		}
+		remaining[payment.from] += payment.amount
+		remaining[payment.to] -= payment.amount
+	}
+	for personID, amount := range remaining {
+		if amount != 0 {
+			t.Errorf("person %d is left with %d cents", personID, amount)
+		}
+	}
+}
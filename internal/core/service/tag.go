@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+	"github.com/edwins-leonardi/finaid-api/internal/core/port"
+)
+
+type tagService struct {
+	repo        port.TagRepository
+	expenseRepo port.ExpenseRepository
+	txManager   port.TxManager
+	auditRepo   port.AuditRepository
+}
+
+// NewTagService creates a new tag service
+func NewTagService(
+	repo port.TagRepository,
+	expenseRepo port.ExpenseRepository,
+	txManager port.TxManager,
+	auditRepo port.AuditRepository,
+) port.TagService {
+	return &tagService{
+		repo:        repo,
+		expenseRepo: expenseRepo,
+		txManager:   txManager,
+		auditRepo:   auditRepo,
+	}
+}
+
+func (s *tagService) Create(ctx context.Context, req *domain.CreateTagRequest) (*domain.Tag, error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Creating tag", "name", req.Name)
+
+	// Validate and sanitize input
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, requiredField("name")
+	}
+
+	tag := &domain.Tag{
+		Name:      name,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.checkNameAvailable(ctx, name, 0); err != nil {
+			return err
+		}
+		if err := s.repo.Create(ctx, tag); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditEntityTag, uint64(tag.ID), domain.AuditActionCreate, nil, tag)
+	})
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to create tag", "error", err, "name", name)
+		return nil, err
+	}
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Tag created successfully", "id", tag.ID, "name", tag.Name)
+	return tag, nil
+}
+
+func (s *tagService) GetByID(ctx context.Context, id int) (*domain.Tag, error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Getting tag by ID", "id", id)
+
+	if id <= 0 {
+		return nil, invalidID("id")
+	}
+
+	tag, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to get tag", "error", err, "id", id)
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+func (s *tagService) List(ctx context.Context, req *domain.ListTagsRequest) (*domain.Page[*domain.Tag], error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Listing tags", "cursor", req.Cursor, "limit", req.Limit)
+
+	limit, err := pageLimit(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	after, err := domain.DecodeCursor(req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read one more tag than asked to tell whether another page follows
+	tags, err := s.repo.List(ctx, after, limit+1)
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to list tags", "error", err)
+		return nil, err
+	}
+	page := newPage(tags, limit, tagCursor)
+
+	if req.IncludeTotal {
+		total, err := s.repo.Count(ctx)
+		if err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to count tags", "error", err)
+			return nil, err
+		}
+		page.TotalCount = &total
+	}
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Tags retrieved successfully", "count", len(page.Items), "has_more", page.HasMore)
+	return page, nil
+}
+
+func (s *tagService) Update(ctx context.Context, id int, req *domain.UpdateTagRequest) (*domain.Tag, error) {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Updating tag", "id", id, "name", req.Name)
+
+	if id <= 0 {
+		return nil, invalidID("id")
+	}
+
+	// Validate and sanitize input
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, requiredField("name")
+	}
+
+	// Check if tag exists
+	existingTag, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to get tag for update", "error", err, "id", id)
+		return nil, err
+	}
+
+	// Reject updates based on a stale version
+	if req.Version != 0 && existingTag.Version != req.Version {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Stale tag version", "id", id, "version", req.Version, "current_version", existingTag.Version)
+		return nil, domain.ErrPreconditionFailed
+	}
+
+	// Snapshot the current state before changing it
+	before := *existingTag
+
+	// Update fields
+	existingTag.Name = name
+	existingTag.UpdatedAt = time.Now()
+	existingTag.Version = req.Version
+
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.checkNameAvailable(ctx, name, id); err != nil {
+			return err
+		}
+		if err := s.repo.Update(ctx, existingTag); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditEntityTag, uint64(id), domain.AuditActionUpdate, before, existingTag)
+	})
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to update tag", "error", err, "id", id)
+		return nil, err
+	}
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Tag updated successfully", "id", id, "name", name)
+	return existingTag, nil
+}
+
+func (s *tagService) Delete(ctx context.Context, id int, version int) error {
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Deleting tag", "id", id)
+
+	if id <= 0 {
+		return invalidID("id")
+	}
+
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Check if tag exists, within the transaction deleting it
+		existingTag, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to get tag for deletion", "error", err, "id", id)
+			return err
+		}
+
+		// Reject deletions based on a stale version
+		if version != 0 && existingTag.Version != version {
+			domain.LoggerFromContext(ctx).ErrorContext(ctx, "Stale tag version", "id", id, "version", version, "current_version", existingTag.Version)
+			return domain.ErrPreconditionFailed
+		}
+
+		// Untag the expenses first, the tag disappears from all of them at once
+		if err := s.expenseRepo.RemoveTag(ctx, id); err != nil {
+			return err
+		}
+		if err := s.repo.Delete(ctx, id, version); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditEntityTag, uint64(id), domain.AuditActionDelete, existingTag, nil)
+	})
+	if err != nil {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Failed to delete tag", "error", err, "id", id)
+		return err
+	}
+
+	domain.LoggerFromContext(ctx).InfoContext(ctx, "Tag deleted successfully", "id", id)
+	return nil
+}
+
+// checkNameAvailable rejects a tag name already used by another tag, ignoring case
+func (s *tagService) checkNameAvailable(ctx context.Context, name string, id int) error {
+	tag, err := s.repo.GetByName(ctx, name)
+	if errors.Is(err, domain.ErrDataNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if tag.ID != id {
+		domain.LoggerFromContext(ctx).ErrorContext(ctx, "Tag name already used", "name", name, "tag_id", tag.ID)
+		return domain.ErrConflictingData
+	}
+	return nil
+}
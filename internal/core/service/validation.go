@@ -0,0 +1,55 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/edwins-leonardi/finaid-api/internal/core/domain"
+)
+
+// invalidID returns the validation error of an ID field that is not positive
+func invalidID(field string) error {
+	return domain.InvalidField(field, "min", "must be at least 1")
+}
+
+// invalidDate returns the validation error of a date field not formatted as YYYY-MM-DD
+func invalidDate(field string) error {
+	return domain.InvalidField(field, "date", "must be a date formatted as YYYY-MM-DD")
+}
+
+// nullField returns the validation error of a patch setting a required field to null
+func nullField(field string) error {
+	return domain.InvalidField(field, "required", "must not be null")
+}
+
+// checkIDs validates that every ID of a list field is positive
+func checkIDs(field string, ids []int) error {
+	for i, id := range ids {
+		if id <= 0 {
+			return invalidID(fmt.Sprintf("%s[%d]", field, i))
+		}
+	}
+	return nil
+}
+
+// errSubCategoryOutsideCategory is the validation error of a subcategory used with another category than its own
+var errSubCategoryOutsideCategory = domain.InvalidField("subcategory_id", "category", "must belong to the category")
+
+// requiredField returns the validation error of a required field left empty
+func requiredField(field string) error {
+	return domain.InvalidField(field, "required", "is required")
+}
+
+// nestedFields prefixes the fields of a validation error with the path of the object holding them
+func nestedFields(prefix string, err error) error {
+	var validationErr *domain.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+	fields := make([]domain.FieldError, len(validationErr.Fields))
+	for i, field := range validationErr.Fields {
+		field.Field = prefix + "." + field.Field
+		fields[i] = field
+	}
+	return &domain.ValidationError{Fields: fields}
+}